@@ -31,10 +31,12 @@ import (
 	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
 	"github.com/bluenviron/gortsplib/v4/pkg/mikey"
+	"github.com/bluenviron/gortsplib/v4/pkg/onvif"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtcpreceiver"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtcpsender"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtptime"
 	"github.com/bluenviron/gortsplib/v4/pkg/sdp"
+	"github.com/bluenviron/gortsplib/v4/pkg/ulpfec"
 )
 
 const (
@@ -199,10 +201,12 @@ func prepareForAnnounce(
 	desc *description.Session,
 	announceData map[*description.Media]*clientAnnounceDataMedia,
 	secure bool,
+	rtcpMux bool,
 ) error {
 	for i, m := range desc.Medias {
 		m.Control = "trackID=" + strconv.FormatInt(int64(i), 10)
 		m.Secure = secure
+		m.RTCPMux = rtcpMux
 
 		if secure {
 			announceDataMedia := announceData[m]
@@ -295,16 +299,15 @@ type announceReq struct {
 }
 
 type setupReq struct {
-	baseURL  *base.URL
-	media    *description.Media
-	rtpPort  int
-	rtcpPort int
-	res      chan clientRes
+	baseURL *base.URL
+	media   *description.Media
+	options *ClientSetupOptions
+	res     chan clientRes
 }
 
 type playReq struct {
-	ra  *headers.Range
-	res chan clientRes
+	options *ClientPlayOptions
+	res     chan clientRes
 }
 
 type recordReq struct {
@@ -327,6 +330,62 @@ type ClientOnRequestFunc func(*base.Request)
 // ClientOnResponseFunc is the prototype of Client.OnResponse.
 type ClientOnResponseFunc func(*base.Response)
 
+// ClientOnRedirectFunc is the prototype of Client.OnRedirect.
+type ClientOnRedirectFunc func(location *base.URL)
+
+// ClientOnPlayNotifyFunc is the prototype of Client.OnPlayNotify.
+type ClientOnPlayNotifyFunc func(reason headers.NotifyReason, req *base.Request)
+
+// ClientSetupOptions contains options of a SETUP request.
+type ClientSetupOptions struct {
+	// (optional) local RTP and RTCP ports, used only if transport is UDP.
+	// If they are zero, they are chosen automatically.
+	RTPPort  int
+	RTCPPort int
+
+	// (optional) multicast destination, used only if transport is UDP-multicast.
+	// It defaults to the one chosen by the server.
+	MulticastDestination *net.IP
+
+	// (optional) multicast RTP and RTCP ports, used only if transport is UDP-multicast.
+	// They default to the ones chosen by the server.
+	MulticastPorts *[2]int
+
+	// (optional) multicast TTL, used only if transport is UDP-multicast.
+	// It defaults to the one chosen by the server.
+	MulticastTTL *uint
+}
+
+// ClientPlayOptions contains options of a PLAY request.
+type ClientPlayOptions struct {
+	// (optional) range of the stream to play.
+	// It defaults to the beginning of the stream.
+	Range *headers.Range
+
+	// (optional) playback scale, used for fast forward, slow motion and reverse playback.
+	Scale *headers.Scale
+
+	// (optional) delivery speed.
+	Speed *headers.Speed
+
+	// (optional) seek policy, sent with RTSP 2.0 only.
+	// It is replaced by the one chosen by the server, if any.
+	SeekStyle *headers.SeekStyle
+
+	// (optional) ONVIF replay rate control.
+	// When false, the server sends data as fast as possible.
+	RateControl *headers.RateControl
+
+	// (optional) ONVIF replay frame filter.
+	Frames *headers.Frames
+
+	// (optional) ONVIF replay immediate flag.
+	Immediate *headers.Immediate
+}
+
+// ClientOnKeyFrameRequestFunc is the prototype of Client.OnKeyFrameRequest.
+type ClientOnKeyFrameRequestFunc func(medi *description.Media, forma format.Format)
+
 // ClientOnTransportSwitchFunc is the prototype of Client.OnTransportSwitch.
 type ClientOnTransportSwitchFunc func(err error)
 
@@ -384,6 +443,11 @@ type Client struct {
 	// If nil, it is chosen automatically (first UDP, then, if it fails, TCP).
 	// It defaults to nil.
 	Transport *Transport
+	// tunnel RTSP into another protocol (none, HTTP or WebSocket).
+	// When a tunnel is in use, Transport must be nil or TCP.
+	// With the "rtsps" scheme, the tunnel is established with HTTPS / WSS.
+	// It defaults to TunnelNone.
+	Tunnel Tunnel
 	// If the client is reading with UDP, it must receive
 	// at least a packet within this timeout, otherwise it switches to TCP.
 	// It defaults to 3 seconds.
@@ -395,9 +459,35 @@ type Client struct {
 	// This must be less than the UDP MTU (1472 bytes).
 	// It defaults to 1472.
 	MaxPacketSize int
+	// number of outgoing RTP packets that are kept in order to be retransmitted
+	// when requested by readers with NACKs. It is used only when the media
+	// description contains a RTX format (RFC 4588).
+	// It defaults to 1024.
+	RetransmissionBufferSize int
+	// number of outgoing RTP packets protected by each FEC packet.
+	// It is used only when the description contains a FEC group
+	// with a ULPFEC format (RFC 5109).
+	// It defaults to 8.
+	FECGroupSize int
+	// request the server to multiplex RTP and RTCP packets on a single UDP port (RFC 5761).
+	// When reading, multiplexing is requested only for medias that advertise it.
+	// When publishing, medias are advertised with the rtcp-mux attribute.
+	// If the server does not support it, a port pair is used.
+	RTCPMux bool
+	// send empty RTP and RTCP packets to the server right after every SETUP
+	// with the UDP transport, in order to open NAT bindings in advance.
+	UDPHolePunching bool
+	// period of empty RTP and RTCP packets that are sent to the server
+	// while playing with the UDP transport, in order to keep NAT bindings open.
+	// It defaults to zero, that means that keep-alives are disabled.
+	UDPKeepAlivePeriod time.Duration
 	// user agent header.
 	// It defaults to "gortsplib"
 	UserAgent string
+	// try to use RTSP 2.0 and fall back to RTSP 1.0
+	// if the server doesn't support it.
+	// It defaults to false.
+	TryRTSP2 bool
 	// disable automatic RTCP sender reports.
 	DisableRTCPSenderReports bool
 	// explicitly request back channels to the server.
@@ -434,6 +524,15 @@ type Client struct {
 	OnServerResponse ClientOnResponseFunc
 	// called when the transport protocol changes.
 	OnTransportSwitch ClientOnTransportSwitchFunc
+	// called when the server requests to move to another location (REDIRECT).
+	// After this, the client tears down the session and sets it up again
+	// against the new location.
+	OnRedirect ClientOnRedirectFunc
+	// called when the server sends a notification about the stream (PLAY_NOTIFY),
+	// for instance when the end of the stream is reached.
+	OnPlayNotify ClientOnPlayNotifyFunc
+	// called when the server requests a key frame (RTCP PLI or FIR) while recording.
+	OnKeyFrameRequest ClientOnKeyFrameRequestFunc
 	// called when the client detects lost packets.
 	//
 	// Deprecated: replaced by OnPacketsLost
@@ -451,6 +550,8 @@ type Client struct {
 	senderReportPeriod   time.Duration
 	receiverReportPeriod time.Duration
 	checkTimeoutPeriod   time.Duration
+	// read packets with the UDP transport in batches (recvmmsg, Linux only).
+	udpBatchIO bool
 
 	ctx                  context.Context
 	ctxCancel            func()
@@ -460,11 +561,13 @@ type Client struct {
 	session              string
 	sender               *auth.Sender
 	cseq                 int
+	version              base.Version
 	optionsSent          bool
 	useGetParameter      bool
 	lastDescribeURL      *base.URL
 	lastDescribeDesc     *description.Session
 	baseURL              *base.URL
+	announceDesc         *description.Session                            // record
 	announceData         map[*description.Media]*clientAnnounceDataMedia // record
 	effectiveTransport   *Transport
 	effectiveSecure      bool
@@ -472,12 +575,15 @@ type Client struct {
 	stdChannelSetupped   bool
 	setuppedMedias       map[*description.Media]*clientMedia
 	tcpCallbackByChannel map[int]readFunc
-	lastRange            *headers.Range
+	lastPlayOptions      ClientPlayOptions
+	pendingRedirect      *base.Request
+	redirectedControls   map[*description.Media]string
 	checkTimeoutTimer    *time.Timer
 	checkTimeoutInitial  bool
 	tcpLastFrameTime     *int64
 	keepAlivePeriod      time.Duration
 	keepAliveTimer       *time.Timer
+	udpKeepAliveTimer    *time.Timer
 	closeError           error
 	writer               *asyncProcessor
 	writerMutex          sync.RWMutex
@@ -533,9 +639,26 @@ func (c *Client) Start2() error {
 	} else if c.MaxPacketSize > udpMaxPayloadSize {
 		return fmt.Errorf("MaxPacketSize must be less than %d", udpMaxPayloadSize)
 	}
+	if c.RetransmissionBufferSize == 0 {
+		c.RetransmissionBufferSize = 1024
+	}
+	if c.FECGroupSize == 0 {
+		c.FECGroupSize = 8
+	} else if c.FECGroupSize < 0 || c.FECGroupSize > ulpfec.MaxGroupSize {
+		return fmt.Errorf("FECGroupSize must be less than or equal to %d", ulpfec.MaxGroupSize)
+	}
 	if c.UserAgent == "" {
 		c.UserAgent = clientUserAgent
 	}
+	c.version = c.initialVersion()
+	if c.Tunnel != TunnelNone {
+		if c.Transport == nil {
+			v := TransportTCP
+			c.Transport = &v
+		} else if *c.Transport != TransportTCP {
+			return fmt.Errorf("tunneling can be used with the TCP transport only")
+		}
+	}
 
 	// system functions
 	if c.DialContext == nil {
@@ -567,6 +690,18 @@ func (c *Client) Start2() error {
 			log.Println(err.Error())
 		}
 	}
+	if c.OnRedirect == nil {
+		c.OnRedirect = func(*base.URL) {
+		}
+	}
+	if c.OnPlayNotify == nil {
+		c.OnPlayNotify = func(headers.NotifyReason, *base.Request) {
+		}
+	}
+	if c.OnKeyFrameRequest == nil {
+		c.OnKeyFrameRequest = func(*description.Media, format.Format) {
+		}
+	}
 	if c.OnPacketLost != nil {
 		c.OnPacketsLost = func(lost uint64) {
 			c.OnPacketLost(liberrors.ErrClientRTPPacketsLost{Lost: uint(lost)}) //nolint:staticcheck
@@ -612,6 +747,7 @@ func (c *Client) Start2() error {
 	c.checkTimeoutTimer = emptyTimer()
 	c.keepAlivePeriod = 30 * time.Second
 	c.keepAliveTimer = emptyTimer()
+	c.udpKeepAliveTimer = emptyTimer()
 
 	if c.BytesReceived != nil {
 		c.bytesReceived = c.BytesReceived
@@ -699,6 +835,13 @@ func (c *Client) run() {
 
 func (c *Client) runInner() error {
 	for {
+		if c.pendingRedirect != nil {
+			err := c.doRedirect()
+			if err != nil {
+				return err
+			}
+		}
+
 		chReaderResponse := func() chan *base.Response {
 			if c.reader != nil {
 				return c.reader.chResponse
@@ -753,7 +896,7 @@ func (c *Client) runInner() error {
 			}
 
 		case req := <-c.chSetup:
-			res, err := c.doSetup(req.baseURL, req.media, req.rtpPort, req.rtcpPort)
+			res, err := c.doSetup(req.baseURL, req.media, req.options)
 			req.res <- clientRes{res: res, err: err}
 
 			if c.mustClose {
@@ -761,7 +904,7 @@ func (c *Client) runInner() error {
 			}
 
 		case req := <-c.chPlay:
-			res, err := c.doPlay(req.ra)
+			res, err := c.doPlay(req.options)
 			req.res <- clientRes{res: res, err: err}
 
 			if c.mustClose {
@@ -798,6 +941,13 @@ func (c *Client) runInner() error {
 			}
 			c.keepAliveTimer = time.NewTimer(c.keepAlivePeriod)
 
+		case <-c.udpKeepAliveTimer.C:
+			err := c.doUDPKeepAlive()
+			if err != nil {
+				return err
+			}
+			c.udpKeepAliveTimer = time.NewTimer(c.UDPKeepAlivePeriod)
+
 		case <-chWriterError:
 			return c.writer.stopError
 
@@ -857,7 +1007,30 @@ func (c *Client) waitResponse(requestCseqStr string) (*base.Response, error) {
 func (c *Client) handleServerRequest(req *base.Request) error {
 	c.OnServerRequest(req)
 
-	if req.Method != base.Options {
+	statusCode := base.StatusOK
+
+	switch req.Method {
+	case base.Options:
+
+	case base.Redirect:
+		if len(req.Header["Location"]) == 1 {
+			// the redirect is performed after the response is sent,
+			// outside of any other request.
+			c.pendingRedirect = req
+		} else {
+			statusCode = base.StatusBadRequest
+		}
+
+	case base.PlayNotify:
+		var reason headers.NotifyReason
+		err := reason.Unmarshal(req.Header["Notify-Reason"])
+		if err != nil {
+			statusCode = base.StatusBadRequest
+		} else {
+			c.OnPlayNotify(reason, req)
+		}
+
+	default:
 		return liberrors.ErrClientUnhandledMethod{Method: req.Method}
 	}
 
@@ -869,8 +1042,13 @@ func (c *Client) handleServerRequest(req *base.Request) error {
 		h["CSeq"] = cseq
 	}
 
+	if session, ok := req.Header["Session"]; ok {
+		h["Session"] = session
+	}
+
 	res := &base.Response{
-		StatusCode: base.StatusOK,
+		Version:    req.Version,
+		StatusCode: statusCode,
 		Header:     h,
 	}
 
@@ -924,6 +1102,7 @@ func (c *Client) reset() {
 	c.session = ""
 	c.sender = nil
 	c.cseq = 0
+	c.version = c.initialVersion()
 	c.optionsSent = false
 	c.useGetParameter = false
 	c.baseURL = nil
@@ -934,6 +1113,13 @@ func (c *Client) reset() {
 	c.tcpCallbackByChannel = nil
 }
 
+func (c *Client) initialVersion() base.Version {
+	if c.TryRTSP2 {
+		return base.Version20
+	}
+	return base.Version10
+}
+
 func (c *Client) checkState(allowed map[clientState]struct{}) error {
 	if _, ok := allowed[c.state]; ok {
 		return nil
@@ -967,7 +1153,7 @@ func (c *Client) trySwitchingProtocol() error {
 	}
 
 	for i, cm := range prevMedias {
-		_, err = c.doSetup(prevBaseURL, cm.media, 0, 0)
+		_, err = c.doSetup(prevBaseURL, cm.media, nil)
 		if err != nil {
 			return err
 		}
@@ -978,7 +1164,7 @@ func (c *Client) trySwitchingProtocol() error {
 		}
 	}
 
-	_, err = c.doPlay(c.lastRange)
+	_, err = c.doPlay(&c.lastPlayOptions)
 	if err != nil {
 		return err
 	}
@@ -986,14 +1172,146 @@ func (c *Client) trySwitchingProtocol() error {
 	return nil
 }
 
-func (c *Client) startTransportRoutines() {
-	c.timeDecoder = &rtptime.GlobalDecoder2{}
-	c.timeDecoder.Initialize()
+// doRedirect tears down the session and sets it up again against
+// the location provided by a REDIRECT request.
+func (c *Client) doRedirect() error {
+	req := c.pendingRedirect
+	c.pendingRedirect = nil
+
+	location, err := base.ParseURL(req.Header["Location"][0])
+	if err != nil {
+		return err
+	}
+
+	if c.Scheme == "rtsps" && location.Scheme != "rtsps" {
+		return fmt.Errorf("connection cannot be downgraded from RTSPS to RTSP")
+	}
+
+	if c.lastDescribeURL != nil && c.lastDescribeURL.User != nil {
+		location.User = c.lastDescribeURL.User
+	}
+
+	playOptions := c.lastPlayOptions
+	if v, ok := req.Header["Range"]; ok {
+		var tmp headers.Range
+		err = tmp.Unmarshal(v)
+		if err == nil {
+			playOptions.Range = &tmp
+		}
+	}
+
+	prevState := c.state
+	prevDesc := c.lastDescribeDesc
+	prevMedias := c.setuppedMedias
+	prevTransport := c.effectiveTransport
+
+	switch prevState {
+	case clientStatePreRecord, clientStateRecord:
+		return liberrors.ErrClientRedirectWhileRecording{}
+	}
+
+	c.OnRedirect(location)
+
+	c.reset()
+
+	c.Scheme = location.Scheme
+	c.Host = location.Host
+	c.effectiveTransport = prevTransport
+
+	if prevState == clientStateInitial {
+		return nil
+	}
+
+	if prevDesc == nil {
+		return liberrors.ErrClientRedirectMediasIncompatible{}
+	}
+
+	desc, _, err := c.doDescribe(location)
+	if err != nil {
+		return err
+	}
+
+	// medias are owned by the user, therefore the control attributes
+	// of the new location are stored separately.
+	c.redirectedControls = make(map[*description.Media]string)
+
+	// set up medias in the same order of the original description,
+	// reusing the original medias in order to preserve callbacks.
+	for i, medi := range prevDesc.Medias {
+		cm, ok := prevMedias[medi]
+		if !ok {
+			continue
+		}
+
+		if i >= len(desc.Medias) || !mediasAreCompatible(medi, desc.Medias[i]) {
+			return liberrors.ErrClientRedirectMediasIncompatible{}
+		}
+
+		c.redirectedControls[medi] = desc.Medias[i].Control
+
+		_, err = c.doSetup(desc.BaseURL, medi, nil)
+		if err != nil {
+			return err
+		}
+
+		c.setuppedMedias[medi].onPacketRTCP = cm.onPacketRTCP
+		for j, tr := range cm.formats {
+			c.setuppedMedias[medi].formats[j].onPacketRTP = tr.onPacketRTP
+		}
+	}
+
+	newDesc := *prevDesc
+	newDesc.BaseURL = desc.BaseURL
+	c.lastDescribeDesc = &newDesc
+
+	if prevState == clientStatePlay {
+		_, err = c.doPlay(&playOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mediasAreCompatible(a *description.Media, b *description.Media) bool {
+	if a.Type != b.Type {
+		return false
+	}
+
+	for _, forma := range a.Formats {
+		found := false
+		for _, formb := range b.Formats {
+			if forma.PayloadType() == formb.PayloadType() && forma.Codec() == formb.Codec() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Client) startTransportRoutines() error {
+	started := make([]*clientMedia, 0, len(c.setuppedMedias))
 
 	for _, cm := range c.setuppedMedias {
-		cm.start()
+		err := cm.start()
+		if err != nil {
+			for _, cm2 := range started {
+				cm2.stop()
+			}
+			return err
+		}
+		started = append(started, cm)
 	}
 
+	c.timeDecoder = &rtptime.GlobalDecoder2{}
+	c.timeDecoder.Initialize()
+
 	if *c.effectiveTransport == TransportTCP {
 		c.tcpFrame = &base.InterleavedFrame{}
 		c.tcpBuffer = make([]byte, c.MaxPacketSize+4)
@@ -1010,6 +1328,10 @@ func (c *Client) startTransportRoutines() {
 			c.checkTimeoutTimer = time.NewTimer(c.InitialUDPReadTimeout)
 			c.checkTimeoutInitial = true
 
+			if c.UDPKeepAlivePeriod != 0 {
+				c.udpKeepAliveTimer = time.NewTimer(c.UDPKeepAlivePeriod)
+			}
+
 		case TransportUDPMulticast:
 			c.checkTimeoutTimer = time.NewTimer(c.checkTimeoutPeriod)
 
@@ -1023,6 +1345,8 @@ func (c *Client) startTransportRoutines() {
 	if *c.effectiveTransport == TransportTCP {
 		c.reader.setAllowInterleavedFrames(true)
 	}
+
+	return nil
 }
 
 func (c *Client) stopTransportRoutines() {
@@ -1032,6 +1356,7 @@ func (c *Client) stopTransportRoutines() {
 
 	c.checkTimeoutTimer = emptyTimer()
 	c.keepAliveTimer = emptyTimer()
+	c.udpKeepAliveTimer = emptyTimer()
 
 	for _, cm := range c.setuppedMedias {
 		cm.stop()
@@ -1073,7 +1398,7 @@ func (c *Client) destroyWriter() {
 	c.writerMutex.Unlock()
 }
 
-func (c *Client) connOpen() error {
+func (c *Client) connOpen(u *base.URL) error {
 	if c.nconn != nil {
 		return nil
 	}
@@ -1085,25 +1410,43 @@ func (c *Client) connOpen() error {
 	dialCtx, dialCtxCancel := context.WithTimeout(c.ctx, c.ReadTimeout)
 	defer dialCtxCancel()
 
-	nconn, err := c.DialContext(dialCtx, "tcp", canonicalAddr(&base.URL{
-		Scheme: c.Scheme,
-		Host:   c.Host,
-	}))
-	if err != nil {
-		return err
-	}
+	var nconn net.Conn
+	var err error
+
+	switch c.Tunnel {
+	case TunnelHTTP:
+		nconn, err = c.dialHTTPTunnel(dialCtx, u)
+		if err != nil {
+			return err
+		}
 
-	if c.Scheme == "rtsps" {
-		tlsConfig := c.TLSConfig
-		if tlsConfig == nil {
-			tlsConfig = &tls.Config{}
+	case TunnelWebSocket:
+		nconn, err = c.dialWebSocket(dialCtx, u)
+		if err != nil {
+			return err
 		}
-		tlsConfig.ServerName = (&base.URL{
+
+	default:
+		nconn, err = c.DialContext(dialCtx, "tcp", canonicalAddr(&base.URL{
 			Scheme: c.Scheme,
 			Host:   c.Host,
-		}).Hostname()
+		}))
+		if err != nil {
+			return err
+		}
 
-		nconn = tls.Client(nconn, tlsConfig)
+		if c.Scheme == "rtsps" {
+			tlsConfig := c.TLSConfig
+			if tlsConfig == nil {
+				tlsConfig = &tls.Config{}
+			}
+			tlsConfig.ServerName = (&base.URL{
+				Scheme: c.Scheme,
+				Host:   c.Host,
+			}).Hostname()
+
+			nconn = tls.Client(nconn, tlsConfig)
+		}
 	}
 
 	c.nconn = nconn
@@ -1133,6 +1476,8 @@ func (c *Client) do(req *base.Request, skipResponse bool) (*base.Response, error
 		req.Header["Session"] = base.HeaderValue{c.session}
 	}
 
+	req.Version = c.version
+
 	c.cseq++
 	cseqStr := strconv.FormatInt(int64(c.cseq), 10)
 	req.Header["CSeq"] = base.HeaderValue{cseqStr}
@@ -1256,6 +1601,19 @@ func (c *Client) doCheckTimeout() error {
 	return nil
 }
 
+func (c *Client) doUDPKeepAlive() error {
+	for _, cm := range c.setuppedMedias {
+		if !cm.media.IsBackChannel && cm.udpRTPListener.writeAddr != nil {
+			err := cm.openFirewall()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 func (c *Client) doKeepAlive() error {
 	// some cameras do not reply to keepalives, do not wait for responses.
 	_, err := c.do(&base.Request{
@@ -1282,7 +1640,7 @@ func (c *Client) doOptions(u *base.URL) (*base.Response, error) {
 		return nil, err
 	}
 
-	err = c.connOpen()
+	err = c.connOpen(u)
 	if err != nil {
 		return nil, err
 	}
@@ -1291,6 +1649,21 @@ func (c *Client) doOptions(u *base.URL) (*base.Response, error) {
 		Method: base.Options,
 		URL:    u,
 	}, false)
+
+	if c.version == base.Version20 && !c.optionsSent && c.state == clientStateInitial &&
+		(err != nil || res.StatusCode == base.StatusRTSPVersionNotSupported || res.Version != base.Version20) {
+		// the server doesn't support RTSP 2.0, fall back to RTSP 1.0.
+		// some servers close the connection when they receive an unknown protocol version,
+		// therefore open a new one.
+		c.version = base.Version10
+
+		if err != nil || res.StatusCode == base.StatusRTSPVersionNotSupported {
+			c.doClose()
+			c.mustClose = false
+			return c.doOptions(u)
+		}
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -1333,7 +1706,7 @@ func (c *Client) doDescribe(u *base.URL) (*description.Session, *base.Response,
 		return nil, nil, err
 	}
 
-	err = c.connOpen()
+	err = c.connOpen(u)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1446,7 +1819,7 @@ func (c *Client) doAnnounce(u *base.URL, desc *description.Session) (*base.Respo
 		return nil, fmt.Errorf("recording with UDP multicast is not supported")
 	}
 
-	err = c.connOpen()
+	err = c.connOpen(u)
 	if err != nil {
 		return nil, err
 	}
@@ -1456,7 +1829,7 @@ func (c *Client) doAnnounce(u *base.URL, desc *description.Session) (*base.Respo
 		return nil, err
 	}
 
-	err = prepareForAnnounce(desc, announceData, c.Scheme == "rtsps")
+	err = prepareForAnnounce(desc, announceData, c.Scheme == "rtsps", c.RTCPMux)
 	if err != nil {
 		return nil, err
 	}
@@ -1486,6 +1859,7 @@ func (c *Client) doAnnounce(u *base.URL, desc *description.Session) (*base.Respo
 
 	c.baseURL = u.Clone()
 	c.state = clientStatePreRecord
+	c.announceDesc = desc
 	c.announceData = announceData
 
 	return res, nil
@@ -1507,9 +1881,12 @@ func (c *Client) Announce(u *base.URL, desc *description.Session) (*base.Respons
 func (c *Client) doSetup(
 	baseURL *base.URL,
 	medi *description.Media,
-	rtpPort int,
-	rtcpPort int,
+	options *ClientSetupOptions,
 ) (*base.Response, error) {
+	if options == nil {
+		options = &ClientSetupOptions{}
+	}
+
 	err := c.checkState(map[clientState]struct{}{
 		clientStateInitial:   {},
 		clientStatePrePlay:   {},
@@ -1519,7 +1896,7 @@ func (c *Client) doSetup(
 		return nil, err
 	}
 
-	err = c.connOpen()
+	err = c.connOpen(baseURL)
 	if err != nil {
 		return nil, err
 	}
@@ -1584,6 +1961,9 @@ func (c *Client) doSetup(
 		th.Protocol = headers.TransportProtocolUDP
 
 		if transport == TransportUDP {
+			rtpPort := options.RTPPort
+			rtcpPort := options.RTCPPort
+
 			if (rtpPort == 0 && rtcpPort != 0) ||
 				(rtpPort != 0 && rtcpPort == 0) {
 				cm.close()
@@ -1608,10 +1988,38 @@ func (c *Client) doSetup(
 
 			v1 := headers.TransportDeliveryUnicast
 			th.Delivery = &v1
-			th.ClientPorts = &[2]int{cm.udpRTPListener.port(), cm.udpRTCPListener.port()}
+
+			if c.version == base.Version20 {
+				th.DestAddr = transportAddrsFromPorts(nil, [2]int{cm.udpRTPListener.port(), cm.udpRTCPListener.port()}, false)
+			} else {
+				th.ClientPorts = &[2]int{cm.udpRTPListener.port(), cm.udpRTCPListener.port()}
+			}
+
+			// offer multiplexing only when the media advertises it (RFC 5761),
+			// and a port pair too, in order to fall back to it
+			// when the server does not support multiplexing.
+			th.RTCPMux = c.RTCPMux && medi.RTCPMux
 		} else {
 			v1 := headers.TransportDeliveryMulticast
 			th.Delivery = &v1
+			th.TTL = options.MulticastTTL
+
+			if c.version == base.Version20 {
+				switch {
+				case options.MulticastPorts != nil:
+					var ip net.IP
+					if options.MulticastDestination != nil {
+						ip = *options.MulticastDestination
+					}
+					th.DestAddr = transportAddrsFromPorts(ip, *options.MulticastPorts, false)
+
+				case options.MulticastDestination != nil:
+					th.DestAddr = []headers.TransportAddr{{Host: options.MulticastDestination.String()}}
+				}
+			} else {
+				th.Destination = options.MulticastDestination
+				th.Ports = options.MulticastPorts
+			}
 		}
 
 	case TransportTCP:
@@ -1622,7 +2030,16 @@ func (c *Client) doSetup(
 		th.InterleavedIDs = &[2]int{ch, ch + 1}
 	}
 
-	mediaURL, err := medi.URL(baseURL)
+	// after a redirect, use the control attribute of the new location
+	// on a copy of the media.
+	setupMedia := medi
+	if control, ok := c.redirectedControls[medi]; ok {
+		tmp := *medi
+		tmp.Control = control
+		setupMedia = &tmp
+	}
+
+	mediaURL, err := setupMedia.URL(baseURL)
 	if err != nil {
 		cm.close()
 		return nil, err
@@ -1685,7 +2102,7 @@ func (c *Client) doSetup(
 			c.effectiveTransport = &v
 			c.effectiveSecure = th.Secure
 
-			return c.doSetup(baseURL, medi, 0, 0)
+			return c.doSetup(baseURL, medi, nil)
 		}
 
 		return nil, liberrors.ErrClientBadStatusCode{Code: res.StatusCode, Message: res.StatusMessage}
@@ -1698,6 +2115,10 @@ func (c *Client) doSetup(
 		return nil, liberrors.ErrClientTransportHeaderInvalid{Err: err}
 	}
 
+	if res.Version == base.Version20 {
+		transportFillFromRTSP2(&thRes, false)
+	}
+
 	switch transport {
 	case TransportUDP, TransportUDPMulticast:
 		if thRes.Protocol == headers.TransportProtocolTCP {
@@ -1721,7 +2142,7 @@ func (c *Client) doSetup(
 					return nil, err
 				}
 
-				return c.doSetup(baseURL, medi, 0, 0)
+				return c.doSetup(baseURL, medi, nil)
 			}
 
 			return nil, liberrors.ErrClientServerRequestedTCP{}
@@ -1749,6 +2170,13 @@ func (c *Client) doSetup(
 			remoteIP = c.nconn.RemoteAddr().(*net.TCPAddr).IP
 		}
 
+		// RTCP packets are sent and received through the RTP listener.
+		if c.RTCPMux && thRes.RTCPMux {
+			cm.udpRTCPListener.close()
+			cm.udpRTCPListener = cm.udpRTPListener
+			cm.rtcpMux = true
+		}
+
 		if serverPortsValid {
 			if !c.AnyPortEnable {
 				cm.udpRTPListener.readPort = thRes.ServerPorts[0]
@@ -1761,7 +2189,7 @@ func (c *Client) doSetup(
 		}
 		cm.udpRTPListener.readIP = remoteIP
 
-		if serverPortsValid {
+		if serverPortsValid && !cm.rtcpMux {
 			if !c.AnyPortEnable {
 				cm.udpRTCPListener.readPort = thRes.ServerPorts[1]
 			}
@@ -1900,6 +2328,16 @@ func (c *Client) doSetup(
 
 	c.setuppedMedias[medi] = cm
 
+	// open NAT bindings before the server starts sending packets,
+	// in the same order in which medias are setupped.
+	if transport == TransportUDP && c.UDPHolePunching &&
+		!medi.IsBackChannel && cm.udpRTPListener.writeAddr != nil {
+		err = cm.openFirewall()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	c.baseURL = baseURL
 	c.effectiveTransport = &transport
 	c.effectiveSecure = th.Secure
@@ -1943,14 +2381,29 @@ func (c *Client) Setup(
 	rtpPort int,
 	rtcpPort int,
 ) (*base.Response, error) {
+	return c.SetupWithOptions(baseURL, media, &ClientSetupOptions{
+		RTPPort:  rtpPort,
+		RTCPPort: rtcpPort,
+	})
+}
+
+// SetupWithOptions sends a SETUP request with additional options.
+func (c *Client) SetupWithOptions(
+	baseURL *base.URL,
+	media *description.Media,
+	options *ClientSetupOptions,
+) (*base.Response, error) {
+	if options == nil {
+		options = &ClientSetupOptions{}
+	}
+
 	cres := make(chan clientRes)
 	select {
 	case c.chSetup <- setupReq{
-		baseURL:  baseURL,
-		media:    media,
-		rtpPort:  rtpPort,
-		rtcpPort: rtcpPort,
-		res:      cres,
+		baseURL: baseURL,
+		media:   media,
+		options: options,
+		res:     cres,
 	}:
 		res := <-cres
 		return res.res, res.err
@@ -1971,7 +2424,7 @@ func (c *Client) SetupAll(baseURL *base.URL, medias []*description.Media) error
 	return nil
 }
 
-func (c *Client) doPlay(ra *headers.Range) (*base.Response, error) {
+func (c *Client) doPlay(options *ClientPlayOptions) (*base.Response, error) {
 	err := c.checkState(map[clientState]struct{}{
 		clientStatePrePlay: {},
 	})
@@ -1980,9 +2433,17 @@ func (c *Client) doPlay(ra *headers.Range) (*base.Response, error) {
 	}
 
 	c.state = clientStatePlay
-	c.startTransportRoutines()
+
+	err = c.startTransportRoutines()
+	if err != nil {
+		c.state = clientStatePrePlay
+		return nil, err
+	}
+
 	c.createWriter()
 
+	ra := options.Range
+
 	// Range is mandatory in Parrot Streaming Server
 	if ra == nil {
 		ra = &headers.Range{
@@ -1996,8 +2457,36 @@ func (c *Client) doPlay(ra *headers.Range) (*base.Response, error) {
 		"Range": ra.Marshal(),
 	}
 
+	if options.Scale != nil {
+		header["Scale"] = options.Scale.Marshal()
+	}
+
+	if options.Speed != nil {
+		header["Speed"] = options.Speed.Marshal()
+	}
+
+	if options.SeekStyle != nil && c.version == base.Version20 {
+		header["Seek-Style"] = options.SeekStyle.Marshal()
+	}
+
+	if options.RateControl != nil {
+		header["Rate-Control"] = options.RateControl.Marshal()
+	}
+
+	if options.Frames != nil {
+		header["Frames"] = options.Frames.Marshal()
+	}
+
+	if options.Immediate != nil {
+		header["Immediate"] = options.Immediate.Marshal()
+	}
+
 	if c.backChannelSetupped {
-		header["Require"] = base.HeaderValue{"www.onvif.org/ver20/backchannel"}
+		header["Require"] = append(header["Require"], "www.onvif.org/ver20/backchannel")
+	}
+
+	if options.RateControl != nil || options.Frames != nil || options.Immediate != nil {
+		header["Require"] = append(header["Require"], "onvif-replay")
 	}
 
 	// when protocol is UDP,
@@ -2006,30 +2495,7 @@ func (c *Client) doPlay(ra *headers.Range) (*base.Response, error) {
 	if *c.effectiveTransport == TransportUDP {
 		for _, cm := range c.setuppedMedias {
 			if !cm.media.IsBackChannel && cm.udpRTPListener.writeAddr != nil {
-				buf, _ := (&rtp.Packet{Header: rtp.Header{Version: 2}}).Marshal()
-				if cm.srtpOutCtx != nil {
-					encr := make([]byte, cm.c.MaxPacketSize)
-					encr, err = cm.srtpOutCtx.encryptRTP(encr, buf, nil)
-					if err != nil {
-						return nil, err
-					}
-					buf = encr
-				}
-				err = cm.udpRTPListener.write(buf)
-				if err != nil {
-					return nil, err
-				}
-
-				buf, _ = (&rtcp.ReceiverReport{}).Marshal()
-				if cm.srtpOutCtx != nil {
-					encr := make([]byte, cm.c.MaxPacketSize)
-					encr, err = cm.srtpOutCtx.encryptRTCP(encr, buf, nil)
-					if err != nil {
-						return nil, err
-					}
-					buf = encr
-				}
-				err = cm.udpRTCPListener.write(buf)
+				err = cm.openFirewall()
 				if err != nil {
 					return nil, err
 				}
@@ -2058,9 +2524,24 @@ func (c *Client) doPlay(ra *headers.Range) (*base.Response, error) {
 		}
 	}
 
+	seekStyle := options.SeekStyle
+	if v, ok := res.Header["Seek-Style"]; ok {
+		var tmp headers.SeekStyle
+		err = tmp.Unmarshal(v)
+		if err != nil {
+			c.destroyWriter()
+			c.stopTransportRoutines()
+			c.state = clientStatePrePlay
+			return nil, liberrors.ErrClientSeekStyleHeaderInvalid{Err: err}
+		}
+		seekStyle = &tmp
+	}
+
 	c.startWriter()
 
-	c.lastRange = ra
+	c.lastPlayOptions = *options
+	c.lastPlayOptions.Range = ra
+	c.lastPlayOptions.SeekStyle = seekStyle
 
 	return res, nil
 }
@@ -2068,9 +2549,21 @@ func (c *Client) doPlay(ra *headers.Range) (*base.Response, error) {
 // Play sends a PLAY request.
 // This can be called only after Setup().
 func (c *Client) Play(ra *headers.Range) (*base.Response, error) {
+	return c.PlayWithOptions(&ClientPlayOptions{
+		Range: ra,
+	})
+}
+
+// PlayWithOptions sends a PLAY request with additional options.
+// This can be called only after Setup().
+func (c *Client) PlayWithOptions(options *ClientPlayOptions) (*base.Response, error) {
+	if options == nil {
+		options = &ClientPlayOptions{}
+	}
+
 	cres := make(chan clientRes)
 	select {
-	case c.chPlay <- playReq{ra: ra, res: cres}:
+	case c.chPlay <- playReq{options: options, res: cres}:
 		res := <-cres
 		return res.res, res.err
 
@@ -2088,7 +2581,13 @@ func (c *Client) doRecord() (*base.Response, error) {
 	}
 
 	c.state = clientStateRecord
-	c.startTransportRoutines()
+
+	err = c.startTransportRoutines()
+	if err != nil {
+		c.state = clientStatePreRecord
+		return nil, err
+	}
+
 	c.createWriter()
 
 	res, err := c.do(&base.Request{
@@ -2277,6 +2776,45 @@ func (c *Client) WritePacketRTCP(medi *description.Media, pkt rtcp.Packet) error
 	return cm.writePacketRTCP(pkt)
 }
 
+// RequestKeyFrame asks the server to send a key frame of a media,
+// by sending a RTCP Picture Loss Indication (PLI) for each format of the media.
+// This can be called only while playing.
+func (c *Client) RequestKeyFrame(medi *description.Media) error {
+	select {
+	case <-c.done:
+		return c.closeError
+	default:
+	}
+
+	c.writerMutex.RLock()
+	defer c.writerMutex.RUnlock()
+
+	// medias can't be changed while the writer is present.
+	if c.writer == nil {
+		return fmt.Errorf("stream is not playing")
+	}
+
+	cm, ok := c.setuppedMedias[medi]
+	if !ok {
+		return fmt.Errorf("media has not been setupped")
+	}
+
+	for _, cf := range cm.formats {
+		// when the SSRC of the source is still unknown, it is left to zero.
+		mediaSSRC, _ := cf.remoteSSRC()
+
+		err := cm.writePacketRTCP(&rtcp.PictureLossIndication{
+			SenderSSRC: cf.localSSRC,
+			MediaSSRC:  mediaSSRC,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // PacketPTS returns the PTS (presentation timestamp) of an incoming RTP packet.
 // It is computed by decoding the packet timestamp and sychronizing it with other tracks.
 //
@@ -2309,6 +2847,17 @@ func (c *Client) PacketNTP(medi *description.Media, pkt *rtp.Packet) (time.Time,
 	return ct.rtcpReceiver.PacketNTP(pkt.Timestamp)
 }
 
+// PacketReplayExtension returns the ONVIF replay RTP header extension of an incoming RTP packet.
+// It is filled by ONVIF servers when playing recordings.
+func (c *Client) PacketReplayExtension(pkt *rtp.Packet) (*onvif.ReplayExtension, bool) {
+	var e onvif.ReplayExtension
+	err := e.Unmarshal(&pkt.Header)
+	if err != nil {
+		return nil, false
+	}
+	return &e, true
+}
+
 // Stats returns client statistics.
 func (c *Client) Stats() *ClientStats {
 	return &ClientStats{
@@ -2434,10 +2983,14 @@ func (c *Client) Stats() *ClientStats {
 								}()
 
 								ret[fo.format] = StatsSessionFormat{ //nolint:dupl
-									RTPPacketsReceived: atomic.LoadUint64(fo.rtpPacketsReceived),
-									RTPPacketsSent:     atomic.LoadUint64(fo.rtpPacketsSent),
-									RTPPacketsLost:     atomic.LoadUint64(fo.rtpPacketsLost),
-									LocalSSRC:          fo.localSSRC,
+									RTPPacketsReceived:              atomic.LoadUint64(fo.rtpPacketsReceived),
+									RTPPacketsSent:                  atomic.LoadUint64(fo.rtpPacketsSent),
+									RTPPacketsLost:                  atomic.LoadUint64(fo.rtpPacketsLost),
+									RTPPacketsNACKed:                atomic.LoadUint64(fo.rtpPacketsNACKed),
+									RTPPacketsRetransmitted:         atomic.LoadUint64(fo.rtpPacketsRetrans),
+									RTPPacketsRetransmittedReceived: atomic.LoadUint64(fo.rtpPacketsRetransRecv),
+									RTPPacketsRecovered:             atomic.LoadUint64(fo.rtpPacketsRecovered),
+									LocalSSRC:                       fo.localSSRC,
 									RemoteSSRC: func() uint32 {
 										if v, ok := fo.remoteSSRC(); ok {
 											return v
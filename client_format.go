@@ -8,12 +8,15 @@ import (
 	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
 
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtcpreceiver"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtcpsender"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtplossdetector"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtpreorderer"
+	"github.com/bluenviron/gortsplib/v4/pkg/rtx"
+	"github.com/bluenviron/gortsplib/v4/pkg/ulpfec"
 )
 
 func clientPickLocalSSRC(cf *clientFormat) (uint32, error) {
@@ -51,10 +54,21 @@ type clientFormat struct {
 	tcpLossDetector       *rtplossdetector.LossDetector // play
 	rtcpReceiver          *rtcpreceiver.RTCPReceiver    // play
 	rtcpSender            *rtcpsender.RTCPSender        // record or back channel
+	rtxSender             *rtx.Sender                   // record or back channel
+	rtxReceiver           *rtx.Receiver                 // play
+	rtxTarget             *clientFormat                 // play, RTX format only
+	fecEncoder            *ulpfec.Encoder               // record
+	fecMedia              *description.Media            // record
+	fecDecoder            *ulpfec.Decoder               // play
+	fecTarget             *clientFormat                 // play, ULPFEC format only
 	writePacketRTPInQueue func([]byte) error
 	rtpPacketsReceived    *uint64
 	rtpPacketsSent        *uint64
 	rtpPacketsLost        *uint64
+	rtpPacketsNACKed      *uint64
+	rtpPacketsRetrans     *uint64
+	rtpPacketsRetransRecv *uint64
+	rtpPacketsRecovered   *uint64
 }
 
 func (cf *clientFormat) initialize() error {
@@ -71,11 +85,21 @@ func (cf *clientFormat) initialize() error {
 	cf.rtpPacketsReceived = new(uint64)
 	cf.rtpPacketsSent = new(uint64)
 	cf.rtpPacketsLost = new(uint64)
+	cf.rtpPacketsNACKed = new(uint64)
+	cf.rtpPacketsRetrans = new(uint64)
+	cf.rtpPacketsRetransRecv = new(uint64)
+	cf.rtpPacketsRecovered = new(uint64)
+
+	if cf.cm.c.state != clientStatePreRecord && !cf.cm.media.IsBackChannel {
+		if fecMedia, _ := findFECFormat(cf.cm.c.lastDescribeDesc, cf.cm.media); fecMedia != nil {
+			cf.fecDecoder = &ulpfec.Decoder{}
+		}
+	}
 
 	return nil
 }
 
-func (cf *clientFormat) start() {
+func (cf *clientFormat) start() error {
 	if cf.cm.udpRTPListener != nil {
 		cf.writePacketRTPInQueue = cf.writePacketRTPInQueueUDP
 	} else {
@@ -83,6 +107,34 @@ func (cf *clientFormat) start() {
 	}
 
 	if cf.cm.c.state == clientStateRecord || cf.cm.media.IsBackChannel {
+		if cf.cm.udpRTPListener != nil {
+			if rtxFormat := findRTXFormat(cf.cm.media, cf.format); rtxFormat != nil {
+				rtxSender := &rtx.Sender{
+					PayloadType: rtxFormat.PayloadType(),
+					SSRC:        cf.cm.formats[rtxFormat.PayloadType()].localSSRC,
+					BufferSize:  cf.cm.c.RetransmissionBufferSize,
+				}
+				err := rtxSender.Initialize()
+				if err != nil {
+					return err
+				}
+				cf.rtxSender = rtxSender
+			}
+
+			if fecMedia, fecFormat := findFECFormat(cf.cm.c.announceDesc, cf.cm.media); fecMedia != nil {
+				fecEncoder := &ulpfec.Encoder{
+					PayloadType: fecFormat.PayloadType(),
+					GroupSize:   cf.cm.c.FECGroupSize,
+				}
+				err := fecEncoder.Initialize()
+				if err != nil {
+					return err
+				}
+				cf.fecMedia = fecMedia
+				cf.fecEncoder = fecEncoder
+			}
+		}
+
 		cf.rtcpSender = &rtcpsender.RTCPSender{
 			ClockRate: cf.format.ClockRate(),
 			Period:    cf.cm.c.senderReportPeriod,
@@ -98,6 +150,12 @@ func (cf *clientFormat) start() {
 		if cf.cm.udpRTPListener != nil {
 			cf.udpReorderer = &rtpreorderer.Reorderer{}
 			cf.udpReorderer.Initialize()
+
+			if *cf.cm.c.effectiveTransport == TransportUDP && findRTXFormat(cf.cm.media, cf.format) != nil {
+				cf.rtxReceiver = &rtx.Receiver{
+					PayloadType: cf.format.PayloadType(),
+				}
+			}
 		} else {
 			cf.tcpLossDetector = &rtplossdetector.LossDetector{}
 		}
@@ -115,9 +173,12 @@ func (cf *clientFormat) start() {
 		}
 		err := cf.rtcpReceiver.Initialize()
 		if err != nil {
-			panic(err)
+			cf.rtcpReceiver = nil
+			return err
 		}
 	}
+
+	return nil
 }
 
 func (cf *clientFormat) stop() {
@@ -142,14 +203,62 @@ func (cf *clientFormat) remoteSSRC() (uint32, bool) {
 }
 
 func (cf *clientFormat) readPacketRTPUDP(pkt *rtp.Packet) {
+	if cf.rtxTarget != nil {
+		orig, err := cf.rtxTarget.rtxReceiver.Decode(pkt)
+		if err != nil {
+			cf.cm.onPacketRTPDecodeError(err)
+			return
+		}
+
+		atomic.AddUint64(cf.rtxTarget.rtpPacketsRetransRecv, 1)
+		cf.rtxTarget.readPacketRTPUDP(orig)
+		return
+	}
+
+	if cf.fecTarget != nil {
+		err := cf.fecTarget.fecDecoder.AddFEC(pkt)
+		if err != nil {
+			cf.cm.onPacketRTPDecodeError(err)
+			return
+		}
+		// do not return
+	}
+
+	now := cf.cm.c.timeNow()
+
+	cf.processPacketRTPUDP(pkt, now)
+
+	if cf.fecDecoder != nil {
+		recovered := cf.fecDecoder.Process(pkt)
+		atomic.AddUint64(cf.rtpPacketsRecovered, uint64(len(recovered)))
+
+		for _, pkt := range recovered {
+			cf.processPacketRTPUDP(pkt, now)
+		}
+	}
+
+	// request packets that are still missing after reordering and recovery.
+	if cf.rtxReceiver != nil {
+		missing := cf.rtxReceiver.ProcessPacket(pkt, cf.udpReorderer.Missing())
+		if missing != nil {
+			atomic.AddUint64(cf.rtpPacketsNACKed, uint64(len(missing)))
+
+			cf.cm.c.WritePacketRTCP(cf.cm.media, &rtcp.TransportLayerNack{ //nolint:errcheck
+				SenderSSRC: cf.localSSRC,
+				MediaSSRC:  pkt.SSRC,
+				Nacks:      rtcp.NackPairsFromSequenceNumbers(missing),
+			})
+		}
+	}
+}
+
+func (cf *clientFormat) processPacketRTPUDP(pkt *rtp.Packet, now time.Time) {
 	packets, lost := cf.udpReorderer.Process(pkt)
 	if lost != 0 {
 		cf.handlePacketsLost(uint64(lost))
 		// do not return
 	}
 
-	now := cf.cm.c.timeNow()
-
 	for _, pkt := range packets {
 		cf.handlePacketRTP(pkt, now)
 	}
@@ -201,6 +310,10 @@ func (cf *clientFormat) writePacketRTP(pkt *rtp.Packet, ntp time.Time) error {
 	}
 	buf = buf[:n]
 
+	if cf.rtxSender != nil {
+		cf.rtxSender.Add(pkt, ntp)
+	}
+
 	if cf.cm.srtpOutCtx != nil {
 		encr := make([]byte, cf.cm.c.MaxPacketSize)
 		encr, err = cf.cm.srtpOutCtx.encryptRTP(encr, buf, &pkt.Header)
@@ -217,6 +330,19 @@ func (cf *clientFormat) writePacketRTP(pkt *rtp.Packet, ntp time.Time) error {
 		return liberrors.ErrClientWriteQueueFull{}
 	}
 
+	if cf.fecEncoder != nil {
+		fecPkt, err := cf.fecEncoder.Encode(pkt)
+		if err != nil {
+			return err
+		}
+
+		if fecPkt != nil {
+			if fcm, ok := cf.cm.c.setuppedMedias[cf.fecMedia]; ok {
+				return fcm.formats[fecPkt.PayloadType].writePacketRTP(fecPkt, ntp)
+			}
+		}
+	}
+
 	return nil
 }
 
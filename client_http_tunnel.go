@@ -0,0 +1,138 @@
+package gortsplib
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
+)
+
+func generateHTTPTunnelCookie() (string, error) {
+	buf := make([]byte, 16)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func httpTunnelPath(u *base.URL) string {
+	if u == nil || u.Path == "" {
+		return "/"
+	}
+	if u.RawQuery != "" {
+		return u.Path + "?" + u.RawQuery
+	}
+	return u.Path
+}
+
+func (c *Client) dialHTTPTunnelLeg(ctx context.Context) (net.Conn, error) {
+	nconn, err := c.DialContext(ctx, "tcp", canonicalAddr(&base.URL{
+		Scheme: c.Scheme,
+		Host:   c.Host,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Scheme == "rtsps" {
+		tlsConfig := c.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.ServerName = (&base.URL{
+			Scheme: c.Scheme,
+			Host:   c.Host,
+		}).Hostname()
+
+		nconn = tls.Client(nconn, tlsConfig)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		nconn.SetDeadline(deadline)
+	}
+
+	return nconn, nil
+}
+
+// dialHTTPTunnel opens a RTSP-over-HTTP tunnel.
+// The GET leg is used to receive data, while the POST leg is used to send base64-encoded data.
+func (c *Client) dialHTTPTunnel(ctx context.Context, u *base.URL) (net.Conn, error) {
+	cookie, err := generateHTTPTunnelCookie()
+	if err != nil {
+		return nil, err
+	}
+
+	path := httpTunnelPath(u)
+
+	getConn, err := c.dialHTTPTunnelLeg(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = getConn.Write([]byte("GET " + path + " HTTP/1.0\r\n" +
+		"User-Agent: " + c.UserAgent + "\r\n" +
+		"x-sessioncookie: " + cookie + "\r\n" +
+		"Accept: " + httpTunnelContentType + "\r\n" +
+		"Pragma: no-cache\r\n" +
+		"Cache-Control: no-cache\r\n" +
+		"\r\n"))
+	if err != nil {
+		getConn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(getConn)
+
+	res, err := http.ReadResponse(br, nil)
+	if err != nil {
+		getConn.Close()
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		getConn.Close()
+		return nil, liberrors.ErrClientHTTPTunnelBadStatusCode{
+			Code:    res.StatusCode,
+			Message: http.StatusText(res.StatusCode),
+		}
+	}
+
+	postConn, err := c.dialHTTPTunnelLeg(ctx)
+	if err != nil {
+		getConn.Close()
+		return nil, err
+	}
+
+	_, err = postConn.Write([]byte("POST " + path + " HTTP/1.0\r\n" +
+		"User-Agent: " + c.UserAgent + "\r\n" +
+		"x-sessioncookie: " + cookie + "\r\n" +
+		"Content-Type: " + httpTunnelContentType + "\r\n" +
+		"Pragma: no-cache\r\n" +
+		"Cache-Control: no-cache\r\n" +
+		"Content-Length: 32767\r\n" +
+		"Expires: Sun, 9 Jan 1972 00:00:00 GMT\r\n" +
+		"\r\n"))
+	if err != nil {
+		getConn.Close()
+		postConn.Close()
+		return nil, err
+	}
+
+	getConn.SetDeadline(time.Time{})
+	postConn.SetDeadline(time.Time{})
+
+	return &httpTunnelConn{
+		readConn:  getConn,
+		writeConn: postConn,
+		r:         br,
+		w:         &base64Writer{w: postConn},
+	}, nil
+}
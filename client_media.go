@@ -12,6 +12,7 @@ import (
 	"github.com/pion/rtp"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
 )
 
@@ -26,7 +27,8 @@ type clientMedia struct {
 	formats                map[uint8]*clientFormat
 	tcpChannel             int
 	udpRTPListener         *clientUDPListener
-	udpRTCPListener        *clientUDPListener
+	udpRTCPListener        *clientUDPListener // equal to udpRTPListener when rtcpMux is true
+	rtcpMux                bool
 	writePacketRTCPInQueue func([]byte) error
 	bytesReceived          *uint64
 	bytesSent              *uint64
@@ -96,7 +98,9 @@ func (cm *clientMedia) initialize() error {
 func (cm *clientMedia) close() {
 	if cm.udpRTPListener != nil {
 		cm.udpRTPListener.close()
-		cm.udpRTCPListener.close()
+		if !cm.rtcpMux {
+			cm.udpRTCPListener.close()
+		}
 	}
 }
 
@@ -175,14 +179,22 @@ func (cm *clientMedia) createUDPListeners(
 	}
 }
 
-func (cm *clientMedia) start() {
+func (cm *clientMedia) start() error {
 	if cm.udpRTPListener != nil {
 		cm.writePacketRTCPInQueue = cm.writePacketRTCPInQueueUDP
 
-		if cm.c.state == clientStateRecord || cm.media.IsBackChannel {
+		switch {
+		case cm.rtcpMux && (cm.c.state == clientStateRecord || cm.media.IsBackChannel):
+			cm.udpRTPListener.readFunc = rtcpMuxReadFunc(cm.readPacketRTPUDPRecord, cm.readPacketRTCPUDPRecord)
+
+		case cm.rtcpMux:
+			cm.udpRTPListener.readFunc = rtcpMuxReadFunc(cm.readPacketRTPUDPPlay, cm.readPacketRTCPUDPPlay)
+
+		case cm.c.state == clientStateRecord || cm.media.IsBackChannel:
 			cm.udpRTPListener.readFunc = cm.readPacketRTPUDPRecord
 			cm.udpRTCPListener.readFunc = cm.readPacketRTCPUDPRecord
-		} else {
+
+		default:
 			cm.udpRTPListener.readFunc = cm.readPacketRTPUDPPlay
 			cm.udpRTCPListener.readFunc = cm.readPacketRTCPUDPPlay
 		}
@@ -202,20 +214,84 @@ func (cm *clientMedia) start() {
 		}
 	}
 
+	started := make([]*clientFormat, 0, len(cm.formats))
+
+	for _, ct := range cm.formats {
+		err := ct.start()
+		if err != nil {
+			for _, ct2 := range started {
+				ct2.stop()
+			}
+			return err
+		}
+		started = append(started, ct)
+	}
+
 	for _, ct := range cm.formats {
-		ct.start()
+		switch forma := ct.format.(type) {
+		case *format.RTX:
+			if target, ok := cm.formats[forma.APT]; ok && target.rtxReceiver != nil {
+				ct.rtxTarget = target
+			}
+
+		case *format.ULPFEC:
+			if ct.udpReorderer != nil {
+				if protectedMedia := findFECProtectedMedia(cm.c.lastDescribeDesc, cm.media); protectedMedia != nil {
+					if pcm, ok := cm.c.setuppedMedias[protectedMedia]; ok {
+						if target := pcm.formats[protectedMedia.Formats[0].PayloadType()]; target.fecDecoder != nil {
+							ct.fecTarget = target
+						}
+					}
+				}
+			}
+		}
 	}
 
 	if cm.udpRTPListener != nil {
 		cm.udpRTPListener.start()
-		cm.udpRTCPListener.start()
+		if !cm.rtcpMux {
+			cm.udpRTCPListener.start()
+		}
+	}
+
+	return nil
+}
+
+// openFirewall opens the firewall, or keeps it open,
+// by sending empty packets to the remote part.
+func (cm *clientMedia) openFirewall() error {
+	buf, _ := (&rtp.Packet{Header: rtp.Header{Version: 2}}).Marshal()
+	if cm.srtpOutCtx != nil {
+		encr := make([]byte, cm.c.MaxPacketSize)
+		encr, err := cm.srtpOutCtx.encryptRTP(encr, buf, nil)
+		if err != nil {
+			return err
+		}
+		buf = encr
+	}
+	err := cm.udpRTPListener.write(buf)
+	if err != nil {
+		return err
+	}
+
+	buf, _ = (&rtcp.ReceiverReport{}).Marshal()
+	if cm.srtpOutCtx != nil {
+		encr := make([]byte, cm.c.MaxPacketSize)
+		encr, err = cm.srtpOutCtx.encryptRTCP(encr, buf, nil)
+		if err != nil {
+			return err
+		}
+		buf = encr
 	}
+	return cm.udpRTCPListener.write(buf)
 }
 
 func (cm *clientMedia) stop() {
 	if cm.udpRTPListener != nil {
 		cm.udpRTPListener.stop()
-		cm.udpRTCPListener.stop()
+		if !cm.rtcpMux {
+			cm.udpRTCPListener.stop()
+		}
 	}
 
 	for _, ct := range cm.formats {
@@ -232,6 +308,74 @@ func (cm *clientMedia) findFormatByRemoteSSRC(ssrc uint32) *clientFormat {
 	return nil
 }
 
+func (cm *clientMedia) findFormatByLocalSSRC(ssrc uint32) *clientFormat {
+	for _, cf := range cm.formats {
+		if cf.localSSRC == ssrc {
+			return cf
+		}
+	}
+	return nil
+}
+
+func (cm *clientMedia) handleKeyFrameRequest(pkt rtcp.Packet) {
+	ssrcs, ok := keyFrameRequestSSRCs(pkt)
+	if !ok {
+		return
+	}
+
+	for _, ssrc := range ssrcs {
+		cf := cm.findFormatByLocalSSRC(ssrc)
+
+		// some servers do not fill the media SSRC
+		if cf == nil && len(cm.formats) == 1 {
+			for _, cf2 := range cm.formats {
+				cf = cf2
+			}
+		}
+
+		if cf != nil {
+			cm.c.OnKeyFrameRequest(cm.media, cf.format)
+		}
+	}
+}
+
+func (cm *clientMedia) handleNACK(pkt rtcp.Packet) {
+	nack, ok := pkt.(*rtcp.TransportLayerNack)
+	if !ok {
+		return
+	}
+
+	cf := cm.findFormatByLocalSSRC(nack.MediaSSRC)
+	if cf == nil || cf.rtxSender == nil {
+		return
+	}
+
+	for _, pair := range nack.Nacks {
+		atomic.AddUint64(cf.rtpPacketsNACKed, uint64(len(pair.PacketList())))
+	}
+
+	rtxFormat := cm.formats[cf.rtxSender.PayloadType]
+
+	cm.c.writerMutex.RLock()
+	defer cm.c.writerMutex.RUnlock()
+
+	if cm.c.writer == nil {
+		return
+	}
+
+	rtxPkts := cf.rtxSender.ProcessNACK(nack)
+	atomic.AddUint64(cf.rtpPacketsRetrans, uint64(len(rtxPkts)))
+
+	// keep the timestamp of the original packet,
+	// in order not to alter the RTP/NTP mapping of sender reports.
+	for _, rtxPkt := range rtxPkts {
+		err := rtxFormat.writePacketRTP(rtxPkt.Packet, rtxPkt.NTP)
+		if err != nil {
+			return
+		}
+	}
+}
+
 func (cm *clientMedia) decodeRTP(payload []byte) (*rtp.Packet, error) {
 	if cm.srtpInCtx != nil {
 		var err error
@@ -340,6 +484,8 @@ func (cm *clientMedia) readPacketRTCPTCPRecord(payload []byte) bool {
 	atomic.AddUint64(cm.rtcpPacketsReceived, uint64(len(packets)))
 
 	for _, pkt := range packets {
+		cm.handleKeyFrameRequest(pkt)
+		cm.handleNACK(pkt)
 		cm.onPacketRTCP(pkt)
 	}
 
@@ -424,6 +570,8 @@ func (cm *clientMedia) readPacketRTCPUDPRecord(payload []byte) bool {
 	atomic.AddUint64(cm.rtcpPacketsReceived, uint64(len(packets)))
 
 	for _, pkt := range packets {
+		cm.handleKeyFrameRequest(pkt)
+		cm.handleNACK(pkt)
 		cm.onPacketRTCP(pkt)
 	}
 
@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/ipv4"
 
+	"github.com/bluenviron/gortsplib/v4/internal/ntp"
 	"github.com/bluenviron/gortsplib/v4/pkg/auth"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
 	"github.com/bluenviron/gortsplib/v4/pkg/conn"
@@ -24,6 +25,7 @@ import (
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 	"github.com/bluenviron/gortsplib/v4/pkg/mikey"
+	"github.com/bluenviron/gortsplib/v4/pkg/onvif"
 	"github.com/bluenviron/mediacommon/v2/pkg/codecs/mpeg4audio"
 )
 
@@ -60,6 +62,23 @@ func mediasToSDP(medias []*description.Media) []byte {
 	return byts
 }
 
+func mediasToSDPWithControl(medias []*description.Media, control string) []byte {
+	desc := &description.Session{}
+
+	for _, m := range medias {
+		m2 := *m
+		m2.Control = control
+		desc.Medias = append(desc.Medias, &m2)
+	}
+
+	byts, err := desc.Marshal(false)
+	if err != nil {
+		panic(err)
+	}
+
+	return byts
+}
+
 func mustMarshalPacketRTP(pkt *rtp.Packet) []byte {
 	byts, err := pkt.Marshal()
 	if err != nil {
@@ -2189,6 +2208,244 @@ func TestClientPlayRedirect(t *testing.T) {
 	}
 }
 
+func TestClientPlayServerRedirect(t *testing.T) {
+	l1, err := net.Listen("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer l1.Close()
+
+	l2, err := net.Listen("tcp", "localhost:8555")
+	require.NoError(t, err)
+	defer l2.Close()
+
+	medias := []*description.Media{testH264Media}
+
+	serveUntilPlay := func(conn *conn.Conn, host string, control string, ra base.HeaderValue) {
+		req, err2 := conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Options, req.Method)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Public": base.HeaderValue{strings.Join([]string{
+					string(base.Describe),
+					string(base.Setup),
+					string(base.Play),
+				}, ", ")},
+			},
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Describe, req.Method)
+		require.Equal(t, mustParseURL("rtsp://"+host+"/teststream"), req.URL)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Content-Type": base.HeaderValue{"application/sdp"},
+				"Content-Base": base.HeaderValue{"rtsp://" + host + "/teststream/"},
+			},
+			Body: mediasToSDPWithControl(medias, control),
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Setup, req.Method)
+		require.Equal(t, mustParseURL("rtsp://"+host+"/teststream/"+control), req.URL)
+
+		var inTH headers.Transport
+		err2 = inTH.Unmarshal(req.Header["Transport"])
+		require.NoError(t, err2)
+
+		th := headers.Transport{
+			Delivery:       deliveryPtr(headers.TransportDeliveryUnicast),
+			Protocol:       headers.TransportProtocolTCP,
+			InterleavedIDs: inTH.InterleavedIDs,
+		}
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Transport": th.Marshal(),
+			},
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Play, req.Method)
+		require.Equal(t, mustParseURL("rtsp://"+host+"/teststream/"), req.URL)
+		require.Equal(t, ra, req.Header["Range"])
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err2)
+	}
+
+	serverDone := make(chan struct{})
+	defer func() { <-serverDone }()
+
+	go func() {
+		defer close(serverDone)
+
+		nconn1, err2 := l1.Accept()
+		require.NoError(t, err2)
+		defer nconn1.Close()
+		conn1 := conn.NewConn(nconn1)
+
+		serveUntilPlay(conn1, "localhost:8554", "trackID=0", base.HeaderValue{"npt=0-"})
+
+		err2 = conn1.WriteRequest(&base.Request{
+			Method: base.Redirect,
+			URL:    mustParseURL("rtsp://localhost:8554/teststream"),
+			Header: base.Header{
+				"CSeq":     base.HeaderValue{"1"},
+				"Location": base.HeaderValue{"rtsp://localhost:8555/teststream"},
+				"Range":    base.HeaderValue{"npt=5-"},
+			},
+		})
+		require.NoError(t, err2)
+
+		res, err2 := conn1.ReadResponse()
+		require.NoError(t, err2)
+		require.Equal(t, base.StatusOK, res.StatusCode)
+
+		req, err2 := conn1.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Teardown, req.Method)
+
+		err2 = conn1.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err2)
+
+		nconn2, err2 := l2.Accept()
+		require.NoError(t, err2)
+		defer nconn2.Close()
+		conn2 := conn.NewConn(nconn2)
+
+		serveUntilPlay(conn2, "localhost:8555", "streamid=7", base.HeaderValue{"npt=5-"})
+
+		err2 = conn2.WriteInterleavedFrame(&base.InterleavedFrame{
+			Channel: 0,
+			Payload: testRTPPacketMarshaled,
+		}, make([]byte, 1024))
+		require.NoError(t, err2)
+
+		req, err2 = conn2.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Teardown, req.Method)
+		require.Equal(t, mustParseURL("rtsp://localhost:8555/teststream/"), req.URL)
+
+		err2 = conn2.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err2)
+	}()
+
+	redirected := make(chan *base.URL, 1)
+	packetRecv := make(chan struct{})
+
+	c := Client{
+		Transport: transportPtr(TransportTCP),
+		OnRedirect: func(location *base.URL) {
+			redirected <- location
+		},
+	}
+
+	u := mustParseURL("rtsp://localhost:8554/teststream")
+
+	err = c.Start(u.Scheme, u.Host)
+	require.NoError(t, err)
+	defer c.Close()
+
+	sd, _, err := c.Describe(u)
+	require.NoError(t, err)
+
+	err = c.SetupAll(sd.BaseURL, sd.Medias)
+	require.NoError(t, err)
+
+	c.OnPacketRTPAny(func(_ *description.Media, _ format.Format, _ *rtp.Packet) {
+		close(packetRecv)
+	})
+
+	_, err = c.Play(nil)
+	require.NoError(t, err)
+
+	require.Equal(t, mustParseURL("rtsp://localhost:8555/teststream"), <-redirected)
+	<-packetRecv
+
+	// the description owned by the user is not altered.
+	require.Equal(t, "trackID=0", sd.Medias[0].Control)
+	require.Equal(t, mustParseURL("rtsp://localhost:8554/teststream/"), sd.BaseURL)
+}
+
+func TestClientPlayNotify(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer l.Close()
+
+	serverDone := make(chan struct{})
+	defer func() { <-serverDone }()
+
+	go func() {
+		defer close(serverDone)
+
+		nconn, err2 := l.Accept()
+		require.NoError(t, err2)
+		defer nconn.Close()
+		conn := conn.NewConn(nconn)
+
+		req, err2 := conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Options, req.Method)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err2)
+
+		err2 = conn.WriteRequest(&base.Request{
+			Version: base.Version20,
+			Method:  base.PlayNotify,
+			URL:     mustParseURL("rtsp://localhost:8554/teststream"),
+			Header: base.Header{
+				"CSeq":          base.HeaderValue{"1"},
+				"Notify-Reason": base.HeaderValue{"end-of-stream"},
+				"Session":       base.HeaderValue{"12345678"},
+			},
+		})
+		require.NoError(t, err2)
+
+		res, err2 := conn.ReadResponse()
+		require.NoError(t, err2)
+		require.Equal(t, base.StatusOK, res.StatusCode)
+		require.Equal(t, base.Version20, res.Version)
+		require.Equal(t, base.HeaderValue{"12345678"}, res.Header["Session"])
+	}()
+
+	notified := make(chan headers.NotifyReason, 1)
+
+	c := Client{
+		OnPlayNotify: func(reason headers.NotifyReason, _ *base.Request) {
+			notified <- reason
+		},
+	}
+
+	err = c.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.Options(mustParseURL("rtsp://localhost:8554/teststream"))
+	require.NoError(t, err)
+
+	require.Equal(t, headers.NotifyReasonEndOfStream, <-notified)
+}
+
 func TestClientPlayRedirectPreventDecrypt(t *testing.T) {
 	cert, err := tls.X509KeyPair(serverCert, serverKey)
 	require.NoError(t, err)
@@ -2456,6 +2713,283 @@ func TestClientPlayPausePlay(t *testing.T) {
 	}
 }
 
+func TestClientPlayWithOptions(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer l.Close()
+
+	serverDone := make(chan struct{})
+	defer func() { <-serverDone }()
+
+	go func() {
+		defer close(serverDone)
+
+		nconn, err2 := l.Accept()
+		require.NoError(t, err2)
+		defer nconn.Close()
+		conn := conn.NewConn(nconn)
+
+		req, err2 := conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Options, req.Method)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Public": base.HeaderValue{strings.Join([]string{
+					string(base.Describe),
+					string(base.Setup),
+					string(base.Play),
+				}, ", ")},
+			},
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Describe, req.Method)
+
+		medias := []*description.Media{testH264Media}
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Content-Type": base.HeaderValue{"application/sdp"},
+				"Content-Base": base.HeaderValue{"rtsp://localhost:8554/teststream/"},
+			},
+			Body: mediasToSDP(medias),
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Setup, req.Method)
+
+		var inTH headers.Transport
+		err2 = inTH.Unmarshal(req.Header["Transport"])
+		require.NoError(t, err2)
+
+		th := headers.Transport{
+			Delivery:       deliveryPtr(headers.TransportDeliveryUnicast),
+			Protocol:       headers.TransportProtocolTCP,
+			InterleavedIDs: inTH.InterleavedIDs,
+		}
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Transport": th.Marshal(),
+			},
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Play, req.Method)
+		require.Equal(t, base.HeaderValue{"npt=10-"}, req.Header["Range"])
+		require.Equal(t, base.HeaderValue{"-2"}, req.Header["Scale"])
+		require.Equal(t, base.HeaderValue{"1.5"}, req.Header["Speed"])
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Scale": base.HeaderValue{"-2"},
+			},
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Teardown, req.Method)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err2)
+	}()
+
+	c := Client{
+		Transport: transportPtr(TransportTCP),
+	}
+
+	err = c.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer c.Close()
+
+	sd, _, err := c.Describe(mustParseURL("rtsp://localhost:8554/teststream"))
+	require.NoError(t, err)
+
+	err = c.SetupAll(sd.BaseURL, sd.Medias)
+	require.NoError(t, err)
+
+	scale := headers.Scale(-2)
+	speed := headers.Speed(1.5)
+
+	res, err := c.PlayWithOptions(&ClientPlayOptions{
+		Range: &headers.Range{
+			Value: &headers.RangeNPT{
+				Start: 10 * time.Second,
+			},
+		},
+		Scale: &scale,
+		Speed: &speed,
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.HeaderValue{"-2"}, res.Header["Scale"])
+}
+
+func TestClientPlayReplay(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer l.Close()
+
+	serverDone := make(chan struct{})
+	defer func() { <-serverDone }()
+
+	ntpTime := time.Date(2023, 11, 4, 10, 30, 40, 0, time.UTC)
+
+	go func() {
+		defer close(serverDone)
+
+		nconn, err2 := l.Accept()
+		require.NoError(t, err2)
+		defer nconn.Close()
+		conn := conn.NewConn(nconn)
+
+		req, err2 := conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Options, req.Method)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Public": base.HeaderValue{strings.Join([]string{
+					string(base.Describe),
+					string(base.Setup),
+					string(base.Play),
+				}, ", ")},
+			},
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Describe, req.Method)
+
+		medias := []*description.Media{testH264Media}
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Content-Type": base.HeaderValue{"application/sdp"},
+				"Content-Base": base.HeaderValue{"rtsp://localhost:8554/teststream/"},
+			},
+			Body: mediasToSDP(medias),
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Setup, req.Method)
+
+		var inTH headers.Transport
+		err2 = inTH.Unmarshal(req.Header["Transport"])
+		require.NoError(t, err2)
+
+		th := headers.Transport{
+			Delivery:       deliveryPtr(headers.TransportDeliveryUnicast),
+			Protocol:       headers.TransportProtocolTCP,
+			InterleavedIDs: inTH.InterleavedIDs,
+		}
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Transport": th.Marshal(),
+			},
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Play, req.Method)
+		require.Equal(t, base.HeaderValue{"onvif-replay"}, req.Header["Require"])
+		require.Equal(t, base.HeaderValue{"no"}, req.Header["Rate-Control"])
+		require.Equal(t, base.HeaderValue{"intra"}, req.Header["Frames"])
+		require.Equal(t, base.HeaderValue{"yes"}, req.Header["Immediate"])
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err2)
+
+		pkt := testRTPPacket
+		err2 = onvif.ReplayExtension{
+			NTP:        ntpTime,
+			CleanPoint: true,
+			CSeq:       4,
+		}.Marshal(&pkt.Header)
+		require.NoError(t, err2)
+
+		buf, err2 := pkt.Marshal()
+		require.NoError(t, err2)
+
+		err2 = conn.WriteInterleavedFrame(&base.InterleavedFrame{
+			Channel: 0,
+			Payload: buf,
+		}, make([]byte, 1024))
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Teardown, req.Method)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err2)
+	}()
+
+	c := Client{
+		Transport: transportPtr(TransportTCP),
+	}
+
+	err = c.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer c.Close()
+
+	sd, _, err := c.Describe(mustParseURL("rtsp://localhost:8554/teststream"))
+	require.NoError(t, err)
+
+	err = c.SetupAll(sd.BaseURL, sd.Medias)
+	require.NoError(t, err)
+
+	packetRecv := make(chan struct{})
+
+	c.OnPacketRTPAny(func(_ *description.Media, _ format.Format, pkt *rtp.Packet) {
+		e, ok := c.PacketReplayExtension(pkt)
+		require.True(t, ok)
+		require.True(t, ntpTime.Equal(e.NTP))
+		require.True(t, e.CleanPoint)
+		require.Equal(t, uint8(4), e.CSeq)
+		close(packetRecv)
+	})
+
+	rateControl := headers.RateControl(false)
+	immediate := headers.Immediate(true)
+
+	_, err = c.PlayWithOptions(&ClientPlayOptions{
+		RateControl: &rateControl,
+		Frames: &headers.Frames{
+			Type: headers.FramesTypeIntra,
+		},
+		Immediate: &immediate,
+	})
+	require.NoError(t, err)
+
+	<-packetRecv
+}
+
 func TestClientPlayRTCPReport(t *testing.T) {
 	reportReceived := make(chan struct{})
 
@@ -2570,7 +3104,7 @@ func TestClientPlayRTCPReport(t *testing.T) {
 
 		_, err2 = l2.WriteTo(mustMarshalPacketRTCP(&rtcp.SenderReport{
 			SSRC:        753621,
-			NTPTime:     ntpTimeGoToRTCP(time.Date(2017, 8, 12, 15, 30, 0, 0, time.UTC)),
+			NTPTime:     ntp.Encode(time.Date(2017, 8, 12, 15, 30, 0, 0, time.UTC)),
 			RTPTime:     54352,
 			PacketCount: 1,
 			OctetCount:  4,
@@ -3581,7 +4115,7 @@ func TestClientPlayPacketNTP(t *testing.T) {
 
 		_, err2 = l2.WriteTo(mustMarshalPacketRTCP(&rtcp.SenderReport{
 			SSRC:        753621,
-			NTPTime:     ntpTimeGoToRTCP(time.Date(2017, 8, 12, 15, 30, 0, 0, time.UTC)),
+			NTPTime:     ntp.Encode(time.Date(2017, 8, 12, 15, 30, 0, 0, time.UTC)),
 			RTPTime:     54352,
 			PacketCount: 1,
 			OctetCount:  4,
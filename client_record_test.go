@@ -15,11 +15,13 @@ import (
 	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
 
+	"github.com/bluenviron/gortsplib/v4/internal/ntp"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
 	"github.com/bluenviron/gortsplib/v4/pkg/conn"
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/gortsplib/v4/pkg/headers"
+	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
 	"github.com/bluenviron/gortsplib/v4/pkg/mikey"
 	"github.com/bluenviron/gortsplib/v4/pkg/sdp"
 )
@@ -69,11 +71,6 @@ var testRTCPPacket = rtcp.SourceDescription{
 
 var testRTCPPacketMarshaled = mustMarshalPacketRTCP(&testRTCPPacket)
 
-func ntpTimeGoToRTCP(v time.Time) uint64 {
-	s := uint64(v.UnixNano()) + 2208988800*1000000000
-	return (s/1000000000)<<32 | (s % 1000000000)
-}
-
 func record(c *Client, ur string, medias []*description.Media, cb func(*description.Media, rtcp.Packet)) error {
 	u, err := base.ParseURL(ur)
 	if err != nil {
@@ -1371,7 +1368,7 @@ func TestClientRecordRTCPReport(t *testing.T) {
 				require.Equal(t, []rtcp.Packet{
 					&rtcp.SenderReport{
 						SSRC:        packets[0].(*rtcp.SenderReport).SSRC,
-						NTPTime:     ntpTimeGoToRTCP(time.Date(1996, 2, 13, 14, 33, 5, 0, time.UTC)),
+						NTPTime:     ntp.Encode(time.Date(1996, 2, 13, 14, 33, 5, 0, time.UTC)),
 						RTPTime:     1300000 + 60*90000,
 						PacketCount: 1,
 						OctetCount:  1,
@@ -1555,3 +1552,119 @@ func TestClientRecordIgnoreTCPRTPPackets(t *testing.T) {
 
 	<-rtcpReceived
 }
+
+func TestClientRecordServerRedirect(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer l.Close()
+
+	serverDone := make(chan struct{})
+	defer func() { <-serverDone }()
+
+	go func() {
+		defer close(serverDone)
+
+		nconn, err2 := l.Accept()
+		require.NoError(t, err2)
+		defer nconn.Close()
+		conn := conn.NewConn(nconn)
+
+		req, err2 := conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Options, req.Method)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Public": base.HeaderValue{strings.Join([]string{
+					string(base.Announce),
+					string(base.Setup),
+					string(base.Record),
+				}, ", ")},
+			},
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Announce, req.Method)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Setup, req.Method)
+
+		var inTH headers.Transport
+		err2 = inTH.Unmarshal(req.Header["Transport"])
+		require.NoError(t, err2)
+
+		th := headers.Transport{
+			Delivery:       deliveryPtr(headers.TransportDeliveryUnicast),
+			Protocol:       headers.TransportProtocolTCP,
+			InterleavedIDs: inTH.InterleavedIDs,
+		}
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+			Header: base.Header{
+				"Transport": th.Marshal(),
+			},
+		})
+		require.NoError(t, err2)
+
+		req, err2 = conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Record, req.Method)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err2)
+
+		err2 = conn.WriteRequest(&base.Request{
+			Method: base.Redirect,
+			URL:    mustParseURL("rtsp://localhost:8554/teststream"),
+			Header: base.Header{
+				"CSeq":     base.HeaderValue{"1"},
+				"Location": base.HeaderValue{"rtsp://localhost:8555/teststream"},
+			},
+		})
+		require.NoError(t, err2)
+
+		res, err2 := conn.ReadResponse()
+		require.NoError(t, err2)
+		require.Equal(t, base.StatusOK, res.StatusCode)
+
+		for {
+			req, err2 = conn.ReadRequest()
+			if err2 != nil {
+				return
+			}
+
+			err2 = conn.WriteResponse(&base.Response{
+				StatusCode: base.StatusOK,
+			})
+			require.NoError(t, err2)
+		}
+	}()
+
+	c := Client{
+		Transport: transportPtr(TransportTCP),
+		OnRedirect: func(_ *base.URL) {
+			t.Error("should not happen")
+		},
+	}
+
+	medias := []*description.Media{testH264Media}
+
+	err = record(&c, "rtsp://localhost:8554/teststream", medias, nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	err = c.Wait()
+	require.Equal(t, liberrors.ErrClientRedirectWhileRecording{}, err)
+}
@@ -5,13 +5,17 @@ import (
 	"net"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/auth"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
 	"github.com/bluenviron/gortsplib/v4/pkg/conn"
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 )
 
 func mustParseURL(s string) *base.URL {
@@ -636,3 +640,340 @@ func TestClientRelativeContentBase(t *testing.T) {
 
 	require.Equal(t, "rtsp://localhost:8554/relative-content-base", desc.BaseURL.String())
 }
+
+func TestClientTunnel(t *testing.T) {
+	for _, ca := range []struct {
+		name        string
+		tunnel      Tunnel
+		scheme      string
+		httpAddress string
+	}{
+		{
+			"http rtsp address",
+			TunnelHTTP,
+			"rtsp",
+			"localhost:8554",
+		},
+		{
+			"http dedicated address",
+			TunnelHTTP,
+			"rtsp",
+			"localhost:8080",
+		},
+		{
+			"https dedicated address",
+			TunnelHTTP,
+			"rtsps",
+			"localhost:8080",
+		},
+		{
+			"websocket rtsp address",
+			TunnelWebSocket,
+			"rtsp",
+			"localhost:8554",
+		},
+		{
+			"websocket dedicated address",
+			TunnelWebSocket,
+			"rtsp",
+			"localhost:8080",
+		},
+		{
+			"secure websocket dedicated address",
+			TunnelWebSocket,
+			"rtsps",
+			"localhost:8080",
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			var stream *ServerStream
+
+			s := &Server{
+				Handler: &testServerHandler{
+					onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+						require.Equal(t, TransportTCP, ctx.Transport)
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+						go func() {
+							time.Sleep(500 * time.Millisecond)
+							err := stream.WritePacketRTP(stream.Description().Medias[0], &testRTPPacket)
+							require.NoError(t, err)
+						}()
+
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, nil
+					},
+				},
+				RTSPAddress: "localhost:8554",
+				HTTPAddress: ca.httpAddress,
+			}
+
+			if ca.scheme == "rtsps" {
+				cert, err := tls.X509KeyPair(serverCert, serverKey)
+				require.NoError(t, err)
+				s.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+			}
+
+			err := s.Start()
+			require.NoError(t, err)
+			defer s.Close()
+
+			stream = &ServerStream{
+				Server: s,
+				Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+			}
+			err = stream.Initialize()
+			require.NoError(t, err)
+			defer stream.Close()
+
+			u := mustParseURL(ca.scheme + "://" + ca.httpAddress + "/teststream")
+
+			c := Client{
+				Scheme:    u.Scheme,
+				Host:      u.Host,
+				Tunnel:    ca.tunnel,
+				TLSConfig: &tls.Config{InsecureSkipVerify: true},
+			}
+
+			err = c.Start2()
+			require.NoError(t, err)
+			defer c.Close()
+
+			sd, _, err := c.Describe(u)
+			require.NoError(t, err)
+
+			err = c.SetupAll(sd.BaseURL, sd.Medias)
+			require.NoError(t, err)
+
+			packetRecv := make(chan struct{})
+
+			c.OnPacketRTPAny(func(_ *description.Media, _ format.Format, pkt *rtp.Packet) {
+				require.Equal(t, &testRTPPacket, pkt)
+				close(packetRecv)
+			})
+
+			_, err = c.Play(nil)
+			require.NoError(t, err)
+
+			<-packetRecv
+		})
+	}
+}
+
+func TestClientTunnelErrorTransport(t *testing.T) {
+	c := Client{
+		Scheme:    "rtsp",
+		Host:      "localhost:8554",
+		Tunnel:    TunnelHTTP,
+		Transport: transportPtr(TransportUDP),
+	}
+
+	err := c.Start2()
+	require.EqualError(t, err, "tunneling can be used with the TCP transport only")
+}
+
+func TestClientRTSP2(t *testing.T) {
+	for _, ca := range []struct {
+		name         string
+		transport    Transport
+		disableRTSP2 bool
+	}{
+		{
+			"udp",
+			TransportUDP,
+			false,
+		},
+		{
+			"tcp",
+			TransportTCP,
+			false,
+		},
+		{
+			"fallback",
+			TransportTCP,
+			true,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			var stream *ServerStream
+
+			s := &Server{
+				Handler: &testServerHandler{
+					onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+						require.Equal(t, ca.transport, ctx.Transport)
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+						go func() {
+							time.Sleep(500 * time.Millisecond)
+							err := stream.WritePacketRTP(stream.Description().Medias[0], &testRTPPacket)
+							require.NoError(t, err)
+						}()
+
+						if ca.disableRTSP2 {
+							require.Nil(t, ctx.SeekStyle)
+							return &base.Response{
+								StatusCode: base.StatusOK,
+							}, nil
+						}
+
+						require.Equal(t, headers.SeekStyleRAP, *ctx.SeekStyle)
+
+						return &base.Response{
+							StatusCode: base.StatusOK,
+							Header: base.Header{
+								"Seek-Style": headers.SeekStyleNext.Marshal(),
+							},
+						}, nil
+					},
+				},
+				RTSPAddress:    "localhost:8554",
+				UDPRTPAddress:  "127.0.0.1:8000",
+				UDPRTCPAddress: "127.0.0.1:8001",
+				DisableRTSP2:   ca.disableRTSP2,
+			}
+
+			err := s.Start()
+			require.NoError(t, err)
+			defer s.Close()
+
+			stream = &ServerStream{
+				Server: s,
+				Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+			}
+			err = stream.Initialize()
+			require.NoError(t, err)
+			defer stream.Close()
+
+			var versions []base.Version
+
+			c := Client{
+				Transport: transportPtr(ca.transport),
+				TryRTSP2:  true,
+				OnResponse: func(res *base.Response) {
+					versions = append(versions, res.Version)
+				},
+			}
+
+			err = c.Start("rtsp", "localhost:8554")
+			require.NoError(t, err)
+			defer c.Close()
+
+			u := mustParseURL("rtsp://localhost:8554/teststream")
+
+			sd, _, err := c.Describe(u)
+			require.NoError(t, err)
+
+			err = c.SetupAll(sd.BaseURL, sd.Medias)
+			require.NoError(t, err)
+
+			packetRecv := make(chan struct{})
+
+			c.OnPacketRTPAny(func(_ *description.Media, _ format.Format, pkt *rtp.Packet) {
+				require.Equal(t, &testRTPPacket, pkt)
+				close(packetRecv)
+			})
+
+			seekStyle := headers.SeekStyleRAP
+
+			res, err := c.PlayWithOptions(&ClientPlayOptions{
+				SeekStyle: &seekStyle,
+			})
+			require.NoError(t, err)
+
+			<-packetRecv
+
+			if ca.disableRTSP2 {
+				require.Equal(t, headers.SeekStyleRAP, *c.lastPlayOptions.SeekStyle)
+				require.NotContains(t, res.Header, "Media-Properties")
+			} else {
+				require.Equal(t, headers.SeekStyleNext, *c.lastPlayOptions.SeekStyle)
+				require.Equal(t, base.HeaderValue{"No-Seeking, Time-Progressing, Time-Duration=0"}, res.Header["Media-Properties"])
+				require.Equal(t, base.HeaderValue{"npt"}, res.Header["Accept-Ranges"])
+				require.Equal(t, base.HeaderValue{""}, res.Header["Media-Range"])
+			}
+
+			if ca.disableRTSP2 {
+				require.Equal(t, []base.Version{
+					base.Version10, // OPTIONS with RTSP 2.0
+					base.Version10, // OPTIONS with RTSP 1.0
+					base.Version10,
+					base.Version10,
+					base.Version10,
+				}, versions)
+			} else {
+				require.Equal(t, []base.Version{
+					base.Version20,
+					base.Version20,
+					base.Version20,
+					base.Version20,
+				}, versions)
+			}
+		})
+	}
+}
+
+func TestClientRTSP2FallbackConnectionClosed(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer l.Close()
+
+	serverDone := make(chan struct{})
+	defer func() { <-serverDone }()
+
+	go func() {
+		defer close(serverDone)
+
+		// the first connection is closed since the request can't be parsed
+		nconn, err2 := l.Accept()
+		require.NoError(t, err2)
+
+		buf := make([]byte, 1024)
+		n, err2 := nconn.Read(buf)
+		require.NoError(t, err2)
+		require.Equal(t, "OPTIONS rtsp://localhost:8554/teststream RTSP/2.0\r\n", string(buf[:n])[:51])
+		nconn.Close()
+
+		nconn, err2 = l.Accept()
+		require.NoError(t, err2)
+		defer nconn.Close()
+		conn := conn.NewConn(nconn)
+
+		req, err2 := conn.ReadRequest()
+		require.NoError(t, err2)
+		require.Equal(t, base.Options, req.Method)
+		require.Equal(t, base.Version10, req.Version)
+
+		err2 = conn.WriteResponse(&base.Response{
+			StatusCode: base.StatusOK,
+		})
+		require.NoError(t, err2)
+	}()
+
+	c := Client{
+		TryRTSP2: true,
+	}
+
+	err = c.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.Options(mustParseURL("rtsp://localhost:8554/teststream"))
+	require.NoError(t, err)
+}
@@ -53,7 +53,17 @@ func (u *clientUDPListener) initialize() error {
 			return err
 		}
 
-		u.pc, err = multicast.NewSingleConn(intf, u.address, u.c.ListenPacket)
+		host, _, err := net.SplitHostPort(u.address)
+		if err != nil {
+			return err
+		}
+
+		// source-specific groups are joined by using the source announced by the server.
+		if multicast.IsSSM(net.ParseIP(host)) {
+			u.pc, err = multicast.NewSingleConnSSM(intf, u.address, u.multicastSourceIP, u.c.ListenPacket)
+		} else {
+			u.pc, err = multicast.NewSingleConn(intf, u.address, u.c.ListenPacket)
+		}
 		if err != nil {
 			return err
 		}
@@ -102,6 +112,11 @@ func (u *clientUDPListener) stop() {
 func (u *clientUDPListener) run() {
 	defer close(u.done)
 
+	if u.c.udpBatchIO && udpBatchSupported && !u.multicastEnable {
+		udpReadBatch(u.pc.(*net.UDPConn), u.processPacket) //nolint:errcheck
+		return
+	}
+
 	var buf []byte
 
 	createNewBuffer := func() {
@@ -116,27 +131,31 @@ func (u *clientUDPListener) run() {
 			return
 		}
 
-		uaddr := addr.(*net.UDPAddr)
-
-		if !u.readIP.Equal(uaddr.IP) {
-			continue
-		}
-
-		// in case of anyPortEnable, store the port of the first packet we receive.
-		// this reduces security issues
-		if u.c.AnyPortEnable && u.readPort == 0 {
-			u.readPort = uaddr.Port
-		} else if u.readPort != uaddr.Port {
-			continue
+		if u.processPacket(buf[:n], addr.(*net.UDPAddr)) {
+			createNewBuffer()
 		}
+	}
+}
 
-		now := u.c.timeNow()
-		atomic.StoreInt64(u.lastPacketTime, now.Unix())
+// processPacket processes a packet if it comes from the server.
+// It returns true if the buffer has been retained.
+func (u *clientUDPListener) processPacket(buf []byte, addr *net.UDPAddr) bool {
+	if !u.readIP.Equal(addr.IP) {
+		return false
+	}
 
-		if u.readFunc(buf[:n]) {
-			createNewBuffer()
-		}
+	// in case of anyPortEnable, store the port of the first packet we receive.
+	// this reduces security issues
+	if u.c.AnyPortEnable && u.readPort == 0 {
+		u.readPort = addr.Port
+	} else if u.readPort != addr.Port {
+		return false
 	}
+
+	now := u.c.timeNow()
+	atomic.StoreInt64(u.lastPacketTime, now.Unix())
+
+	return u.readFunc(buf)
 }
 
 func (u *clientUDPListener) write(payload []byte) error {
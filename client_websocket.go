@@ -0,0 +1,43 @@
+package gortsplib
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// dialWebSocket opens a RTSP-over-WebSocket connection.
+func (c *Client) dialWebSocket(ctx context.Context, u *base.URL) (net.Conn, error) {
+	wsScheme := "ws"
+	httpScheme := "http"
+	if c.Scheme == "rtsps" {
+		wsScheme = "wss"
+		httpScheme = "https"
+	}
+
+	config, err := websocket.NewConfig(wsScheme+"://"+c.Host+httpTunnelPath(u), httpScheme+"://"+c.Host)
+	if err != nil {
+		return nil, err
+	}
+	config.Protocol = []string{webSocketSubprotocol}
+	config.Header.Set("User-Agent", c.UserAgent)
+
+	nconn, err := c.dialHTTPTunnelLeg(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := websocket.NewClient(config, nconn)
+	if err != nil {
+		nconn.Close()
+		return nil, err
+	}
+
+	nconn.SetDeadline(time.Time{})
+
+	return newWebSocketConn(ws, nconn), nil
+}
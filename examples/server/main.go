@@ -91,15 +91,20 @@ func (sh *serverHandler) OnAnnounce(ctx *gortsplib.ServerHandlerOnAnnounceCtx) (
 	}
 
 	// create the stream and save the publisher
+	publisher := ctx.Session
 	sh.stream = &gortsplib.ServerStream{
 		Server: sh.server,
 		Desc:   ctx.Description,
+		// forward key frame requests of readers to the publisher
+		OnKeyFrameRequest: func(medi *description.Media, _ format.Format) {
+			publisher.RequestKeyFrame(medi) //nolint:errcheck
+		},
 	}
 	err := sh.stream.Initialize()
 	if err != nil {
 		panic(err)
 	}
-	sh.publisher = ctx.Session
+	sh.publisher = publisher
 
 	return &base.Response{
 		StatusCode: base.StatusOK,
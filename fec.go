@@ -0,0 +1,64 @@
+package gortsplib
+
+import (
+	"slices"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+)
+
+// fecGroupMedias returns the medias that are in the same FEC group of a media.
+func fecGroupMedias(desc *description.Session, medi *description.Media) []*description.Media {
+	if desc == nil || medi.ID == "" {
+		return nil
+	}
+
+	for _, group := range desc.FECGroups {
+		if !slices.Contains(group, medi.ID) {
+			continue
+		}
+
+		var ret []*description.Media
+		for _, m := range desc.Medias {
+			if m != medi && slices.Contains(group, m.ID) {
+				ret = append(ret, m)
+			}
+		}
+		return ret
+	}
+
+	return nil
+}
+
+// findFECFormat returns the media and the format that carry FEC packets protecting a media.
+// Protection is supported only when the media contains a single format,
+// since each format has its own sequence numbers.
+func findFECFormat(desc *description.Session, medi *description.Media) (*description.Media, *format.ULPFEC) {
+	if len(medi.Formats) != 1 {
+		return nil, nil
+	}
+
+	if _, ok := medi.Formats[0].(*format.ULPFEC); ok {
+		return nil, nil
+	}
+
+	for _, m := range fecGroupMedias(desc, medi) {
+		for _, forma := range m.Formats {
+			if fecFormat, ok := forma.(*format.ULPFEC); ok {
+				return m, fecFormat
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// findFECProtectedMedia returns the media protected by the FEC packets of a media.
+func findFECProtectedMedia(desc *description.Session, fecMedia *description.Media) *description.Media {
+	for _, m := range fecGroupMedias(desc, fecMedia) {
+		if m2, _ := findFECFormat(desc, m); m2 == fecMedia {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package gortsplib
+
+import (
+	"encoding/base64"
+	"io"
+	"net"
+	"time"
+)
+
+const (
+	httpTunnelContentType = "application/x-rtsp-tunnelled"
+)
+
+func isHTTPRequestPrefix(buf []byte) bool {
+	switch string(buf) {
+	case "GET ", "POST":
+		return true
+	}
+	return false
+}
+
+// base64ChunkReader decodes a stream made of independently-encoded base64 chunks,
+// as sent on the POST leg of a RTSP-over-HTTP tunnel.
+// Every quantum is decoded separately, since chunks can contain padding.
+type base64ChunkReader struct {
+	r io.Reader
+
+	readBuf []byte
+	enc     []byte
+	dec     []byte
+}
+
+func (r *base64ChunkReader) Read(p []byte) (int, error) {
+	if r.readBuf == nil {
+		r.readBuf = make([]byte, 4096)
+	}
+
+	for len(r.dec) == 0 {
+		n, err := r.r.Read(r.readBuf)
+
+		for _, b := range r.readBuf[:n] {
+			switch b {
+			case '\r', '\n', ' ', '\t':
+			default:
+				r.enc = append(r.enc, b)
+			}
+		}
+
+		quantaLen := (len(r.enc) / 4) * 4
+
+		for i := 0; i < quantaLen; i += 4 {
+			var tmp [3]byte
+			n2, err2 := base64.StdEncoding.Decode(tmp[:], r.enc[i:i+4])
+			if err2 != nil {
+				return 0, err2
+			}
+			r.dec = append(r.dec, tmp[:n2]...)
+		}
+
+		r.enc = r.enc[:copy(r.enc, r.enc[quantaLen:])]
+
+		if err != nil && len(r.dec) == 0 {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.dec)
+	r.dec = r.dec[:copy(r.dec, r.dec[n:])]
+
+	return n, nil
+}
+
+// base64Writer encodes every write into an independent base64 chunk.
+type base64Writer struct {
+	w io.Writer
+}
+
+func (w *base64Writer) Write(p []byte) (int, error) {
+	buf := make([]byte, base64.StdEncoding.EncodedLen(len(p)))
+	base64.StdEncoding.Encode(buf, p)
+
+	_, err := w.w.Write(buf)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// httpTunnelConn is a net.Conn that reads from a HTTP leg and writes to another HTTP leg.
+type httpTunnelConn struct {
+	readConn  net.Conn
+	writeConn net.Conn
+	r         io.Reader
+	w         io.Writer
+}
+
+// Read implements net.Conn.
+func (c *httpTunnelConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// Write implements net.Conn.
+func (c *httpTunnelConn) Write(p []byte) (int, error) {
+	return c.w.Write(p)
+}
+
+// Close implements net.Conn.
+func (c *httpTunnelConn) Close() error {
+	err1 := c.readConn.Close()
+	err2 := c.writeConn.Close()
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
+
+// LocalAddr implements net.Conn.
+func (c *httpTunnelConn) LocalAddr() net.Addr {
+	return c.readConn.LocalAddr()
+}
+
+// RemoteAddr implements net.Conn.
+func (c *httpTunnelConn) RemoteAddr() net.Addr {
+	return c.readConn.RemoteAddr()
+}
+
+// SetDeadline implements net.Conn.
+func (c *httpTunnelConn) SetDeadline(t time.Time) error {
+	err := c.readConn.SetReadDeadline(t)
+	if err != nil {
+		return err
+	}
+	return c.writeConn.SetWriteDeadline(t)
+}
+
+// SetReadDeadline implements net.Conn.
+func (c *httpTunnelConn) SetReadDeadline(t time.Time) error {
+	return c.readConn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline implements net.Conn.
+func (c *httpTunnelConn) SetWriteDeadline(t time.Time) error {
+	return c.writeConn.SetWriteDeadline(t)
+}
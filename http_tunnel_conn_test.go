@@ -0,0 +1,56 @@
+package gortsplib
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase64ChunkReader(t *testing.T) {
+	for _, ca := range []struct {
+		name string
+		enc  string
+		dec  []byte
+	}{
+		{
+			"single chunk",
+			"T1BUSU9OUw==",
+			[]byte("OPTIONS"),
+		},
+		{
+			"multiple padded chunks",
+			"T1BU" + "SU9OUw==" + "YWI=" + "Yw==",
+			[]byte("OPTIONSabc"),
+		},
+		{
+			"newlines",
+			"T1BU\r\nSU9O\r\nUw==\r\n",
+			[]byte("OPTIONS"),
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			r := &base64ChunkReader{r: bytes.NewReader([]byte(ca.enc))}
+			dec, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.Equal(t, ca.dec, dec)
+		})
+	}
+}
+
+func TestBase64WriterReader(t *testing.T) {
+	var buf bytes.Buffer
+	w := &base64Writer{w: &buf}
+
+	for _, chunk := range [][]byte{{1}, {2, 3}, {4, 5, 6}, {7, 8, 9, 10}} {
+		n, err := w.Write(chunk)
+		require.NoError(t, err)
+		require.Equal(t, len(chunk), n)
+	}
+
+	r := &base64ChunkReader{r: &buf}
+	dec, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, dec)
+}
@@ -0,0 +1,20 @@
+// Package ntp contains functions to convert between NTP and Go timestamps.
+package ntp
+
+import (
+	"time"
+)
+
+// Encode converts a time.Time into a NTP timestamp:
+// seconds since 1st January 1900,
+// higher 32 bits are the integer part, lower 32 bits are the fractional part.
+func Encode(v time.Time) uint64 {
+	s := uint64(v.UnixNano()) + 2208988800*1000000000
+	return (s/1000000000)<<32 | ((s%1000000000)<<32)/1000000000
+}
+
+// Decode converts a NTP timestamp into a time.Time.
+func Decode(v uint64) time.Time {
+	nano := int64((v>>32)*1000000000+((v&0xFFFFFFFF)*1000000000)>>32) - 2208988800*1000000000
+	return time.Unix(0, nano)
+}
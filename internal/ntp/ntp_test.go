@@ -0,0 +1,46 @@
+package ntp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var cases = []struct {
+	name string
+	dec  time.Time
+	enc  uint64
+}{
+	{
+		"a",
+		time.Date(2013, 4, 15, 11, 15, 17, 500000000, time.UTC).Local(),
+		0xd5165fc580000000,
+	},
+	{
+		"b",
+		time.Date(2008, 5, 20, 22, 15, 20, 0, time.UTC).Local(),
+		0xcbddcbf800000000,
+	},
+	{
+		"fraction",
+		time.Date(2008, 5, 20, 22, 15, 20, 250000000, time.UTC).Local(),
+		0xcbddcbf840000000,
+	},
+}
+
+func TestEncode(t *testing.T) {
+	for _, ca := range cases {
+		t.Run(ca.name, func(t *testing.T) {
+			require.Equal(t, ca.enc, Encode(ca.dec))
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	for _, ca := range cases {
+		t.Run(ca.name, func(t *testing.T) {
+			require.Equal(t, ca.dec, Decode(ca.enc))
+		})
+	}
+}
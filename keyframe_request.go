@@ -0,0 +1,23 @@
+package gortsplib
+
+import (
+	"github.com/pion/rtcp"
+)
+
+// keyFrameRequestSSRCs returns the SSRCs of the media sources
+// a key frame is requested for, if the packet is a PLI or a FIR.
+func keyFrameRequestSSRCs(pkt rtcp.Packet) ([]uint32, bool) {
+	switch pkt := pkt.(type) {
+	case *rtcp.PictureLossIndication:
+		return []uint32{pkt.MediaSSRC}, true
+
+	case *rtcp.FullIntraRequest:
+		ssrcs := make([]uint32, len(pkt.FIR))
+		for i, entry := range pkt.FIR {
+			ssrcs[i] = entry.SSRC
+		}
+		return ssrcs, true
+	}
+
+	return nil, false
+}
@@ -8,7 +8,6 @@ import (
 )
 
 const (
-	rtspProtocol10           = "RTSP/1.0"
 	requestMaxMethodLength   = 64
 	requestMaxURLLength      = 2048
 	requestMaxProtocolLength = 64
@@ -26,9 +25,11 @@ const (
 	Pause        Method = "PAUSE"
 	Play         Method = "PLAY"
 	Record       Method = "RECORD"
+	Redirect     Method = "REDIRECT"
 	Setup        Method = "SETUP"
 	SetParameter Method = "SET_PARAMETER"
 	Teardown     Method = "TEARDOWN"
+	PlayNotify   Method = "PLAY_NOTIFY"
 )
 
 // Request is a RTSP request.
@@ -39,6 +40,10 @@ type Request struct {
 	// request url
 	URL *URL
 
+	// protocol version.
+	// It defaults to RTSP/1.0.
+	Version Version
+
 	// map of header values
 	Header Header
 
@@ -81,8 +86,9 @@ func (req *Request) Unmarshal(br *bufio.Reader) error {
 	}
 	proto := byts[:len(byts)-1]
 
-	if string(proto) != rtspProtocol10 {
-		return fmt.Errorf("expected '%s', got %v", rtspProtocol10, proto)
+	err = req.Version.unmarshal(proto)
+	if err != nil {
+		return err
 	}
 
 	err = readByteEqual(br, '\n')
@@ -113,7 +119,7 @@ func (req Request) MarshalSize() int {
 		n++
 	}
 
-	n += 1 + len(rtspProtocol10) + 2
+	n += 1 + len(req.Version.String()) + 2
 
 	if len(req.Body) != 0 {
 		req.Header["Content-Length"] = HeaderValue{strconv.FormatInt(int64(len(req.Body)), 10)}
@@ -142,7 +148,7 @@ func (req Request) MarshalTo(buf []byte) (int, error) {
 
 	buf[pos] = ' '
 	pos++
-	pos += copy(buf[pos:], rtspProtocol10)
+	pos += copy(buf[pos:], req.Version.String())
 	buf[pos] = '\r'
 	pos++
 	buf[pos] = '\n'
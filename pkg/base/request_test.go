@@ -143,6 +143,24 @@ var casesRequest = []struct {
 			},
 		},
 	},
+	{
+		"rtsp 2.0",
+		[]byte("PLAY_NOTIFY rtsp://example.com/fizzle/foo RTSP/2.0\r\n" +
+			"CSeq: 854\r\n" +
+			"Notify-Reason: end-of-stream\r\n" +
+			"Session: uZ3ci0K+Ld-M\r\n" +
+			"\r\n"),
+		Request{
+			Method:  PlayNotify,
+			URL:     mustParseURL("rtsp://example.com/fizzle/foo"),
+			Version: Version20,
+			Header: Header{
+				"CSeq":          HeaderValue{"854"},
+				"Notify-Reason": HeaderValue{"end-of-stream"},
+				"Session":       HeaderValue{"uZ3ci0K+Ld-M"},
+			},
+		},
+	},
 }
 
 func TestRequestUnmarshal(t *testing.T) {
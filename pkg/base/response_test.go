@@ -90,6 +90,25 @@ var casesResponse = []struct {
 			),
 		},
 	},
+	{
+		"rtsp 2.0",
+		[]byte("RTSP/2.0 200 OK\r\n" +
+			"CSeq: 2\r\n" +
+			"Media-Properties: Random-Access=2.5, Unlimited, Immutable\r\n" +
+			"Pipelined-Requests: 7\r\n" +
+			"\r\n",
+		),
+		Response{
+			Version:       Version20,
+			StatusCode:    StatusOK,
+			StatusMessage: "OK",
+			Header: Header{
+				"CSeq":               HeaderValue{"2"},
+				"Media-Properties":   HeaderValue{"Random-Access=2.5, Unlimited, Immutable"},
+				"Pipelined-Requests": HeaderValue{"7"},
+			},
+		},
+	},
 }
 
 func TestResponseUnmarshal(t *testing.T) {
@@ -0,0 +1,42 @@
+package base
+
+import (
+	"fmt"
+)
+
+const (
+	rtspProtocol10 = "RTSP/1.0"
+	rtspProtocol20 = "RTSP/2.0"
+)
+
+// Version is a RTSP protocol version.
+type Version int
+
+// versions.
+const (
+	Version10 Version = iota
+	Version20
+)
+
+func (v *Version) unmarshal(byts []byte) error {
+	switch string(byts) {
+	case rtspProtocol10:
+		*v = Version10
+
+	case rtspProtocol20:
+		*v = Version20
+
+	default:
+		return fmt.Errorf("expected '%s' or '%s', got %v", rtspProtocol10, rtspProtocol20, byts)
+	}
+
+	return nil
+}
+
+// String implements fmt.Stringer.
+func (v Version) String() string {
+	if v == Version20 {
+		return rtspProtocol20
+	}
+	return rtspProtocol10
+}
@@ -27,15 +27,19 @@ func getAttribute(attributes []psdp.Attribute, key string) string {
 	return ""
 }
 
-func isBackChannel(attributes []psdp.Attribute) bool {
+func hasAttribute(attributes []psdp.Attribute, key string) bool {
 	for _, attr := range attributes {
-		if attr.Key == "sendonly" {
+		if attr.Key == key {
 			return true
 		}
 	}
 	return false
 }
 
+func isBackChannel(attributes []psdp.Attribute) bool {
+	return hasAttribute(attributes, "sendonly")
+}
+
 func sortedKeys(fmtp map[string]string) []string {
 	keys := make([]string, len(fmtp))
 	i := 0
@@ -84,6 +88,9 @@ type Media struct {
 	// Whether the transport is secure.
 	Secure bool
 
+	// Whether RTP and RTCP packets are multiplexed on a single port (RFC 5761).
+	RTCPMux bool
+
 	// key-mgmt attribute.
 	KeyMgmtMikey *mikey.Message
 
@@ -103,6 +110,7 @@ func (m *Media) Unmarshal(md *psdp.MediaDescription) error {
 	m.IsBackChannel = isBackChannel(md.Attributes)
 	m.Control = getAttribute(md.Attributes, "control")
 	m.Secure = slices.Contains(md.MediaName.Protos, "SAVP")
+	m.RTCPMux = hasAttribute(md.Attributes, "rtcp-mux")
 
 	if enc := getAttribute(md.Attributes, "key-mgmt"); enc != "" {
 		if !strings.HasPrefix(enc, "mikey ") {
@@ -179,6 +187,12 @@ func (m Media) Marshal2() (*psdp.MediaDescription, error) {
 		})
 	}
 
+	if m.RTCPMux {
+		md.Attributes = append(md.Attributes, psdp.Attribute{
+			Key: "rtcp-mux",
+		})
+	}
+
 	if m.KeyMgmtMikey != nil {
 		keyEnc, err := m.KeyMgmtMikey.Marshal()
 		if err != nil {
@@ -212,7 +226,11 @@ func (m Media) Marshal2() (*psdp.MediaDescription, error) {
 		if len(fmtp) != 0 {
 			tmp := make([]string, len(fmtp))
 			for i, key := range sortedKeys(fmtp) {
-				tmp[i] = key + "=" + fmtp[key]
+				if fmtp[key] == "" {
+					tmp[i] = key
+				} else {
+					tmp[i] = key + "=" + fmtp[key]
+				}
 			}
 
 			md.Attributes = append(md.Attributes, psdp.Attribute{
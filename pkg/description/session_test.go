@@ -269,6 +269,7 @@ var casesSession = []struct {
 			"m=audio 0 RTP/AVP 111 103 104 9 102 0 8 106 105 13 110 112 113 126\r\n" +
 			"a=mid:audio\r\n" +
 			"a=sendonly\r\n" +
+			"a=rtcp-mux\r\n" +
 			"a=control\r\n" +
 			"a=rtpmap:111 opus/48000/2\r\n" +
 			"a=fmtp:111 sprop-stereo=0\r\n" +
@@ -288,6 +289,7 @@ var casesSession = []struct {
 			"m=video 0 RTP/AVP 96 97 98 99 100 101 127 124 125\r\n" +
 			"a=mid:video\r\n" +
 			"a=sendonly\r\n" +
+			"a=rtcp-mux\r\n" +
 			"a=control\r\n" +
 			"a=rtpmap:96 VP8/90000\r\n" +
 			"a=rtpmap:97 rtx/90000\r\n" +
@@ -309,6 +311,7 @@ var casesSession = []struct {
 					ID:            "audio",
 					Type:          MediaTypeAudio,
 					IsBackChannel: true,
+					RTCPMux:       true,
 					Formats: []format.Format{
 						&format.Opus{
 							PayloadTyp:   111,
@@ -384,57 +387,45 @@ var casesSession = []struct {
 					ID:            "video",
 					Type:          MediaTypeVideo,
 					IsBackChannel: true,
+					RTCPMux:       true,
 					Formats: []format.Format{
 						&format.VP8{
 							PayloadTyp: 96,
 						},
-						&format.Generic{
+						&format.RTX{
 							PayloadTyp: 97,
-							RTPMa:      "rtx/90000",
-							FMT: map[string]string{
-								"apt": "96",
-							},
-							ClockRat: 90000,
+							ClockRat:   90000,
+							APT:        96,
 						},
 						&format.VP9{
 							PayloadTyp: 98,
 						},
-						&format.Generic{
+						&format.RTX{
 							PayloadTyp: 99,
-							RTPMa:      "rtx/90000",
-							FMT: map[string]string{
-								"apt": "98",
-							},
-							ClockRat: 90000,
+							ClockRat:   90000,
+							APT:        98,
 						},
 						&format.H264{
 							PayloadTyp:        100,
 							PacketizationMode: 1,
 						},
-						&format.Generic{
+						&format.RTX{
 							PayloadTyp: 101,
-							RTPMa:      "rtx/90000",
-							FMT: map[string]string{
-								"apt": "100",
-							},
-							ClockRat: 90000,
+							ClockRat:   90000,
+							APT:        100,
 						},
 						&format.Generic{
 							PayloadTyp: 127,
 							RTPMa:      "red/90000",
 							ClockRat:   90000,
 						},
-						&format.Generic{
+						&format.RTX{
 							PayloadTyp: 124,
-							RTPMa:      "rtx/90000",
-							FMT: map[string]string{
-								"apt": "127",
-							},
-							ClockRat: 90000,
+							ClockRat:   90000,
+							APT:        127,
 						},
-						&format.Generic{
+						&format.ULPFEC{
 							PayloadTyp: 125,
-							RTPMa:      "ulpfec/90000",
 							ClockRat:   90000,
 						},
 					},
@@ -460,6 +451,7 @@ var casesSession = []struct {
 			"c=IN IP4 0.0.0.0\r\n" +
 			"t=0 0\r\n" +
 			"m=video 0 RTP/AVP 96 98\r\n" +
+			"a=rtcp-mux\r\n" +
 			"a=control\r\n" +
 			"a=rtpmap:96 H264/90000\r\n" +
 			"a=fmtp:96 packetization-mode=1; profile-level-id=4D002A; " +
@@ -469,7 +461,8 @@ var casesSession = []struct {
 			Title: `-`,
 			Medias: []*Media{
 				{
-					Type: MediaTypeVideo,
+					Type:    MediaTypeVideo,
+					RTCPMux: true,
 					Formats: []format.Format{
 						&format.H264{
 							PayloadTyp: 96,
@@ -613,9 +606,8 @@ var casesSession = []struct {
 				{
 					ID:   "2",
 					Type: MediaTypeApplication,
-					Formats: []format.Format{&format.Generic{
+					Formats: []format.Format{&format.ULPFEC{
 						PayloadTyp: 100,
-						RTPMa:      "ulpfec/8000",
 						ClockRat:   8000,
 					}},
 				},
@@ -630,9 +622,8 @@ var casesSession = []struct {
 				{
 					ID:   "4",
 					Type: MediaTypeApplication,
-					Formats: []format.Format{&format.Generic{
+					Formats: []format.Format{&format.ULPFEC{
 						PayloadTyp: 101,
-						RTPMa:      "ulpfec/8000",
 						ClockRat:   8000,
 					}},
 				},
@@ -68,7 +68,10 @@ func decodeFMTP(enc string) map[string]string {
 		}
 
 		tmp := strings.SplitN(kv, "=", 2)
+
+		// parameters without a value are flags, like "interlace".
 		if len(tmp) != 2 {
+			ret[strings.ToLower(tmp[0])] = ""
 			continue
 		}
 
@@ -143,6 +146,9 @@ func Unmarshal(md *psdp.MediaDescription, payloadTypeStr string) (Format, error)
 		case codec == "vp8" && clock == "90000" && payloadType >= 96 && payloadType <= 127:
 			return &VP8{}
 
+		case codec == "h266" && clock == "90000" && payloadType >= 96 && payloadType <= 127:
+			return &H266{}
+
 		case codec == "h265" && clock == "90000" && payloadType >= 96 && payloadType <= 127:
 			return &H265{}
 
@@ -152,6 +158,9 @@ func Unmarshal(md *psdp.MediaDescription, payloadTypeStr string) (Format, error)
 		case codec == "mp4v-es" && clock == "90000" && payloadType >= 96 && payloadType <= 127:
 			return &MPEG4Video{}
 
+		case codec == "raw" && clock == "90000" && payloadType >= 96 && payloadType <= 127:
+			return &RawVideo{}
+
 		// audio
 
 		case codec == "opus", codec == "multiopus" && payloadType >= 96 && payloadType <= 127:
@@ -190,6 +199,14 @@ func Unmarshal(md *psdp.MediaDescription, payloadTypeStr string) (Format, error)
 		case codec == "smtpe336m" && payloadType >= 96 && payloadType <= 127:
 			return &KLV{}
 
+		// other
+
+		case codec == "rtx" && clock != "" && fmtp["apt"] != "" && payloadType >= 96 && payloadType <= 127:
+			return &RTX{}
+
+		case codec == "ulpfec" && clock != "" && payloadType >= 96 && payloadType <= 127:
+			return &ULPFEC{}
+
 		/*
 		* static payload types
 		**/
@@ -765,6 +765,61 @@ var casesFormat = []struct {
 				"D8AEE053C04641443000001B24C61766335382E3133342E313030",
 		},
 	},
+	{
+		"video raw",
+		"v=0\n" +
+			"s=\n" +
+			"m=video 0 RTP/AVP 96\n" +
+			"a=rtpmap:96 raw/90000\n" +
+			"a=fmtp:96 sampling=YCbCr-4:2:2; width=1920; height=1080; exactframerate=30000/1001; " +
+			"depth=10; TCS=SDR; colorimetry=BT709; PM=2110GPM; SSN=ST2110-20:2017; TP=2110TPN\n",
+		&RawVideo{
+			PayloadTyp:     96,
+			Sampling:       "YCbCr-4:2:2",
+			Width:          1920,
+			Height:         1080,
+			Depth:          10,
+			Colorimetry:    "BT709",
+			ExactFrameRate: "30000/1001",
+		},
+		96,
+		"raw/90000",
+		map[string]string{
+			"sampling":       "YCbCr-4:2:2",
+			"width":          "1920",
+			"height":         "1080",
+			"depth":          "10",
+			"colorimetry":    "BT709",
+			"exactframerate": "30000/1001",
+		},
+	},
+	{
+		"video raw interlaced",
+		"v=0\n" +
+			"s=\n" +
+			"m=video 0 RTP/AVP 97\n" +
+			"a=rtpmap:97 raw/90000\n" +
+			"a=fmtp:97 sampling=RGB; width=720; height=576; depth=8; colorimetry=BT601-5; interlace\n",
+		&RawVideo{
+			PayloadTyp:  97,
+			Sampling:    "RGB",
+			Width:       720,
+			Height:      576,
+			Depth:       8,
+			Colorimetry: "BT601-5",
+			Interlace:   true,
+		},
+		97,
+		"raw/90000",
+		map[string]string{
+			"sampling":    "RGB",
+			"width":       "720",
+			"height":      "576",
+			"depth":       "8",
+			"colorimetry": "BT601-5",
+			"interlace":   "",
+		},
+	},
 	{
 		"video h264",
 		"v=0\n" +
@@ -1038,6 +1093,46 @@ var casesFormat = []struct {
 			"sprop-pps": "RAHgdrAmQA==",
 		},
 	},
+	{
+		"video h266",
+		"v=0\n" +
+			"s=\n" +
+			"m=video 0 RTP/AVP 96\n" +
+			"a=rtpmap:96 H266/90000\n" +
+			"a=fmtp:96 sprop-vps=AHEAAg==; sprop-sps=AHkADSI=; sprop-pps=AIEABkA=; sprop-max-don-diff=2\n",
+		&H266{
+			PayloadTyp: 96,
+			VPS:        []byte{0x00, 0x71, 0x00, 0x02},
+			SPS:        []byte{0x00, 0x79, 0x00, 0x0d, 0x22},
+			PPS:        []byte{0x00, 0x81, 0x00, 0x06, 0x40},
+			MaxDONDiff: 2,
+		},
+		96,
+		"H266/90000",
+		map[string]string{
+			"sprop-vps":          "AHEAAg==",
+			"sprop-sps":          "AHkADSI=",
+			"sprop-pps":          "AIEABkA=",
+			"sprop-max-don-diff": "2",
+		},
+	},
+	{
+		"video h266 annexb",
+		"v=0\n" +
+			"s=\n" +
+			"m=video 0 RTP/AVP 96\n" +
+			"a=rtpmap:96 H266/90000\n" +
+			"a=fmtp:96 sprop-sps=AAAAAQB5AA0i\n",
+		&H266{
+			PayloadTyp: 96,
+			SPS:        []byte{0x00, 0x79, 0x00, 0x0d, 0x22},
+		},
+		96,
+		"H266/90000",
+		map[string]string{
+			"sprop-sps": "AHkADSI=",
+		},
+	},
 	{
 		"video vp8",
 		"v=0\n" +
@@ -1207,6 +1302,40 @@ var casesFormat = []struct {
 		"smtpe336m/90000",
 		nil,
 	},
+	{
+		"video rtx",
+		"v=0\n" +
+			"s=\n" +
+			"m=video 0 RTP/AVP 97\n" +
+			"a=rtpmap:97 rtx/90000\n" +
+			"a=fmtp:97 apt=96;rtx-time=3000\n",
+		&RTX{
+			PayloadTyp: 97,
+			ClockRat:   90000,
+			APT:        96,
+			RTXTime:    intPtr(3000),
+		},
+		97,
+		"rtx/90000",
+		map[string]string{
+			"apt":      "96",
+			"rtx-time": "3000",
+		},
+	},
+	{
+		"application ulpfec",
+		"v=0\n" +
+			"s=\n" +
+			"m=application 0 RTP/AVP 100\n" +
+			"a=rtpmap:100 ulpfec/8000\n",
+		&ULPFEC{
+			PayloadTyp: 100,
+			ClockRat:   8000,
+		},
+		100,
+		"ulpfec/8000",
+		nil,
+	},
 	{
 		"audio aac from AVOIP (issue mediamtx/4183)",
 		"v=0\r\n" +
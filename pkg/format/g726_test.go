@@ -13,3 +13,26 @@ func TestG726Attributes(t *testing.T) {
 	require.Equal(t, 8000, format.ClockRate())
 	require.Equal(t, true, format.PTSEqualsDTS(&rtp.Packet{}))
 }
+
+func TestG726DecEncoder(t *testing.T) {
+	format := &G726{
+		PayloadTyp: 96,
+		BitRate:    32,
+		BigEndian:  true,
+	}
+
+	enc, err := format.CreateEncoder()
+	require.NoError(t, err)
+
+	pkts, err := enc.Encode([]byte{0x01, 0x02, 0x03, 0x04})
+	require.NoError(t, err)
+	require.Equal(t, format.PayloadType(), pkts[0].PayloadType)
+	require.Equal(t, []byte{0x12, 0x34}, pkts[0].Payload)
+
+	dec, err := format.CreateDecoder()
+	require.NoError(t, err)
+
+	codewords, err := dec.Decode(pkts[0])
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x02, 0x03, 0x04}, codewords)
+}
@@ -0,0 +1,174 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pion/rtp"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format/rtprawvideo"
+)
+
+// RawVideo is the RTP format for uncompressed video.
+// Specification: https://datatracker.ietf.org/doc/html/rfc4175
+// It is compatible with SMPTE ST 2110-20.
+type RawVideo struct {
+	PayloadTyp     uint8
+	Sampling       string
+	Width          int
+	Height         int
+	Depth          int
+	Colorimetry    string
+	ExactFrameRate string
+	Interlace      bool
+	Segmented      bool
+}
+
+func (f *RawVideo) unmarshal(ctx *unmarshalContext) error {
+	f.PayloadTyp = ctx.payloadType
+
+	for key, val := range ctx.fmtp {
+		switch key {
+		case "sampling":
+			f.Sampling = val
+
+		case "width":
+			tmp, err := strconv.ParseUint(val, 10, 15)
+			if err != nil || tmp == 0 {
+				return fmt.Errorf("invalid width: '%s'", val)
+			}
+			f.Width = int(tmp)
+
+		case "height":
+			tmp, err := strconv.ParseUint(val, 10, 15)
+			if err != nil || tmp == 0 {
+				return fmt.Errorf("invalid height: '%s'", val)
+			}
+			f.Height = int(tmp)
+
+		case "depth":
+			tmp, err := strconv.ParseUint(val, 10, 31)
+			if err != nil || tmp == 0 {
+				return fmt.Errorf("invalid depth: '%s'", val)
+			}
+			f.Depth = int(tmp)
+
+		case "colorimetry":
+			f.Colorimetry = val
+
+		case "exactframerate":
+			f.ExactFrameRate = val
+
+		case "interlace":
+			f.Interlace = true
+
+		case "segmented":
+			f.Segmented = true
+		}
+	}
+
+	if f.Sampling == "" {
+		return fmt.Errorf("sampling is missing")
+	}
+
+	if f.Width == 0 || f.Height == 0 {
+		return fmt.Errorf("width or height is missing")
+	}
+
+	if f.Depth == 0 {
+		return fmt.Errorf("depth is missing")
+	}
+
+	return nil
+}
+
+// Codec implements Format.
+func (f *RawVideo) Codec() string {
+	return "RawVideo"
+}
+
+// ClockRate implements Format.
+func (f *RawVideo) ClockRate() int {
+	return 90000
+}
+
+// PayloadType implements Format.
+func (f *RawVideo) PayloadType() uint8 {
+	return f.PayloadTyp
+}
+
+// RTPMap implements Format.
+func (f *RawVideo) RTPMap() string {
+	return "raw/90000"
+}
+
+// FMTP implements Format.
+func (f *RawVideo) FMTP() map[string]string {
+	fmtp := map[string]string{
+		"sampling": f.Sampling,
+		"width":    strconv.FormatInt(int64(f.Width), 10),
+		"height":   strconv.FormatInt(int64(f.Height), 10),
+		"depth":    strconv.FormatInt(int64(f.Depth), 10),
+	}
+
+	if f.Colorimetry != "" {
+		fmtp["colorimetry"] = f.Colorimetry
+	}
+
+	if f.ExactFrameRate != "" {
+		fmtp["exactframerate"] = f.ExactFrameRate
+	}
+
+	// flags are encoded without value.
+	if f.Interlace {
+		fmtp["interlace"] = ""
+	}
+
+	if f.Segmented {
+		fmtp["segmented"] = ""
+	}
+
+	return fmtp
+}
+
+// PTSEqualsDTS implements Format.
+func (f *RawVideo) PTSEqualsDTS(*rtp.Packet) bool {
+	return true
+}
+
+// CreateDecoder creates a decoder able to decode the content of the format.
+func (f *RawVideo) CreateDecoder() (*rtprawvideo.Decoder, error) {
+	d := &rtprawvideo.Decoder{
+		Sampling:   f.Sampling,
+		Depth:      f.Depth,
+		Width:      f.Width,
+		Height:     f.Height,
+		Interlaced: f.Interlace || f.Segmented,
+	}
+
+	err := d.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// CreateEncoder creates an encoder able to encode the content of the format.
+func (f *RawVideo) CreateEncoder() (*rtprawvideo.Encoder, error) {
+	e := &rtprawvideo.Encoder{
+		PayloadType: f.PayloadTyp,
+		Sampling:    f.Sampling,
+		Depth:       f.Depth,
+		Width:       f.Width,
+		Height:      f.Height,
+		Interlaced:  f.Interlace || f.Segmented,
+	}
+
+	err := e.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
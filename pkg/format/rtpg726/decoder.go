@@ -0,0 +1,67 @@
+package rtpg726
+
+import (
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+// Decoder is a RTP/G726 decoder.
+// Specification: https://datatracker.ietf.org/doc/html/rfc3551
+type Decoder struct {
+	// bit rate, in kbit/s (16, 24, 32 or 40).
+	BitRate int
+
+	// whether codewords are packed in big-endian order (AAL2),
+	// instead of the little-endian order defined by RFC3551.
+	BigEndian bool
+
+	bits int
+}
+
+// Init initializes the decoder.
+func (d *Decoder) Init() error {
+	var err error
+	d.bits, err = codewordSize(d.BitRate)
+	return err
+}
+
+// Decode decodes codewords from a RTP packet.
+// Each codeword is returned in a separate byte, and corresponds to a sample.
+func (d *Decoder) Decode(pkt *rtp.Packet) ([]byte, error) {
+	plen := len(pkt.Payload)
+	if plen == 0 || ((plen*8)%(d.bits*groupSize(d.bits))) != 0 {
+		return nil, fmt.Errorf("received payload of wrong size")
+	}
+
+	codewords := make([]byte, plen*8/d.bits)
+	mask := uint32(1)<<d.bits - 1
+	acc := uint32(0)
+	accBits := 0
+	n := 0
+
+	for _, b := range pkt.Payload {
+		if d.BigEndian {
+			acc = acc<<8 | uint32(b)
+			accBits += 8
+
+			for accBits >= d.bits {
+				accBits -= d.bits
+				codewords[n] = byte((acc >> accBits) & mask)
+				n++
+			}
+		} else {
+			acc |= uint32(b) << accBits
+			accBits += 8
+
+			for accBits >= d.bits {
+				codewords[n] = byte(acc & mask)
+				n++
+				acc >>= d.bits
+				accBits -= d.bits
+			}
+		}
+	}
+
+	return codewords, nil
+}
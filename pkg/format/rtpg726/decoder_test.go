@@ -0,0 +1,54 @@
+package rtpg726
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	for _, ca := range cases {
+		t.Run(ca.name, func(t *testing.T) {
+			d := &Decoder{
+				BitRate:   ca.bitRate,
+				BigEndian: ca.bigEndian,
+			}
+			err := d.Init()
+			require.NoError(t, err)
+
+			var codewords []byte
+
+			for _, pkt := range ca.pkts {
+				partial, err := d.Decode(pkt)
+				require.NoError(t, err)
+				codewords = append(codewords, partial...)
+			}
+
+			require.Equal(t, ca.codewords, codewords)
+		})
+	}
+}
+
+func FuzzDecoder(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte, bitRate uint8, bigEndian bool) {
+		d := &Decoder{
+			BitRate:   16 + 8*int(bitRate%4),
+			BigEndian: bigEndian,
+		}
+		err := d.Init()
+		require.NoError(t, err)
+
+		d.Decode(&rtp.Packet{ //nolint:errcheck
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         false,
+				PayloadType:    96,
+				SequenceNumber: 17645,
+				Timestamp:      2289527317,
+				SSRC:           0x9dbb7812,
+			},
+			Payload: b,
+		})
+	})
+}
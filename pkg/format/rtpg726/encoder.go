@@ -0,0 +1,176 @@
+package rtpg726
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	rtpVersion            = 2
+	defaultPayloadMaxSize = 1450 // 1500 (UDP MTU) - 20 (IP header) - 8 (UDP header) - 12 (RTP header) - 10 (SRTP overhead)
+)
+
+func randUint32() (uint32, error) {
+	var b [4]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return 0, err
+	}
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3]), nil
+}
+
+// Encoder is a RTP/G726 encoder.
+// Specification: https://datatracker.ietf.org/doc/html/rfc3551
+type Encoder struct {
+	// payload type of packets.
+	PayloadType uint8
+
+	// bit rate, in kbit/s (16, 24, 32 or 40).
+	BitRate int
+
+	// whether codewords are packed in big-endian order (AAL2),
+	// instead of the little-endian order defined by RFC3551.
+	BigEndian bool
+
+	// SSRC of packets (optional).
+	// It defaults to a random value.
+	SSRC *uint32
+
+	// initial sequence number of packets (optional).
+	// It defaults to a random value.
+	InitialSequenceNumber *uint16
+
+	// maximum size of packet payloads (optional).
+	// It defaults to 1450.
+	PayloadMaxSize int
+
+	sequenceNumber   uint16
+	bits             int
+	group            int
+	maxCodewordCount int
+}
+
+// Init initializes the encoder.
+func (e *Encoder) Init() error {
+	var err error
+	e.bits, err = codewordSize(e.BitRate)
+	if err != nil {
+		return err
+	}
+
+	if e.SSRC == nil {
+		v, err := randUint32()
+		if err != nil {
+			return err
+		}
+		e.SSRC = &v
+	}
+	if e.InitialSequenceNumber == nil {
+		v, err := randUint32()
+		if err != nil {
+			return err
+		}
+		v2 := uint16(v)
+		e.InitialSequenceNumber = &v2
+	}
+	if e.PayloadMaxSize == 0 {
+		e.PayloadMaxSize = defaultPayloadMaxSize
+	}
+
+	e.sequenceNumber = *e.InitialSequenceNumber
+	e.group = groupSize(e.bits)
+	e.maxCodewordCount = ((e.PayloadMaxSize * 8 / e.bits) / e.group) * e.group
+
+	if e.maxCodewordCount == 0 {
+		return fmt.Errorf("payload max size (%d) is too small", e.PayloadMaxSize)
+	}
+
+	return nil
+}
+
+func (e *Encoder) packetCount(clen int) int {
+	n := (clen / e.maxCodewordCount)
+	if (clen % e.maxCodewordCount) != 0 {
+		n++
+	}
+	return n
+}
+
+func (e *Encoder) pack(codewords []byte) []byte {
+	payload := make([]byte, len(codewords)*e.bits/8)
+	acc := uint32(0)
+	accBits := 0
+	n := 0
+
+	for _, c := range codewords {
+		if e.BigEndian {
+			acc = acc<<e.bits | uint32(c)
+			accBits += e.bits
+
+			for accBits >= 8 {
+				accBits -= 8
+				payload[n] = byte(acc >> accBits)
+				n++
+			}
+		} else {
+			acc |= uint32(c) << accBits
+			accBits += e.bits
+
+			for accBits >= 8 {
+				payload[n] = byte(acc)
+				n++
+				acc >>= 8
+				accBits -= 8
+			}
+		}
+	}
+
+	return payload
+}
+
+// Encode encodes codewords into RTP packets.
+// Each codeword must be provided in a separate byte, and corresponds to a sample.
+func (e *Encoder) Encode(codewords []byte) ([]*rtp.Packet, error) {
+	clen := len(codewords)
+	if clen == 0 || (clen%e.group) != 0 {
+		return nil, fmt.Errorf("codeword count (%d) is not a multiple of %d", clen, e.group)
+	}
+
+	for _, c := range codewords {
+		if c >= (1 << e.bits) {
+			return nil, fmt.Errorf("invalid codeword (%d)", c)
+		}
+	}
+
+	packetCount := e.packetCount(clen)
+	ret := make([]*rtp.Packet, packetCount)
+	pos := 0
+	count := e.maxCodewordCount
+	timestamp := uint32(0)
+
+	for i := range ret {
+		if count > len(codewords[pos:]) {
+			count = len(codewords[pos:])
+		}
+
+		ret[i] = &rtp.Packet{
+			Header: rtp.Header{
+				Version:        rtpVersion,
+				PayloadType:    e.PayloadType,
+				SequenceNumber: e.sequenceNumber,
+				Timestamp:      timestamp,
+				SSRC:           *e.SSRC,
+				Marker:         false,
+			},
+			Payload: e.pack(codewords[pos : pos+count]),
+		}
+
+		e.sequenceNumber++
+		pos += count
+		timestamp += uint32(count)
+	}
+
+	return ret, nil
+}
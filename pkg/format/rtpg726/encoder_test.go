@@ -0,0 +1,246 @@
+package rtpg726
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func uint16Ptr(v uint16) *uint16 {
+	return &v
+}
+
+func uint32Ptr(v uint32) *uint32 {
+	return &v
+}
+
+var cases = []struct {
+	name      string
+	bitRate   int
+	bigEndian bool
+	codewords []byte
+	pkts      []*rtp.Packet
+}{
+	{
+		"16 little-endian",
+		16,
+		false,
+		[]byte{0, 1, 2, 3},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{0xe4},
+			},
+		},
+	},
+	{
+		"16 big-endian",
+		16,
+		true,
+		[]byte{0, 1, 2, 3},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{0x1b},
+			},
+		},
+	},
+	{
+		"24 little-endian",
+		24,
+		false,
+		[]byte{1, 2, 3, 4, 5, 6, 7, 0},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{0xd1, 0x58, 0x1f},
+			},
+		},
+	},
+	{
+		"24 big-endian",
+		24,
+		true,
+		[]byte{1, 2, 3, 4, 5, 6, 7, 0},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{0x29, 0xcb, 0xb8},
+			},
+		},
+	},
+	{
+		"32 little-endian",
+		32,
+		false,
+		[]byte{1, 2, 3, 4},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{0x21, 0x43},
+			},
+		},
+	},
+	{
+		"32 big-endian",
+		32,
+		true,
+		[]byte{1, 2, 3, 4},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{0x12, 0x34},
+			},
+		},
+	},
+	{
+		"40 little-endian",
+		40,
+		false,
+		[]byte{1, 2, 3, 4, 5, 6, 7, 8},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{0x41, 0x0c, 0x52, 0xcc, 0x41},
+			},
+		},
+	},
+	{
+		"40 big-endian",
+		40,
+		true,
+		[]byte{1, 2, 3, 4, 5, 6, 7, 8},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{0x08, 0x86, 0x42, 0x98, 0xe8},
+			},
+		},
+	},
+	{
+		"fragmented",
+		32,
+		false,
+		bytes.Repeat([]byte{1, 2}, 1500),
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: bytes.Repeat([]byte{0x21}, 1000),
+			},
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17646,
+					Timestamp:      2000,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: bytes.Repeat([]byte{0x21}, 500),
+			},
+		},
+	},
+}
+
+func TestEncode(t *testing.T) {
+	for _, ca := range cases {
+		t.Run(ca.name, func(t *testing.T) {
+			e := &Encoder{
+				PayloadType:           96,
+				BitRate:               ca.bitRate,
+				BigEndian:             ca.bigEndian,
+				SSRC:                  uint32Ptr(0x9dbb7812),
+				InitialSequenceNumber: uint16Ptr(0x44ed),
+				PayloadMaxSize:        1000,
+			}
+			err := e.Init()
+			require.NoError(t, err)
+
+			pkts, err := e.Encode(ca.codewords)
+			require.NoError(t, err)
+			require.Equal(t, ca.pkts, pkts)
+		})
+	}
+}
+
+func TestEncodeRandomInitialState(t *testing.T) {
+	e := &Encoder{
+		PayloadType: 96,
+		BitRate:     32,
+	}
+	err := e.Init()
+	require.NoError(t, err)
+	require.NotEqual(t, nil, e.SSRC)
+	require.NotEqual(t, nil, e.InitialSequenceNumber)
+}
+
+func TestEncodeErrorInvalidCodewords(t *testing.T) {
+	e := &Encoder{
+		PayloadType: 96,
+		BitRate:     24,
+	}
+	err := e.Init()
+	require.NoError(t, err)
+
+	_, err = e.Encode([]byte{1, 2, 3})
+	require.EqualError(t, err, "codeword count (3) is not a multiple of 8")
+
+	_, err = e.Encode([]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	require.EqualError(t, err, "invalid codeword (8)")
+}
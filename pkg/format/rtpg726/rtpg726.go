@@ -0,0 +1,25 @@
+// Package rtpg726 contains a RTP/G726 decoder and encoder.
+package rtpg726
+
+import (
+	"fmt"
+)
+
+func codewordSize(bitRate int) (int, error) {
+	switch bitRate {
+	case 16, 24, 32, 40:
+		return bitRate / 8, nil
+	}
+	return 0, fmt.Errorf("unsupported bit rate: %d", bitRate)
+}
+
+// groupSize returns the minimum number of codewords that fill an integral number of bytes.
+func groupSize(bits int) int {
+	switch bits {
+	case 2:
+		return 4
+	case 4:
+		return 2
+	}
+	return 8
+}
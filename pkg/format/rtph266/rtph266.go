@@ -0,0 +1,16 @@
+// Package rtph266 contains a RTP/H266 decoder and encoder.
+package rtph266
+
+const (
+	// maximum size of an access unit.
+	maxAccessUnitSize = 8 * 1024 * 1024
+
+	// maximum number of NALUs per access unit.
+	maxNALUsPerAccessUnit = 21
+)
+
+// payload structure types.
+const (
+	naluTypeAggregationUnit   = 28
+	naluTypeFragmentationUnit = 29
+)
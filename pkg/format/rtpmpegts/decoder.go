@@ -0,0 +1,32 @@
+package rtpmpegts
+
+import (
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+// Decoder is a RTP/MPEG-TS decoder.
+// Specification: https://datatracker.ietf.org/doc/html/rfc2250
+type Decoder struct{}
+
+// Init initializes the decoder.
+func (d *Decoder) Init() error {
+	return nil
+}
+
+// Decode decodes MPEG-TS packets from a RTP packet.
+func (d *Decoder) Decode(pkt *rtp.Packet) ([]byte, error) {
+	plen := len(pkt.Payload)
+	if plen == 0 || (plen%packetSize) != 0 {
+		return nil, fmt.Errorf("payload size (%d) is not a multiple of %d", plen, packetSize)
+	}
+
+	for i := 0; i < plen; i += packetSize {
+		if pkt.Payload[i] != syncByte {
+			return nil, fmt.Errorf("invalid sync byte")
+		}
+	}
+
+	return pkt.Payload, nil
+}
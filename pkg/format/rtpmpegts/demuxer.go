@@ -0,0 +1,175 @@
+package rtpmpegts
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bluenviron/mediacommon/v2/pkg/formats/mpegts"
+)
+
+const (
+	// maximum amount of data that is buffered while waiting for the PMT.
+	demuxerMaxInitSize = 1 * 1024 * 1024
+)
+
+var errNeedMore = errors.New("need more data")
+
+// demuxerQueue is a io.Reader that returns queued data,
+// and errNeedMore when the queue is empty.
+type demuxerQueue struct {
+	buf []byte
+}
+
+func (q *demuxerQueue) Read(p []byte) (int, error) {
+	if len(q.buf) == 0 {
+		return 0, errNeedMore
+	}
+
+	n := copy(p, q.buf)
+	q.buf = q.buf[n:]
+	return n, nil
+}
+
+// DemuxerOnDataFunc is the prototype of the callback passed to Demuxer.
+//
+// PTS and DTS are expressed in 90kHz units, are relative to the first received timestamp
+// and are unwrapped, therefore they never overflow.
+// Depending on the codec of the track, au contains:
+//   - H264, H265: NAL units of an access unit
+//   - MPEG-4 Audio: access units
+//   - Opus: packets
+//   - KLV: a KLV unit
+type DemuxerOnDataFunc func(track *mpegts.Track, pts int64, dts int64, au [][]byte) error
+
+// Demuxer extracts elementary streams from MPEG-TS packets.
+// Supported codecs are H264, H265, MPEG-4 Audio, Opus and KLV.
+// Data of tracks with other codecs is ignored.
+type Demuxer struct {
+	// called when data of an elementary stream is available.
+	OnData DemuxerOnDataFunc
+
+	// called when a non-fatal decode error occurs (optional).
+	OnDecodeError func(error)
+
+	initBuf     []byte
+	queue       *demuxerQueue
+	reader      *mpegts.Reader
+	timeDecoder *mpegts.TimeDecoder
+}
+
+// Init initializes the demuxer.
+func (d *Demuxer) Init() error {
+	if d.OnData == nil {
+		return fmt.Errorf("OnData is not set")
+	}
+
+	d.timeDecoder = &mpegts.TimeDecoder{}
+	d.timeDecoder.Initialize()
+
+	return nil
+}
+
+// Tracks returns the tracks contained in the stream.
+// It returns nil until the program map table has been received.
+func (d *Demuxer) Tracks() []*mpegts.Track {
+	if d.reader == nil {
+		return nil
+	}
+	return d.reader.Tracks()
+}
+
+// Demux demuxes MPEG-TS packets, usually decoded by Decoder.
+// Data of an elementary stream is passed to OnData
+// when the beginning of the following one is received.
+func (d *Demuxer) Demux(tsPackets []byte) error {
+	if (len(tsPackets) % packetSize) != 0 {
+		return fmt.Errorf("data size (%d) is not a multiple of %d", len(tsPackets), packetSize)
+	}
+
+	if d.reader == nil {
+		return d.initialize(tsPackets)
+	}
+
+	d.queue.buf = append(d.queue.buf, tsPackets...)
+	return d.read()
+}
+
+// initialize tries to read the program map table from all the data received so far.
+func (d *Demuxer) initialize(tsPackets []byte) error {
+	if (len(d.initBuf) + len(tsPackets)) > demuxerMaxInitSize {
+		d.initBuf = nil
+		return fmt.Errorf("program map table not found")
+	}
+
+	d.initBuf = append(d.initBuf, tsPackets...)
+
+	queue := &demuxerQueue{buf: d.initBuf}
+	reader := &mpegts.Reader{R: queue}
+
+	err := reader.Initialize()
+	if err != nil {
+		if errors.Is(err, errNeedMore) {
+			return nil
+		}
+		d.initBuf = nil
+		return err
+	}
+
+	d.initBuf = nil
+	d.queue = queue
+	d.reader = reader
+
+	if d.OnDecodeError != nil {
+		reader.OnDecodeError(d.OnDecodeError)
+	}
+
+	for _, track := range reader.Tracks() {
+		d.setTrackCallback(track)
+	}
+
+	return d.read()
+}
+
+func (d *Demuxer) setTrackCallback(track *mpegts.Track) {
+	switch track.Codec.(type) {
+	case *mpegts.CodecH264:
+		d.reader.OnDataH264(track, func(pts int64, dts int64, au [][]byte) error {
+			return d.OnData(track, d.timeDecoder.Decode(pts), d.timeDecoder.Decode(dts), au)
+		})
+
+	case *mpegts.CodecH265:
+		d.reader.OnDataH265(track, func(pts int64, dts int64, au [][]byte) error {
+			return d.OnData(track, d.timeDecoder.Decode(pts), d.timeDecoder.Decode(dts), au)
+		})
+
+	case *mpegts.CodecMPEG4Audio:
+		d.reader.OnDataMPEG4Audio(track, func(pts int64, aus [][]byte) error {
+			pts = d.timeDecoder.Decode(pts)
+			return d.OnData(track, pts, pts, aus)
+		})
+
+	case *mpegts.CodecOpus:
+		d.reader.OnDataOpus(track, func(pts int64, packets [][]byte) error {
+			pts = d.timeDecoder.Decode(pts)
+			return d.OnData(track, pts, pts, packets)
+		})
+
+	case *mpegts.CodecKLV:
+		d.reader.OnDataKLV(track, func(pts int64, data []byte) error {
+			pts = d.timeDecoder.Decode(pts)
+			return d.OnData(track, pts, pts, [][]byte{data})
+		})
+	}
+}
+
+func (d *Demuxer) read() error {
+	for {
+		err := d.reader.Read()
+		if err != nil {
+			if errors.Is(err, errNeedMore) {
+				return nil
+			}
+			return err
+		}
+	}
+}
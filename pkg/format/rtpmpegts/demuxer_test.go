@@ -0,0 +1,120 @@
+package rtpmpegts
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bluenviron/mediacommon/v2/pkg/codecs/mpeg4audio"
+	"github.com/bluenviron/mediacommon/v2/pkg/formats/mpegts"
+	"github.com/stretchr/testify/require"
+)
+
+type demuxerTestData struct {
+	codec string
+	pts   int64
+	dts   int64
+	au    [][]byte
+}
+
+func TestDemuxer(t *testing.T) {
+	h264Track := &mpegts.Track{
+		Codec: &mpegts.CodecH264{},
+	}
+
+	mpeg4AudioTrack := &mpegts.Track{
+		Codec: &mpegts.CodecMPEG4Audio{
+			Config: mpeg4audio.Config{
+				Type:         2,
+				SampleRate:   44100,
+				ChannelCount: 2,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	w := &mpegts.Writer{
+		W:      &buf,
+		Tracks: []*mpegts.Track{h264Track, mpeg4AudioTrack},
+	}
+	err := w.Initialize()
+	require.NoError(t, err)
+
+	// timestamps are close to the overflow of 33 bits
+	// in order to check that they are unwrapped.
+	const base = 0x1FFFFFFFF - 3000
+
+	for i := int64(0); i < 3; i++ {
+		err = w.WriteH264(h264Track, base+i*3000, base+i*3000, [][]byte{
+			{0x09, 0xf0},
+			{0x65, byte(i)},
+		})
+		require.NoError(t, err)
+
+		err = w.WriteMPEG4Audio(mpeg4AudioTrack, base+i*3000, [][]byte{{1, 2, byte(i)}})
+		require.NoError(t, err)
+	}
+
+	e := &Encoder{
+		PayloadType: 33,
+	}
+	err = e.Init()
+	require.NoError(t, err)
+
+	pkts, err := e.Encode(buf.Bytes())
+	require.NoError(t, err)
+
+	d := &Decoder{}
+	err = d.Init()
+	require.NoError(t, err)
+
+	var received []demuxerTestData
+
+	dem := &Demuxer{
+		OnData: func(track *mpegts.Track, pts int64, dts int64, au [][]byte) error {
+			codec := "h264"
+			if _, ok := track.Codec.(*mpegts.CodecMPEG4Audio); ok {
+				codec = "mpeg4audio"
+			}
+			received = append(received, demuxerTestData{codec, pts, dts, au})
+			return nil
+		},
+	}
+	err = dem.Init()
+	require.NoError(t, err)
+	require.Nil(t, dem.Tracks())
+
+	for _, pkt := range pkts {
+		data, err := d.Decode(pkt)
+		require.NoError(t, err)
+
+		err = dem.Demux(data)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, dem.Tracks(), 2)
+	require.Equal(t, &mpegts.CodecH264{}, dem.Tracks()[0].Codec)
+	require.Equal(t, mpeg4AudioTrack.Codec, dem.Tracks()[1].Codec)
+
+	// the last access unit of each track is returned
+	// when the beginning of the next one is received.
+	require.Equal(t, []demuxerTestData{
+		{"h264", 0, 0, [][]byte{{0x65, 0}}},
+		{"mpeg4audio", 0, 0, [][]byte{{1, 2, 0}}},
+		{"h264", 3000, 3000, [][]byte{{0x65, 1}}},
+		{"mpeg4audio", 3000, 3000, [][]byte{{1, 2, 1}}},
+	}, received)
+}
+
+func TestDemuxerInvalidSize(t *testing.T) {
+	dem := &Demuxer{
+		OnData: func(*mpegts.Track, int64, int64, [][]byte) error {
+			return nil
+		},
+	}
+	err := dem.Init()
+	require.NoError(t, err)
+
+	err = dem.Demux([]byte{0x47, 0x01})
+	require.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package rtpmpegts
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	rtpVersion            = 2
+	defaultPayloadMaxSize = 1450 // 1500 (UDP MTU) - 20 (IP header) - 8 (UDP header) - 12 (RTP header) - 10 (SRTP overhead)
+)
+
+func randUint32() (uint32, error) {
+	var b [4]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return 0, err
+	}
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3]), nil
+}
+
+// Encoder is a RTP/MPEG-TS encoder.
+// Specification: https://datatracker.ietf.org/doc/html/rfc2250
+type Encoder struct {
+	// payload type of packets.
+	PayloadType uint8
+
+	// SSRC of packets (optional).
+	// It defaults to a random value.
+	SSRC *uint32
+
+	// initial sequence number of packets (optional).
+	// It defaults to a random value.
+	InitialSequenceNumber *uint16
+
+	// maximum size of packet payloads (optional).
+	// It defaults to 1450.
+	PayloadMaxSize int
+
+	sequenceNumber uint16
+	maxPayloadSize int
+}
+
+// Init initializes the encoder.
+func (e *Encoder) Init() error {
+	if e.SSRC == nil {
+		v, err := randUint32()
+		if err != nil {
+			return err
+		}
+		e.SSRC = &v
+	}
+	if e.InitialSequenceNumber == nil {
+		v, err := randUint32()
+		if err != nil {
+			return err
+		}
+		v2 := uint16(v)
+		e.InitialSequenceNumber = &v2
+	}
+	if e.PayloadMaxSize == 0 {
+		e.PayloadMaxSize = defaultPayloadMaxSize
+	}
+
+	e.sequenceNumber = *e.InitialSequenceNumber
+	e.maxPayloadSize = (e.PayloadMaxSize / packetSize) * packetSize
+	if e.maxPayloadSize == 0 {
+		return fmt.Errorf("payload max size (%d) is lower than the size of a MPEG-TS packet", e.PayloadMaxSize)
+	}
+
+	return nil
+}
+
+func (e *Encoder) packetCount(plen int) int {
+	n := (plen / e.maxPayloadSize)
+	if (plen % e.maxPayloadSize) != 0 {
+		n++
+	}
+	return n
+}
+
+// Encode encodes MPEG-TS packets into RTP packets.
+// Each RTP packet contains an integral number of MPEG-TS packets.
+// The RTP timestamp must be set by the caller and must represent
+// the transmission time of the MPEG-TS packets, in 90kHz units,
+// therefore it is the same for all packets returned by a single call.
+func (e *Encoder) Encode(tsPackets []byte) ([]*rtp.Packet, error) {
+	plen := len(tsPackets)
+	if plen == 0 || (plen%packetSize) != 0 {
+		return nil, fmt.Errorf("data size (%d) is not a multiple of %d", plen, packetSize)
+	}
+
+	packetCount := e.packetCount(plen)
+	ret := make([]*rtp.Packet, packetCount)
+	pos := 0
+	payloadSize := e.maxPayloadSize
+
+	for i := range ret {
+		if payloadSize > len(tsPackets[pos:]) {
+			payloadSize = len(tsPackets[pos:])
+		}
+
+		ret[i] = &rtp.Packet{
+			Header: rtp.Header{
+				Version:        rtpVersion,
+				PayloadType:    e.PayloadType,
+				SequenceNumber: e.sequenceNumber,
+				SSRC:           *e.SSRC,
+				Marker:         false,
+			},
+			Payload: tsPackets[pos : pos+payloadSize],
+		}
+
+		e.sequenceNumber++
+		pos += payloadSize
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,10 @@
+// Package rtpmpegts contains a RTP/MPEG-TS decoder and encoder, and a MPEG-TS demuxer.
+package rtpmpegts
+
+const (
+	// size of a MPEG-TS packet.
+	packetSize = 188
+
+	// sync byte of MPEG-TS packets.
+	syncByte = 0x47
+)
@@ -0,0 +1,184 @@
+package rtprawvideo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+// ErrMorePacketsNeeded is returned when more packets are needed.
+var ErrMorePacketsNeeded = errors.New("need more packets")
+
+// ErrNonStartingPacketAndNoPrevious is returned when we received a non-starting
+// packet of a frame and we didn't received anything before.
+// It's normal to receive this when decoding a stream that has been already
+// running for some time.
+var ErrNonStartingPacketAndNoPrevious = errors.New(
+	"received a non-starting packet without any previous starting packet")
+
+type segmentHeader struct {
+	length int
+	field  int
+	line   int
+	offset int
+}
+
+// Decoder is a RTP/raw video decoder.
+// Specification: https://datatracker.ietf.org/doc/html/rfc4175
+// It is compatible with SMPTE ST 2110-20.
+type Decoder struct {
+	// sampling, as in the "sampling" SDP parameter (for instance "YCbCr-4:2:2").
+	Sampling string
+
+	// bit depth of each sample.
+	Depth int
+
+	// frame width, in pixels.
+	Width int
+
+	// frame height, in scan lines.
+	Height int
+
+	// whether frames are transmitted as two fields,
+	// as happens with interlaced and segmented frames.
+	Interlaced bool
+
+	layout         frameLayout
+	frame          []byte
+	field          int
+	expectedSeqNum uint16
+	segments       []segmentHeader
+}
+
+// Init initializes the decoder.
+func (d *Decoder) Init() error {
+	return d.layout.init(d.Sampling, d.Depth, d.Width, d.Height, d.Interlaced)
+}
+
+func (d *Decoder) resetFrame() {
+	d.frame = nil
+	d.field = 0
+}
+
+func (d *Decoder) parseHeaders(payload []byte) ([]byte, error) {
+	if len(payload) < extSeqNumSize {
+		return nil, fmt.Errorf("payload is too short")
+	}
+	payload = payload[extSeqNumSize:]
+
+	d.segments = d.segments[:0]
+
+	for {
+		if len(payload) < segmentHeaderSize {
+			return nil, fmt.Errorf("payload is too short")
+		}
+
+		h := segmentHeader{
+			length: int(uint16(payload[0])<<8 | uint16(payload[1])),
+			line:   int(uint16(payload[2])<<8|uint16(payload[3])) & maxLineOrOffset,
+			offset: int(uint16(payload[4])<<8|uint16(payload[5])) & maxLineOrOffset,
+		}
+		if (payload[2] & 0x80) != 0 {
+			h.field = 1
+		}
+		cont := (payload[4] & 0x80) != 0
+		payload = payload[segmentHeaderSize:]
+
+		d.segments = append(d.segments, h)
+
+		if !cont {
+			break
+		}
+	}
+
+	return payload, nil
+}
+
+func (d *Decoder) writeSegment(h segmentHeader, data []byte) error {
+	if !d.layout.interlaced && h.field != 0 {
+		return fmt.Errorf("received a field of an interlaced frame, but the decoder is not set to interlaced")
+	}
+
+	if (h.line%d.layout.yLines) != 0 || (h.line/d.layout.yLines) >= d.layout.fieldRows() {
+		return fmt.Errorf("invalid line number: %d", h.line)
+	}
+
+	if (h.offset % d.layout.xPixels) != 0 {
+		return fmt.Errorf("invalid offset: %d", h.offset)
+	}
+
+	if (h.length % d.layout.size) != 0 {
+		return fmt.Errorf("invalid segment length: %d", h.length)
+	}
+
+	start := (h.offset / d.layout.xPixels) * d.layout.size
+	if (start + h.length) > d.layout.stride {
+		return fmt.Errorf("segment exceeds the line size")
+	}
+
+	row := d.layout.frameRow(h.field, h.line/d.layout.yLines)
+	copy(d.frame[row*d.layout.stride+start:], data)
+
+	return nil
+}
+
+// Decode decodes a frame from a RTP packet.
+// The frame is returned as a sequence of pgroups, as they are transmitted,
+// starting from the top-left pixel, with interlaced fields woven together.
+func (d *Decoder) Decode(pkt *rtp.Packet) ([]byte, error) {
+	payload, err := d.parseHeaders(pkt.Payload)
+	if err != nil {
+		d.resetFrame()
+		return nil, err
+	}
+
+	if d.frame == nil {
+		first := d.segments[0]
+		if first.field != 0 || first.line != 0 || first.offset != 0 {
+			return nil, ErrNonStartingPacketAndNoPrevious
+		}
+
+		d.frame = make([]byte, d.layout.rows*d.layout.stride)
+	} else if pkt.SequenceNumber != d.expectedSeqNum {
+		d.resetFrame()
+		return nil, fmt.Errorf("discarding frame since a RTP packet is missing")
+	}
+
+	d.expectedSeqNum = pkt.SequenceNumber + 1
+
+	for _, h := range d.segments {
+		if len(payload) < h.length {
+			d.resetFrame()
+			return nil, fmt.Errorf("payload is too short")
+		}
+
+		if h.field != d.field {
+			d.resetFrame()
+			return nil, fmt.Errorf("received a segment of field %d while decoding field %d", h.field, d.field)
+		}
+
+		err = d.writeSegment(h, payload[:h.length])
+		if err != nil {
+			d.resetFrame()
+			return nil, err
+		}
+
+		payload = payload[h.length:]
+	}
+
+	if !pkt.Marker {
+		return nil, ErrMorePacketsNeeded
+	}
+
+	// the marker bit is set at the end of every field.
+	if d.layout.interlaced && d.field == 0 {
+		d.field = 1
+		return nil, ErrMorePacketsNeeded
+	}
+
+	frame := d.frame
+	d.resetFrame()
+
+	return frame, nil
+}
@@ -0,0 +1,104 @@
+package rtprawvideo
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	for _, ca := range cases {
+		t.Run(ca.name, func(t *testing.T) {
+			d := &Decoder{
+				Sampling:   ca.sampling,
+				Depth:      ca.depth,
+				Width:      ca.width,
+				Height:     ca.height,
+				Interlaced: ca.interlaced,
+			}
+			err := d.Init()
+			require.NoError(t, err)
+
+			var frame []byte
+
+			for _, pkt := range ca.pkts {
+				frame, err = d.Decode(pkt)
+				if err == ErrMorePacketsNeeded {
+					continue
+				}
+
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, ca.frame, frame)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	d := &Decoder{
+		Sampling: "RGB",
+		Depth:    8,
+		Width:    4,
+		Height:   2,
+	}
+	err := d.Init()
+	require.NoError(t, err)
+
+	pkts := cases[1].pkts
+
+	_, err = d.Decode(pkts[1])
+	require.Equal(t, ErrNonStartingPacketAndNoPrevious, err)
+
+	_, err = d.Decode(pkts[0])
+	require.Equal(t, ErrMorePacketsNeeded, err)
+
+	pkt := *pkts[1]
+	pkt.SequenceNumber++
+	_, err = d.Decode(&pkt)
+	require.EqualError(t, err, "discarding frame since a RTP packet is missing")
+
+	_, err = d.Decode(pkts[0])
+	require.Equal(t, ErrMorePacketsNeeded, err)
+
+	frame, err := d.Decode(pkts[1])
+	require.NoError(t, err)
+	require.Equal(t, cases[1].frame, frame)
+}
+
+func FuzzDecoder(f *testing.F) {
+	f.Fuzz(func(t *testing.T, a []byte, am bool, b []byte, bm bool) {
+		d := &Decoder{
+			Sampling:   "YCbCr-4:2:2",
+			Depth:      8,
+			Width:      8,
+			Height:     4,
+			Interlaced: true,
+		}
+		err := d.Init()
+		require.NoError(t, err)
+
+		d.Decode(&rtp.Packet{ //nolint:errcheck
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         am,
+				PayloadType:    96,
+				SequenceNumber: 17645,
+				SSRC:           0x9dbb7812,
+			},
+			Payload: a,
+		})
+
+		d.Decode(&rtp.Packet{ //nolint:errcheck
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         bm,
+				PayloadType:    96,
+				SequenceNumber: 17646,
+				SSRC:           0x9dbb7812,
+			},
+			Payload: b,
+		})
+	})
+}
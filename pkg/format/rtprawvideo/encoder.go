@@ -0,0 +1,200 @@
+package rtprawvideo
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	rtpVersion            = 2
+	defaultPayloadMaxSize = 1450 // 1500 (UDP MTU) - 20 (IP header) - 8 (UDP header) - 12 (RTP header) - 10 (SRTP overhead)
+)
+
+func randUint32() (uint32, error) {
+	var b [4]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return 0, err
+	}
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3]), nil
+}
+
+// Encoder is a RTP/raw video encoder.
+// Specification: https://datatracker.ietf.org/doc/html/rfc4175
+// It is compatible with SMPTE ST 2110-20.
+type Encoder struct {
+	// payload type of packets.
+	PayloadType uint8
+
+	// sampling, as in the "sampling" SDP parameter (for instance "YCbCr-4:2:2").
+	Sampling string
+
+	// bit depth of each sample.
+	Depth int
+
+	// frame width, in pixels.
+	Width int
+
+	// frame height, in scan lines.
+	Height int
+
+	// whether frames are transmitted as two fields,
+	// as happens with interlaced and segmented frames.
+	Interlaced bool
+
+	// SSRC of packets (optional).
+	// It defaults to a random value.
+	SSRC *uint32
+
+	// initial sequence number of packets (optional).
+	// It defaults to a random value.
+	InitialSequenceNumber *uint16
+
+	// maximum size of packet payloads (optional).
+	// It defaults to 1450.
+	PayloadMaxSize int
+
+	layout         frameLayout
+	sequenceNumber uint32 // extended sequence number
+}
+
+// Init initializes the encoder.
+func (e *Encoder) Init() error {
+	err := e.layout.init(e.Sampling, e.Depth, e.Width, e.Height, e.Interlaced)
+	if err != nil {
+		return err
+	}
+
+	if e.SSRC == nil {
+		v, err := randUint32()
+		if err != nil {
+			return err
+		}
+		e.SSRC = &v
+	}
+	if e.InitialSequenceNumber == nil {
+		v, err := randUint32()
+		if err != nil {
+			return err
+		}
+		v2 := uint16(v)
+		e.InitialSequenceNumber = &v2
+	}
+	if e.PayloadMaxSize == 0 {
+		e.PayloadMaxSize = defaultPayloadMaxSize
+	}
+
+	if e.PayloadMaxSize < (extSeqNumSize + segmentHeaderSize + e.layout.size) {
+		return fmt.Errorf("payload max size (%d) is too small", e.PayloadMaxSize)
+	}
+
+	e.sequenceNumber = uint32(*e.InitialSequenceNumber)
+
+	return nil
+}
+
+func (e *Encoder) writePacket(headers []segmentHeader, data [][]byte, marker bool) *rtp.Packet {
+	size := extSeqNumSize + len(headers)*segmentHeaderSize
+	for _, d := range data {
+		size += len(d)
+	}
+
+	payload := make([]byte, size)
+	payload[0] = byte(e.sequenceNumber >> 24)
+	payload[1] = byte(e.sequenceNumber >> 16)
+	n := extSeqNumSize
+
+	for i, h := range headers {
+		payload[n] = byte(h.length >> 8)
+		payload[n+1] = byte(h.length)
+		payload[n+2] = byte(h.line >> 8)
+		payload[n+3] = byte(h.line)
+		if h.field != 0 {
+			payload[n+2] |= 0x80
+		}
+		payload[n+4] = byte(h.offset >> 8)
+		payload[n+5] = byte(h.offset)
+		if i != (len(headers) - 1) {
+			payload[n+4] |= 0x80
+		}
+		n += segmentHeaderSize
+	}
+
+	for _, d := range data {
+		n += copy(payload[n:], d)
+	}
+
+	pkt := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        rtpVersion,
+			PayloadType:    e.PayloadType,
+			SequenceNumber: uint16(e.sequenceNumber),
+			SSRC:           *e.SSRC,
+			Marker:         marker,
+		},
+		Payload: payload,
+	}
+
+	e.sequenceNumber++
+
+	return pkt
+}
+
+func (e *Encoder) encodeField(frame []byte, field int) []*rtp.Packet {
+	var ret []*rtp.Packet
+	var headers []segmentHeader
+	var data [][]byte
+	avail := e.PayloadMaxSize - extSeqNumSize
+
+	for row := 0; row < e.layout.fieldRows(); row++ {
+		line := frame[e.layout.frameRow(field, row)*e.layout.stride:][:e.layout.stride]
+		pos := 0
+
+		for pos < len(line) {
+			n := ((avail - segmentHeaderSize) / e.layout.size) * e.layout.size
+
+			if n <= 0 {
+				ret = append(ret, e.writePacket(headers, data, false))
+				headers = headers[:0]
+				data = data[:0]
+				avail = e.PayloadMaxSize - extSeqNumSize
+				continue
+			}
+
+			if n > (len(line) - pos) {
+				n = len(line) - pos
+			}
+
+			headers = append(headers, segmentHeader{
+				length: n,
+				field:  field,
+				line:   row * e.layout.yLines,
+				offset: (pos / e.layout.size) * e.layout.xPixels,
+			})
+			data = append(data, line[pos:pos+n])
+			avail -= segmentHeaderSize + n
+			pos += n
+		}
+	}
+
+	return append(ret, e.writePacket(headers, data, true))
+}
+
+// Encode encodes a frame into RTP packets.
+// The frame must be a sequence of pgroups, starting from the top-left pixel,
+// with interlaced fields woven together.
+func (e *Encoder) Encode(frame []byte) ([]*rtp.Packet, error) {
+	if len(frame) != (e.layout.rows * e.layout.stride) {
+		return nil, fmt.Errorf("invalid frame size: %d, expected %d", len(frame), e.layout.rows*e.layout.stride)
+	}
+
+	ret := e.encodeField(frame, 0)
+
+	if e.layout.interlaced {
+		ret = append(ret, e.encodeField(frame, 1)...)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,256 @@
+package rtprawvideo
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func uint16Ptr(v uint16) *uint16 {
+	return &v
+}
+
+func uint32Ptr(v uint32) *uint32 {
+	return &v
+}
+
+func mergeBytes(vals ...[]byte) []byte {
+	size := 0
+	for _, v := range vals {
+		size += len(v)
+	}
+	res := make([]byte, size)
+
+	pos := 0
+	for _, v := range vals {
+		n := copy(res[pos:], v)
+		pos += n
+	}
+
+	return res
+}
+
+func sequence(start byte, count int) []byte {
+	ret := make([]byte, count)
+	for i := range ret {
+		ret[i] = start + byte(i)
+	}
+	return ret
+}
+
+var cases = []struct {
+	name           string
+	sampling       string
+	depth          int
+	width          int
+	height         int
+	interlaced     bool
+	payloadMaxSize int
+	frame          []byte
+	pkts           []*rtp.Packet
+}{
+	{
+		"single packet",
+		"YCbCr-4:2:2",
+		8,
+		4,
+		2,
+		false,
+		1000,
+		sequence(1, 16),
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					[]byte{
+						0x00, 0x00,
+						0x00, 0x08, 0x00, 0x00, 0x80, 0x00,
+						0x00, 0x08, 0x00, 0x01, 0x00, 0x00,
+					},
+					sequence(1, 16),
+				),
+			},
+		},
+	},
+	{
+		"line split between packets",
+		"RGB",
+		8,
+		4,
+		2,
+		false,
+		29,
+		sequence(1, 24),
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					[]byte{
+						0x00, 0x00,
+						0x00, 0x0c, 0x00, 0x00, 0x80, 0x00,
+						0x00, 0x03, 0x00, 0x01, 0x00, 0x00,
+					},
+					sequence(1, 15),
+				),
+			},
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    96,
+					SequenceNumber: 17646,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					[]byte{
+						0x00, 0x00,
+						0x00, 0x09, 0x00, 0x01, 0x00, 0x01,
+					},
+					sequence(16, 9),
+				),
+			},
+		},
+	},
+	{
+		"interlaced",
+		"YCbCr-4:2:2",
+		8,
+		2,
+		2,
+		true,
+		1000,
+		sequence(1, 8),
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{
+					0x00, 0x00,
+					0x00, 0x04, 0x00, 0x00, 0x00, 0x00,
+					0x01, 0x02, 0x03, 0x04,
+				},
+			},
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    96,
+					SequenceNumber: 17646,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{
+					0x00, 0x00,
+					0x00, 0x04, 0x80, 0x00, 0x00, 0x00,
+					0x05, 0x06, 0x07, 0x08,
+				},
+			},
+		},
+	},
+	{
+		"4:2:0",
+		"YCbCr-4:2:0",
+		8,
+		2,
+		4,
+		false,
+		1000,
+		sequence(1, 12),
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					[]byte{
+						0x00, 0x00,
+						0x00, 0x06, 0x00, 0x00, 0x80, 0x00,
+						0x00, 0x06, 0x00, 0x02, 0x00, 0x00,
+					},
+					sequence(1, 12),
+				),
+			},
+		},
+	},
+}
+
+func TestEncode(t *testing.T) {
+	for _, ca := range cases {
+		t.Run(ca.name, func(t *testing.T) {
+			e := &Encoder{
+				PayloadType:           96,
+				Sampling:              ca.sampling,
+				Depth:                 ca.depth,
+				Width:                 ca.width,
+				Height:                ca.height,
+				Interlaced:            ca.interlaced,
+				SSRC:                  uint32Ptr(0x9dbb7812),
+				InitialSequenceNumber: uint16Ptr(0x44ed),
+				PayloadMaxSize:        ca.payloadMaxSize,
+			}
+			err := e.Init()
+			require.NoError(t, err)
+
+			pkts, err := e.Encode(ca.frame)
+			require.NoError(t, err)
+			require.Equal(t, ca.pkts, pkts)
+		})
+	}
+}
+
+func TestEncodeExtendedSequenceNumber(t *testing.T) {
+	e := &Encoder{
+		PayloadType:           96,
+		Sampling:              "YCbCr-4:2:2",
+		Depth:                 8,
+		Width:                 2,
+		Height:                2,
+		Interlaced:            true,
+		SSRC:                  uint32Ptr(0x9dbb7812),
+		InitialSequenceNumber: uint16Ptr(0xffff),
+	}
+	err := e.Init()
+	require.NoError(t, err)
+
+	pkts, err := e.Encode(sequence(1, 8))
+	require.NoError(t, err)
+	require.Equal(t, uint16(0xffff), pkts[0].SequenceNumber)
+	require.Equal(t, []byte{0x00, 0x00}, pkts[0].Payload[:2])
+	require.Equal(t, uint16(0), pkts[1].SequenceNumber)
+	require.Equal(t, []byte{0x00, 0x01}, pkts[1].Payload[:2])
+}
+
+func TestEncodeRandomInitialState(t *testing.T) {
+	e := &Encoder{
+		PayloadType: 96,
+		Sampling:    "YCbCr-4:2:2",
+		Depth:       10,
+		Width:       1920,
+		Height:      1080,
+	}
+	err := e.Init()
+	require.NoError(t, err)
+	require.NotEqual(t, nil, e.SSRC)
+	require.NotEqual(t, nil, e.InitialSequenceNumber)
+}
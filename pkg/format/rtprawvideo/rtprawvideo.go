@@ -0,0 +1,139 @@
+// Package rtprawvideo contains a RTP/raw video decoder and encoder.
+package rtprawvideo
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	extSeqNumSize      = 2
+	segmentHeaderSize  = 6
+	maxFrameSize       = 256 * 1024 * 1024
+	maxLineOrOffset    = 0x7FFF
+	fieldOrContinueBit = 0x8000
+)
+
+// pgroup is the smallest group of pixels that is aligned to a byte boundary.
+type pgroup struct {
+	size    int // size in bytes
+	xPixels int // covered pixels on the horizontal axis
+	yLines  int // covered scan lines
+}
+
+func newPgroup(sampling string, depth int) (pgroup, error) {
+	// colorimetry-specific prefixes of SMPTE ST 2110-20 do not alter the layout.
+	sampling = strings.TrimPrefix(sampling, "CL")
+	sampling = strings.Replace(sampling, "ICtCp-", "YCbCr-", 1)
+
+	switch sampling {
+	case "YCbCr-4:2:2":
+		switch depth {
+		case 8:
+			return pgroup{4, 2, 1}, nil
+		case 10:
+			return pgroup{5, 2, 1}, nil
+		case 12:
+			return pgroup{6, 2, 1}, nil
+		case 16:
+			return pgroup{8, 2, 1}, nil
+		}
+
+	case "YCbCr-4:4:4", "RGB", "BGR", "XYZ":
+		switch depth {
+		case 8:
+			return pgroup{3, 1, 1}, nil
+		case 10:
+			return pgroup{15, 4, 1}, nil
+		case 12:
+			return pgroup{9, 2, 1}, nil
+		case 16:
+			return pgroup{6, 1, 1}, nil
+		}
+
+	case "RGBA", "BGRA":
+		switch depth {
+		case 8:
+			return pgroup{4, 1, 1}, nil
+		case 10:
+			return pgroup{5, 1, 1}, nil
+		case 12:
+			return pgroup{6, 1, 1}, nil
+		case 16:
+			return pgroup{8, 1, 1}, nil
+		}
+
+	case "YCbCr-4:2:0":
+		switch depth {
+		case 8:
+			return pgroup{6, 2, 2}, nil
+		case 10:
+			return pgroup{15, 4, 2}, nil
+		case 12:
+			return pgroup{9, 2, 2}, nil
+		case 16:
+			return pgroup{12, 2, 2}, nil
+		}
+
+	default:
+		return pgroup{}, fmt.Errorf("unsupported sampling: %s", sampling)
+	}
+
+	return pgroup{}, fmt.Errorf("unsupported depth: %d", depth)
+}
+
+// frameLayout describes how a frame is stored in memory:
+// a sequence of rows, each made of the pgroups that cover yLines scan lines.
+type frameLayout struct {
+	pgroup
+	rows       int
+	stride     int
+	interlaced bool
+}
+
+func (l *frameLayout) init(sampling string, depth int, width int, height int, interlaced bool) error {
+	var err error
+	l.pgroup, err = newPgroup(sampling, depth)
+	if err != nil {
+		return err
+	}
+
+	if width <= 0 || (width%l.xPixels) != 0 || (width-1) > maxLineOrOffset {
+		return fmt.Errorf("invalid width: %d", width)
+	}
+
+	lines := l.yLines
+	if interlaced {
+		lines *= 2
+	}
+
+	if height <= 0 || (height%lines) != 0 || (height-1) > maxLineOrOffset {
+		return fmt.Errorf("invalid height: %d", height)
+	}
+
+	l.rows = height / l.yLines
+	l.stride = (width / l.xPixels) * l.size
+	l.interlaced = interlaced
+
+	if (l.rows * l.stride) > maxFrameSize {
+		return fmt.Errorf("frame size (%d) is too big, maximum is %d", l.rows*l.stride, maxFrameSize)
+	}
+
+	return nil
+}
+
+// fieldRows returns the number of rows of each field.
+func (l *frameLayout) fieldRows() int {
+	if l.interlaced {
+		return l.rows / 2
+	}
+	return l.rows
+}
+
+// frameRow returns the position in the frame of a row of a field.
+func (l *frameLayout) frameRow(field int, row int) int {
+	if l.interlaced {
+		return row*2 + field
+	}
+	return row
+}
@@ -0,0 +1,55 @@
+package rtpspeex
+
+import (
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+// Decoder is a RTP/Speex decoder.
+// Specification: https://datatracker.ietf.org/doc/html/rfc5574
+type Decoder struct{}
+
+// Init initializes the decoder.
+func (d *Decoder) Init() error {
+	return nil
+}
+
+// Decode decodes frames from a RTP packet.
+// Each frame lasts 20ms and is returned in a separate buffer,
+// padded to the byte boundary, in order to be decodable independently.
+// In-band signaling is skipped.
+func (d *Decoder) Decode(pkt *rtp.Packet) ([][]byte, error) {
+	var frames [][]byte
+	pos := 0
+
+	for !isPadding(pkt.Payload, pos) {
+		var err error
+		pos, err = skipInBandSignaling(pkt.Payload, pos)
+		if err != nil {
+			return nil, err
+		}
+
+		if isPadding(pkt.Payload, pos) {
+			break
+		}
+
+		size, err := frameSize(pkt.Payload, pos)
+		if err != nil {
+			return nil, err
+		}
+
+		frame := make([]byte, (size+7)/8)
+		copyBits(frame, 0, pkt.Payload, pos, size)
+		writeTerminator(frame, size)
+
+		frames = append(frames, frame)
+		pos += size
+	}
+
+	if frames == nil {
+		return nil, fmt.Errorf("payload does not contain any frame")
+	}
+
+	return frames, nil
+}
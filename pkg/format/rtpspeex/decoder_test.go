@@ -0,0 +1,84 @@
+package rtpspeex
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	for _, ca := range cases {
+		t.Run(ca.name, func(t *testing.T) {
+			d := &Decoder{}
+			err := d.Init()
+			require.NoError(t, err)
+
+			var frames [][]byte
+
+			for _, pkt := range ca.pkts {
+				partial, err := d.Decode(pkt)
+				require.NoError(t, err)
+				frames = append(frames, partial...)
+			}
+
+			require.Equal(t, ca.frames, frames)
+		})
+	}
+}
+
+func TestDecodeInBandSignaling(t *testing.T) {
+	d := &Decoder{}
+	err := d.Init()
+	require.NoError(t, err)
+
+	// in-band request with a 4-bit value, narrowband frame (sub-mode 1),
+	// user in-band message of 1 byte, narrowband frame (sub-mode 1).
+	frames, err := d.Decode(&rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         false,
+			PayloadType:    96,
+			SequenceNumber: 17645,
+			SSRC:           0x9dbb7812,
+		},
+		Payload: []byte{
+			0x72, 0x50, 0x72, 0xe5, 0xcb, 0x97, 0x2e, 0x68,
+			0xdb, 0x28, 0x39, 0x72, 0xe5, 0xcb, 0x97, 0x3f,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{testFrameNB1, testFrameNB1}, frames)
+}
+
+func FuzzDecoder(f *testing.F) {
+	f.Fuzz(func(t *testing.T, b []byte) {
+		d := &Decoder{}
+		err := d.Init()
+		require.NoError(t, err)
+
+		frames, err := d.Decode(&rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         false,
+				PayloadType:    96,
+				SequenceNumber: 17645,
+				Timestamp:      2289527317,
+				SSRC:           0x9dbb7812,
+			},
+			Payload: b,
+		})
+
+		if err == nil {
+			if len(frames) == 0 {
+				t.Errorf("should not happen")
+			}
+
+			for _, frame := range frames {
+				if len(frame) == 0 {
+					t.Errorf("should not happen")
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package rtpspeex
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	rtpVersion            = 2
+	defaultPayloadMaxSize = 1450 // 1500 (UDP MTU) - 20 (IP header) - 8 (UDP header) - 12 (RTP header) - 10 (SRTP overhead)
+)
+
+func randUint32() (uint32, error) {
+	var b [4]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return 0, err
+	}
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3]), nil
+}
+
+// Encoder is a RTP/Speex encoder.
+// Specification: https://datatracker.ietf.org/doc/html/rfc5574
+type Encoder struct {
+	// payload type of packets.
+	PayloadType uint8
+
+	// sample rate of frames.
+	SampleRate int
+
+	// SSRC of packets (optional).
+	// It defaults to a random value.
+	SSRC *uint32
+
+	// initial sequence number of packets (optional).
+	// It defaults to a random value.
+	InitialSequenceNumber *uint16
+
+	// maximum size of packet payloads (optional).
+	// It defaults to 1450.
+	PayloadMaxSize int
+
+	sequenceNumber  uint16
+	samplesPerFrame int
+}
+
+// Init initializes the encoder.
+func (e *Encoder) Init() error {
+	if e.SampleRate == 0 {
+		return fmt.Errorf("sample rate is not set")
+	}
+
+	if e.SSRC == nil {
+		v, err := randUint32()
+		if err != nil {
+			return err
+		}
+		e.SSRC = &v
+	}
+	if e.InitialSequenceNumber == nil {
+		v, err := randUint32()
+		if err != nil {
+			return err
+		}
+		v2 := uint16(v)
+		e.InitialSequenceNumber = &v2
+	}
+	if e.PayloadMaxSize == 0 {
+		e.PayloadMaxSize = defaultPayloadMaxSize
+	}
+
+	e.sequenceNumber = *e.InitialSequenceNumber
+	e.samplesPerFrame = e.SampleRate / 50 // 20ms
+	return nil
+}
+
+// Encode encodes frames into RTP packets.
+// Each frame must contain a single Speex frame, padded to the byte boundary.
+// Frames are packed together into the same RTP packet, as long as they fit into it.
+func (e *Encoder) Encode(frames [][]byte) ([]*rtp.Packet, error) {
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames provided")
+	}
+
+	sizes := make([]int, len(frames))
+
+	for i, frame := range frames {
+		// in-band signaling that precedes the frame is kept.
+		start, err := skipInBandSignaling(frame, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := frameSize(frame, start)
+		if err != nil {
+			return nil, err
+		}
+		size += start
+
+		if !isPadding(frame, size) {
+			return nil, fmt.Errorf("frame contains trailing data")
+		}
+
+		if ((size + 7) / 8) > e.PayloadMaxSize {
+			return nil, fmt.Errorf("frame is too big")
+		}
+
+		sizes[i] = size
+	}
+
+	var rets []*rtp.Packet
+	timestamp := uint32(0)
+
+	for len(frames) != 0 {
+		n := 0
+		bits := 0
+
+		for n < len(frames) && ((bits+sizes[n]+7)/8) <= e.PayloadMaxSize {
+			bits += sizes[n]
+			n++
+		}
+
+		payload := make([]byte, (bits+7)/8)
+		pos := 0
+
+		for i := 0; i < n; i++ {
+			copyBits(payload, pos, frames[i], 0, sizes[i])
+			pos += sizes[i]
+		}
+
+		writeTerminator(payload, pos)
+
+		rets = append(rets, &rtp.Packet{
+			Header: rtp.Header{
+				Version:        rtpVersion,
+				PayloadType:    e.PayloadType,
+				SequenceNumber: e.sequenceNumber,
+				Timestamp:      timestamp,
+				SSRC:           *e.SSRC,
+				Marker:         false,
+			},
+			Payload: payload,
+		})
+
+		e.sequenceNumber++
+		timestamp += uint32(n * e.samplesPerFrame)
+		frames = frames[n:]
+		sizes = sizes[n:]
+	}
+
+	return rets, nil
+}
@@ -0,0 +1,184 @@
+package rtpspeex
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func uint16Ptr(v uint16) *uint16 {
+	return &v
+}
+
+func uint32Ptr(v uint32) *uint32 {
+	return &v
+}
+
+func mergeBytes(vals ...[]byte) []byte {
+	size := 0
+	for _, v := range vals {
+		size += len(v)
+	}
+	res := make([]byte, size)
+
+	pos := 0
+	for _, v := range vals {
+		n := copy(res[pos:], v)
+		pos += n
+	}
+
+	return res
+}
+
+func repeatFrames(frame []byte, n int) [][]byte {
+	ret := make([][]byte, n)
+	for i := range ret {
+		ret[i] = frame
+	}
+	return ret
+}
+
+// narrowband, sub-mode 3, 160 bits
+var testFrameNB3 = mergeBytes(
+	[]byte{0x1d},
+	bytes.Repeat([]byte{0x95}, 19),
+)
+
+// narrowband, sub-mode 1, 43 bits
+var testFrameNB1 = []byte{0x0e, 0x5c, 0xb9, 0x72, 0xe5, 0xcf}
+
+// narrowband, sub-mode 0, wideband, sub-mode 1, 41 bits
+var testFrameWB1 = []byte{0x04, 0xaa, 0xaa, 0xaa, 0xaa, 0xbf}
+
+var cases = []struct {
+	name   string
+	frames [][]byte
+	pkts   []*rtp.Packet
+}{
+	{
+		"single",
+		[][]byte{testFrameNB3},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: testFrameNB3,
+			},
+		},
+	},
+	{
+		"aggregated",
+		[][]byte{testFrameNB3, testFrameNB1, testFrameWB1},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					testFrameNB3,
+					[]byte{
+						0x0e, 0x5c, 0xb9, 0x72, 0xe5, 0xc0, 0x95, 0x55,
+						0x55, 0x55, 0x57,
+					},
+				),
+			},
+		},
+	},
+	{
+		"multiple packets",
+		repeatFrames(testFrameNB3, 60),
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: bytes.Repeat(testFrameNB3, 50),
+			},
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17646,
+					Timestamp:      8000,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: bytes.Repeat(testFrameNB3, 10),
+			},
+		},
+	},
+}
+
+func TestEncode(t *testing.T) {
+	for _, ca := range cases {
+		t.Run(ca.name, func(t *testing.T) {
+			e := &Encoder{
+				PayloadType:           96,
+				SampleRate:            8000,
+				SSRC:                  uint32Ptr(0x9dbb7812),
+				InitialSequenceNumber: uint16Ptr(0x44ed),
+				PayloadMaxSize:        1000,
+			}
+			err := e.Init()
+			require.NoError(t, err)
+
+			pkts, err := e.Encode(ca.frames)
+			require.NoError(t, err)
+			require.Equal(t, ca.pkts, pkts)
+		})
+	}
+}
+
+func TestEncodeRandomInitialState(t *testing.T) {
+	e := &Encoder{
+		PayloadType: 96,
+		SampleRate:  8000,
+	}
+	err := e.Init()
+	require.NoError(t, err)
+	require.NotEqual(t, nil, e.SSRC)
+	require.NotEqual(t, nil, e.InitialSequenceNumber)
+}
+
+func TestEncodeErrorTrailingData(t *testing.T) {
+	e := &Encoder{
+		PayloadType: 96,
+		SampleRate:  8000,
+	}
+	err := e.Init()
+	require.NoError(t, err)
+
+	_, err = e.Encode([][]byte{mergeBytes(testFrameNB3, testFrameNB3)})
+	require.EqualError(t, err, "frame contains trailing data")
+}
+
+func TestEncodeInBandSignaling(t *testing.T) {
+	e := &Encoder{
+		PayloadType:           96,
+		SampleRate:            8000,
+		SSRC:                  uint32Ptr(0x9dbb7812),
+		InitialSequenceNumber: uint16Ptr(0x44ed),
+	}
+	err := e.Init()
+	require.NoError(t, err)
+
+	// in-band request with a 4-bit value, followed by a narrowband frame (sub-mode 1).
+	pkts, err := e.Encode([][]byte{{0x72, 0x50, 0x72, 0xe5, 0xcb, 0x97, 0x2e}})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x72, 0x50, 0x72, 0xe5, 0xcb, 0x97, 0x2e}, pkts[0].Payload)
+}
@@ -0,0 +1,177 @@
+// Package rtpspeex contains a RTP/Speex decoder and encoder.
+package rtpspeex
+
+import (
+	"fmt"
+)
+
+// size in bits of narrowband frames, by sub-mode,
+// including the wideband bit and the sub-mode.
+var narrowbandFrameSizes = []int{5, 43, 119, 160, 220, 300, 364, 492, 79}
+
+// size in bits of wideband and ultra-wideband layers, by sub-mode,
+// including the wideband bit and the sub-mode.
+var widebandLayerSizes = []int{4, 36, 112, 192, 352}
+
+const (
+	// maximum number of layers that extend a narrowband frame (wideband, ultra-wideband).
+	maxWidebandLayers = 2
+
+	// sub-modes used for in-band signaling.
+	subModeUserInBand = 13
+	subModeInBand     = 14
+
+	// sub-mode used to mark the end of a payload.
+	subModeTerminator = 15
+)
+
+func readBits(buf []byte, pos int, n int) int {
+	v := 0
+	for i := 0; i < n; i++ {
+		v = v<<1 | int(buf[(pos+i)/8]>>(7-(pos+i)%8))&0x01
+	}
+	return v
+}
+
+func copyBits(dst []byte, dpos int, src []byte, spos int, n int) {
+	for i := 0; i < n; i++ {
+		bit := (src[(spos+i)/8] >> (7 - (spos+i)%8)) & 0x01
+		dst[(dpos+i)/8] |= bit << (7 - (dpos+i)%8)
+	}
+}
+
+// writeTerminator fills the remaining bits of the last byte with a zero followed by ones.
+func writeTerminator(dst []byte, dpos int) {
+	if (dpos % 8) == 0 {
+		return
+	}
+
+	for dpos++; (dpos % 8) != 0; dpos++ {
+		dst[dpos/8] |= 1 << (7 - dpos%8)
+	}
+}
+
+// isPadding checks whether the remaining bits of a payload are padding.
+func isPadding(buf []byte, pos int) bool {
+	remaining := len(buf)*8 - pos
+
+	if remaining < 5 {
+		return true
+	}
+
+	if readBits(buf, pos, 5) == subModeTerminator {
+		return true
+	}
+
+	return remaining < 8 && readBits(buf, pos, remaining) == 0
+}
+
+// inBandSignalingSize returns the size in bits of the in-band signaling
+// that starts at the given position, or zero if there's no in-band signaling.
+// Sizes are the ones used by the reference decoder to skip unhandled messages.
+func inBandSignalingSize(buf []byte, pos int) (int, error) {
+	total := len(buf) * 8
+
+	if (total-pos) < 5 || readBits(buf, pos, 1) != 0 {
+		return 0, nil
+	}
+
+	var size int
+
+	switch readBits(buf, pos+1, 4) {
+	case subModeInBand:
+		if (total - pos) < 9 {
+			return 0, fmt.Errorf("in-band signaling is truncated")
+		}
+
+		id := readBits(buf, pos+5, 4)
+
+		switch {
+		case id < 2:
+			size = 9 + 1
+		case id < 8:
+			size = 9 + 4
+		case id < 10:
+			size = 9 + 8
+		case id < 12:
+			size = 9 + 16
+		case id < 14:
+			size = 9 + 32
+		default:
+			size = 9 + 64
+		}
+
+	case subModeUserInBand:
+		if (total - pos) < 9 {
+			return 0, fmt.Errorf("in-band signaling is truncated")
+		}
+
+		size = 9 + 5 + 8*readBits(buf, pos+5, 4)
+
+	default:
+		return 0, nil
+	}
+
+	if (pos + size) > total {
+		return 0, fmt.Errorf("in-band signaling is truncated")
+	}
+
+	return size, nil
+}
+
+// skipInBandSignaling returns the position of the first frame
+// that follows the in-band signaling that starts at the given position.
+func skipInBandSignaling(buf []byte, pos int) (int, error) {
+	for {
+		size, err := inBandSignalingSize(buf, pos)
+		if err != nil {
+			return 0, err
+		}
+
+		if size == 0 {
+			return pos, nil
+		}
+
+		pos += size
+	}
+}
+
+// frameSize returns the size in bits of the frame that starts at the given position.
+func frameSize(buf []byte, pos int) (int, error) {
+	total := len(buf) * 8
+
+	if (total - pos) < 5 {
+		return 0, fmt.Errorf("frame is too short")
+	}
+
+	if readBits(buf, pos, 1) != 0 {
+		return 0, fmt.Errorf("frame does not start with a narrowband layer")
+	}
+
+	subMode := readBits(buf, pos+1, 4)
+
+	if subMode >= len(narrowbandFrameSizes) {
+		return 0, fmt.Errorf("invalid sub-mode (%d)", subMode)
+	}
+
+	size := narrowbandFrameSizes[subMode]
+
+	for i := 0; i < maxWidebandLayers; i++ {
+		if (pos+size+4) > total || readBits(buf, pos+size, 1) != 1 {
+			break
+		}
+
+		subMode = readBits(buf, pos+size+1, 3)
+		if subMode >= len(widebandLayerSizes) {
+			return 0, fmt.Errorf("invalid wideband sub-mode (%d)", subMode)
+		}
+
+		size += widebandLayerSizes[subMode]
+	}
+
+	if (pos + size) > total {
+		return 0, fmt.Errorf("frame is truncated")
+	}
+
+	return size, nil
+}
@@ -0,0 +1,181 @@
+package rtpvorbis
+
+import (
+	"fmt"
+)
+
+func readLacedValue(buf []byte) (int, int, error) {
+	v := 0
+	n := 0
+
+	for {
+		if n >= len(buf) {
+			return 0, 0, fmt.Errorf("buffer is too short")
+		}
+
+		b := buf[n]
+		n++
+		v += int(b)
+
+		if b != 255 {
+			return v, n, nil
+		}
+	}
+}
+
+func lacedValueSize(v int) int {
+	return v/255 + 1
+}
+
+func writeLacedValue(buf []byte, v int) int {
+	n := 0
+	for v >= 255 {
+		buf[n] = 255
+		v -= 255
+		n++
+	}
+	buf[n] = byte(v)
+	return n + 1
+}
+
+// Configuration is a Vorbis configuration,
+// made of the identification, comment and setup headers.
+// Specification: https://datatracker.ietf.org/doc/html/rfc5215#section-3.2.1
+type Configuration struct {
+	// configuration identifier.
+	Ident uint32
+
+	// identification, comment and setup headers.
+	Headers [][]byte
+}
+
+// unmarshalHeaders decodes packed headers.
+func (c *Configuration) unmarshalHeaders(buf []byte) error {
+	if len(buf) < 1 {
+		return fmt.Errorf("buffer is too short")
+	}
+
+	if buf[0] != 2 {
+		return fmt.Errorf("invalid number of headers (%d)", int(buf[0])+1)
+	}
+	buf = buf[1:]
+
+	len0, n, err := readLacedValue(buf)
+	if err != nil {
+		return err
+	}
+	buf = buf[n:]
+
+	len1, n, err := readLacedValue(buf)
+	if err != nil {
+		return err
+	}
+	buf = buf[n:]
+
+	if (len0 + len1) > len(buf) {
+		return fmt.Errorf("buffer is too short")
+	}
+
+	c.Headers = [][]byte{
+		buf[:len0],
+		buf[len0 : len0+len1],
+		buf[len0+len1:],
+	}
+
+	return nil
+}
+
+func (c Configuration) marshalHeadersSize() int {
+	return 1 + lacedValueSize(len(c.Headers[0])) + lacedValueSize(len(c.Headers[1])) +
+		len(c.Headers[0]) + len(c.Headers[1]) + len(c.Headers[2])
+}
+
+// marshalHeaders encodes packed headers.
+func (c Configuration) marshalHeaders() ([]byte, error) {
+	if len(c.Headers) != 3 {
+		return nil, fmt.Errorf("invalid number of headers (%d)", len(c.Headers))
+	}
+
+	buf := make([]byte, c.marshalHeadersSize())
+	buf[0] = 2
+	n := 1
+	n += writeLacedValue(buf[n:], len(c.Headers[0]))
+	n += writeLacedValue(buf[n:], len(c.Headers[1]))
+
+	for _, h := range c.Headers {
+		n += copy(buf[n:], h)
+	}
+
+	return buf, nil
+}
+
+// UnmarshalConfigurations decodes configurations delivered out-of-band,
+// that are contained in the "configuration" parameter of SDPs.
+func UnmarshalConfigurations(buf []byte) ([]*Configuration, error) {
+	if len(buf) < 4 {
+		return nil, fmt.Errorf("buffer is too short")
+	}
+
+	count := uint32(buf[0])<<24 | uint32(buf[1])<<16 | uint32(buf[2])<<8 | uint32(buf[3])
+	buf = buf[4:]
+
+	var confs []*Configuration
+
+	for i := uint32(0); i < count; i++ {
+		if len(buf) < 5 {
+			return nil, fmt.Errorf("buffer is too short")
+		}
+
+		ident := uint32(buf[0])<<16 | uint32(buf[1])<<8 | uint32(buf[2])
+		le := int(uint16(buf[3])<<8 | uint16(buf[4]))
+		buf = buf[5:]
+
+		if le > len(buf) {
+			return nil, fmt.Errorf("buffer is too short")
+		}
+
+		c := &Configuration{Ident: ident}
+		err := c.unmarshalHeaders(buf[:le])
+		if err != nil {
+			return nil, err
+		}
+		buf = buf[le:]
+
+		confs = append(confs, c)
+	}
+
+	return confs, nil
+}
+
+// MarshalConfigurations encodes configurations in order to be delivered out-of-band,
+// in the "configuration" parameter of SDPs.
+func MarshalConfigurations(confs []*Configuration) ([]byte, error) {
+	buf := []byte{
+		byte(len(confs) >> 24),
+		byte(len(confs) >> 16),
+		byte(len(confs) >> 8),
+		byte(len(confs)),
+	}
+
+	for _, c := range confs {
+		if c.Ident > 0xFFFFFF {
+			return nil, fmt.Errorf("invalid ident (%d)", c.Ident)
+		}
+
+		headers, err := c.marshalHeaders()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(headers) > 0xFFFF {
+			return nil, fmt.Errorf("headers are too big")
+		}
+
+		buf = append(buf,
+			byte(c.Ident>>16), byte(c.Ident>>8), byte(c.Ident),
+			byte(len(headers)>>8), byte(len(headers)))
+		buf = append(buf, headers...)
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,86 @@
+package rtpvorbis
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var casesConfiguration = []struct {
+	name  string
+	enc   []byte
+	confs []*Configuration
+}{
+	{
+		"single",
+		[]byte{
+			0x00, 0x00, 0x00, 0x01, 0x12, 0x34, 0x56, 0x00,
+			0x09, 0x02, 0x02, 0x01, 0x01, 0x02, 0x03, 0x04,
+			0x05, 0x06,
+		},
+		[]*Configuration{{
+			Ident:   0x123456,
+			Headers: [][]byte{{0x01, 0x02}, {0x03}, {0x04, 0x05, 0x06}},
+		}},
+	},
+	{
+		"multiple with laced lengths",
+		mergeBytes(
+			[]byte{
+				0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x01, 0x00,
+				0x07, 0x02, 0x01, 0x02, 0x01, 0x02, 0x03, 0x04,
+				0x00, 0x00, 0x02, 0x01, 0x32, 0x02, 0xff, 0x2d,
+				0x01,
+			},
+			bytes.Repeat([]byte{0x01}, 300),
+			[]byte{0x02, 0x03},
+		),
+		[]*Configuration{
+			{
+				Ident:   1,
+				Headers: [][]byte{{0x01}, {0x02, 0x03}, {0x04}},
+			},
+			{
+				Ident:   2,
+				Headers: [][]byte{bytes.Repeat([]byte{0x01}, 300), {0x02}, {0x03}},
+			},
+		},
+	},
+}
+
+func TestConfigurationsUnmarshal(t *testing.T) {
+	for _, ca := range casesConfiguration {
+		t.Run(ca.name, func(t *testing.T) {
+			confs, err := UnmarshalConfigurations(ca.enc)
+			require.NoError(t, err)
+			require.Equal(t, ca.confs, confs)
+		})
+	}
+}
+
+func TestConfigurationsMarshal(t *testing.T) {
+	for _, ca := range casesConfiguration {
+		t.Run(ca.name, func(t *testing.T) {
+			enc, err := MarshalConfigurations(ca.confs)
+			require.NoError(t, err)
+			require.Equal(t, ca.enc, enc)
+		})
+	}
+}
+
+func FuzzConfigurationsUnmarshal(f *testing.F) {
+	for _, ca := range casesConfiguration {
+		f.Add(ca.enc)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		confs, err := UnmarshalConfigurations(b)
+		if err != nil {
+			return
+		}
+
+		_, err = MarshalConfigurations(confs)
+		require.NoError(t, err)
+	})
+}
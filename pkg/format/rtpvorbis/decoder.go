@@ -0,0 +1,234 @@
+package rtpvorbis
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	// maximum number of configurations kept by the decoder.
+	maxConfigurations = 16
+)
+
+// ErrMorePacketsNeeded is returned when more packets are needed.
+var ErrMorePacketsNeeded = errors.New("need more packets")
+
+// ErrNonStartingPacketAndNoPrevious is returned when we received a non-starting
+// packet of a fragmented packet and we didn't received anything before.
+// It's normal to receive this when decoding a stream that has been already
+// running for some time.
+var ErrNonStartingPacketAndNoPrevious = errors.New(
+	"received a non-starting fragment without any previous starting fragment")
+
+// ErrMissingConfiguration is returned when we received packets whose
+// configuration has not been received yet.
+// It's normal to receive this when decoding a stream whose configuration
+// is delivered in-band, until the configuration is received.
+var ErrMissingConfiguration = errors.New("configuration has not been received yet")
+
+func joinFragments(fragments [][]byte, size int) []byte {
+	ret := make([]byte, size)
+	n := 0
+	for _, p := range fragments {
+		n += copy(ret[n:], p)
+	}
+	return ret
+}
+
+// Decoder is a RTP/Vorbis decoder.
+// Specification: https://datatracker.ietf.org/doc/html/rfc5215
+type Decoder struct {
+	// configurations delivered out-of-band (optional).
+	// It is the value of the "configuration" parameter of SDPs.
+	// Configurations can also be delivered in-band.
+	Configuration []byte
+
+	confs               map[uint32][][]byte
+	headers             [][]byte
+	firstPacketReceived bool
+	fragments           [][]byte
+	fragmentsSize       int
+	fragmentsIdent      uint32
+	fragmentsDataType   uint8
+	fragmentNextSeqNum  uint16
+}
+
+// Init initializes the decoder.
+func (d *Decoder) Init() error {
+	d.confs = make(map[uint32][][]byte)
+
+	if d.Configuration != nil {
+		confs, err := UnmarshalConfigurations(d.Configuration)
+		if err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+
+		for _, c := range confs {
+			d.confs[c.Ident] = c.Headers
+		}
+	}
+
+	return nil
+}
+
+// Headers returns the identification, comment and setup headers
+// that are needed to decode the packets returned by the last call to Decode().
+func (d *Decoder) Headers() [][]byte {
+	return d.headers
+}
+
+func (d *Decoder) resetFragments() {
+	d.fragments = d.fragments[:0]
+	d.fragmentsSize = 0
+}
+
+// Decode decodes Vorbis packets from a RTP packet.
+func (d *Decoder) Decode(pkt *rtp.Packet) ([][]byte, error) {
+	if len(pkt.Payload) < payloadHeaderSize {
+		d.resetFragments()
+		return nil, fmt.Errorf("payload is too short")
+	}
+
+	ident := uint32(pkt.Payload[0])<<16 | uint32(pkt.Payload[1])<<8 | uint32(pkt.Payload[2])
+	fragmentType := pkt.Payload[3] >> 6
+	dataType := (pkt.Payload[3] >> 4) & 0b11
+	count := int(pkt.Payload[3] & 0b1111)
+	payload := pkt.Payload[payloadHeaderSize:]
+
+	if dataType > dataTypeComment {
+		d.resetFragments()
+		return nil, fmt.Errorf("unsupported data type (%d)", dataType)
+	}
+
+	if fragmentType == fragmentTypeNone {
+		d.resetFragments()
+
+		if count == 0 {
+			return nil, fmt.Errorf("invalid packet count")
+		}
+
+		packets := make([][]byte, count)
+
+		for i := range packets {
+			if len(payload) < 2 {
+				return nil, fmt.Errorf("payload is too short")
+			}
+
+			size := int(uint16(payload[0])<<8 | uint16(payload[1]))
+			payload = payload[2:]
+
+			if size == 0 || size > len(payload) {
+				return nil, fmt.Errorf("invalid packet size")
+			}
+
+			packets[i], payload = payload[:size], payload[size:]
+		}
+
+		if len(payload) != 0 {
+			return nil, fmt.Errorf("payload contains trailing data")
+		}
+
+		d.firstPacketReceived = true
+
+		return d.handlePackets(ident, dataType, packets)
+	}
+
+	if len(payload) < 2 {
+		d.resetFragments()
+		return nil, fmt.Errorf("payload is too short")
+	}
+
+	size := int(uint16(payload[0])<<8 | uint16(payload[1]))
+	payload = payload[2:]
+
+	if size == 0 || size != len(payload) {
+		d.resetFragments()
+		return nil, fmt.Errorf("invalid fragment size")
+	}
+
+	if fragmentType == fragmentTypeStart {
+		d.resetFragments()
+
+		d.fragments = append(d.fragments, payload)
+		d.fragmentsSize = size
+		d.fragmentsIdent = ident
+		d.fragmentsDataType = dataType
+		d.fragmentNextSeqNum = pkt.SequenceNumber + 1
+		d.firstPacketReceived = true
+
+		return nil, ErrMorePacketsNeeded
+	}
+
+	if d.fragmentsSize == 0 {
+		if !d.firstPacketReceived {
+			return nil, ErrNonStartingPacketAndNoPrevious
+		}
+
+		return nil, fmt.Errorf("invalid fragment (non-starting)")
+	}
+
+	if pkt.SequenceNumber != d.fragmentNextSeqNum {
+		d.resetFragments()
+		return nil, fmt.Errorf("discarding frame since a RTP packet is missing")
+	}
+
+	if ident != d.fragmentsIdent || dataType != d.fragmentsDataType {
+		d.resetFragments()
+		return nil, fmt.Errorf("fragment has a different ident or data type than the previous ones")
+	}
+
+	d.fragmentsSize += size
+
+	if d.fragmentsSize > maxPacketSize {
+		errSize := d.fragmentsSize
+		d.resetFragments()
+		return nil, fmt.Errorf("packet size (%d) is too big, maximum is %d",
+			errSize, maxPacketSize)
+	}
+
+	d.fragments = append(d.fragments, payload)
+	d.fragmentNextSeqNum++
+
+	if fragmentType == fragmentTypeContinuation {
+		return nil, ErrMorePacketsNeeded
+	}
+
+	packet := joinFragments(d.fragments, d.fragmentsSize)
+	d.resetFragments()
+
+	return d.handlePackets(ident, dataType, [][]byte{packet})
+}
+
+func (d *Decoder) handlePackets(ident uint32, dataType uint8, packets [][]byte) ([][]byte, error) {
+	switch dataType {
+	case dataTypeRaw:
+		headers, ok := d.confs[ident]
+		if !ok {
+			return nil, ErrMissingConfiguration
+		}
+
+		d.headers = headers
+		return packets, nil
+
+	case dataTypeConfiguration:
+		for _, packet := range packets {
+			// copy the packet, since headers are kept after the call.
+			var c Configuration
+			err := c.unmarshalHeaders(append([]byte(nil), packet...))
+			if err != nil {
+				return nil, fmt.Errorf("invalid configuration: %w", err)
+			}
+
+			if _, ok := d.confs[ident]; !ok && len(d.confs) >= maxConfigurations {
+				return nil, fmt.Errorf("configuration count exceeds maximum allowed (%d)", maxConfigurations)
+			}
+
+			d.confs[ident] = c.Headers
+		}
+	}
+
+	// configuration and comment packets do not contain audio.
+	return nil, ErrMorePacketsNeeded
+}
@@ -0,0 +1,325 @@
+package rtpvorbis
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	conf, err := MarshalConfigurations([]*Configuration{{
+		Ident:   0x123456,
+		Headers: testHeaders,
+	}})
+	require.NoError(t, err)
+
+	for _, ca := range cases {
+		t.Run(ca.name, func(t *testing.T) {
+			d := &Decoder{
+				Configuration: conf,
+			}
+			err := d.Init()
+			require.NoError(t, err)
+
+			var packets [][]byte
+
+			for _, pkt := range ca.pkts {
+				clone := pkt.Clone()
+
+				addPackets, err := d.Decode(pkt)
+
+				// test input integrity
+				require.Equal(t, clone, pkt)
+
+				if errors.Is(err, ErrMorePacketsNeeded) {
+					continue
+				}
+
+				require.NoError(t, err)
+				packets = append(packets, addPackets...)
+			}
+
+			require.Equal(t, ca.packets, packets)
+			require.Equal(t, testHeaders, d.Headers())
+		})
+	}
+}
+
+func TestDecodeInBandConfiguration(t *testing.T) {
+	e := &Encoder{
+		PayloadType:    96,
+		Ident:          0x123456,
+		PayloadMaxSize: 1000,
+	}
+	err := e.Init()
+	require.NoError(t, err)
+
+	d := &Decoder{}
+	err = d.Init()
+	require.NoError(t, err)
+
+	pkts, err := e.Encode([][]byte{{0x01, 0x02}})
+	require.NoError(t, err)
+
+	_, err = d.Decode(pkts[0])
+	require.Equal(t, ErrMissingConfiguration, err)
+
+	pkts, err = e.EncodeConfiguration(testHeaders)
+	require.NoError(t, err)
+	require.Len(t, pkts, 2)
+
+	for _, pkt := range pkts {
+		_, err = d.Decode(pkt)
+		require.Equal(t, ErrMorePacketsNeeded, err)
+	}
+
+	pkts, err = e.Encode([][]byte{{0x01, 0x02}})
+	require.NoError(t, err)
+
+	packets, err := d.Decode(pkts[0])
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0x01, 0x02}}, packets)
+	require.Equal(t, testHeaders, d.Headers())
+}
+
+// headers and packets of a stream encoded with libvorbis (44100Hz, stereo).
+// A capture of a real RTP/Vorbis stream is not available, therefore the identification
+// and comment headers are the ones written by libvorbis, while the codebooks of the setup header
+// and audio packets are synthetic.
+var testStreamHeaders = [][]byte{
+	{ // identification: 2 channels, 44100Hz, 112kbit/s, block sizes 256 and 2048
+		0x01, 0x76, 0x6f, 0x72, 0x62, 0x69, 0x73, 0x00,
+		0x00, 0x00, 0x00, 0x02, 0x44, 0xac, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x80, 0xb5, 0x01, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0xb8, 0x01,
+	},
+	{ // comment: vendor "Xiph.Org libVorbis I 20200704 (Reducing Environment)", "encoder=Lavc61.3.100 libvorbis"
+		0x03, 0x76, 0x6f, 0x72, 0x62, 0x69, 0x73, 0x34,
+		0x00, 0x00, 0x00, 0x58, 0x69, 0x70, 0x68, 0x2e,
+		0x4f, 0x72, 0x67, 0x20, 0x6c, 0x69, 0x62, 0x56,
+		0x6f, 0x72, 0x62, 0x69, 0x73, 0x20, 0x49, 0x20,
+		0x32, 0x30, 0x32, 0x30, 0x30, 0x37, 0x30, 0x34,
+		0x20, 0x28, 0x52, 0x65, 0x64, 0x75, 0x63, 0x69,
+		0x6e, 0x67, 0x20, 0x45, 0x6e, 0x76, 0x69, 0x72,
+		0x6f, 0x6e, 0x6d, 0x65, 0x6e, 0x74, 0x29, 0x01,
+		0x00, 0x00, 0x00, 0x1e, 0x00, 0x00, 0x00, 0x65,
+		0x6e, 0x63, 0x6f, 0x64, 0x65, 0x72, 0x3d, 0x4c,
+		0x61, 0x76, 0x63, 0x36, 0x31, 0x2e, 0x33, 0x2e,
+		0x31, 0x30, 0x30, 0x20, 0x6c, 0x69, 0x62, 0x76,
+		0x6f, 0x72, 0x62, 0x69, 0x73, 0x01,
+	},
+	mergeBytes( // setup: 29 codebooks, starting with the codebook sync pattern
+		[]byte{0x05, 0x76, 0x6f, 0x72, 0x62, 0x69, 0x73, 0x1c, 0x42, 0x43, 0x56},
+		bytes.Repeat([]byte{0x00, 0x08, 0x00, 0x00, 0x31, 0x4c, 0x20, 0xc5}, 150),
+	),
+}
+
+var testStreamPackets = [][]byte{
+	{
+		0x2a, 0xa4, 0x29, 0x13, 0x35, 0x80, 0xe7, 0xcf,
+		0x7f, 0x8c, 0x38, 0x73,
+	},
+	{
+		0x3c, 0x55, 0xff, 0xc2, 0x73, 0x6d, 0x23, 0x8c,
+		0x31, 0x3e,
+	},
+	{
+		0x16, 0x2c, 0x57, 0x8e, 0x17, 0x51, 0x3d, 0x5e,
+		0x42, 0xcf, 0x91, 0x33, 0xe3, 0x05,
+	},
+	{
+		0x2a, 0xde, 0x69, 0x62, 0x69, 0xbe, 0x00, 0x26,
+		0x86, 0x35, 0x60,
+	},
+}
+
+func TestDecodeStream(t *testing.T) {
+	// packed headers are 1346 bytes long and are split into two packets.
+	packed := mergeBytes(
+		[]byte{0x02, 0x1e, 0x66},
+		testStreamHeaders[0],
+		testStreamHeaders[1],
+		testStreamHeaders[2],
+	)
+	require.Len(t, packed, 1346)
+
+	configurationPackets := []*rtp.Packet{
+		{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    96,
+				SequenceNumber: 31180,
+				Timestamp:      2919140394,
+				SSRC:           0x6b8b4567,
+			},
+			Payload: mergeBytes(
+				[]byte{0xfe, 0xcd, 0xba, 0x50, 0x03, 0xe2},
+				packed[:994],
+			),
+		},
+		{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    96,
+				SequenceNumber: 31181,
+				Timestamp:      2919140394,
+				SSRC:           0x6b8b4567,
+			},
+			Payload: mergeBytes(
+				[]byte{0xfe, 0xcd, 0xba, 0xd0, 0x01, 0x60},
+				packed[994:],
+			),
+		},
+	}
+
+	audioPackets := []*rtp.Packet{
+		{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    96,
+				SequenceNumber: 31182,
+				Timestamp:      2919140394,
+				SSRC:           0x6b8b4567,
+			},
+			Payload: mergeBytes(
+				[]byte{0xfe, 0xcd, 0xba, 0x03, 0x00, 0x0c},
+				testStreamPackets[0],
+				[]byte{0x00, 0x0a},
+				testStreamPackets[1],
+				[]byte{0x00, 0x0e},
+				testStreamPackets[2],
+			),
+		},
+		{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    96,
+				SequenceNumber: 31183,
+				Timestamp:      2919142442,
+				SSRC:           0x6b8b4567,
+			},
+			Payload: mergeBytes(
+				[]byte{0xfe, 0xcd, 0xba, 0x01, 0x00, 0x0b},
+				testStreamPackets[3],
+			),
+		},
+	}
+
+	t.Run("out-of-band configuration", func(t *testing.T) {
+		conf, err := MarshalConfigurations([]*Configuration{{
+			Ident:   0xfecdba,
+			Headers: testStreamHeaders,
+		}})
+		require.NoError(t, err)
+
+		d := &Decoder{
+			Configuration: conf,
+		}
+		err = d.Init()
+		require.NoError(t, err)
+
+		var packets [][]byte
+
+		for _, pkt := range audioPackets {
+			var addPackets [][]byte
+			addPackets, err = d.Decode(pkt)
+			require.NoError(t, err)
+			packets = append(packets, addPackets...)
+		}
+
+		require.Equal(t, testStreamPackets, packets)
+		require.Equal(t, testStreamHeaders, d.Headers())
+	})
+
+	t.Run("in-band configuration", func(t *testing.T) {
+		d := &Decoder{}
+		err := d.Init()
+		require.NoError(t, err)
+
+		for _, pkt := range configurationPackets {
+			_, err = d.Decode(pkt)
+			require.Equal(t, ErrMorePacketsNeeded, err)
+		}
+
+		var packets [][]byte
+
+		for _, pkt := range audioPackets {
+			var addPackets [][]byte
+			addPackets, err = d.Decode(pkt)
+			require.NoError(t, err)
+			packets = append(packets, addPackets...)
+		}
+
+		require.Equal(t, testStreamPackets, packets)
+		require.Equal(t, testStreamHeaders, d.Headers())
+	})
+}
+
+func TestDecodeErrorMissingPacket(t *testing.T) {
+	d := &Decoder{}
+	err := d.Init()
+	require.NoError(t, err)
+
+	_, err = d.Decode(&rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         false,
+			PayloadType:    96,
+			SequenceNumber: 17645,
+			SSRC:           0x9dbb7812,
+		},
+		Payload: []byte{0x12, 0x34, 0x56, 0x40, 0x00, 0x02, 0x01, 0x02},
+	})
+	require.Equal(t, ErrMorePacketsNeeded, err)
+
+	_, err = d.Decode(&rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         false,
+			PayloadType:    96,
+			SequenceNumber: 17647,
+			SSRC:           0x9dbb7812,
+		},
+		Payload: []byte{0x12, 0x34, 0x56, 0xc0, 0x00, 0x02, 0x03, 0x04},
+	})
+	require.EqualError(t, err, "discarding frame since a RTP packet is missing")
+}
+
+func FuzzDecoder(f *testing.F) {
+	f.Fuzz(func(t *testing.T, a []byte, b []byte) {
+		d := &Decoder{}
+		err := d.Init()
+		require.NoError(t, err)
+
+		packets, err := d.Decode(&rtp.Packet{
+			Header: rtp.Header{
+				SequenceNumber: 17645,
+			},
+			Payload: a,
+		})
+
+		if errors.Is(err, ErrMorePacketsNeeded) {
+			packets, err = d.Decode(&rtp.Packet{
+				Header: rtp.Header{
+					SequenceNumber: 17646,
+				},
+				Payload: b,
+			})
+		}
+
+		if err == nil {
+			if len(packets) == 0 {
+				t.Errorf("should not happen")
+			}
+
+			for _, packet := range packets {
+				if len(packet) == 0 {
+					t.Errorf("should not happen")
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package rtpvorbis
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	rtpVersion            = 2
+	defaultPayloadMaxSize = 1450 // 1500 (UDP MTU) - 20 (IP header) - 8 (UDP header) - 12 (RTP header) - 10 (SRTP overhead)
+)
+
+func randUint32() (uint32, error) {
+	var b [4]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return 0, err
+	}
+	return uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3]), nil
+}
+
+func packetCount(avail, le int) int {
+	n := le / avail
+	if (le % avail) != 0 {
+		n++
+	}
+	return n
+}
+
+// Encoder is a RTP/Vorbis encoder.
+// Specification: https://datatracker.ietf.org/doc/html/rfc5215
+type Encoder struct {
+	// payload type of packets.
+	PayloadType uint8
+
+	// identifier of the configuration of packets.
+	Ident uint32
+
+	// SSRC of packets (optional).
+	// It defaults to a random value.
+	SSRC *uint32
+
+	// initial sequence number of packets (optional).
+	// It defaults to a random value.
+	InitialSequenceNumber *uint16
+
+	// maximum size of packet payloads (optional).
+	// It defaults to 1450.
+	PayloadMaxSize int
+
+	sequenceNumber uint16
+}
+
+// Init initializes the encoder.
+func (e *Encoder) Init() error {
+	if e.Ident > 0xFFFFFF {
+		return fmt.Errorf("invalid ident (%d)", e.Ident)
+	}
+
+	if e.SSRC == nil {
+		v, err := randUint32()
+		if err != nil {
+			return err
+		}
+		e.SSRC = &v
+	}
+	if e.InitialSequenceNumber == nil {
+		v, err := randUint32()
+		if err != nil {
+			return err
+		}
+		v2 := uint16(v)
+		e.InitialSequenceNumber = &v2
+	}
+	if e.PayloadMaxSize == 0 {
+		e.PayloadMaxSize = defaultPayloadMaxSize
+	}
+
+	if e.PayloadMaxSize <= (payloadHeaderSize + 2) {
+		return fmt.Errorf("payload max size (%d) is too small", e.PayloadMaxSize)
+	}
+
+	e.sequenceNumber = *e.InitialSequenceNumber
+	return nil
+}
+
+// Encode encodes Vorbis packets into RTP packets.
+// Since the RTP timestamp is the one of the first Vorbis packet,
+// multiple Vorbis packets are put into a single RTP packet and an error is returned
+// if they don't fit into it. A single Vorbis packet is fragmented when needed.
+func (e *Encoder) Encode(packets [][]byte) ([]*rtp.Packet, error) {
+	if len(packets) == 0 {
+		return nil, fmt.Errorf("no packets provided")
+	}
+
+	if len(packets) == 1 {
+		return e.writePacket(dataTypeRaw, packets[0])
+	}
+
+	return e.writeAggregated(dataTypeRaw, packets)
+}
+
+// EncodeConfiguration encodes the identification, comment and setup headers
+// into RTP packets, in order to deliver the configuration in-band.
+func (e *Encoder) EncodeConfiguration(headers [][]byte) ([]*rtp.Packet, error) {
+	c := Configuration{
+		Ident:   e.Ident,
+		Headers: headers,
+	}
+
+	buf, err := c.marshalHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	return e.writePacket(dataTypeConfiguration, buf)
+}
+
+func (e *Encoder) writePacket(dataType uint8, packet []byte) ([]*rtp.Packet, error) {
+	if len(packet) == 0 {
+		return nil, fmt.Errorf("packet is empty")
+	}
+
+	if (payloadHeaderSize + 2 + len(packet)) <= e.PayloadMaxSize {
+		return e.writeAggregated(dataType, [][]byte{packet})
+	}
+
+	return e.writeFragmented(dataType, packet)
+}
+
+func (e *Encoder) writeAggregated(dataType uint8, packets [][]byte) ([]*rtp.Packet, error) {
+	if len(packets) > maxPacketsPerRTPPacket {
+		return nil, fmt.Errorf("packet count (%d) exceeds maximum allowed (%d)",
+			len(packets), maxPacketsPerRTPPacket)
+	}
+
+	size := payloadHeaderSize
+	for _, packet := range packets {
+		if len(packet) == 0 {
+			return nil, fmt.Errorf("packet is empty")
+		}
+		size += 2 + len(packet)
+	}
+
+	if size > e.PayloadMaxSize {
+		return nil, fmt.Errorf("packets don't fit into a single RTP packet")
+	}
+
+	payload := make([]byte, size)
+	marshalPayloadHeader(payload, e.Ident, fragmentTypeNone, dataType, len(packets))
+	n := payloadHeaderSize
+
+	for _, packet := range packets {
+		payload[n] = byte(len(packet) >> 8)
+		payload[n+1] = byte(len(packet))
+		n += 2
+		n += copy(payload[n:], packet)
+	}
+
+	pkt := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        rtpVersion,
+			PayloadType:    e.PayloadType,
+			SequenceNumber: e.sequenceNumber,
+			SSRC:           *e.SSRC,
+			Marker:         false,
+		},
+		Payload: payload,
+	}
+
+	e.sequenceNumber++
+
+	return []*rtp.Packet{pkt}, nil
+}
+
+func (e *Encoder) writeFragmented(dataType uint8, packet []byte) ([]*rtp.Packet, error) {
+	avail := e.PayloadMaxSize - payloadHeaderSize - 2
+	le := len(packet)
+	packetCount := packetCount(avail, le)
+
+	ret := make([]*rtp.Packet, packetCount)
+	le = avail
+
+	for i := range ret {
+		var fragmentType uint8
+
+		switch {
+		case i == 0:
+			fragmentType = fragmentTypeStart
+
+		case i == (packetCount - 1):
+			fragmentType = fragmentTypeEnd
+			le = len(packet)
+
+		default:
+			fragmentType = fragmentTypeContinuation
+		}
+
+		payload := make([]byte, payloadHeaderSize+2+le)
+		marshalPayloadHeader(payload, e.Ident, fragmentType, dataType, 0)
+		payload[payloadHeaderSize] = byte(le >> 8)
+		payload[payloadHeaderSize+1] = byte(le)
+		copy(payload[payloadHeaderSize+2:], packet)
+		packet = packet[le:]
+
+		ret[i] = &rtp.Packet{
+			Header: rtp.Header{
+				Version:        rtpVersion,
+				PayloadType:    e.PayloadType,
+				SequenceNumber: e.sequenceNumber,
+				SSRC:           *e.SSRC,
+				Marker:         false,
+			},
+			Payload: payload,
+		}
+
+		e.sequenceNumber++
+	}
+
+	return ret, nil
+}
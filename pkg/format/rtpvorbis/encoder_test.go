@@ -0,0 +1,244 @@
+package rtpvorbis
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func uint16Ptr(v uint16) *uint16 {
+	return &v
+}
+
+func uint32Ptr(v uint32) *uint32 {
+	return &v
+}
+
+func mergeBytes(vals ...[]byte) []byte {
+	size := 0
+	for _, v := range vals {
+		size += len(v)
+	}
+	res := make([]byte, size)
+
+	pos := 0
+	for _, v := range vals {
+		n := copy(res[pos:], v)
+		pos += n
+	}
+
+	return res
+}
+
+var testHeaders = [][]byte{
+	{ // identification: 2 channels, 44100Hz, 128kbit/s, block sizes 256 and 2048
+		0x01, 0x76, 0x6f, 0x72, 0x62, 0x69, 0x73, 0x00,
+		0x00, 0x00, 0x00, 0x02, 0x44, 0xac, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0xf4, 0x01, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0xb8, 0x01,
+	},
+	{ // comment: vendor "gortsplib"
+		0x03, 0x76, 0x6f, 0x72, 0x62, 0x69, 0x73, 0x09,
+		0x00, 0x00, 0x00, 0x67, 0x6f, 0x72, 0x74, 0x73,
+		0x70, 0x6c, 0x69, 0x62, 0x00, 0x00, 0x00, 0x00,
+		0x01,
+	},
+	mergeBytes( // setup
+		[]byte{0x05, 0x76, 0x6f, 0x72, 0x62, 0x69, 0x73},
+		bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04}, 250),
+	),
+}
+
+var cases = []struct {
+	name    string
+	packets [][]byte
+	pkts    []*rtp.Packet
+}{
+	{
+		"single",
+		[][]byte{{0x01, 0x02, 0x03, 0x04}},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{
+					0x12, 0x34, 0x56, 0x01, 0x00, 0x04, 0x01, 0x02,
+					0x03, 0x04,
+				},
+			},
+		},
+	},
+	{
+		"aggregated",
+		[][]byte{
+			{0x01, 0x02, 0x03},
+			{0x04, 0x05},
+			{0x06},
+		},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: []byte{
+					0x12, 0x34, 0x56, 0x03, 0x00, 0x03, 0x01, 0x02,
+					0x03, 0x00, 0x02, 0x04, 0x05, 0x00, 0x01, 0x06,
+				},
+			},
+		},
+	},
+	{
+		"fragmented",
+		[][]byte{bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04}, 500)},
+		[]*rtp.Packet{
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17645,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					[]byte{0x12, 0x34, 0x56, 0x40, 0x03, 0xe2},
+					bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04}, 248),
+					[]byte{0x01, 0x02},
+				),
+			},
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17646,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					[]byte{0x12, 0x34, 0x56, 0x80, 0x03, 0xe2},
+					[]byte{0x03, 0x04},
+					bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04}, 248),
+				),
+			},
+			{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         false,
+					PayloadType:    96,
+					SequenceNumber: 17647,
+					SSRC:           0x9dbb7812,
+				},
+				Payload: mergeBytes(
+					[]byte{0x12, 0x34, 0x56, 0xc0, 0x00, 0x0c},
+					bytes.Repeat([]byte{0x01, 0x02, 0x03, 0x04}, 3),
+				),
+			},
+		},
+	},
+}
+
+func TestEncode(t *testing.T) {
+	for _, ca := range cases {
+		t.Run(ca.name, func(t *testing.T) {
+			e := &Encoder{
+				PayloadType:           96,
+				Ident:                 0x123456,
+				SSRC:                  uint32Ptr(0x9dbb7812),
+				InitialSequenceNumber: uint16Ptr(0x44ed),
+				PayloadMaxSize:        1000,
+			}
+			err := e.Init()
+			require.NoError(t, err)
+
+			pkts, err := e.Encode(ca.packets)
+			require.NoError(t, err)
+			require.Equal(t, ca.pkts, pkts)
+		})
+	}
+}
+
+func TestEncodeRandomInitialState(t *testing.T) {
+	e := &Encoder{
+		PayloadType: 96,
+	}
+	err := e.Init()
+	require.NoError(t, err)
+	require.NotEqual(t, nil, e.SSRC)
+	require.NotEqual(t, nil, e.InitialSequenceNumber)
+}
+
+func TestEncodeConfiguration(t *testing.T) {
+	e := &Encoder{
+		PayloadType:           96,
+		Ident:                 0x123456,
+		SSRC:                  uint32Ptr(0x9dbb7812),
+		InitialSequenceNumber: uint16Ptr(0x44ed),
+		PayloadMaxSize:        1000,
+	}
+	err := e.Init()
+	require.NoError(t, err)
+
+	pkts, err := e.EncodeConfiguration(testHeaders)
+	require.NoError(t, err)
+
+	packed := mergeBytes(
+		[]byte{0x02, 0x1e, 0x19},
+		testHeaders[0],
+		testHeaders[1],
+		testHeaders[2],
+	)
+
+	require.Equal(t, []*rtp.Packet{
+		{
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         false,
+				PayloadType:    96,
+				SequenceNumber: 17645,
+				SSRC:           0x9dbb7812,
+			},
+			Payload: mergeBytes(
+				[]byte{0x12, 0x34, 0x56, 0x50, 0x03, 0xe2},
+				packed[:994],
+			),
+		},
+		{
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         false,
+				PayloadType:    96,
+				SequenceNumber: 17646,
+				SSRC:           0x9dbb7812,
+			},
+			Payload: mergeBytes(
+				[]byte{0x12, 0x34, 0x56, 0xd0, 0x00, 0x47},
+				packed[994:],
+			),
+		},
+	}, pkts)
+}
+
+func TestEncodeErrorTooManyPackets(t *testing.T) {
+	e := &Encoder{
+		PayloadType:    96,
+		PayloadMaxSize: 1000,
+	}
+	err := e.Init()
+	require.NoError(t, err)
+
+	_, err = e.Encode([][]byte{
+		bytes.Repeat([]byte{1}, 600),
+		bytes.Repeat([]byte{2}, 600),
+	})
+	require.EqualError(t, err, "packets don't fit into a single RTP packet")
+}
@@ -0,0 +1,35 @@
+// Package rtpvorbis contains a RTP/Vorbis decoder and encoder.
+package rtpvorbis
+
+// fragment types.
+const (
+	fragmentTypeNone         = 0
+	fragmentTypeStart        = 1
+	fragmentTypeContinuation = 2
+	fragmentTypeEnd          = 3
+)
+
+// Vorbis data types.
+const (
+	dataTypeRaw           = 0
+	dataTypeConfiguration = 1
+	dataTypeComment       = 2
+)
+
+const (
+	// size of the payload header.
+	payloadHeaderSize = 4
+
+	// maximum number of packets in a RTP packet.
+	maxPacketsPerRTPPacket = 15
+
+	// maximum size of a packet.
+	maxPacketSize = 1 * 1024 * 1024
+)
+
+func marshalPayloadHeader(buf []byte, ident uint32, fragmentType uint8, dataType uint8, count int) {
+	buf[0] = byte(ident >> 16)
+	buf[1] = byte(ident >> 8)
+	buf[2] = byte(ident)
+	buf[3] = fragmentType<<6 | dataType<<4 | uint8(count)
+}
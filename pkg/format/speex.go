@@ -5,6 +5,8 @@ import (
 	"strconv"
 
 	"github.com/pion/rtp"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format/rtpspeex"
 )
 
 // Speex is the RTP format for the Speex codec.
@@ -77,3 +79,30 @@ func (f *Speex) FMTP() map[string]string {
 func (f *Speex) PTSEqualsDTS(*rtp.Packet) bool {
 	return true
 }
+
+// CreateDecoder creates a decoder able to decode the content of the format.
+func (f *Speex) CreateDecoder() (*rtpspeex.Decoder, error) {
+	d := &rtpspeex.Decoder{}
+
+	err := d.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// CreateEncoder creates an encoder able to encode the content of the format.
+func (f *Speex) CreateEncoder() (*rtpspeex.Encoder, error) {
+	e := &rtpspeex.Encoder{
+		PayloadType: f.PayloadTyp,
+		SampleRate:  f.SampleRate,
+	}
+
+	err := e.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
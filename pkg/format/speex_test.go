@@ -16,3 +16,27 @@ func TestSpeexAttributes(t *testing.T) {
 	require.Equal(t, 16000, format.ClockRate())
 	require.Equal(t, true, format.PTSEqualsDTS(&rtp.Packet{}))
 }
+
+func TestSpeexDecEncoder(t *testing.T) {
+	format := &Speex{
+		PayloadTyp: 96,
+		SampleRate: 8000,
+	}
+
+	// narrowband, sub-mode 0
+	frame := []byte{0x03}
+
+	enc, err := format.CreateEncoder()
+	require.NoError(t, err)
+
+	pkts, err := enc.Encode([][]byte{frame, frame})
+	require.NoError(t, err)
+	require.Equal(t, format.PayloadType(), pkts[0].PayloadType)
+
+	dec, err := format.CreateDecoder()
+	require.NoError(t, err)
+
+	frames, err := dec.Decode(pkts[0])
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{frame, frame}, frames)
+}
@@ -0,0 +1,57 @@
+package format
+
+import (
+	"strconv"
+
+	"github.com/pion/rtp"
+)
+
+// ULPFEC is the RTP format for generic forward error correction.
+// FEC packets protect the media that is in the same FEC group (a=group:FEC).
+// Specification: https://datatracker.ietf.org/doc/html/rfc5109
+type ULPFEC struct {
+	PayloadTyp uint8
+	ClockRat   int
+}
+
+func (f *ULPFEC) unmarshal(ctx *unmarshalContext) error {
+	f.PayloadTyp = ctx.payloadType
+
+	tmp, err := strconv.ParseUint(ctx.clock, 10, 31)
+	if err != nil {
+		return err
+	}
+	f.ClockRat = int(tmp)
+
+	return nil
+}
+
+// Codec implements Format.
+func (f *ULPFEC) Codec() string {
+	return "ULPFEC"
+}
+
+// ClockRate implements Format.
+func (f *ULPFEC) ClockRate() int {
+	return f.ClockRat
+}
+
+// PayloadType implements Format.
+func (f *ULPFEC) PayloadType() uint8 {
+	return f.PayloadTyp
+}
+
+// RTPMap implements Format.
+func (f *ULPFEC) RTPMap() string {
+	return "ulpfec/" + strconv.FormatInt(int64(f.ClockRat), 10)
+}
+
+// FMTP implements Format.
+func (f *ULPFEC) FMTP() map[string]string {
+	return nil
+}
+
+// PTSEqualsDTS implements Format.
+func (f *ULPFEC) PTSEqualsDTS(*rtp.Packet) bool {
+	return false
+}
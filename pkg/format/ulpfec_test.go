@@ -0,0 +1,18 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestULPFECAttributes(t *testing.T) {
+	format := &ULPFEC{
+		PayloadTyp: 100,
+		ClockRat:   8000,
+	}
+	require.Equal(t, "ULPFEC", format.Codec())
+	require.Equal(t, 8000, format.ClockRate())
+	require.Equal(t, false, format.PTSEqualsDTS(&rtp.Packet{}))
+}
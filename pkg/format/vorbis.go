@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/pion/rtp"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format/rtpvorbis"
 )
 
 // Vorbis is the RTP format for the Vorbis codec.
@@ -90,3 +92,42 @@ func (f *Vorbis) FMTP() map[string]string {
 func (f *Vorbis) PTSEqualsDTS(*rtp.Packet) bool {
 	return true
 }
+
+// CreateDecoder creates a decoder able to decode the content of the format.
+func (f *Vorbis) CreateDecoder() (*rtpvorbis.Decoder, error) {
+	d := &rtpvorbis.Decoder{
+		Configuration: f.Configuration,
+	}
+
+	err := d.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// CreateEncoder creates an encoder able to encode the content of the format.
+// Packets are tagged with the identifier of the first configuration.
+func (f *Vorbis) CreateEncoder() (*rtpvorbis.Encoder, error) {
+	confs, err := rtpvorbis.UnmarshalConfigurations(f.Configuration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	if len(confs) == 0 {
+		return nil, fmt.Errorf("configuration is empty")
+	}
+
+	e := &rtpvorbis.Encoder{
+		PayloadType: f.PayloadTyp,
+		Ident:       confs[0].Ident,
+	}
+
+	err = e.Init()
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
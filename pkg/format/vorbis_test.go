@@ -18,3 +18,31 @@ func TestVorbisAttributes(t *testing.T) {
 	require.Equal(t, 48000, format.ClockRate())
 	require.Equal(t, true, format.PTSEqualsDTS(&rtp.Packet{}))
 }
+
+func TestVorbisDecEncoder(t *testing.T) {
+	format := &Vorbis{
+		PayloadTyp:   96,
+		SampleRate:   48000,
+		ChannelCount: 2,
+		Configuration: []byte{
+			0x00, 0x00, 0x00, 0x01, 0x12, 0x34, 0x56, 0x00,
+			0x09, 0x02, 0x02, 0x01, 0x01, 0x02, 0x03, 0x04,
+			0x05, 0x06,
+		},
+	}
+
+	enc, err := format.CreateEncoder()
+	require.NoError(t, err)
+
+	pkts, err := enc.Encode([][]byte{{0x01, 0x02, 0x03, 0x04}})
+	require.NoError(t, err)
+	require.Equal(t, format.PayloadType(), pkts[0].PayloadType)
+
+	dec, err := format.CreateDecoder()
+	require.NoError(t, err)
+
+	packets, err := dec.Decode(pkts[0])
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0x01, 0x02, 0x03, 0x04}}, packets)
+	require.Equal(t, [][]byte{{0x01, 0x02}, {0x03}, {0x04, 0x05, 0x06}}, dec.Headers())
+}
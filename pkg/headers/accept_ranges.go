@@ -0,0 +1,39 @@
+package headers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// AcceptRanges is an Accept-Ranges header (RTSP 2.0).
+// It contains the range units supported by the server, like "npt", "smpte" or "clock".
+type AcceptRanges []string
+
+// Unmarshal decodes an Accept-Ranges header.
+func (h *AcceptRanges) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	*h = nil
+
+	for _, unit := range splitList(v[0]) {
+		if unit == "" {
+			return fmt.Errorf("invalid value (%v)", v[0])
+		}
+		*h = append(*h, strings.ToLower(unit))
+	}
+
+	return nil
+}
+
+// Marshal encodes an Accept-Ranges header.
+func (h AcceptRanges) Marshal() base.HeaderValue {
+	return base.HeaderValue{strings.Join(h, ", ")}
+}
@@ -0,0 +1,85 @@
+package headers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+var casesAcceptRanges = []struct {
+	name string
+	vin  base.HeaderValue
+	vout base.HeaderValue
+	h    AcceptRanges
+}{
+	{
+		"single",
+		base.HeaderValue{`npt`},
+		base.HeaderValue{`npt`},
+		AcceptRanges{"npt"},
+	},
+	{
+		"multiple",
+		base.HeaderValue{`NPT, SMPTE,clock`},
+		base.HeaderValue{`npt, smpte, clock`},
+		AcceptRanges{"npt", "smpte", "clock"},
+	},
+}
+
+func TestAcceptRangesUnmarshal(t *testing.T) {
+	for _, ca := range casesAcceptRanges {
+		t.Run(ca.name, func(t *testing.T) {
+			var h AcceptRanges
+			err := h.Unmarshal(ca.vin)
+			require.NoError(t, err)
+			require.Equal(t, ca.h, h)
+		})
+	}
+}
+
+func TestAcceptRangesMarshal(t *testing.T) {
+	for _, ca := range casesAcceptRanges {
+		t.Run(ca.name, func(t *testing.T) {
+			req := ca.h.Marshal()
+			require.Equal(t, ca.vout, req)
+		})
+	}
+}
+
+func FuzzAcceptRangesUnmarshal(f *testing.F) {
+	for _, ca := range casesAcceptRanges {
+		f.Add(ca.vin[0])
+	}
+
+	f.Fuzz(func(_ *testing.T, b string) {
+		var h AcceptRanges
+		err := h.Unmarshal(base.HeaderValue{b})
+		if err != nil {
+			return
+		}
+
+		h.Marshal()
+	})
+}
+
+func TestAcceptRangesAdditionalErrors(t *testing.T) {
+	func() {
+		var h AcceptRanges
+		err := h.Unmarshal(base.HeaderValue{})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h AcceptRanges
+		err := h.Unmarshal(base.HeaderValue{"a", "b"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h AcceptRanges
+		err := h.Unmarshal(base.HeaderValue{"npt,,clock"})
+		require.Error(t, err)
+	}()
+}
@@ -0,0 +1,84 @@
+package headers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// FramesType is the type of frames requested by a Frames header.
+type FramesType int
+
+// frames types.
+const (
+	FramesTypeIntra FramesType = iota
+	FramesTypePredicted
+)
+
+// Frames is a Frames header (ONVIF).
+type Frames struct {
+	// type of frames to send.
+	Type FramesType
+
+	// (optional) minimum interval between intra frames.
+	// It can be used with FramesTypeIntra only.
+	Interval *time.Duration
+}
+
+// Unmarshal decodes a Frames header.
+func (h *Frames) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	typ, interval, hasInterval := strings.Cut(v[0], "/")
+
+	switch strings.ToLower(typ) {
+	case "intra":
+		h.Type = FramesTypeIntra
+
+	case "predicted":
+		h.Type = FramesTypePredicted
+
+	default:
+		return fmt.Errorf("invalid frames type (%v)", typ)
+	}
+
+	h.Interval = nil
+
+	if hasInterval {
+		if h.Type != FramesTypeIntra {
+			return fmt.Errorf("interval can be used with intra frames only")
+		}
+
+		tmp, err := strconv.ParseUint(interval, 10, 31)
+		if err != nil {
+			return fmt.Errorf("invalid frames interval (%v)", interval)
+		}
+
+		d := time.Duration(tmp) * time.Millisecond
+		h.Interval = &d
+	}
+
+	return nil
+}
+
+// Marshal encodes a Frames header.
+func (h Frames) Marshal() base.HeaderValue {
+	if h.Type == FramesTypePredicted {
+		return base.HeaderValue{"predicted"}
+	}
+
+	if h.Interval != nil {
+		return base.HeaderValue{"intra/" + strconv.FormatInt(h.Interval.Milliseconds(), 10)}
+	}
+
+	return base.HeaderValue{"intra"}
+}
@@ -0,0 +1,111 @@
+package headers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+var casesFrames = []struct {
+	name string
+	vin  base.HeaderValue
+	vout base.HeaderValue
+	h    Frames
+}{
+	{
+		"intra",
+		base.HeaderValue{`intra`},
+		base.HeaderValue{`intra`},
+		Frames{
+			Type: FramesTypeIntra,
+		},
+	},
+	{
+		"intra with interval",
+		base.HeaderValue{`intra/4000`},
+		base.HeaderValue{`intra/4000`},
+		Frames{
+			Type:     FramesTypeIntra,
+			Interval: durationPtr(4 * time.Second),
+		},
+	},
+	{
+		"predicted",
+		base.HeaderValue{`Predicted`},
+		base.HeaderValue{`predicted`},
+		Frames{
+			Type: FramesTypePredicted,
+		},
+	},
+}
+
+func TestFramesUnmarshal(t *testing.T) {
+	for _, ca := range casesFrames {
+		t.Run(ca.name, func(t *testing.T) {
+			var h Frames
+			err := h.Unmarshal(ca.vin)
+			require.NoError(t, err)
+			require.Equal(t, ca.h, h)
+		})
+	}
+}
+
+func TestFramesMarshal(t *testing.T) {
+	for _, ca := range casesFrames {
+		t.Run(ca.name, func(t *testing.T) {
+			req := ca.h.Marshal()
+			require.Equal(t, ca.vout, req)
+		})
+	}
+}
+
+func FuzzFramesUnmarshal(f *testing.F) {
+	for _, ca := range casesFrames {
+		f.Add(ca.vin[0])
+	}
+
+	f.Fuzz(func(_ *testing.T, b string) {
+		var h Frames
+		err := h.Unmarshal(base.HeaderValue{b})
+		if err != nil {
+			return
+		}
+
+		h.Marshal()
+	})
+}
+
+func TestFramesAdditionalErrors(t *testing.T) {
+	func() {
+		var h Frames
+		err := h.Unmarshal(base.HeaderValue{})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h Frames
+		err := h.Unmarshal(base.HeaderValue{"a", "b"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h Frames
+		err := h.Unmarshal(base.HeaderValue{"all"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h Frames
+		err := h.Unmarshal(base.HeaderValue{"intra/aa"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h Frames
+		err := h.Unmarshal(base.HeaderValue{"predicted/1000"})
+		require.Error(t, err)
+	}()
+}
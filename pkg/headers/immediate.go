@@ -0,0 +1,44 @@
+package headers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// Immediate is an Immediate header (ONVIF).
+// When true, the server stops any ongoing transmission and starts the new one immediately.
+type Immediate bool
+
+// Unmarshal decodes an Immediate header.
+func (h *Immediate) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	switch strings.ToLower(v[0]) {
+	case "yes":
+		*h = true
+
+	case "no":
+		*h = false
+
+	default:
+		return fmt.Errorf("invalid immediate value (%v)", v[0])
+	}
+
+	return nil
+}
+
+// Marshal encodes an Immediate header.
+func (h Immediate) Marshal() base.HeaderValue {
+	if h {
+		return base.HeaderValue{"yes"}
+	}
+	return base.HeaderValue{"no"}
+}
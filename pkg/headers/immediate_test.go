@@ -0,0 +1,85 @@
+package headers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+var casesImmediate = []struct {
+	name string
+	vin  base.HeaderValue
+	vout base.HeaderValue
+	h    Immediate
+}{
+	{
+		"yes",
+		base.HeaderValue{`yes`},
+		base.HeaderValue{`yes`},
+		true,
+	},
+	{
+		"no",
+		base.HeaderValue{`No`},
+		base.HeaderValue{`no`},
+		false,
+	},
+}
+
+func TestImmediateUnmarshal(t *testing.T) {
+	for _, ca := range casesImmediate {
+		t.Run(ca.name, func(t *testing.T) {
+			var h Immediate
+			err := h.Unmarshal(ca.vin)
+			require.NoError(t, err)
+			require.Equal(t, ca.h, h)
+		})
+	}
+}
+
+func TestImmediateMarshal(t *testing.T) {
+	for _, ca := range casesImmediate {
+		t.Run(ca.name, func(t *testing.T) {
+			req := ca.h.Marshal()
+			require.Equal(t, ca.vout, req)
+		})
+	}
+}
+
+func FuzzImmediateUnmarshal(f *testing.F) {
+	for _, ca := range casesImmediate {
+		f.Add(ca.vin[0])
+	}
+
+	f.Fuzz(func(_ *testing.T, b string) {
+		var h Immediate
+		err := h.Unmarshal(base.HeaderValue{b})
+		if err != nil {
+			return
+		}
+
+		h.Marshal()
+	})
+}
+
+func TestImmediateAdditionalErrors(t *testing.T) {
+	func() {
+		var h Immediate
+		err := h.Unmarshal(base.HeaderValue{})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h Immediate
+		err := h.Unmarshal(base.HeaderValue{"a", "b"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h Immediate
+		err := h.Unmarshal(base.HeaderValue{"maybe"})
+		require.Error(t, err)
+	}()
+}
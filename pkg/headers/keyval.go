@@ -21,6 +21,11 @@ func readValue(origstr string, str string, separator byte) (string, string, erro
 			}
 
 			if str[i] == '"' {
+				// a list of quoted values, like dest_addr="a"/"b",
+				// is returned as it is.
+				if (i+1) < len(str) && str[i+1] == '/' {
+					break
+				}
 				return str[1:i], str[i+1:], nil
 			}
 
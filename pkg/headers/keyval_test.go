@@ -35,6 +35,14 @@ var casesKeyVal = []struct {
 			"key2": "v2",
 		},
 	},
+	{
+		"with list of apexes",
+		`key1="v1"/"v2", key2=v3`,
+		map[string]string{
+			"key1": `"v1"/"v2"`,
+			"key2": "v3",
+		},
+	},
 	{
 		"with apexes and comma",
 		`key1="v,1", key2="v2"`,
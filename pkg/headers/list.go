@@ -0,0 +1,29 @@
+package headers
+
+import (
+	"strings"
+)
+
+// splitList splits a comma-separated list, ignoring commas between apexes.
+func splitList(v string) []string {
+	var ret []string
+	inApexes := false
+	start := 0
+
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '"':
+			inApexes = !inApexes
+
+		case ',':
+			if !inApexes {
+				ret = append(ret, strings.Trim(v[start:i], " "))
+				start = i + 1
+			}
+		}
+	}
+
+	ret = append(ret, strings.Trim(v[start:], " "))
+
+	return ret
+}
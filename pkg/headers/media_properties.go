@@ -0,0 +1,265 @@
+package headers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// MediaPropertiesRandomAccess is the random access capability of a media.
+type MediaPropertiesRandomAccess int
+
+// random access capabilities.
+const (
+	MediaPropertiesRandomAccessRandomAccess MediaPropertiesRandomAccess = iota
+	MediaPropertiesRandomAccessBeginningOnly
+	MediaPropertiesRandomAccessNoSeeking
+)
+
+// MediaPropertiesContentModifications describes how the content of a media changes.
+type MediaPropertiesContentModifications int
+
+// content modifications.
+const (
+	MediaPropertiesContentModificationsImmutable MediaPropertiesContentModifications = iota
+	MediaPropertiesContentModificationsDynamic
+	MediaPropertiesContentModificationsTimeProgressing
+)
+
+// MediaPropertiesRetention describes how long the content of a media is retained.
+type MediaPropertiesRetention int
+
+// retentions.
+const (
+	MediaPropertiesRetentionUnlimited MediaPropertiesRetention = iota
+	MediaPropertiesRetentionTimeLimited
+	MediaPropertiesRetentionTimeDuration
+)
+
+// MediaPropertiesScale is a scale, or a range of scales, supported by a media.
+type MediaPropertiesScale struct {
+	Min float64
+	Max float64
+}
+
+func (s *MediaPropertiesScale) unmarshal(v string) error {
+	parts := strings.Split(v, ":")
+	if len(parts) > 2 {
+		return fmt.Errorf("invalid scale (%v)", v)
+	}
+
+	tmp, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return fmt.Errorf("invalid scale (%v)", v)
+	}
+	s.Min = tmp
+	s.Max = tmp
+
+	if len(parts) == 2 {
+		tmp, err = strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return fmt.Errorf("invalid scale (%v)", v)
+		}
+		s.Max = tmp
+	}
+
+	return nil
+}
+
+func (s MediaPropertiesScale) marshal() string {
+	ret := strconv.FormatFloat(s.Min, 'f', -1, 64)
+	if s.Max != s.Min {
+		ret += ":" + strconv.FormatFloat(s.Max, 'f', -1, 64)
+	}
+	return ret
+}
+
+// MediaProperties is a Media-Properties header (RTSP 2.0).
+type MediaProperties struct {
+	// (optional) random access capability.
+	RandomAccess *MediaPropertiesRandomAccess
+
+	// (optional) maximum distance between random access points.
+	// It can be used with MediaPropertiesRandomAccessRandomAccess only.
+	RandomAccessMaxDelta *time.Duration
+
+	// (optional) content modifications.
+	ContentModifications *MediaPropertiesContentModifications
+
+	// (optional) retention.
+	Retention *MediaPropertiesRetention
+
+	// (optional) time until which the content is retained.
+	// It can be used with MediaPropertiesRetentionTimeLimited only.
+	RetentionTimeLimited *time.Time
+
+	// (optional) duration of the retention.
+	// It can be used with MediaPropertiesRetentionTimeDuration only.
+	RetentionTimeDuration *time.Duration
+
+	// (optional) supported scales.
+	Scales []MediaPropertiesScale
+}
+
+// Unmarshal decodes a Media-Properties header.
+func (h *MediaProperties) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	for _, part := range splitList(v[0]) {
+		var key, val string
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			key, val = part[:i], part[i+1:]
+		} else {
+			key = part
+		}
+
+		switch strings.ToLower(key) {
+		case "random-access":
+			ra := MediaPropertiesRandomAccessRandomAccess
+			h.RandomAccess = &ra
+
+			if val != "" {
+				var d time.Duration
+				err := unmarshalRangeNPTTime(&d, val)
+				if err != nil {
+					return fmt.Errorf("invalid random access delta (%v)", val)
+				}
+				h.RandomAccessMaxDelta = &d
+			}
+
+		case "beginning-only":
+			ra := MediaPropertiesRandomAccessBeginningOnly
+			h.RandomAccess = &ra
+
+		case "no-seeking":
+			ra := MediaPropertiesRandomAccessNoSeeking
+			h.RandomAccess = &ra
+
+		case "immutable":
+			cm := MediaPropertiesContentModificationsImmutable
+			h.ContentModifications = &cm
+
+		case "dynamic":
+			cm := MediaPropertiesContentModificationsDynamic
+			h.ContentModifications = &cm
+
+		case "time-progressing":
+			cm := MediaPropertiesContentModificationsTimeProgressing
+			h.ContentModifications = &cm
+
+		case "unlimited":
+			r := MediaPropertiesRetentionUnlimited
+			h.Retention = &r
+
+		case "time-limited":
+			r := MediaPropertiesRetentionTimeLimited
+			h.Retention = &r
+
+			var t time.Time
+			err := unmarshalRangeUTCTime(&t, val)
+			if err != nil {
+				return fmt.Errorf("invalid time limit (%v)", val)
+			}
+			h.RetentionTimeLimited = &t
+
+		case "time-duration":
+			r := MediaPropertiesRetentionTimeDuration
+			h.Retention = &r
+
+			var d time.Duration
+			err := unmarshalRangeNPTTime(&d, val)
+			if err != nil {
+				return fmt.Errorf("invalid time duration (%v)", val)
+			}
+			h.RetentionTimeDuration = &d
+
+		case "scales":
+			val = strings.Trim(val, "\"")
+
+			for _, scaleStr := range splitList(val) {
+				var s MediaPropertiesScale
+				err := s.unmarshal(scaleStr)
+				if err != nil {
+					return err
+				}
+				h.Scales = append(h.Scales, s)
+			}
+
+		default:
+			// ignore non-standard properties
+		}
+	}
+
+	return nil
+}
+
+// Marshal encodes a Media-Properties header.
+func (h MediaProperties) Marshal() base.HeaderValue {
+	var rets []string
+
+	if h.RandomAccess != nil {
+		switch *h.RandomAccess {
+		case MediaPropertiesRandomAccessRandomAccess:
+			if h.RandomAccessMaxDelta != nil {
+				rets = append(rets, "Random-Access="+marshalRangeNPTTime(*h.RandomAccessMaxDelta))
+			} else {
+				rets = append(rets, "Random-Access")
+			}
+
+		case MediaPropertiesRandomAccessBeginningOnly:
+			rets = append(rets, "Beginning-Only")
+
+		case MediaPropertiesRandomAccessNoSeeking:
+			rets = append(rets, "No-Seeking")
+		}
+	}
+
+	if h.ContentModifications != nil {
+		switch *h.ContentModifications {
+		case MediaPropertiesContentModificationsImmutable:
+			rets = append(rets, "Immutable")
+
+		case MediaPropertiesContentModificationsDynamic:
+			rets = append(rets, "Dynamic")
+
+		case MediaPropertiesContentModificationsTimeProgressing:
+			rets = append(rets, "Time-Progressing")
+		}
+	}
+
+	if h.Retention != nil {
+		switch *h.Retention {
+		case MediaPropertiesRetentionUnlimited:
+			rets = append(rets, "Unlimited")
+
+		case MediaPropertiesRetentionTimeLimited:
+			if h.RetentionTimeLimited != nil {
+				rets = append(rets, "Time-Limited="+marshalRangeUTCTime(*h.RetentionTimeLimited))
+			}
+
+		case MediaPropertiesRetentionTimeDuration:
+			if h.RetentionTimeDuration != nil {
+				rets = append(rets, "Time-Duration="+marshalRangeNPTTime(*h.RetentionTimeDuration))
+			}
+		}
+	}
+
+	if len(h.Scales) != 0 {
+		tmp := make([]string, len(h.Scales))
+		for i, s := range h.Scales {
+			tmp[i] = s.marshal()
+		}
+		rets = append(rets, "Scales=\""+strings.Join(tmp, ", ")+"\"")
+	}
+
+	return base.HeaderValue{strings.Join(rets, ", ")}
+}
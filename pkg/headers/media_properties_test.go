@@ -0,0 +1,128 @@
+package headers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+func randomAccessPtr(v MediaPropertiesRandomAccess) *MediaPropertiesRandomAccess {
+	return &v
+}
+
+func contentModificationsPtr(v MediaPropertiesContentModifications) *MediaPropertiesContentModifications {
+	return &v
+}
+
+func retentionPtr(v MediaPropertiesRetention) *MediaPropertiesRetention {
+	return &v
+}
+
+var casesMediaProperties = []struct {
+	name string
+	vin  base.HeaderValue
+	vout base.HeaderValue
+	h    MediaProperties
+}{
+	{
+		"on demand",
+		base.HeaderValue{`Random-Access=2.5, Unlimited, Immutable, Scales="-20, -10, -4, 0.5:1.5, 4, 8, 10, 15, 20"`},
+		base.HeaderValue{`Random-Access=2.5, Immutable, Unlimited, Scales="-20, -10, -4, 0.5:1.5, 4, 8, 10, 15, 20"`},
+		MediaProperties{
+			RandomAccess:         randomAccessPtr(MediaPropertiesRandomAccessRandomAccess),
+			RandomAccessMaxDelta: durationPtr(2500 * time.Millisecond),
+			ContentModifications: contentModificationsPtr(MediaPropertiesContentModificationsImmutable),
+			Retention:            retentionPtr(MediaPropertiesRetentionUnlimited),
+			Scales: []MediaPropertiesScale{
+				{Min: -20, Max: -20},
+				{Min: -10, Max: -10},
+				{Min: -4, Max: -4},
+				{Min: 0.5, Max: 1.5},
+				{Min: 4, Max: 4},
+				{Min: 8, Max: 8},
+				{Min: 10, Max: 10},
+				{Min: 15, Max: 15},
+				{Min: 20, Max: 20},
+			},
+		},
+	},
+	{
+		"live",
+		base.HeaderValue{`No-Seeking, Time-Progressing, Time-Duration=0.0`},
+		base.HeaderValue{`No-Seeking, Time-Progressing, Time-Duration=0`},
+		MediaProperties{
+			RandomAccess:          randomAccessPtr(MediaPropertiesRandomAccessNoSeeking),
+			ContentModifications:  contentModificationsPtr(MediaPropertiesContentModificationsTimeProgressing),
+			Retention:             retentionPtr(MediaPropertiesRetentionTimeDuration),
+			RetentionTimeDuration: durationPtr(0),
+		},
+	},
+	{
+		"time limited",
+		base.HeaderValue{`Beginning-Only, Dynamic, Time-Limited=20081012T152401Z`},
+		base.HeaderValue{`Beginning-Only, Dynamic, Time-Limited=20081012T152401Z`},
+		MediaProperties{
+			RandomAccess:         randomAccessPtr(MediaPropertiesRandomAccessBeginningOnly),
+			ContentModifications: contentModificationsPtr(MediaPropertiesContentModificationsDynamic),
+			Retention:            retentionPtr(MediaPropertiesRetentionTimeLimited),
+			RetentionTimeLimited: timePtr(time.Date(2008, 10, 12, 15, 24, 1, 0, time.UTC)),
+		},
+	},
+}
+
+func TestMediaPropertiesUnmarshal(t *testing.T) {
+	for _, ca := range casesMediaProperties {
+		t.Run(ca.name, func(t *testing.T) {
+			var h MediaProperties
+			err := h.Unmarshal(ca.vin)
+			require.NoError(t, err)
+			require.Equal(t, ca.h, h)
+		})
+	}
+}
+
+func TestMediaPropertiesMarshal(t *testing.T) {
+	for _, ca := range casesMediaProperties {
+		t.Run(ca.name, func(t *testing.T) {
+			req := ca.h.Marshal()
+			require.Equal(t, ca.vout, req)
+		})
+	}
+}
+
+func FuzzMediaPropertiesUnmarshal(f *testing.F) {
+	for _, ca := range casesMediaProperties {
+		f.Add(ca.vin[0])
+	}
+
+	f.Add("Random-Access=")
+	f.Add("Time-Limited=")
+	f.Add(`Scales="1:2:3"`)
+
+	f.Fuzz(func(_ *testing.T, b string) {
+		var h MediaProperties
+		err := h.Unmarshal(base.HeaderValue{b})
+		if err != nil {
+			return
+		}
+
+		h.Marshal()
+	})
+}
+
+func TestMediaPropertiesAdditionalErrors(t *testing.T) {
+	func() {
+		var h MediaProperties
+		err := h.Unmarshal(base.HeaderValue{})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h MediaProperties
+		err := h.Unmarshal(base.HeaderValue{"a", "b"})
+		require.Error(t, err)
+	}()
+}
@@ -0,0 +1,59 @@
+package headers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// MediaRange is a Media-Range header (RTSP 2.0).
+// It contains the available range of the media, expressed in one or more units.
+// It is empty when the media doesn't have a defined range.
+type MediaRange []RangeValue
+
+// Unmarshal decodes a Media-Range header.
+func (h *MediaRange) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	*h = nil
+
+	if v[0] == "" {
+		return nil
+	}
+
+	for _, part := range splitList(v[0]) {
+		i := strings.IndexByte(part, '=')
+		if i < 0 {
+			return fmt.Errorf("invalid value (%v)", part)
+		}
+
+		rv, err := rangeValueUnmarshalKeyVal(part[:i], part[i+1:])
+		if err != nil {
+			return err
+		}
+
+		if rv == nil {
+			return fmt.Errorf("unsupported range unit (%v)", part[:i])
+		}
+
+		*h = append(*h, rv)
+	}
+
+	return nil
+}
+
+// Marshal encodes a Media-Range header.
+func (h MediaRange) Marshal() base.HeaderValue {
+	tmp := make([]string, len(h))
+	for i, rv := range h {
+		tmp[i] = rv.marshal()
+	}
+	return base.HeaderValue{strings.Join(tmp, ", ")}
+}
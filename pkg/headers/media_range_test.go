@@ -0,0 +1,105 @@
+package headers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+var casesMediaRange = []struct {
+	name string
+	vin  base.HeaderValue
+	vout base.HeaderValue
+	h    MediaRange
+}{
+	{
+		"empty",
+		base.HeaderValue{``},
+		base.HeaderValue{``},
+		nil,
+	},
+	{
+		"npt",
+		base.HeaderValue{`npt=0-34.5`},
+		base.HeaderValue{`npt=0-34.5`},
+		MediaRange{
+			&RangeNPT{
+				Start: 0,
+				End:   durationPtr(34500 * time.Millisecond),
+			},
+		},
+	},
+	{
+		"multiple",
+		base.HeaderValue{`npt=0-, clock=20081012T152401Z-`},
+		base.HeaderValue{`npt=0-, clock=20081012T152401Z-`},
+		MediaRange{
+			&RangeNPT{},
+			&RangeUTC{
+				Start: time.Date(2008, 10, 12, 15, 24, 1, 0, time.UTC),
+			},
+		},
+	},
+}
+
+func TestMediaRangeUnmarshal(t *testing.T) {
+	for _, ca := range casesMediaRange {
+		t.Run(ca.name, func(t *testing.T) {
+			var h MediaRange
+			err := h.Unmarshal(ca.vin)
+			require.NoError(t, err)
+			require.Equal(t, ca.h, h)
+		})
+	}
+}
+
+func TestMediaRangeMarshal(t *testing.T) {
+	for _, ca := range casesMediaRange {
+		t.Run(ca.name, func(t *testing.T) {
+			req := ca.h.Marshal()
+			require.Equal(t, ca.vout, req)
+		})
+	}
+}
+
+func FuzzMediaRangeUnmarshal(f *testing.F) {
+	for _, ca := range casesMediaRange {
+		f.Add(ca.vin[0])
+	}
+
+	f.Add("npt=")
+	f.Add("other=0-1")
+
+	f.Fuzz(func(_ *testing.T, b string) {
+		var h MediaRange
+		err := h.Unmarshal(base.HeaderValue{b})
+		if err != nil {
+			return
+		}
+
+		h.Marshal()
+	})
+}
+
+func TestMediaRangeAdditionalErrors(t *testing.T) {
+	func() {
+		var h MediaRange
+		err := h.Unmarshal(base.HeaderValue{})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h MediaRange
+		err := h.Unmarshal(base.HeaderValue{"a", "b"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h MediaRange
+		err := h.Unmarshal(base.HeaderValue{"other=0-1"})
+		require.Error(t, err)
+	}()
+}
@@ -0,0 +1,57 @@
+package headers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// NotifyReason is a Notify-Reason header (RTSP 2.0).
+type NotifyReason int
+
+// notify reasons.
+const (
+	NotifyReasonEndOfStream NotifyReason = iota
+	NotifyReasonMediaPropertiesUpdate
+	NotifyReasonScaleChange
+)
+
+var notifyReasonLabels = map[NotifyReason]string{
+	NotifyReasonEndOfStream:           "end-of-stream",
+	NotifyReasonMediaPropertiesUpdate: "media-properties-update",
+	NotifyReasonScaleChange:           "scale-change",
+}
+
+// Unmarshal decodes a Notify-Reason header.
+func (h *NotifyReason) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	for reason, label := range notifyReasonLabels {
+		if strings.EqualFold(v[0], label) {
+			*h = reason
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid notify reason (%v)", v[0])
+}
+
+// Marshal encodes a Notify-Reason header.
+func (h NotifyReason) Marshal() base.HeaderValue {
+	return base.HeaderValue{h.String()}
+}
+
+// String implements fmt.Stringer.
+func (h NotifyReason) String() string {
+	if l, ok := notifyReasonLabels[h]; ok {
+		return l
+	}
+	return "unknown"
+}
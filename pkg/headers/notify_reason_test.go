@@ -0,0 +1,91 @@
+package headers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+var casesNotifyReason = []struct {
+	name string
+	vin  base.HeaderValue
+	vout base.HeaderValue
+	h    NotifyReason
+}{
+	{
+		"end of stream",
+		base.HeaderValue{`end-of-stream`},
+		base.HeaderValue{`end-of-stream`},
+		NotifyReasonEndOfStream,
+	},
+	{
+		"media properties update",
+		base.HeaderValue{`Media-Properties-Update`},
+		base.HeaderValue{`media-properties-update`},
+		NotifyReasonMediaPropertiesUpdate,
+	},
+	{
+		"scale change",
+		base.HeaderValue{`scale-change`},
+		base.HeaderValue{`scale-change`},
+		NotifyReasonScaleChange,
+	},
+}
+
+func TestNotifyReasonUnmarshal(t *testing.T) {
+	for _, ca := range casesNotifyReason {
+		t.Run(ca.name, func(t *testing.T) {
+			var h NotifyReason
+			err := h.Unmarshal(ca.vin)
+			require.NoError(t, err)
+			require.Equal(t, ca.h, h)
+		})
+	}
+}
+
+func TestNotifyReasonMarshal(t *testing.T) {
+	for _, ca := range casesNotifyReason {
+		t.Run(ca.name, func(t *testing.T) {
+			req := ca.h.Marshal()
+			require.Equal(t, ca.vout, req)
+		})
+	}
+}
+
+func FuzzNotifyReasonUnmarshal(f *testing.F) {
+	for _, ca := range casesNotifyReason {
+		f.Add(ca.vin[0])
+	}
+
+	f.Fuzz(func(_ *testing.T, b string) {
+		var h NotifyReason
+		err := h.Unmarshal(base.HeaderValue{b})
+		if err != nil {
+			return
+		}
+
+		h.Marshal()
+	})
+}
+
+func TestNotifyReasonAdditionalErrors(t *testing.T) {
+	func() {
+		var h NotifyReason
+		err := h.Unmarshal(base.HeaderValue{})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h NotifyReason
+		err := h.Unmarshal(base.HeaderValue{"a", "b"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h NotifyReason
+		err := h.Unmarshal(base.HeaderValue{"other"})
+		require.Error(t, err)
+	}()
+}
@@ -0,0 +1,38 @@
+package headers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// PipelinedRequests is a Pipelined-Requests header (RTSP 2.0).
+type PipelinedRequests struct {
+	// identifier of the pipeline.
+	ID uint32
+}
+
+// Unmarshal decodes a Pipelined-Requests header.
+func (h *PipelinedRequests) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	tmp, err := strconv.ParseUint(v[0], 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid value (%v)", v[0])
+	}
+	h.ID = uint32(tmp)
+
+	return nil
+}
+
+// Marshal encodes a Pipelined-Requests header.
+func (h PipelinedRequests) Marshal() base.HeaderValue {
+	return base.HeaderValue{strconv.FormatUint(uint64(h.ID), 10)}
+}
@@ -0,0 +1,81 @@
+package headers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+var casesPipelinedRequests = []struct {
+	name string
+	vin  base.HeaderValue
+	vout base.HeaderValue
+	h    PipelinedRequests
+}{
+	{
+		"base",
+		base.HeaderValue{`7`},
+		base.HeaderValue{`7`},
+		PipelinedRequests{
+			ID: 7,
+		},
+	},
+}
+
+func TestPipelinedRequestsUnmarshal(t *testing.T) {
+	for _, ca := range casesPipelinedRequests {
+		t.Run(ca.name, func(t *testing.T) {
+			var h PipelinedRequests
+			err := h.Unmarshal(ca.vin)
+			require.NoError(t, err)
+			require.Equal(t, ca.h, h)
+		})
+	}
+}
+
+func TestPipelinedRequestsMarshal(t *testing.T) {
+	for _, ca := range casesPipelinedRequests {
+		t.Run(ca.name, func(t *testing.T) {
+			req := ca.h.Marshal()
+			require.Equal(t, ca.vout, req)
+		})
+	}
+}
+
+func FuzzPipelinedRequestsUnmarshal(f *testing.F) {
+	for _, ca := range casesPipelinedRequests {
+		f.Add(ca.vin[0])
+	}
+
+	f.Fuzz(func(_ *testing.T, b string) {
+		var h PipelinedRequests
+		err := h.Unmarshal(base.HeaderValue{b})
+		if err != nil {
+			return
+		}
+
+		h.Marshal()
+	})
+}
+
+func TestPipelinedRequestsAdditionalErrors(t *testing.T) {
+	func() {
+		var h PipelinedRequests
+		err := h.Unmarshal(base.HeaderValue{})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h PipelinedRequests
+		err := h.Unmarshal(base.HeaderValue{"a", "b"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h PipelinedRequests
+		err := h.Unmarshal(base.HeaderValue{"a"})
+		require.Error(t, err)
+	}()
+}
@@ -266,6 +266,33 @@ func rangeValueUnmarshal(s RangeValue, v string) error {
 	return s.unmarshal(parts[0], parts[1])
 }
 
+// rangeValueUnmarshalKeyVal decodes a range value from a key and a value.
+// It returns nil if the key is not a range unit.
+func rangeValueUnmarshalKeyVal(k string, v string) (RangeValue, error) {
+	var rv RangeValue
+
+	switch k {
+	case "smpte":
+		rv = &RangeSMPTE{}
+
+	case "npt":
+		rv = &RangeNPT{}
+
+	case "clock":
+		rv = &RangeUTC{}
+
+	default:
+		return nil, nil
+	}
+
+	err := rangeValueUnmarshal(rv, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return rv, nil
+}
+
 // Range is a Range header.
 type Range struct {
 	// range expressed in some measurement units.
@@ -293,38 +320,7 @@ func (h *Range) Unmarshal(v base.HeaderValue) error {
 	specFound := false
 
 	for k, v := range kvs {
-		switch k {
-		case "smpte":
-			s := &RangeSMPTE{}
-			err := rangeValueUnmarshal(s, v)
-			if err != nil {
-				return err
-			}
-
-			specFound = true
-			h.Value = s
-
-		case "npt":
-			s := &RangeNPT{}
-			err := rangeValueUnmarshal(s, v)
-			if err != nil {
-				return err
-			}
-
-			specFound = true
-			h.Value = s
-
-		case "clock":
-			s := &RangeUTC{}
-			err := rangeValueUnmarshal(s, v)
-			if err != nil {
-				return err
-			}
-
-			specFound = true
-			h.Value = s
-
-		case "time":
+		if k == "time" {
 			var t time.Time
 			err := unmarshalRangeUTCTime(&t, v)
 			if err != nil {
@@ -332,6 +328,17 @@ func (h *Range) Unmarshal(v base.HeaderValue) error {
 			}
 
 			h.Time = &t
+			continue
+		}
+
+		rv, err := rangeValueUnmarshalKeyVal(k, v)
+		if err != nil {
+			return err
+		}
+
+		if rv != nil {
+			specFound = true
+			h.Value = rv
 		}
 	}
 
@@ -0,0 +1,44 @@
+package headers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// RateControl is a Rate-Control header (ONVIF).
+// When false, the server streams data as fast as the client is able to receive it.
+type RateControl bool
+
+// Unmarshal decodes a Rate-Control header.
+func (h *RateControl) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	switch strings.ToLower(v[0]) {
+	case "yes":
+		*h = true
+
+	case "no":
+		*h = false
+
+	default:
+		return fmt.Errorf("invalid rate-control value (%v)", v[0])
+	}
+
+	return nil
+}
+
+// Marshal encodes a Rate-Control header.
+func (h RateControl) Marshal() base.HeaderValue {
+	if h {
+		return base.HeaderValue{"yes"}
+	}
+	return base.HeaderValue{"no"}
+}
@@ -0,0 +1,85 @@
+package headers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+var casesRateControl = []struct {
+	name string
+	vin  base.HeaderValue
+	vout base.HeaderValue
+	h    RateControl
+}{
+	{
+		"yes",
+		base.HeaderValue{`yes`},
+		base.HeaderValue{`yes`},
+		true,
+	},
+	{
+		"no",
+		base.HeaderValue{`No`},
+		base.HeaderValue{`no`},
+		false,
+	},
+}
+
+func TestRateControlUnmarshal(t *testing.T) {
+	for _, ca := range casesRateControl {
+		t.Run(ca.name, func(t *testing.T) {
+			var h RateControl
+			err := h.Unmarshal(ca.vin)
+			require.NoError(t, err)
+			require.Equal(t, ca.h, h)
+		})
+	}
+}
+
+func TestRateControlMarshal(t *testing.T) {
+	for _, ca := range casesRateControl {
+		t.Run(ca.name, func(t *testing.T) {
+			req := ca.h.Marshal()
+			require.Equal(t, ca.vout, req)
+		})
+	}
+}
+
+func FuzzRateControlUnmarshal(f *testing.F) {
+	for _, ca := range casesRateControl {
+		f.Add(ca.vin[0])
+	}
+
+	f.Fuzz(func(_ *testing.T, b string) {
+		var h RateControl
+		err := h.Unmarshal(base.HeaderValue{b})
+		if err != nil {
+			return
+		}
+
+		h.Marshal()
+	})
+}
+
+func TestRateControlAdditionalErrors(t *testing.T) {
+	func() {
+		var h RateControl
+		err := h.Unmarshal(base.HeaderValue{})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h RateControl
+		err := h.Unmarshal(base.HeaderValue{"a", "b"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h RateControl
+		err := h.Unmarshal(base.HeaderValue{"maybe"})
+		require.Error(t, err)
+	}()
+}
@@ -0,0 +1,38 @@
+package headers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// Scale is a Scale header.
+// Values greater than 1 denote fast forward, values between 0 and 1 denote slow motion,
+// negative values denote reverse playback.
+type Scale float64
+
+// Unmarshal decodes a Scale header.
+func (h *Scale) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	tmp, err := strconv.ParseFloat(v[0], 64)
+	if err != nil || math.IsNaN(tmp) || math.IsInf(tmp, 0) || tmp == 0 {
+		return fmt.Errorf("invalid scale (%v)", v[0])
+	}
+
+	*h = Scale(tmp)
+	return nil
+}
+
+// Marshal encodes a Scale header.
+func (h Scale) Marshal() base.HeaderValue {
+	return base.HeaderValue{strconv.FormatFloat(float64(h), 'f', -1, 64)}
+}
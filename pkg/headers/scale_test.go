@@ -0,0 +1,103 @@
+package headers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+var casesScale = []struct {
+	name string
+	vin  base.HeaderValue
+	vout base.HeaderValue
+	h    Scale
+}{
+	{
+		"normal",
+		base.HeaderValue{`1`},
+		base.HeaderValue{`1`},
+		Scale(1),
+	},
+	{
+		"fast forward",
+		base.HeaderValue{`2.0`},
+		base.HeaderValue{`2`},
+		Scale(2),
+	},
+	{
+		"slow motion",
+		base.HeaderValue{`0.5`},
+		base.HeaderValue{`0.5`},
+		Scale(0.5),
+	},
+	{
+		"reverse",
+		base.HeaderValue{`-4`},
+		base.HeaderValue{`-4`},
+		Scale(-4),
+	},
+}
+
+func TestScaleUnmarshal(t *testing.T) {
+	for _, ca := range casesScale {
+		t.Run(ca.name, func(t *testing.T) {
+			var h Scale
+			err := h.Unmarshal(ca.vin)
+			require.NoError(t, err)
+			require.Equal(t, ca.h, h)
+		})
+	}
+}
+
+func TestScaleMarshal(t *testing.T) {
+	for _, ca := range casesScale {
+		t.Run(ca.name, func(t *testing.T) {
+			req := ca.h.Marshal()
+			require.Equal(t, ca.vout, req)
+		})
+	}
+}
+
+func FuzzScaleUnmarshal(f *testing.F) {
+	for _, ca := range casesScale {
+		f.Add(ca.vin[0])
+	}
+
+	f.Fuzz(func(_ *testing.T, b string) {
+		var h Scale
+		err := h.Unmarshal(base.HeaderValue{b})
+		if err != nil {
+			return
+		}
+
+		h.Marshal()
+	})
+}
+
+func TestScaleAdditionalErrors(t *testing.T) {
+	func() {
+		var h Scale
+		err := h.Unmarshal(base.HeaderValue{})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h Scale
+		err := h.Unmarshal(base.HeaderValue{"a", "b"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h Scale
+		err := h.Unmarshal(base.HeaderValue{"aa"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h Scale
+		err := h.Unmarshal(base.HeaderValue{"0"})
+		require.Error(t, err)
+	}()
+}
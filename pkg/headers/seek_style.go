@@ -0,0 +1,59 @@
+package headers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// SeekStyle is a Seek-Style header (RTSP 2.0).
+type SeekStyle int
+
+// seek styles.
+const (
+	SeekStyleRAP SeekStyle = iota
+	SeekStyleCoRAP
+	SeekStyleFirstPrior
+	SeekStyleNext
+)
+
+var seekStyleLabels = map[SeekStyle]string{
+	SeekStyleRAP:        "RAP",
+	SeekStyleCoRAP:      "CoRAP",
+	SeekStyleFirstPrior: "First-Prior",
+	SeekStyleNext:       "Next",
+}
+
+// Unmarshal decodes a Seek-Style header.
+func (h *SeekStyle) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	for style, label := range seekStyleLabels {
+		if strings.EqualFold(v[0], label) {
+			*h = style
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid seek style (%v)", v[0])
+}
+
+// Marshal encodes a Seek-Style header.
+func (h SeekStyle) Marshal() base.HeaderValue {
+	return base.HeaderValue{h.String()}
+}
+
+// String implements fmt.Stringer.
+func (h SeekStyle) String() string {
+	if l, ok := seekStyleLabels[h]; ok {
+		return l
+	}
+	return "unknown"
+}
@@ -0,0 +1,97 @@
+package headers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+var casesSeekStyle = []struct {
+	name string
+	vin  base.HeaderValue
+	vout base.HeaderValue
+	h    SeekStyle
+}{
+	{
+		"rap",
+		base.HeaderValue{`RAP`},
+		base.HeaderValue{`RAP`},
+		SeekStyleRAP,
+	},
+	{
+		"corap",
+		base.HeaderValue{`CoRAP`},
+		base.HeaderValue{`CoRAP`},
+		SeekStyleCoRAP,
+	},
+	{
+		"first-prior",
+		base.HeaderValue{`first-prior`},
+		base.HeaderValue{`First-Prior`},
+		SeekStyleFirstPrior,
+	},
+	{
+		"next",
+		base.HeaderValue{`Next`},
+		base.HeaderValue{`Next`},
+		SeekStyleNext,
+	},
+}
+
+func TestSeekStyleUnmarshal(t *testing.T) {
+	for _, ca := range casesSeekStyle {
+		t.Run(ca.name, func(t *testing.T) {
+			var h SeekStyle
+			err := h.Unmarshal(ca.vin)
+			require.NoError(t, err)
+			require.Equal(t, ca.h, h)
+		})
+	}
+}
+
+func TestSeekStyleMarshal(t *testing.T) {
+	for _, ca := range casesSeekStyle {
+		t.Run(ca.name, func(t *testing.T) {
+			req := ca.h.Marshal()
+			require.Equal(t, ca.vout, req)
+		})
+	}
+}
+
+func FuzzSeekStyleUnmarshal(f *testing.F) {
+	for _, ca := range casesSeekStyle {
+		f.Add(ca.vin[0])
+	}
+
+	f.Fuzz(func(_ *testing.T, b string) {
+		var h SeekStyle
+		err := h.Unmarshal(base.HeaderValue{b})
+		if err != nil {
+			return
+		}
+
+		h.Marshal()
+	})
+}
+
+func TestSeekStyleAdditionalErrors(t *testing.T) {
+	func() {
+		var h SeekStyle
+		err := h.Unmarshal(base.HeaderValue{})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h SeekStyle
+		err := h.Unmarshal(base.HeaderValue{"a", "b"})
+		require.Error(t, err)
+	}()
+
+	func() {
+		var h SeekStyle
+		err := h.Unmarshal(base.HeaderValue{"other"})
+		require.Error(t, err)
+	}()
+}
@@ -0,0 +1,37 @@
+package headers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+)
+
+// Speed is a Speed header.
+// It is the rate at which data is delivered, relative to the normal rate.
+type Speed float64
+
+// Unmarshal decodes a Speed header.
+func (h *Speed) Unmarshal(v base.HeaderValue) error {
+	if len(v) == 0 {
+		return fmt.Errorf("value not provided")
+	}
+
+	if len(v) > 1 {
+		return fmt.Errorf("value provided multiple times (%v)", v)
+	}
+
+	tmp, err := strconv.ParseFloat(v[0], 64)
+	if err != nil || math.IsNaN(tmp) || math.IsInf(tmp, 0) || tmp <= 0 {
+		return fmt.Errorf("invalid speed (%v)", v[0])
+	}
+
+	*h = Speed(tmp)
+	return nil
+}
+
+// Marshal encodes a Speed header.
+func (h Speed) Marshal() base.HeaderValue {
+	return base.HeaderValue{strconv.FormatFloat(float64(h), 'f', -1, 64)}
+}
@@ -38,6 +38,79 @@ func parsePorts(val string) (*[2]int, error) {
 	return &[2]int{0, 0}, fmt.Errorf("invalid ports (%v)", val)
 }
 
+func marshalPorts(ports *[2]int, rtcpMux bool) string {
+	if rtcpMux && ports[0] == ports[1] {
+		return strconv.FormatInt(int64(ports[0]), 10)
+	}
+	return strconv.FormatInt(int64(ports[0]), 10) + "-" + strconv.FormatInt(int64(ports[1]), 10)
+}
+
+func parseTransportAddrs(val string) ([]TransportAddr, error) {
+	var ret []TransportAddr
+
+	for _, part := range strings.Split(val, "/") {
+		part = strings.Trim(part, "\"")
+
+		var addr TransportAddr
+		err := addr.unmarshal(part)
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, addr)
+	}
+
+	return ret, nil
+}
+
+func marshalTransportAddrs(addrs []TransportAddr) string {
+	tmp := make([]string, len(addrs))
+	for i, addr := range addrs {
+		tmp[i] = "\"" + addr.marshal() + "\""
+	}
+	return strings.Join(tmp, "/")
+}
+
+// TransportAddr is an address used in the dest_addr and src_addr parameters (RTSP 2.0).
+type TransportAddr struct {
+	// (optional) host.
+	Host string
+
+	// (optional) port.
+	Port int
+}
+
+func (a *TransportAddr) unmarshal(v string) error {
+	host, portStr, err := net.SplitHostPort(v)
+	if err != nil {
+		// address without port
+		if v == "" {
+			return fmt.Errorf("invalid address (%v)", v)
+		}
+		a.Host = strings.Trim(v, "[]")
+		return nil
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid address (%v)", v)
+	}
+
+	a.Host = host
+	a.Port = int(port)
+	return nil
+}
+
+func (a TransportAddr) marshal() string {
+	if a.Port == 0 {
+		if strings.Contains(a.Host, ":") {
+			return "[" + a.Host + "]"
+		}
+		return a.Host
+	}
+	return net.JoinHostPort(a.Host, strconv.FormatInt(int64(a.Port), 10))
+}
+
 // TransportProtocol is a transport protocol.
 type TransportProtocol int
 
@@ -151,6 +224,17 @@ type Transport struct {
 
 	// (optional) mode.
 	Mode *TransportMode
+
+	// (optional) destination addresses (RTSP 2.0).
+	DestAddr []TransportAddr
+
+	// (optional) source addresses (RTSP 2.0).
+	SrcAddr []TransportAddr
+
+	// whether RTP and RTCP are multiplexed on a single port (RFC 5761).
+	// When set, single ports in Ports, ClientPorts and ServerPorts
+	// are decoded and encoded as a pair of identical ports.
+	RTCPMux bool
 }
 
 // Unmarshal decodes a Transport header.
@@ -169,6 +253,7 @@ func (h *Transport) Unmarshal(v base.HeaderValue) error {
 	}
 
 	profileFound := false
+	var singlePorts []*[2]int
 
 	for k, rv := range kvs {
 		v := rv
@@ -247,6 +332,10 @@ func (h *Transport) Unmarshal(v base.HeaderValue) error {
 			}
 			h.Ports = ports
 
+			if !strings.Contains(v, "-") {
+				singlePorts = append(singlePorts, ports)
+			}
+
 		case "client_port":
 			ports, err2 := parsePorts(v)
 			if err2 != nil {
@@ -254,6 +343,10 @@ func (h *Transport) Unmarshal(v base.HeaderValue) error {
 			}
 			h.ClientPorts = ports
 
+			if !strings.Contains(v, "-") {
+				singlePorts = append(singlePorts, ports)
+			}
+
 		case "server_port":
 			ports, err2 := parsePorts(v)
 			if err2 != nil {
@@ -261,6 +354,10 @@ func (h *Transport) Unmarshal(v base.HeaderValue) error {
 			}
 			h.ServerPorts = ports
 
+			if !strings.Contains(v, "-") {
+				singlePorts = append(singlePorts, ports)
+			}
+
 		case "ssrc":
 			v = strings.TrimLeft(v, " ")
 
@@ -283,6 +380,23 @@ func (h *Transport) Unmarshal(v base.HeaderValue) error {
 			}
 			h.Mode = &m
 
+		case "dest_addr":
+			addrs, err2 := parseTransportAddrs(v)
+			if err2 != nil {
+				return err2
+			}
+			h.DestAddr = addrs
+
+		case "src_addr":
+			addrs, err2 := parseTransportAddrs(v)
+			if err2 != nil {
+				return err2
+			}
+			h.SrcAddr = addrs
+
+		case "RTCP-mux", "rtcp-mux":
+			h.RTCPMux = true
+
 		default:
 			// ignore non-standard keys
 		}
@@ -292,6 +406,12 @@ func (h *Transport) Unmarshal(v base.HeaderValue) error {
 		return fmt.Errorf("profile is missing: %v", v[0])
 	}
 
+	if h.RTCPMux {
+		for _, ports := range singlePorts {
+			ports[1] = ports[0]
+		}
+	}
+
 	return nil
 }
 
@@ -342,8 +462,7 @@ func (h Transport) Marshal() base.HeaderValue {
 	}
 
 	if h.Ports != nil {
-		rets = append(rets, "port="+strconv.FormatInt(int64(h.Ports[0]), 10)+
-			"-"+strconv.FormatInt(int64(h.Ports[1]), 10))
+		rets = append(rets, "port="+marshalPorts(h.Ports, h.RTCPMux))
 	}
 
 	if h.TTL != nil {
@@ -351,13 +470,11 @@ func (h Transport) Marshal() base.HeaderValue {
 	}
 
 	if h.ClientPorts != nil {
-		rets = append(rets, "client_port="+strconv.FormatInt(int64(h.ClientPorts[0]), 10)+
-			"-"+strconv.FormatInt(int64(h.ClientPorts[1]), 10))
+		rets = append(rets, "client_port="+marshalPorts(h.ClientPorts, h.RTCPMux))
 	}
 
 	if h.ServerPorts != nil {
-		rets = append(rets, "server_port="+strconv.FormatInt(int64(h.ServerPorts[0]), 10)+
-			"-"+strconv.FormatInt(int64(h.ServerPorts[1]), 10))
+		rets = append(rets, "server_port="+marshalPorts(h.ServerPorts, h.RTCPMux))
 	}
 
 	if h.SSRC != nil {
@@ -373,5 +490,17 @@ func (h Transport) Marshal() base.HeaderValue {
 		rets = append(rets, "mode="+h.Mode.String())
 	}
 
+	if len(h.DestAddr) != 0 {
+		rets = append(rets, "dest_addr="+marshalTransportAddrs(h.DestAddr))
+	}
+
+	if len(h.SrcAddr) != 0 {
+		rets = append(rets, "src_addr="+marshalTransportAddrs(h.SrcAddr))
+	}
+
+	if h.RTCPMux {
+		rets = append(rets, "RTCP-mux")
+	}
+
 	return base.HeaderValue{strings.Join(rets, ";")}
 }
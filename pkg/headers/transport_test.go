@@ -49,6 +49,40 @@ var casesTransport = []struct {
 			ServerPorts: &[2]int{5000, 5001},
 		},
 	},
+	{
+		"rtsp 2.0 udp unicast play request",
+		base.HeaderValue{`RTP/AVP/UDP;unicast;dest_addr=":3456"/":3457"`},
+		base.HeaderValue{`RTP/AVP;unicast;dest_addr=":3456"/":3457"`},
+		Transport{
+			Protocol: TransportProtocolUDP,
+			Delivery: deliveryPtr(TransportDeliveryUnicast),
+			DestAddr: []TransportAddr{{Port: 3456}, {Port: 3457}},
+		},
+	},
+	{
+		"rtsp 2.0 udp unicast play response",
+		base.HeaderValue{`RTP/AVP/UDP;unicast;dest_addr="192.0.2.5:3456"/"192.0.2.5:3457";` +
+			`src_addr="192.0.2.224:6256"/"192.0.2.224:6257";ssrc=2A3F93ED`},
+		base.HeaderValue{`RTP/AVP;unicast;ssrc=2A3F93ED;dest_addr="192.0.2.5:3456"/"192.0.2.5:3457";` +
+			`src_addr="192.0.2.224:6256"/"192.0.2.224:6257"`},
+		Transport{
+			Protocol: TransportProtocolUDP,
+			Delivery: deliveryPtr(TransportDeliveryUnicast),
+			SSRC:     uint32Ptr(0x2A3F93ED),
+			DestAddr: []TransportAddr{{Host: "192.0.2.5", Port: 3456}, {Host: "192.0.2.5", Port: 3457}},
+			SrcAddr:  []TransportAddr{{Host: "192.0.2.224", Port: 6256}, {Host: "192.0.2.224", Port: 6257}},
+		},
+	},
+	{
+		"rtsp 2.0 udp multicast play response",
+		base.HeaderValue{`RTP/AVP/UDP;multicast;dest_addr="[ff02::1]:7000"/"[ff02::1]:7001"`},
+		base.HeaderValue{`RTP/AVP;multicast;dest_addr="[ff02::1]:7000"/"[ff02::1]:7001"`},
+		Transport{
+			Protocol: TransportProtocolUDP,
+			Delivery: deliveryPtr(TransportDeliveryMulticast),
+			DestAddr: []TransportAddr{{Host: "ff02::1", Port: 7000}, {Host: "ff02::1", Port: 7001}},
+		},
+	},
 	{
 		"udp multicast play request / response",
 		base.HeaderValue{`RTP/AVP;multicast;destination=225.219.201.15;port=7000-7001;ttl=127`},
@@ -61,6 +95,32 @@ var casesTransport = []struct {
 			Ports:       &[2]int{7000, 7001},
 		},
 	},
+	{
+		"udp ssm multicast play response",
+		base.HeaderValue{`RTP/AVP;multicast;source=192.0.2.1;destination=232.1.0.1;port=7000-7001;ttl=127`},
+		base.HeaderValue{`RTP/AVP;multicast;source=192.0.2.1;destination=232.1.0.1;port=7000-7001;ttl=127`},
+		Transport{
+			Protocol:    TransportProtocolUDP,
+			Delivery:    deliveryPtr(TransportDeliveryMulticast),
+			Source:      ipPtr(net.ParseIP("192.0.2.1")),
+			Destination: ipPtr(net.ParseIP("232.1.0.1")),
+			TTL:         uintPtr(127),
+			Ports:       &[2]int{7000, 7001},
+		},
+	},
+	{
+		"udp ipv6 ssm multicast play response",
+		base.HeaderValue{`RTP/AVP;multicast;source=2001:db8::1;destination=ff3e::8000:1;port=7000-7001;ttl=127`},
+		base.HeaderValue{`RTP/AVP;multicast;source=2001:db8::1;destination=ff3e::8000:1;port=7000-7001;ttl=127`},
+		Transport{
+			Protocol:    TransportProtocolUDP,
+			Delivery:    deliveryPtr(TransportDeliveryMulticast),
+			Source:      ipPtr(net.ParseIP("2001:db8::1")),
+			Destination: ipPtr(net.ParseIP("ff3e::8000:1")),
+			TTL:         uintPtr(127),
+			Ports:       &[2]int{7000, 7001},
+		},
+	},
 	{
 		"tcp play request / response",
 		base.HeaderValue{`RTP/AVP/TCP;interleaved=0-1`},
@@ -168,6 +228,30 @@ var casesTransport = []struct {
 			ServerPorts: &[2]int{56002, 56003},
 		},
 	},
+	{
+		"udp unicast play request with rtcp-mux",
+		base.HeaderValue{`RTP/AVP;unicast;client_port=3456-3457;RTCP-mux;mode="PLAY"`},
+		base.HeaderValue{`RTP/AVP;unicast;client_port=3456-3457;mode=play;RTCP-mux`},
+		Transport{
+			Protocol:    TransportProtocolUDP,
+			Delivery:    deliveryPtr(TransportDeliveryUnicast),
+			ClientPorts: &[2]int{3456, 3457},
+			Mode:        transportModePtr(TransportModePlay),
+			RTCPMux:     true,
+		},
+	},
+	{
+		"udp unicast play response with rtcp-mux",
+		base.HeaderValue{`RTP/AVP/UDP;unicast;client_port=3456;server_port=5000;rtcp-mux`},
+		base.HeaderValue{`RTP/AVP;unicast;client_port=3456;server_port=5000;RTCP-mux`},
+		Transport{
+			Protocol:    TransportProtocolUDP,
+			Delivery:    deliveryPtr(TransportDeliveryUnicast),
+			ClientPorts: &[2]int{3456, 3456},
+			ServerPorts: &[2]int{5000, 5000},
+			RTCPMux:     true,
+		},
+	},
 	{
 		"secure udp unicast play request",
 		base.HeaderValue{`RTP/SAVP;unicast;client_port=3456-3457;mode="PLAY"`},
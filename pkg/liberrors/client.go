@@ -107,6 +107,16 @@ func (e ErrClientTransportHeaderInvalid) Error() string {
 	return fmt.Sprintf("invalid transport header: %v", e.Err)
 }
 
+// ErrClientSeekStyleHeaderInvalid is an error that can be returned by a client.
+type ErrClientSeekStyleHeaderInvalid struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e ErrClientSeekStyleHeaderInvalid) Error() string {
+	return fmt.Sprintf("invalid seek-style header: %v", e.Err)
+}
+
 // ErrClientServerRequestedTCP is an error that can be returned by a client.
 type ErrClientServerRequestedTCP struct{}
 
@@ -343,3 +353,38 @@ type ErrClientSDPInvalid struct {
 func (e ErrClientSDPInvalid) Error() string {
 	return fmt.Sprintf("invalid SDP: %v", e.Err)
 }
+
+// ErrClientHTTPTunnelBadStatusCode is an error that can be returned by a client.
+type ErrClientHTTPTunnelBadStatusCode struct {
+	Code    int
+	Message string
+}
+
+// Error implements the error interface.
+func (e ErrClientHTTPTunnelBadStatusCode) Error() string {
+	return fmt.Sprintf("bad HTTP tunnel status code: %d (%s)", e.Code, e.Message)
+}
+
+// ErrClientRedirectWhileRecording is an error that can be returned by a client.
+type ErrClientRedirectWhileRecording struct{}
+
+// Error implements the error interface.
+func (e ErrClientRedirectWhileRecording) Error() string {
+	return "server requested a redirect while recording, which is not supported"
+}
+
+// ErrClientRedirectMediasIncompatible is an error that can be returned by a client.
+type ErrClientRedirectMediasIncompatible struct{}
+
+// Error implements the error interface.
+func (e ErrClientRedirectMediasIncompatible) Error() string {
+	return "medias at the redirect location are not compatible with the current ones"
+}
+
+// ErrClientDescriptionIncompatible is an error that can be returned by a client.
+type ErrClientDescriptionIncompatible struct{}
+
+// Error implements the error interface.
+func (e ErrClientDescriptionIncompatible) Error() string {
+	return "stream description is not compatible with the previous one"
+}
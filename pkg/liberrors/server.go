@@ -3,6 +3,7 @@ package liberrors
 import (
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 )
@@ -120,6 +121,44 @@ func (e ErrServerTransportHeaderInterleavedIDsInUse) Error() string {
 	return "interleaved IDs are in use"
 }
 
+// ErrServerTransportHeaderInvalidDestination is an error that can be returned by a server.
+type ErrServerTransportHeaderInvalidDestination struct {
+	Destination net.IP
+}
+
+// Error implements the error interface.
+func (e ErrServerTransportHeaderInvalidDestination) Error() string {
+	return fmt.Sprintf("destination %v is not in the multicast IP range", e.Destination)
+}
+
+// ErrServerTransportHeaderInvalidPorts is an error that can be returned by a server.
+type ErrServerTransportHeaderInvalidPorts struct {
+	Ports [2]int
+}
+
+// Error implements the error interface.
+func (e ErrServerTransportHeaderInvalidPorts) Error() string {
+	return fmt.Sprintf("invalid multicast ports (%d-%d)", e.Ports[0], e.Ports[1])
+}
+
+// ErrServerTransportHeaderInvalidTTL is an error that can be returned by a server.
+type ErrServerTransportHeaderInvalidTTL struct {
+	TTL uint
+}
+
+// Error implements the error interface.
+func (e ErrServerTransportHeaderInvalidTTL) Error() string {
+	return fmt.Sprintf("invalid TTL (%d)", e.TTL)
+}
+
+// ErrServerMulticastGroupInUse is an error that can be returned by a server.
+type ErrServerMulticastGroupInUse struct{}
+
+// Error implements the error interface.
+func (e ErrServerMulticastGroupInUse) Error() string {
+	return "media is already being sent to a multicast group with different parameters"
+}
+
 // ErrServerMediasDifferentPaths is an error that can be returned by a server.
 type ErrServerMediasDifferentPaths struct{}
 
@@ -231,6 +270,14 @@ func (e ErrServerUDPPortsAlreadyInUse) Error() string {
 		e.Port, e.Port+1)
 }
 
+// ErrServerNoUDPPortsAvailable is an error that can be returned by a server.
+type ErrServerNoUDPPortsAvailable struct{}
+
+// Error implements the error interface.
+func (e ErrServerNoUDPPortsAvailable) Error() string {
+	return "no UDP ports are available in the session port range"
+}
+
 // ErrServerSessionNotInUse is an error that can be returned by a server.
 type ErrServerSessionNotInUse struct{}
 
@@ -297,3 +344,73 @@ type ErrServerAuth struct{}
 func (e ErrServerAuth) Error() string {
 	return "authentication error"
 }
+
+// ErrServerScaleHeaderInvalid is an error that can be returned by a server.
+type ErrServerScaleHeaderInvalid struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e ErrServerScaleHeaderInvalid) Error() string {
+	return fmt.Sprintf("invalid scale header: %v", e.Err)
+}
+
+// ErrServerSpeedHeaderInvalid is an error that can be returned by a server.
+type ErrServerSpeedHeaderInvalid struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e ErrServerSpeedHeaderInvalid) Error() string {
+	return fmt.Sprintf("invalid speed header: %v", e.Err)
+}
+
+// ErrServerRateControlHeaderInvalid is an error that can be returned by a server.
+type ErrServerRateControlHeaderInvalid struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e ErrServerRateControlHeaderInvalid) Error() string {
+	return fmt.Sprintf("invalid rate-control header: %v", e.Err)
+}
+
+// ErrServerFramesHeaderInvalid is an error that can be returned by a server.
+type ErrServerFramesHeaderInvalid struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e ErrServerFramesHeaderInvalid) Error() string {
+	return fmt.Sprintf("invalid frames header: %v", e.Err)
+}
+
+// ErrServerImmediateHeaderInvalid is an error that can be returned by a server.
+type ErrServerImmediateHeaderInvalid struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e ErrServerImmediateHeaderInvalid) Error() string {
+	return fmt.Sprintf("invalid immediate header: %v", e.Err)
+}
+
+// ErrServerSeekStyleHeaderInvalid is an error that can be returned by a server.
+type ErrServerSeekStyleHeaderInvalid struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e ErrServerSeekStyleHeaderInvalid) Error() string {
+	return fmt.Sprintf("invalid seek-style header: %v", e.Err)
+}
+
+// ErrServerSlowReader is an error that can be returned by a server.
+type ErrServerSlowReader struct {
+	Backlog time.Duration
+}
+
+// Error implements the error interface.
+func (e ErrServerSlowReader) Error() string {
+	return fmt.Sprintf("reader is too slow, backlog of %v", e.Backlog)
+}
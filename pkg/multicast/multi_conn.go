@@ -5,20 +5,15 @@ package multicast
 import (
 	"fmt"
 	"net"
-	"strconv"
 	"time"
-
-	"golang.org/x/net/ipv4"
 )
 
 // multiConn is a multicast connection
 // that works in parallel on all interfaces.
 type multiConn struct {
-	addr         *net.UDPAddr
-	readConn     *net.UDPConn
-	readConnIP   *ipv4.PacketConn
-	writeConns   []*net.UDPConn
-	writeConnIPs []*ipv4.PacketConn
+	addr       *net.UDPAddr
+	readConn   *net.UDPConn
+	writeConns []*net.UDPConn
 }
 
 // NewMultiConn allocates a multi-interface multicast connection.
@@ -27,12 +22,12 @@ func NewMultiConn(
 	readOnly bool,
 	listenPacket func(network, address string) (net.PacketConn, error),
 ) (Conn, error) {
-	addr, err := net.ResolveUDPAddr("udp4", address)
+	addr, err := net.ResolveUDPAddr("udp", address)
 	if err != nil {
 		return nil, err
 	}
 
-	tmp, err := listenPacket("udp4", "224.0.0.0:"+strconv.FormatInt(int64(addr.Port), 10))
+	tmp, err := listenPacket(listenAddress(addr))
 	if err != nil {
 		return nil, err
 	}
@@ -44,8 +39,6 @@ func NewMultiConn(
 		return nil, err
 	}
 
-	readConnIP := ipv4.NewPacketConn(readConn)
-
 	var enabledInterfaces []*net.Interface //nolint:prealloc
 
 	for _, intf := range intfs {
@@ -54,7 +47,7 @@ func NewMultiConn(
 		}
 		cintf := intf
 
-		err = readConnIP.JoinGroup(&cintf, &net.UDPAddr{IP: addr.IP})
+		err = joinGroup(readConn, &cintf, addr.IP)
 		if err != nil {
 			continue
 		}
@@ -68,14 +61,12 @@ func NewMultiConn(
 	}
 
 	var writeConns []*net.UDPConn
-	var writeConnIPs []*ipv4.PacketConn
 
 	if !readOnly {
 		writeConns = make([]*net.UDPConn, len(enabledInterfaces))
-		writeConnIPs = make([]*ipv4.PacketConn, len(enabledInterfaces))
 
 		for i, intf := range enabledInterfaces {
-			tmp, err := listenPacket("udp4", "224.0.0.0:"+strconv.FormatInt(int64(addr.Port), 10))
+			tmp, err := listenPacket(listenAddress(addr))
 			if err != nil {
 				for j := 0; j < i; j++ {
 					writeConns[j].Close() //nolint:errcheck
@@ -85,10 +76,9 @@ func NewMultiConn(
 			}
 			writeConn := tmp.(*net.UDPConn)
 
-			writeConnIP := ipv4.NewPacketConn(writeConn)
-
-			err = writeConnIP.SetMulticastInterface(intf)
+			err = setMulticastInterface(writeConn, intf, addr.IP)
 			if err != nil {
+				writeConn.Close() //nolint:errcheck
 				for j := 0; j < i; j++ {
 					writeConns[j].Close() //nolint:errcheck
 				}
@@ -96,8 +86,9 @@ func NewMultiConn(
 				return nil, err
 			}
 
-			err = writeConnIP.SetMulticastTTL(multicastTTL)
+			err = setMulticastTTL(writeConn, addr.IP, multicastTTL)
 			if err != nil {
+				writeConn.Close() //nolint:errcheck
 				for j := 0; j < i; j++ {
 					writeConns[j].Close() //nolint:errcheck
 				}
@@ -106,16 +97,13 @@ func NewMultiConn(
 			}
 
 			writeConns[i] = writeConn
-			writeConnIPs[i] = writeConnIP
 		}
 	}
 
 	return &multiConn{
-		addr:         addr,
-		readConn:     readConn,
-		readConnIP:   readConnIP,
-		writeConns:   writeConns,
-		writeConnIPs: writeConnIPs,
+		addr:       addr,
+		readConn:   readConn,
+		writeConns: writeConns,
 	}, nil
 }
 
@@ -133,6 +121,17 @@ func (c *multiConn) SetReadBuffer(bytes int) error {
 	return c.readConn.SetReadBuffer(bytes)
 }
 
+// SetMulticastTTL implements Conn.
+func (c *multiConn) SetMulticastTTL(ttl int) error {
+	for _, c2 := range c.writeConns {
+		err := setMulticastTTL(c2, c.addr.IP, ttl)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // LocalAddr implements Conn.
 func (c *multiConn) LocalAddr() net.Addr {
 	return c.readConn.LocalAddr()
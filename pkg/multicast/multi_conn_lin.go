@@ -26,26 +26,17 @@ func NewMultiConn(
 	readOnly bool,
 	_ func(network, address string) (net.PacketConn, error),
 ) (Conn, error) {
-	addr, err := net.ResolveUDPAddr("udp4", address)
+	addr, err := net.ResolveUDPAddr("udp", address)
 	if err != nil {
 		return nil, err
 	}
 
-	readSock, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, syscall.IPPROTO_UDP)
+	readSock, err := newSocket(addr.IP)
 	if err != nil {
 		return nil, err
 	}
 
-	err = syscall.SetsockoptInt(readSock, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
-	if err != nil {
-		syscall.Close(readSock) //nolint:errcheck
-		return nil, err
-	}
-
-	var lsa syscall.SockaddrInet4
-	lsa.Port = addr.Port
-	copy(lsa.Addr[:], addr.IP.To4())
-	err = syscall.Bind(readSock, &lsa)
+	err = bindSocket(readSock, addr.IP, addr.Port)
 	if err != nil {
 		syscall.Close(readSock) //nolint:errcheck
 		return nil, err
@@ -65,14 +56,7 @@ func NewMultiConn(
 		}
 		cintf := intf
 
-		var mreq syscall.IPMreq
-		copy(mreq.Multiaddr[:], addr.IP.To4())
-		err = setIPMreqInterface(&mreq, &cintf)
-		if err != nil {
-			continue
-		}
-
-		err = syscall.SetsockoptIPMreq(readSock, syscall.IPPROTO_IP, syscall.IP_ADD_MEMBERSHIP, &mreq)
+		err = joinGroup(readSock, &cintf, addr.IP)
 		if err != nil {
 			continue
 		}
@@ -92,7 +76,7 @@ func NewMultiConn(
 		writeSocks := make([]int, len(enabledInterfaces))
 
 		for i, intf := range enabledInterfaces {
-			writeSock, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, syscall.IPPROTO_UDP)
+			writeSock, err := newSocket(addr.IP)
 			if err != nil {
 				for j := 0; j < i; j++ {
 					syscall.Close(writeSocks[j]) //nolint:errcheck
@@ -101,7 +85,7 @@ func NewMultiConn(
 				return nil, err
 			}
 
-			err = syscall.SetsockoptInt(writeSock, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+			err = bindSocket(writeSock, addr.IP, addr.Port)
 			if err != nil {
 				syscall.Close(writeSock) //nolint:errcheck
 				for j := 0; j < i; j++ {
@@ -111,10 +95,7 @@ func NewMultiConn(
 				return nil, err
 			}
 
-			var lsa syscall.SockaddrInet4
-			lsa.Port = addr.Port
-			copy(lsa.Addr[:], addr.IP.To4())
-			err = syscall.Bind(writeSock, &lsa)
+			err = setMulticastInterface(writeSock, intf, addr.IP)
 			if err != nil {
 				syscall.Close(writeSock) //nolint:errcheck
 				for j := 0; j < i; j++ {
@@ -124,20 +105,7 @@ func NewMultiConn(
 				return nil, err
 			}
 
-			var mreqn syscall.IPMreqn
-			mreqn.Ifindex = int32(intf.Index)
-
-			err = syscall.SetsockoptIPMreqn(writeSock, syscall.IPPROTO_IP, syscall.IP_MULTICAST_IF, &mreqn)
-			if err != nil {
-				syscall.Close(writeSock) //nolint:errcheck
-				for j := 0; j < i; j++ {
-					syscall.Close(writeSocks[j]) //nolint:errcheck
-				}
-				syscall.Close(readSock) //nolint:errcheck
-				return nil, err
-			}
-
-			err = syscall.SetsockoptInt(writeSock, syscall.IPPROTO_IP, syscall.IP_MULTICAST_TTL, multicastTTL)
+			err = setMulticastTTL(writeSock, addr.IP, multicastTTL)
 			if err != nil {
 				syscall.Close(writeSock) //nolint:errcheck
 				for j := 0; j < i; j++ {
@@ -187,6 +155,17 @@ func (c *multiConn) SetReadBuffer(bytes int) error {
 	return syscall.SetsockoptInt(int(c.readFile.Fd()), syscall.SOL_SOCKET, syscall.SO_RCVBUF, bytes)
 }
 
+// SetMulticastTTL implements Conn.
+func (c *multiConn) SetMulticastTTL(ttl int) error {
+	for _, f := range c.writeFiles {
+		err := setMulticastTTL(int(f.Fd()), c.addr.IP, ttl)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // LocalAddr implements Conn.
 func (c *multiConn) LocalAddr() net.Addr {
 	return c.readConn.LocalAddr()
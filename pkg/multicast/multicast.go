@@ -4,12 +4,21 @@ package multicast
 import (
 	"fmt"
 	"net"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+var (
+	ssmIPv4Range = &net.IPNet{IP: net.IP{232, 0, 0, 0}, Mask: net.CIDRMask(8, 32)}
+	ssmIPv6Range = &net.IPNet{IP: net.ParseIP("ff30::"), Mask: net.CIDRMask(12, 128)}
 )
 
 // Conn is a Multicast connection.
 type Conn interface {
 	net.PacketConn
 	SetReadBuffer(int) error
+	SetMulticastTTL(int) error
 }
 
 // InterfaceForSource returns a multicast-capable interface that can communicate with given IP.
@@ -41,3 +50,31 @@ func InterfaceForSource(ip net.IP) (*net.Interface, error) {
 
 	return nil, fmt.Errorf("found no interface that is multicast-capable and can communicate with IP %v", ip)
 }
+
+// IsSSM checks whether a group belongs to a source-specific multicast range
+// (232.0.0.0/8 or ff3x::/32).
+// Specification: https://datatracker.ietf.org/doc/html/rfc4607
+func IsSSM(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ssmIPv4Range.Contains(ip4)
+	}
+	return ssmIPv6Range.Contains(ip) && ip[2] == 0 && ip[3] == 0
+}
+
+// joinSourceSpecificGroup joins a source-specific multicast group
+// with IGMPv3 (IPv4) or MLDv2 (IPv6).
+func joinSourceSpecificGroup(conn net.PacketConn, intf *net.Interface, group net.IP, source net.IP) error {
+	if group.To4() != nil {
+		if source.To4() == nil {
+			return fmt.Errorf("source %v and group %v belong to different IP versions", source, group)
+		}
+		return ipv4.NewPacketConn(conn).JoinSourceSpecificGroup(intf,
+			&net.UDPAddr{IP: group}, &net.UDPAddr{IP: source})
+	}
+
+	if source.To4() != nil {
+		return fmt.Errorf("source %v and group %v belong to different IP versions", source, group)
+	}
+	return ipv6.NewPacketConn(conn).JoinSourceSpecificGroup(intf,
+		&net.UDPAddr{IP: group}, &net.UDPAddr{IP: source})
+}
@@ -0,0 +1,29 @@
+package multicast
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsSSM(t *testing.T) {
+	for _, ca := range []struct {
+		ip  string
+		ssm bool
+	}{
+		{"224.1.0.1", false},
+		{"232.0.0.1", true},
+		{"232.255.255.255", true},
+		{"233.0.0.1", false},
+		{"ff02::1", false},
+		{"ff15::1:1", false},
+		{"ff35::1:1", true},
+		{"ff3e::8000:1", true},
+		{"ff35:1::1", false},
+	} {
+		t.Run(ca.ip, func(t *testing.T) {
+			require.Equal(t, ca.ssm, IsSSM(net.ParseIP(ca.ip)))
+		})
+	}
+}
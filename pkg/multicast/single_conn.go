@@ -4,10 +4,7 @@ package multicast
 
 import (
 	"net"
-	"strconv"
 	"time"
-
-	"golang.org/x/net/ipv4"
 )
 
 const (
@@ -18,9 +15,8 @@ const (
 // singleConn is a multicast connection
 // that works on a single interface.
 type singleConn struct {
-	addr   *net.UDPAddr
-	conn   *net.UDPConn
-	connIP *ipv4.PacketConn
+	addr *net.UDPAddr
+	conn *net.UDPConn
 }
 
 // NewSingleConn allocates a single-interface multicast connection.
@@ -29,41 +25,62 @@ func NewSingleConn(
 	address string,
 	listenPacket func(network, address string) (net.PacketConn, error),
 ) (Conn, error) {
-	addr, err := net.ResolveUDPAddr("udp4", address)
+	return newSingleConn(intf, address, nil, listenPacket)
+}
+
+// NewSingleConnSSM allocates a single-interface multicast connection that
+// receives packets of a source-specific multicast group from a given source.
+func NewSingleConnSSM(
+	intf *net.Interface,
+	address string,
+	source net.IP,
+	listenPacket func(network, address string) (net.PacketConn, error),
+) (Conn, error) {
+	return newSingleConn(intf, address, source, listenPacket)
+}
+
+func newSingleConn(
+	intf *net.Interface,
+	address string,
+	source net.IP,
+	listenPacket func(network, address string) (net.PacketConn, error),
+) (Conn, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
 	if err != nil {
 		return nil, err
 	}
 
-	tmp, err := listenPacket("udp4", "224.0.0.0:"+strconv.FormatInt(int64(addr.Port), 10))
+	tmp, err := listenPacket(listenAddress(addr))
 	if err != nil {
 		return nil, err
 	}
 	conn := tmp.(*net.UDPConn)
 
-	connIP := ipv4.NewPacketConn(conn)
-
-	err = connIP.JoinGroup(intf, &net.UDPAddr{IP: addr.IP})
+	if source != nil {
+		err = joinSourceSpecificGroup(conn, intf, addr.IP, source)
+	} else {
+		err = joinGroup(conn, intf, addr.IP)
+	}
 	if err != nil {
 		conn.Close() //nolint:errcheck
 		return nil, err
 	}
 
-	err = connIP.SetMulticastInterface(intf)
+	err = setMulticastInterface(conn, intf, addr.IP)
 	if err != nil {
 		conn.Close() //nolint:errcheck
 		return nil, err
 	}
 
-	err = connIP.SetMulticastTTL(multicastTTL)
+	err = setMulticastTTL(conn, addr.IP, multicastTTL)
 	if err != nil {
 		conn.Close() //nolint:errcheck
 		return nil, err
 	}
 
 	return &singleConn{
-		addr:   addr,
-		conn:   conn,
-		connIP: connIP,
+		addr: addr,
+		conn: conn,
 	}, nil
 }
 
@@ -77,6 +94,11 @@ func (c *singleConn) SetReadBuffer(bytes int) error {
 	return c.conn.SetReadBuffer(bytes)
 }
 
+// SetMulticastTTL implements Conn.
+func (c *singleConn) SetMulticastTTL(ttl int) error {
+	return setMulticastTTL(c.conn, c.addr.IP, ttl)
+}
+
 // LocalAddr implements Conn.
 func (c *singleConn) LocalAddr() net.Addr {
 	return c.conn.LocalAddr()
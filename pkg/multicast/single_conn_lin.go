@@ -3,7 +3,6 @@
 package multicast
 
 import (
-	"fmt"
 	"net"
 	"os"
 	"syscall"
@@ -15,32 +14,6 @@ const (
 	multicastTTL = 16
 )
 
-// https://cs.opensource.google/go/x/net/+/refs/tags/v0.15.0:ipv4/sys_asmreq.go;l=51
-func setIPMreqInterface(mreq *syscall.IPMreq, ifi *net.Interface) error {
-	if ifi == nil {
-		return nil
-	}
-	ifat, err := ifi.Addrs()
-	if err != nil {
-		return err
-	}
-	for _, ifa := range ifat {
-		switch ifa := ifa.(type) {
-		case *net.IPAddr:
-			if ip := ifa.IP.To4(); ip != nil {
-				copy(mreq.Interface[:], ip)
-				return nil
-			}
-		case *net.IPNet:
-			if ip := ifa.IP.To4(); ip != nil {
-				copy(mreq.Interface[:], ip)
-				return nil
-			}
-		}
-	}
-	return fmt.Errorf("no such interface")
-}
-
 // singleConn is a multicast connection
 // that works on a single interface.
 type singleConn struct {
@@ -53,63 +26,65 @@ type singleConn struct {
 func NewSingleConn(
 	intf *net.Interface,
 	address string,
-	_ func(network, address string) (net.PacketConn, error),
+	listenPacket func(network, address string) (net.PacketConn, error),
 ) (Conn, error) {
-	addr, err := net.ResolveUDPAddr("udp4", address)
-	if err != nil {
-		return nil, err
-	}
+	return newSingleConn(intf, address, nil, listenPacket)
+}
 
-	sock, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, syscall.IPPROTO_UDP)
-	if err != nil {
-		return nil, err
-	}
+// NewSingleConnSSM allocates a singleConn that receives packets
+// of a source-specific multicast group from a given source.
+func NewSingleConnSSM(
+	intf *net.Interface,
+	address string,
+	source net.IP,
+	listenPacket func(network, address string) (net.PacketConn, error),
+) (Conn, error) {
+	return newSingleConn(intf, address, source, listenPacket)
+}
 
-	err = syscall.SetsockoptInt(sock, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+func newSingleConn(
+	intf *net.Interface,
+	address string,
+	source net.IP,
+	_ func(network, address string) (net.PacketConn, error),
+) (Conn, error) {
+	addr, err := net.ResolveUDPAddr("udp", address)
 	if err != nil {
-		syscall.Close(sock) //nolint:errcheck
 		return nil, err
 	}
 
-	err = syscall.SetsockoptString(sock, syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, intf.Name)
+	sock, err := newSocket(addr.IP)
 	if err != nil {
-		syscall.Close(sock) //nolint:errcheck
 		return nil, err
 	}
 
-	var lsa syscall.SockaddrInet4
-	lsa.Port = addr.Port
-	copy(lsa.Addr[:], addr.IP.To4())
-	err = syscall.Bind(sock, &lsa)
+	err = syscall.SetsockoptString(sock, syscall.SOL_SOCKET, syscall.SO_BINDTODEVICE, intf.Name)
 	if err != nil {
 		syscall.Close(sock) //nolint:errcheck
 		return nil, err
 	}
 
-	var mreq syscall.IPMreq
-	copy(mreq.Multiaddr[:], addr.IP.To4())
-	err = setIPMreqInterface(&mreq, intf)
+	err = bindSocket(sock, addr.IP, addr.Port)
 	if err != nil {
 		syscall.Close(sock) //nolint:errcheck
 		return nil, err
 	}
 
-	err = syscall.SetsockoptIPMreq(sock, syscall.IPPROTO_IP, syscall.IP_ADD_MEMBERSHIP, &mreq)
-	if err != nil {
-		syscall.Close(sock) //nolint:errcheck
-		return nil, err
+	if source == nil {
+		err = joinGroup(sock, intf, addr.IP)
+		if err != nil {
+			syscall.Close(sock) //nolint:errcheck
+			return nil, err
+		}
 	}
 
-	var mreqn syscall.IPMreqn
-	mreqn.Ifindex = int32(intf.Index)
-
-	err = syscall.SetsockoptIPMreqn(sock, syscall.IPPROTO_IP, syscall.IP_MULTICAST_IF, &mreqn)
+	err = setMulticastInterface(sock, intf, addr.IP)
 	if err != nil {
 		syscall.Close(sock) //nolint:errcheck
 		return nil, err
 	}
 
-	err = syscall.SetsockoptInt(sock, syscall.IPPROTO_IP, syscall.IP_MULTICAST_TTL, multicastTTL)
+	err = setMulticastTTL(sock, addr.IP, multicastTTL)
 	if err != nil {
 		syscall.Close(sock) //nolint:errcheck
 		return nil, err
@@ -118,10 +93,19 @@ func NewSingleConn(
 	file := os.NewFile(uintptr(sock), "")
 	conn, err := net.FilePacketConn(file)
 	if err != nil {
-		file.Close()
+		file.Close() //nolint:errcheck
 		return nil, err
 	}
 
+	if source != nil {
+		err = joinSourceSpecificGroup(conn, intf, addr.IP, source)
+		if err != nil {
+			conn.Close() //nolint:errcheck
+			file.Close() //nolint:errcheck
+			return nil, err
+		}
+	}
+
 	return &singleConn{
 		addr: addr,
 		file: file,
@@ -141,6 +125,11 @@ func (c *singleConn) SetReadBuffer(bytes int) error {
 	return syscall.SetsockoptInt(int(c.file.Fd()), syscall.SOL_SOCKET, syscall.SO_RCVBUF, bytes)
 }
 
+// SetMulticastTTL implements Conn.
+func (c *singleConn) SetMulticastTTL(ttl int) error {
+	return setMulticastTTL(int(c.file.Fd()), c.addr.IP, ttl)
+}
+
 // LocalAddr implements Conn.
 func (c *singleConn) LocalAddr() net.Addr {
 	return c.conn.LocalAddr()
@@ -0,0 +1,42 @@
+//go:build !linux
+
+package multicast
+
+import (
+	"net"
+	"strconv"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// listenAddress returns the network and the address
+// to be used to listen on a multicast group.
+func listenAddress(addr *net.UDPAddr) (string, string) {
+	if addr.IP.To4() != nil {
+		return "udp4", "224.0.0.0:" + strconv.FormatInt(int64(addr.Port), 10)
+	}
+	return "udp6", "[::]:" + strconv.FormatInt(int64(addr.Port), 10)
+}
+
+// joinGroup joins an any-source multicast group.
+func joinGroup(conn net.PacketConn, intf *net.Interface, group net.IP) error {
+	if group.To4() != nil {
+		return ipv4.NewPacketConn(conn).JoinGroup(intf, &net.UDPAddr{IP: group})
+	}
+	return ipv6.NewPacketConn(conn).JoinGroup(intf, &net.UDPAddr{IP: group})
+}
+
+func setMulticastInterface(conn net.PacketConn, intf *net.Interface, group net.IP) error {
+	if group.To4() != nil {
+		return ipv4.NewPacketConn(conn).SetMulticastInterface(intf)
+	}
+	return ipv6.NewPacketConn(conn).SetMulticastInterface(intf)
+}
+
+func setMulticastTTL(conn net.PacketConn, group net.IP, ttl int) error {
+	if group.To4() != nil {
+		return ipv4.NewPacketConn(conn).SetMulticastTTL(ttl)
+	}
+	return ipv6.NewPacketConn(conn).SetMulticastHopLimit(ttl)
+}
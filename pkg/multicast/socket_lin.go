@@ -0,0 +1,109 @@
+//go:build linux
+
+package multicast
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// https://cs.opensource.google/go/x/net/+/refs/tags/v0.15.0:ipv4/sys_asmreq.go;l=51
+func setIPMreqInterface(mreq *syscall.IPMreq, ifi *net.Interface) error {
+	if ifi == nil {
+		return nil
+	}
+	ifat, err := ifi.Addrs()
+	if err != nil {
+		return err
+	}
+	for _, ifa := range ifat {
+		switch ifa := ifa.(type) {
+		case *net.IPAddr:
+			if ip := ifa.IP.To4(); ip != nil {
+				copy(mreq.Interface[:], ip)
+				return nil
+			}
+		case *net.IPNet:
+			if ip := ifa.IP.To4(); ip != nil {
+				copy(mreq.Interface[:], ip)
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no such interface")
+}
+
+// newSocket creates a UDP socket with the address family of a multicast group.
+func newSocket(group net.IP) (int, error) {
+	family := syscall.AF_INET
+	if group.To4() == nil {
+		family = syscall.AF_INET6
+	}
+
+	sock, err := syscall.Socket(family, syscall.SOCK_DGRAM, syscall.IPPROTO_UDP)
+	if err != nil {
+		return 0, err
+	}
+
+	err = syscall.SetsockoptInt(sock, syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1)
+	if err != nil {
+		syscall.Close(sock) //nolint:errcheck
+		return 0, err
+	}
+
+	return sock, nil
+}
+
+func bindSocket(sock int, group net.IP, port int) error {
+	if ip4 := group.To4(); ip4 != nil {
+		var lsa syscall.SockaddrInet4
+		lsa.Port = port
+		copy(lsa.Addr[:], ip4)
+		return syscall.Bind(sock, &lsa)
+	}
+
+	var lsa syscall.SockaddrInet6
+	lsa.Port = port
+	copy(lsa.Addr[:], group.To16())
+	return syscall.Bind(sock, &lsa)
+}
+
+// joinGroup joins an any-source multicast group.
+func joinGroup(sock int, intf *net.Interface, group net.IP) error {
+	if ip4 := group.To4(); ip4 != nil {
+		var mreq syscall.IPMreq
+		copy(mreq.Multiaddr[:], ip4)
+		err := setIPMreqInterface(&mreq, intf)
+		if err != nil {
+			return err
+		}
+
+		return syscall.SetsockoptIPMreq(sock, syscall.IPPROTO_IP, syscall.IP_ADD_MEMBERSHIP, &mreq)
+	}
+
+	var mreq syscall.IPv6Mreq
+	copy(mreq.Multiaddr[:], group.To16())
+	mreq.Interface = uint32(intf.Index)
+
+	return syscall.SetsockoptIPv6Mreq(sock, syscall.IPPROTO_IPV6, syscall.IPV6_JOIN_GROUP, &mreq)
+}
+
+func setMulticastInterface(sock int, intf *net.Interface, group net.IP) error {
+	if group.To4() != nil {
+		var mreqn syscall.IPMreqn
+		mreqn.Ifindex = int32(intf.Index)
+
+		return syscall.SetsockoptIPMreqn(sock, syscall.IPPROTO_IP, syscall.IP_MULTICAST_IF, &mreqn)
+	}
+
+	return syscall.SetsockoptInt(sock, syscall.IPPROTO_IPV6, syscall.IPV6_MULTICAST_IF, intf.Index)
+}
+
+func setMulticastTTL(sock int, group net.IP, ttl int) error {
+	if group.To4() != nil {
+		return syscall.SetsockoptInt(sock, syscall.IPPROTO_IP, syscall.IP_MULTICAST_TTL, ttl)
+	}
+
+	return syscall.SetsockoptInt(sock, syscall.IPPROTO_IPV6, syscall.IPV6_MULTICAST_HOPS, ttl)
+}
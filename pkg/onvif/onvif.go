@@ -0,0 +1,2 @@
+// Package onvif contains utilities to deal with ONVIF extensions of RTSP and RTP.
+package onvif
@@ -0,0 +1,104 @@
+package onvif
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pion/rtp"
+
+	"github.com/bluenviron/gortsplib/v4/internal/ntp"
+)
+
+// ReplayExtensionProfile is the profile of the ONVIF replay RTP header extension.
+const ReplayExtensionProfile = 0xABAC
+
+const replayExtensionSize = 12
+
+// ReplayExtension is the ONVIF replay RTP header extension.
+// Specification: ONVIF Streaming Specification, 6.3
+type ReplayExtension struct {
+	// absolute timestamp of the packet.
+	NTP time.Time
+
+	// whether the packet contains the beginning of a frame
+	// that can be decoded without depending on previous frames.
+	CleanPoint bool
+
+	// whether the packet is the last one of a contiguous section of the recording.
+	End bool
+
+	// whether the packet is the first one after a discontinuity of the recording.
+	Discontinuity bool
+
+	// whether the packet is the last one of the stream.
+	// It can be used together with End only.
+	Terminate bool
+
+	// lower byte of the CSeq of the PLAY request that started the transmission.
+	CSeq uint8
+}
+
+// Unmarshal decodes the extension from a RTP header.
+func (e *ReplayExtension) Unmarshal(h *rtp.Header) error {
+	if !h.Extension || h.ExtensionProfile != ReplayExtensionProfile {
+		return fmt.Errorf("replay extension not present")
+	}
+
+	buf := h.GetExtension(0)
+	if len(buf) < replayExtensionSize {
+		return fmt.Errorf("invalid replay extension size (%d)", len(buf))
+	}
+
+	e.NTP = ntp.Decode(uint64(buf[0])<<56 |
+		uint64(buf[1])<<48 |
+		uint64(buf[2])<<40 |
+		uint64(buf[3])<<32 |
+		uint64(buf[4])<<24 |
+		uint64(buf[5])<<16 |
+		uint64(buf[6])<<8 |
+		uint64(buf[7]))
+	e.CleanPoint = (buf[8] & 0x80) != 0
+	e.End = (buf[8] & 0x40) != 0
+	e.Discontinuity = (buf[8] & 0x20) != 0
+	e.Terminate = (buf[8] & 0x10) != 0
+	e.CSeq = buf[9]
+
+	return nil
+}
+
+// Marshal encodes the extension into a RTP header.
+// Any existing header extension is replaced.
+func (e ReplayExtension) Marshal(h *rtp.Header) error {
+	buf := make([]byte, replayExtensionSize)
+
+	v := ntp.Encode(e.NTP)
+	buf[0] = byte(v >> 56)
+	buf[1] = byte(v >> 48)
+	buf[2] = byte(v >> 40)
+	buf[3] = byte(v >> 32)
+	buf[4] = byte(v >> 24)
+	buf[5] = byte(v >> 16)
+	buf[6] = byte(v >> 8)
+	buf[7] = byte(v)
+
+	if e.CleanPoint {
+		buf[8] |= 0x80
+	}
+	if e.End {
+		buf[8] |= 0x40
+	}
+	if e.Discontinuity {
+		buf[8] |= 0x20
+	}
+	if e.Terminate {
+		buf[8] |= 0x10
+	}
+
+	buf[9] = e.CSeq
+
+	h.Extension = true
+	h.ExtensionProfile = ReplayExtensionProfile
+	h.Extensions = nil
+
+	return h.SetExtension(0, buf)
+}
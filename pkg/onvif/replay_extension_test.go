@@ -0,0 +1,128 @@
+package onvif
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+var casesReplayExtension = []struct {
+	name string
+	enc  []byte
+	dec  ReplayExtension
+}{
+	{
+		"clean point",
+		[]byte{
+			0x90, 0x60, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0xab, 0xac, 0x00, 0x03,
+			0xe8, 0xf0, 0x9b, 0xd0, 0x80, 0x00, 0x00, 0x00,
+			0x80, 0x05, 0x00, 0x00, 0x01, 0x02,
+		},
+		ReplayExtension{
+			NTP:        time.Date(2023, 11, 4, 10, 30, 40, 500000000, time.UTC),
+			CleanPoint: true,
+			CSeq:       5,
+		},
+	},
+	{
+		"end of stream",
+		[]byte{
+			0x90, 0x60, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x00, 0x00, 0xab, 0xac, 0x00, 0x03,
+			0xe8, 0xf0, 0x9b, 0xd0, 0x00, 0x00, 0x00, 0x00,
+			0x70, 0x03, 0x00, 0x00, 0x01, 0x02,
+		},
+		ReplayExtension{
+			NTP:           time.Date(2023, 11, 4, 10, 30, 40, 0, time.UTC),
+			End:           true,
+			Discontinuity: true,
+			Terminate:     true,
+			CSeq:          3,
+		},
+	},
+}
+
+func TestReplayExtensionUnmarshal(t *testing.T) {
+	for _, ca := range casesReplayExtension {
+		t.Run(ca.name, func(t *testing.T) {
+			var pkt rtp.Packet
+			err := pkt.Unmarshal(ca.enc)
+			require.NoError(t, err)
+
+			var e ReplayExtension
+			err = e.Unmarshal(&pkt.Header)
+			require.NoError(t, err)
+			require.True(t, ca.dec.NTP.Equal(e.NTP))
+			e.NTP = ca.dec.NTP
+			require.Equal(t, ca.dec, e)
+		})
+	}
+}
+
+func TestReplayExtensionMarshal(t *testing.T) {
+	for _, ca := range casesReplayExtension {
+		t.Run(ca.name, func(t *testing.T) {
+			pkt := rtp.Packet{
+				Header: rtp.Header{
+					Version:        2,
+					PayloadType:    96,
+					SequenceNumber: 1,
+				},
+				Payload: []byte{1, 2},
+			}
+
+			err := ca.dec.Marshal(&pkt.Header)
+			require.NoError(t, err)
+
+			buf, err := pkt.Marshal()
+			require.NoError(t, err)
+			require.Equal(t, ca.enc, buf)
+		})
+	}
+}
+
+func FuzzReplayExtensionUnmarshal(f *testing.F) {
+	for _, ca := range casesReplayExtension {
+		f.Add(ca.enc)
+	}
+
+	f.Fuzz(func(_ *testing.T, b []byte) {
+		var pkt rtp.Packet
+		err := pkt.Unmarshal(b)
+		if err != nil {
+			return
+		}
+
+		var e ReplayExtension
+		err = e.Unmarshal(&pkt.Header)
+		if err != nil {
+			return
+		}
+
+		e.Marshal(&pkt.Header) //nolint:errcheck
+	})
+}
+
+func TestReplayExtensionUnmarshalErrors(t *testing.T) {
+	func() {
+		var e ReplayExtension
+		err := e.Unmarshal(&rtp.Header{})
+		require.EqualError(t, err, "replay extension not present")
+	}()
+
+	func() {
+		h := rtp.Header{
+			Extension:        true,
+			ExtensionProfile: ReplayExtensionProfile,
+		}
+		err := h.SetExtension(0, []byte{1, 2, 3, 4})
+		require.NoError(t, err)
+
+		var e ReplayExtension
+		err = e.Unmarshal(&h)
+		require.EqualError(t, err, "invalid replay extension size (4)")
+	}()
+}
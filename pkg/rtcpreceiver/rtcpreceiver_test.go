@@ -57,12 +57,8 @@ func TestRTCPReceiver(t *testing.T) {
 	}, stats)
 
 	srPkt := rtcp.SenderReport{
-		SSRC: 0xba9da416,
-		NTPTime: func() uint64 {
-			d := time.Date(2008, 5, 20, 22, 15, 20, 0, time.UTC)
-			s := uint64(d.UnixNano()) + 2208988800*1000000000
-			return (s/1000000000)<<32 | (s % 1000000000)
-		}(),
+		SSRC:        0xba9da416,
+		NTPTime:     0xcbddcbf800000000,
 		RTPTime:     0xafb45733,
 		PacketCount: 714,
 		OctetCount:  859127,
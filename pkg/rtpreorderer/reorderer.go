@@ -146,3 +146,31 @@ func (r *Reorderer) Process(pkt *rtp.Packet) ([]*rtp.Packet, uint) {
 
 	return ret, 0
 }
+
+// Missing returns the sequence numbers of packets that have not been received yet
+// and that are preventing buffered packets from being returned.
+func (r *Reorderer) Missing() []uint16 {
+	if !r.initialized {
+		return nil
+	}
+
+	last := 0
+	for i := r.BufferSize - 1; i > 0; i-- {
+		p := (r.absPos + uint16(i)) & (uint16(r.BufferSize) - 1)
+		if r.buffer[p] != nil {
+			last = i
+			break
+		}
+	}
+
+	var ret []uint16
+
+	for i := 0; i < last; i++ {
+		p := (r.absPos + uint16(i)) & (uint16(r.BufferSize) - 1)
+		if r.buffer[p] == nil {
+			ret = append(ret, r.expectedSeqNum+uint16(i))
+		}
+	}
+
+	return ret
+}
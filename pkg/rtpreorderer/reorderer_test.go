@@ -301,3 +301,30 @@ func TestCustomBufferSize(t *testing.T) {
 		},
 	}}, out)
 }
+
+func TestMissing(t *testing.T) {
+	r := &Reorderer{}
+	r.Initialize()
+
+	require.Equal(t, []uint16(nil), r.Missing())
+
+	for _, ca := range []struct {
+		seqNum  uint16
+		missing []uint16
+	}{
+		{65533, nil},
+		{65534, nil},
+		{1, []uint16{65535, 0}},
+		{0, []uint16{65535}},
+		{3, []uint16{65535, 2}},
+		{65535, []uint16{2}},
+		{2, nil},
+	} {
+		r.Process(&rtp.Packet{
+			Header: rtp.Header{
+				SequenceNumber: ca.seqNum,
+			},
+		})
+		require.Equal(t, ca.missing, r.Missing())
+	}
+}
@@ -0,0 +1,63 @@
+package rtx
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	defaultBufferSize = 1024
+)
+
+type bufferEntry struct {
+	pkt *rtp.Packet
+	ntp time.Time
+}
+
+// Buffer stores sent RTP packets, in order to retransmit them.
+// It can be shared by Senders that retransmit the same stream to different receivers.
+type Buffer struct {
+	// number of packets that are kept for retransmission.
+	// It defaults to 1024.
+	Size int
+
+	mutex   sync.Mutex
+	entries []bufferEntry
+}
+
+// Initialize initializes a Buffer.
+func (b *Buffer) Initialize() {
+	if b.Size == 0 {
+		b.Size = defaultBufferSize
+	}
+
+	b.entries = make([]bufferEntry, b.Size)
+}
+
+// Add adds a sent RTP packet to the buffer.
+// ntp is the absolute timestamp of the packet.
+func (b *Buffer) Add(pkt *rtp.Packet, ntp time.Time) {
+	c := pkt.Clone()
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.entries[int(pkt.SequenceNumber)%b.Size] = bufferEntry{
+		pkt: c,
+		ntp: ntp,
+	}
+}
+
+func (b *Buffer) get(seqNum uint16) (bufferEntry, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	entry := b.entries[int(seqNum)%b.Size]
+	if entry.pkt == nil || entry.pkt.SequenceNumber != seqNum {
+		return bufferEntry{}, false
+	}
+
+	return entry, true
+}
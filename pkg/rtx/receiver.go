@@ -0,0 +1,73 @@
+package rtx
+
+import (
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	// when a missing packet precedes the last requested one by more than this,
+	// the stream is considered resetted.
+	maxSeqNumJump = 1024
+)
+
+// Receiver decides which lost RTP packets have to be requested with NACKs,
+// and decodes retransmission packets.
+type Receiver struct {
+	// payload type of the format whose packets are retransmitted.
+	PayloadType uint8
+
+	initialized   bool
+	ssrc          uint32
+	requested     bool
+	lastRequested uint16
+}
+
+// ProcessPacket processes a RTP packet of the format whose packets are retransmitted.
+// missing are the sequence numbers of packets that are still missing after the packet
+// has been processed, in ascending order, as returned by rtpreorderer.Reorderer.Missing().
+// It returns the sequence numbers whose retransmission has not been requested yet.
+func (r *Receiver) ProcessPacket(pkt *rtp.Packet, missing []uint16) []uint16 {
+	r.initialized = true
+	r.ssrc = pkt.SSRC
+
+	var ret []uint16
+
+	for _, seqNum := range missing {
+		if r.requested {
+			diff := int16(seqNum - r.lastRequested)
+			if diff <= 0 && diff > -maxSeqNumJump {
+				continue
+			}
+		}
+
+		ret = append(ret, seqNum)
+		r.requested = true
+		r.lastRequested = seqNum
+	}
+
+	return ret
+}
+
+// Decode decodes a retransmission packet into the original RTP packet.
+func (r *Receiver) Decode(pkt *rtp.Packet) (*rtp.Packet, error) {
+	if len(pkt.Payload) < 2 {
+		return nil, fmt.Errorf("invalid retransmission packet size (%d)", len(pkt.Payload))
+	}
+
+	orig := &rtp.Packet{
+		Header:  pkt.Header,
+		Payload: pkt.Payload[2:],
+	}
+	orig.PayloadType = r.PayloadType
+	orig.SequenceNumber = uint16(pkt.Payload[0])<<8 | uint16(pkt.Payload[1])
+
+	// retransmission packets use a dedicated SSRC.
+	// restore the one of the original stream.
+	if r.initialized {
+		orig.SSRC = r.ssrc
+	}
+
+	return orig, nil
+}
@@ -0,0 +1,82 @@
+package rtx
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiverProcessPacket(t *testing.T) {
+	r := &Receiver{
+		PayloadType: 96,
+	}
+
+	for _, ca := range []struct {
+		seqNum    uint16
+		missing   []uint16
+		requested []uint16
+	}{
+		{65533, nil, nil},
+		{65534, nil, nil},
+		{1, []uint16{65535, 0}, []uint16{65535, 0}},
+		{0, []uint16{65535}, nil},
+		{3, []uint16{65535, 2}, []uint16{2}},
+		{65535, []uint16{2}, nil},
+		{2, nil, nil},
+		{5, []uint16{4}, []uint16{4}},
+		{40000, []uint16{39999}, []uint16{39999}},
+		{10, []uint16{9}, []uint16{9}},
+	} {
+		requested := r.ProcessPacket(&rtp.Packet{
+			Header: rtp.Header{
+				SequenceNumber: ca.seqNum,
+				SSRC:           563423,
+			},
+		}, ca.missing)
+		require.Equal(t, ca.requested, requested)
+	}
+}
+
+func TestReceiverDecode(t *testing.T) {
+	r := &Receiver{
+		PayloadType: 96,
+	}
+
+	r.ProcessPacket(&rtp.Packet{
+		Header: rtp.Header{
+			SequenceNumber: 123,
+			SSRC:           563423,
+		},
+	}, nil)
+
+	pkt, err := r.Decode(&rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         true,
+			PayloadType:    97,
+			SequenceNumber: 5000,
+			Timestamp:      45343,
+			SSRC:           1234,
+		},
+		Payload: []byte{0x00, 0x7a, 1, 2, 3, 4},
+	})
+	require.NoError(t, err)
+	require.Equal(t, &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Marker:         true,
+			PayloadType:    96,
+			SequenceNumber: 122,
+			Timestamp:      45343,
+			SSRC:           563423,
+		},
+		Payload: []byte{1, 2, 3, 4},
+	}, pkt)
+
+	_, err = r.Decode(&rtp.Packet{
+		Header:  rtp.Header{PayloadType: 97},
+		Payload: []byte{1},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,3 @@
+// Package rtx implements RTP retransmissions.
+// Specification: https://datatracker.ietf.org/doc/html/rfc4588
+package rtx
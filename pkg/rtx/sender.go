@@ -0,0 +1,123 @@
+package rtx
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+)
+
+func randUint16() (uint16, error) {
+	var b [2]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return 0, err
+	}
+	return uint16(b[0])<<8 | uint16(b[1]), nil
+}
+
+// Retransmission is a retransmission packet generated by Sender.
+type Retransmission struct {
+	Packet *rtp.Packet
+
+	// absolute timestamp of the original packet.
+	NTP time.Time
+}
+
+// Sender retransmits sent RTP packets when they are requested with NACKs.
+type Sender struct {
+	// payload type of retransmission packets.
+	PayloadType uint8
+
+	// SSRC of retransmission packets.
+	// Retransmission packets are sent with a dedicated SSRC (SSRC-multiplexing).
+	SSRC uint32
+
+	// number of packets that are kept for retransmission.
+	// It defaults to 1024.
+	// It is ignored when Buffer is provided.
+	BufferSize int
+
+	// (optional) buffer of sent packets.
+	// It can be shared by Senders that retransmit the same stream to different receivers,
+	// each with its own sequence numbers.
+	// It defaults to a dedicated Buffer.
+	Buffer *Buffer
+
+	mutex          sync.Mutex
+	sequenceNumber uint16
+}
+
+// Initialize initializes a Sender.
+func (s *Sender) Initialize() error {
+	if s.Buffer == nil {
+		s.Buffer = &Buffer{
+			Size: s.BufferSize,
+		}
+		s.Buffer.Initialize()
+	}
+
+	var err error
+	s.sequenceNumber, err = randUint16()
+	return err
+}
+
+// Add adds a sent RTP packet to the buffer.
+// ntp is the absolute timestamp of the packet.
+func (s *Sender) Add(pkt *rtp.Packet, ntp time.Time) {
+	s.Buffer.Add(pkt, ntp)
+}
+
+// ProcessNACK returns retransmission packets of the RTP packets requested by a NACK.
+// Packets that are not in the buffer anymore are skipped.
+func (s *Sender) ProcessNACK(nack *rtcp.TransportLayerNack) []*Retransmission {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var ret []*Retransmission
+
+	for _, pair := range nack.Nacks {
+		for _, seqNum := range pair.PacketList() {
+			entry, ok := s.Buffer.get(seqNum)
+			if !ok {
+				continue
+			}
+
+			ret = append(ret, &Retransmission{
+				Packet: s.encode(entry.pkt),
+				NTP:    entry.ntp,
+			})
+		}
+	}
+
+	return ret
+}
+
+func (s *Sender) encode(orig *rtp.Packet) *rtp.Packet {
+	payload := make([]byte, 2+len(orig.Payload))
+	payload[0] = byte(orig.SequenceNumber >> 8)
+	payload[1] = byte(orig.SequenceNumber)
+	copy(payload[2:], orig.Payload)
+
+	pkt := &rtp.Packet{
+		Header: rtp.Header{
+			Version:          2,
+			Marker:           orig.Marker,
+			PayloadType:      s.PayloadType,
+			SequenceNumber:   s.sequenceNumber,
+			Timestamp:        orig.Timestamp,
+			SSRC:             s.SSRC,
+			CSRC:             orig.CSRC,
+			Extension:        orig.Extension,
+			ExtensionProfile: orig.ExtensionProfile,
+			Extensions:       orig.Extensions,
+		},
+		Payload: payload,
+	}
+
+	s.sequenceNumber++
+
+	return pkt
+}
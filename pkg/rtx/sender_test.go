@@ -0,0 +1,128 @@
+package rtx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSender(t *testing.T) {
+	s := &Sender{
+		PayloadType: 97,
+		SSRC:        934223,
+		BufferSize:  4,
+	}
+	err := s.Initialize()
+	require.NoError(t, err)
+
+	for i := 0; i < 6; i++ {
+		s.Add(&rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         true,
+				PayloadType:    96,
+				SequenceNumber: 65533 + uint16(i),
+				Timestamp:      45343,
+				SSRC:           563423,
+			},
+			Payload: []byte{1, 2, 3, byte(i)},
+		}, time.Date(2008, 5, 20, 22, 15, 20+i, 0, time.UTC))
+	}
+
+	firstSeqNum := s.sequenceNumber
+
+	pkts := s.ProcessNACK(&rtcp.TransportLayerNack{
+		MediaSSRC: 563423,
+		Nacks:     rtcp.NackPairsFromSequenceNumbers([]uint16{65534, 65535, 1}),
+	})
+
+	// 65534 has been overwritten
+	require.Equal(t, []*Retransmission{
+		{
+			Packet: &rtp.Packet{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    97,
+					SequenceNumber: firstSeqNum,
+					Timestamp:      45343,
+					SSRC:           934223,
+				},
+				Payload: []byte{0xff, 0xff, 1, 2, 3, 2},
+			},
+			NTP: time.Date(2008, 5, 20, 22, 15, 22, 0, time.UTC),
+		},
+		{
+			Packet: &rtp.Packet{
+				Header: rtp.Header{
+					Version:        2,
+					Marker:         true,
+					PayloadType:    97,
+					SequenceNumber: firstSeqNum + 1,
+					Timestamp:      45343,
+					SSRC:           934223,
+				},
+				Payload: []byte{0x00, 0x01, 1, 2, 3, 4},
+			},
+			NTP: time.Date(2008, 5, 20, 22, 15, 24, 0, time.UTC),
+		},
+	}, pkts)
+}
+
+func TestSenderSharedBuffer(t *testing.T) {
+	b := &Buffer{}
+	b.Initialize()
+
+	senders := make([]*Sender, 2)
+	for i := range senders {
+		senders[i] = &Sender{
+			PayloadType: 97,
+			SSRC:        934223,
+			Buffer:      b,
+		}
+		err := senders[i].Initialize()
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 3; i++ {
+		b.Add(&rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    96,
+				SequenceNumber: 100 + uint16(i),
+				Timestamp:      45343,
+				SSRC:           563423,
+			},
+			Payload: []byte{1, 2, 3, byte(i)},
+		}, time.Date(2008, 5, 20, 22, 15, 20+i, 0, time.UTC))
+	}
+
+	// sequence numbers of each sender are contiguous,
+	// even if NACKs are received by other senders in between.
+	firstSeqNum := senders[0].sequenceNumber
+
+	pkts := senders[0].ProcessNACK(&rtcp.TransportLayerNack{
+		MediaSSRC: 563423,
+		Nacks:     rtcp.NackPairsFromSequenceNumbers([]uint16{100}),
+	})
+	require.Len(t, pkts, 1)
+	require.Equal(t, firstSeqNum, pkts[0].Packet.SequenceNumber)
+
+	pkts = senders[1].ProcessNACK(&rtcp.TransportLayerNack{
+		MediaSSRC: 563423,
+		Nacks:     rtcp.NackPairsFromSequenceNumbers([]uint16{101, 102}),
+	})
+	require.Len(t, pkts, 2)
+	require.Equal(t, []byte{0, 101, 1, 2, 3, 1}, pkts[0].Packet.Payload)
+
+	pkts = senders[0].ProcessNACK(&rtcp.TransportLayerNack{
+		MediaSSRC: 563423,
+		Nacks:     rtcp.NackPairsFromSequenceNumbers([]uint16{102}),
+	})
+	require.Len(t, pkts, 1)
+	require.Equal(t, firstSeqNum+1, pkts[0].Packet.SequenceNumber)
+	require.Equal(t, []byte{0, 102, 1, 2, 3, 2}, pkts[0].Packet.Payload)
+}
@@ -0,0 +1,176 @@
+package ulpfec
+
+import (
+	"sync"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	// number of received RTP packets that are kept in order to perform recovery.
+	bufferSize = 128
+
+	// maximum number of pending FEC packets.
+	maxFECPackets = 32
+)
+
+// Decoder recovers lost RTP packets by using FEC packets.
+// FEC packets and RTP packets can be added by different goroutines.
+type Decoder struct {
+	mutex       sync.Mutex
+	initialized bool
+	ssrc        uint32
+	latest      uint16
+	buffer      [bufferSize]*rtp.Packet
+	fecs        []*fecPacket
+}
+
+// AddFEC adds a FEC packet to the decoder.
+// Recovery is performed when the next RTP packet is processed.
+func (d *Decoder) AddFEC(pkt *rtp.Packet) error {
+	fec := &fecPacket{}
+	err := fec.unmarshal(pkt.Payload)
+	if err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if len(d.fecs) == maxFECPackets {
+		d.fecs = d.fecs[1:]
+	}
+	d.fecs = append(d.fecs, fec)
+
+	return nil
+}
+
+// Process processes a received RTP packet.
+// It returns RTP packets that have been recovered.
+func (d *Decoder) Process(pkt *rtp.Packet) []*rtp.Packet {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.ssrc = pkt.SSRC
+
+	if !d.initialized || int16(pkt.SequenceNumber-d.latest) > 0 {
+		d.initialized = true
+		d.latest = pkt.SequenceNumber
+	}
+
+	d.buffer[pkt.SequenceNumber%bufferSize] = pkt
+
+	var ret []*rtp.Packet
+
+	for {
+		recovered := d.recoverOne()
+		if recovered == nil {
+			break
+		}
+
+		d.buffer[recovered.SequenceNumber%bufferSize] = recovered
+		ret = append(ret, recovered)
+	}
+
+	return ret
+}
+
+func (d *Decoder) find(seqNum uint16) *rtp.Packet {
+	pkt := d.buffer[seqNum%bufferSize]
+	if pkt == nil || pkt.SequenceNumber != seqNum {
+		return nil
+	}
+	return pkt
+}
+
+// recoverOne recovers a packet with the first usable FEC packet,
+// and removes FEC packets that are not useful anymore.
+func (d *Decoder) recoverOne() *rtp.Packet {
+	for i := 0; i < len(d.fecs); {
+		fec := d.fecs[i]
+
+		// FEC packet refers to packets that are not in the buffer anymore
+		if int16(d.latest-fec.snBase) >= (bufferSize - MaxGroupSize) {
+			d.fecs = append(d.fecs[:i], d.fecs[i+1:]...)
+			continue
+		}
+
+		seqNums := fec.protectedSequenceNumbers()
+		var received []*rtp.Packet
+		var missing []uint16
+
+		for _, seqNum := range seqNums {
+			if pkt := d.find(seqNum); pkt != nil {
+				received = append(received, pkt)
+			} else {
+				missing = append(missing, seqNum)
+			}
+		}
+
+		switch len(missing) {
+		case 0:
+			d.fecs = append(d.fecs[:i], d.fecs[i+1:]...)
+			continue
+
+		case 1:
+			d.fecs = append(d.fecs[:i], d.fecs[i+1:]...)
+
+			pkt := d.recover(fec, received, missing[0])
+			if pkt == nil {
+				continue
+			}
+
+			return pkt
+		}
+
+		i++
+	}
+
+	return nil
+}
+
+func (d *Decoder) recover(fec *fecPacket, received []*rtp.Packet, seqNum uint16) *rtp.Packet {
+	rec := fecPacket{
+		headerRecovery: fec.headerRecovery,
+		tsRecovery:     fec.tsRecovery,
+		lengthRecovery: fec.lengthRecovery,
+		payload:        make([]byte, len(fec.payload)),
+	}
+	copy(rec.payload, fec.payload)
+
+	for _, pkt := range received {
+		buf, err := pkt.Marshal()
+		if err != nil || (len(buf)-rtpFixedHeaderSize) > len(rec.payload) {
+			return nil
+		}
+
+		rec.xorPacket(buf)
+	}
+
+	if int(rec.lengthRecovery) > len(rec.payload) {
+		return nil
+	}
+
+	buf := make([]byte, rtpFixedHeaderSize+int(rec.lengthRecovery))
+	buf[0] = 0x80 | rec.headerRecovery[0]
+	buf[1] = rec.headerRecovery[1]
+	buf[2] = byte(seqNum >> 8)
+	buf[3] = byte(seqNum)
+	buf[4] = byte(rec.tsRecovery >> 24)
+	buf[5] = byte(rec.tsRecovery >> 16)
+	buf[6] = byte(rec.tsRecovery >> 8)
+	buf[7] = byte(rec.tsRecovery)
+	buf[8] = byte(d.ssrc >> 24)
+	buf[9] = byte(d.ssrc >> 16)
+	buf[10] = byte(d.ssrc >> 8)
+	buf[11] = byte(d.ssrc)
+	copy(buf[rtpFixedHeaderSize:], rec.payload)
+
+	var pkt rtp.Packet
+	err := pkt.Unmarshal(buf)
+	if err != nil {
+		return nil
+	}
+
+	return &pkt
+}
@@ -0,0 +1,96 @@
+package ulpfec
+
+import (
+	"fmt"
+
+	"github.com/pion/rtp"
+)
+
+const (
+	defaultGroupSize = 8
+)
+
+// Encoder generates FEC packets that protect groups of RTP packets.
+type Encoder struct {
+	// payload type of FEC packets.
+	PayloadType uint8
+
+	// number of RTP packets protected by each FEC packet.
+	// It defaults to 8.
+	GroupSize int
+
+	group          [][]byte
+	snBase         uint16
+	sequenceNumber uint16
+}
+
+// Initialize initializes an Encoder.
+func (e *Encoder) Initialize() error {
+	if e.GroupSize == 0 {
+		e.GroupSize = defaultGroupSize
+	} else if e.GroupSize < 0 || e.GroupSize > MaxGroupSize {
+		return fmt.Errorf("group size must be between 1 and %d", MaxGroupSize)
+	}
+
+	var err error
+	e.sequenceNumber, err = randUint16()
+	return err
+}
+
+// Encode adds a RTP packet to the current group.
+// When the group is complete, it returns a FEC packet that protects the group.
+func (e *Encoder) Encode(pkt *rtp.Packet) (*rtp.Packet, error) {
+	buf, err := pkt.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	// packets must be consecutive. Otherwise, start a new group.
+	if len(e.group) != 0 && pkt.SequenceNumber != e.snBase+uint16(len(e.group)) {
+		e.group = e.group[:0]
+	}
+
+	if len(e.group) == 0 {
+		e.snBase = pkt.SequenceNumber
+	}
+
+	e.group = append(e.group, buf)
+
+	if len(e.group) < e.GroupSize {
+		return nil, nil
+	}
+
+	protectionLength := 0
+	for _, buf := range e.group {
+		if l := len(buf) - rtpFixedHeaderSize; l > protectionLength {
+			protectionLength = l
+		}
+	}
+
+	fec := fecPacket{
+		snBase:  e.snBase,
+		payload: make([]byte, protectionLength),
+	}
+
+	for i, buf := range e.group {
+		fec.mask |= 1 << (MaxGroupSize - 1 - i)
+		fec.xorPacket(buf)
+	}
+
+	e.group = e.group[:0]
+
+	ret := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    e.PayloadType,
+			SequenceNumber: e.sequenceNumber,
+			Timestamp:      pkt.Timestamp,
+			SSRC:           pkt.SSRC,
+		},
+		Payload: fec.marshal(),
+	}
+
+	e.sequenceNumber++
+
+	return ret, nil
+}
@@ -0,0 +1,159 @@
+// Package ulpfec implements the generic forward error correction (ULPFEC) RTP payload format.
+// Specification: https://datatracker.ietf.org/doc/html/rfc5109
+package ulpfec
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+const (
+	// MaxGroupSize is the maximum number of RTP packets that can be protected by a FEC packet.
+	MaxGroupSize = 48
+
+	fecHeaderSize        = 10
+	levelHeaderSizeShort = 4
+	levelHeaderSizeLong  = 8
+	rtpFixedHeaderSize   = 12
+)
+
+func randUint16() (uint16, error) {
+	var b [2]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return 0, err
+	}
+	return uint16(b[0])<<8 | uint16(b[1]), nil
+}
+
+// fecPacket is the payload of a FEC packet with a single protection level.
+type fecPacket struct {
+	headerRecovery [2]byte
+	snBase         uint16
+	tsRecovery     uint32
+	lengthRecovery uint16
+	mask           uint64
+	payload        []byte
+}
+
+func (p *fecPacket) unmarshal(buf []byte) error {
+	if len(buf) < (fecHeaderSize + levelHeaderSizeShort) {
+		return fmt.Errorf("buffer is too short")
+	}
+
+	if (buf[0] >> 7) != 0 {
+		return fmt.Errorf("FEC header extensions are not supported")
+	}
+
+	long := ((buf[0] >> 6) & 0x01) != 0
+
+	p.headerRecovery[0] = buf[0] & 0x3f
+	p.headerRecovery[1] = buf[1]
+	p.snBase = uint16(buf[2])<<8 | uint16(buf[3])
+	p.tsRecovery = uint32(buf[4])<<24 | uint32(buf[5])<<16 | uint32(buf[6])<<8 | uint32(buf[7])
+	p.lengthRecovery = uint16(buf[8])<<8 | uint16(buf[9])
+	buf = buf[fecHeaderSize:]
+
+	protectionLength := int(uint16(buf[0])<<8 | uint16(buf[1]))
+
+	if long {
+		if len(buf) < levelHeaderSizeLong {
+			return fmt.Errorf("buffer is too short")
+		}
+
+		p.mask = uint64(buf[2])<<40 | uint64(buf[3])<<32 | uint64(buf[4])<<24 |
+			uint64(buf[5])<<16 | uint64(buf[6])<<8 | uint64(buf[7])
+		buf = buf[levelHeaderSizeLong:]
+	} else {
+		p.mask = (uint64(buf[2])<<8 | uint64(buf[3])) << 32
+		buf = buf[levelHeaderSizeShort:]
+	}
+
+	if p.mask == 0 {
+		return fmt.Errorf("mask is empty")
+	}
+
+	if len(buf) < protectionLength {
+		return fmt.Errorf("buffer is too short")
+	}
+
+	p.payload = buf[:protectionLength]
+
+	return nil
+}
+
+func (p fecPacket) long() bool {
+	return (p.mask & 0xffffffff) != 0
+}
+
+func (p fecPacket) marshal() []byte {
+	long := p.long()
+
+	n := fecHeaderSize + levelHeaderSizeShort + len(p.payload)
+	if long {
+		n += levelHeaderSizeLong - levelHeaderSizeShort
+	}
+
+	buf := make([]byte, n)
+
+	buf[0] = p.headerRecovery[0] & 0x3f
+	if long {
+		buf[0] |= 1 << 6
+	}
+	buf[1] = p.headerRecovery[1]
+	buf[2] = byte(p.snBase >> 8)
+	buf[3] = byte(p.snBase)
+	buf[4] = byte(p.tsRecovery >> 24)
+	buf[5] = byte(p.tsRecovery >> 16)
+	buf[6] = byte(p.tsRecovery >> 8)
+	buf[7] = byte(p.tsRecovery)
+	buf[8] = byte(p.lengthRecovery >> 8)
+	buf[9] = byte(p.lengthRecovery)
+	pos := fecHeaderSize
+
+	buf[pos] = byte(len(p.payload) >> 8)
+	buf[pos+1] = byte(len(p.payload))
+
+	if long {
+		buf[pos+2] = byte(p.mask >> 40)
+		buf[pos+3] = byte(p.mask >> 32)
+		buf[pos+4] = byte(p.mask >> 24)
+		buf[pos+5] = byte(p.mask >> 16)
+		buf[pos+6] = byte(p.mask >> 8)
+		buf[pos+7] = byte(p.mask)
+		pos += levelHeaderSizeLong
+	} else {
+		buf[pos+2] = byte(p.mask >> 40)
+		buf[pos+3] = byte(p.mask >> 32)
+		pos += levelHeaderSizeShort
+	}
+
+	copy(buf[pos:], p.payload)
+
+	return buf
+}
+
+// protectedSequenceNumbers returns the sequence numbers of the packets protected by the FEC packet.
+func (p fecPacket) protectedSequenceNumbers() []uint16 {
+	var ret []uint16
+	for i := 0; i < MaxGroupSize; i++ {
+		if (p.mask & (1 << (MaxGroupSize - 1 - i))) != 0 {
+			ret = append(ret, p.snBase+uint16(i))
+		}
+	}
+	return ret
+}
+
+// xorPacket applies a marshaled RTP packet to the recovery fields of a FEC packet.
+func (p *fecPacket) xorPacket(buf []byte) {
+	p.headerRecovery[0] ^= buf[0] & 0x3f
+	p.headerRecovery[1] ^= buf[1]
+	p.tsRecovery ^= uint32(buf[4])<<24 | uint32(buf[5])<<16 | uint32(buf[6])<<8 | uint32(buf[7])
+
+	body := buf[rtpFixedHeaderSize:]
+	p.lengthRecovery ^= uint16(len(body))
+
+	for i, b := range body {
+		p.payload[i] ^= b
+	}
+}
@@ -0,0 +1,162 @@
+package ulpfec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func testPackets(n int) []*rtp.Packet {
+	pkts := make([]*rtp.Packet, n)
+
+	for i := range pkts {
+		pkts[i] = &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         (i % 3) == 2,
+				PayloadType:    96,
+				SequenceNumber: 65534 + uint16(i),
+				Timestamp:      45343 + uint32(i/3)*3000,
+				SSRC:           563423,
+				CSRC:           []uint32{},
+			},
+			Payload: make([]byte, 10+i*7),
+		}
+
+		for j := range pkts[i].Payload {
+			pkts[i].Payload[j] = byte(i + j)
+		}
+	}
+
+	return pkts
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, ca := range []struct {
+		name      string
+		groupSize int
+		lost      []int
+		recovered []int
+	}{
+		{
+			"single loss",
+			4,
+			[]int{2},
+			[]int{2},
+		},
+		{
+			"multiple losses",
+			4,
+			[]int{1, 2},
+			nil,
+		},
+		{
+			"long mask",
+			20,
+			[]int{17},
+			[]int{17},
+		},
+		{
+			"multiple groups",
+			3,
+			[]int{0, 4},
+			[]int{0, 4},
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			e := &Encoder{
+				PayloadType: 100,
+				GroupSize:   ca.groupSize,
+			}
+			err := e.Initialize()
+			require.NoError(t, err)
+
+			d := &Decoder{}
+
+			pkts := testPackets(ca.groupSize * 2)
+			var recovered []*rtp.Packet
+
+			for i, pkt := range pkts {
+				fec, err2 := e.Encode(pkt)
+				require.NoError(t, err2)
+
+				if ((i + 1) % ca.groupSize) == 0 {
+					require.NotNil(t, fec)
+					require.Equal(t, uint8(100), fec.PayloadType)
+				} else {
+					require.Nil(t, fec)
+				}
+
+				lost := false
+				for _, j := range ca.lost {
+					if j == i {
+						lost = true
+					}
+				}
+
+				if !lost {
+					recovered = append(recovered, d.Process(pkt)...)
+				}
+
+				if fec != nil {
+					err2 = d.AddFEC(fec)
+					require.NoError(t, err2)
+				}
+			}
+
+			// trigger recovery with the FEC packet of the last group
+			recovered = append(recovered, d.Process(pkts[len(pkts)-1])...)
+
+			var expected []*rtp.Packet
+			for _, j := range ca.recovered {
+				expected = append(expected, pkts[j])
+			}
+
+			require.Equal(t, expected, recovered)
+		})
+	}
+}
+
+func TestEncoderSequenceGap(t *testing.T) {
+	e := &Encoder{
+		PayloadType: 100,
+		GroupSize:   2,
+	}
+	err := e.Initialize()
+	require.NoError(t, err)
+
+	pkts := testPackets(3)
+
+	fec, err := e.Encode(pkts[0])
+	require.NoError(t, err)
+	require.Nil(t, fec)
+
+	fec, err = e.Encode(pkts[2])
+	require.NoError(t, err)
+	require.Nil(t, fec)
+}
+
+func FuzzDecoderAddFEC(f *testing.F) {
+	f.Fuzz(func(_ *testing.T, b []byte) {
+		d := &Decoder{}
+		err := d.AddFEC(&rtp.Packet{Payload: b})
+		if err != nil {
+			return
+		}
+
+		d.Process(&rtp.Packet{Header: rtp.Header{SequenceNumber: 1}})
+	})
+}
+
+func TestEncoderInvalidGroupSize(t *testing.T) {
+	for _, groupSize := range []int{-1, MaxGroupSize + 1} {
+		e := &Encoder{
+			PayloadType: 100,
+			GroupSize:   groupSize,
+		}
+		err := e.Initialize()
+		require.EqualError(t, err, fmt.Sprintf("group size must be between 1 and %d", MaxGroupSize))
+	}
+}
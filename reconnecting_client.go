@@ -0,0 +1,492 @@
+package gortsplib
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
+)
+
+// ReconnectingClientState is the state of a ReconnectingClient.
+type ReconnectingClientState int
+
+// states.
+const (
+	ReconnectingClientStateConnecting ReconnectingClientState = iota
+	ReconnectingClientStatePlaying
+	ReconnectingClientStateWaiting
+)
+
+var reconnectingClientStateLabels = map[ReconnectingClientState]string{
+	ReconnectingClientStateConnecting: "connecting",
+	ReconnectingClientStatePlaying:    "playing",
+	ReconnectingClientStateWaiting:    "waiting",
+}
+
+// String implements fmt.Stringer.
+func (s ReconnectingClientState) String() string {
+	if l, ok := reconnectingClientStateLabels[s]; ok {
+		return l
+	}
+	return "unknown"
+}
+
+// ReconnectingClientOnStateChangeFunc is the prototype of ReconnectingClient.OnStateChange.
+type ReconnectingClientOnStateChangeFunc func(state ReconnectingClientState, err error)
+
+// ReconnectingClientOnDiscontinuityFunc is the prototype of ReconnectingClient.OnDiscontinuity.
+type ReconnectingClientOnDiscontinuityFunc func(medi *description.Media, forma format.Format)
+
+func descriptionsAreCompatible(a *description.Session, b *description.Session) bool {
+	if len(a.Medias) != len(b.Medias) {
+		return false
+	}
+
+	for i, medi := range a.Medias {
+		if len(medi.Formats) != len(b.Medias[i].Formats) ||
+			!mediasAreCompatible(medi, b.Medias[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ReconnectingClient is a reader that sits on top of Client.
+// It reads a stream with the DESCRIBE / SETUP / PLAY sequence and,
+// when an error occurs, runs the sequence again with an exponential backoff.
+// Callbacks remain attached across reconnections.
+type ReconnectingClient struct {
+	//
+	// Target
+	//
+	// URL of the stream.
+	URL *base.URL
+
+	//
+	// parameters (all optional)
+	//
+	// function used to create the underlying Clients.
+	// Scheme and Host are filled automatically.
+	// It defaults to a function that returns a Client with default settings.
+	NewClient func() *Client
+	// delay before the first reconnection attempt.
+	// It is doubled after every failed attempt.
+	// It defaults to 1 second.
+	InitialBackoff time.Duration
+	// maximum delay between reconnection attempts.
+	// It defaults to 30 seconds.
+	MaxBackoff time.Duration
+
+	//
+	// callbacks (all optional)
+	//
+	// called when the state changes.
+	// err is the reason of the change, and it's not nil when state is ReconnectingClientStateWaiting.
+	OnStateChange ReconnectingClientOnStateChangeFunc
+	// called after a reconnection, before the first RTP packet of every format.
+	// Timestamps and sequence numbers of packets received after this call
+	// are not related to the ones of packets received before.
+	OnDiscontinuity ReconnectingClientOnDiscontinuityFunc
+
+	//
+	// private
+	//
+
+	ctx          context.Context
+	ctxCancel    func()
+	desc         *description.Session
+	onPacketRTP  map[format.Format]OnPacketRTPFunc
+	onPacketRTCP map[*description.Media]OnPacketRTCPFunc
+	mutex        sync.RWMutex
+	client       *Client
+	clientDesc   *description.Session
+	playing      bool
+	closeOnce    sync.Once
+	closeError   error
+
+	// out
+	done chan struct{}
+}
+
+// Start connects to the server and sets up all the medias of the stream.
+// Errors that occur during this first attempt are returned.
+// After Start, callbacks can be registered and Play can be called.
+func (rc *ReconnectingClient) Start() error {
+	if rc.URL == nil {
+		return fmt.Errorf("URL not provided")
+	}
+
+	// parameters
+	if rc.NewClient == nil {
+		rc.NewClient = func() *Client {
+			return &Client{}
+		}
+	}
+	if rc.InitialBackoff == 0 {
+		rc.InitialBackoff = 1 * time.Second
+	}
+	if rc.MaxBackoff == 0 {
+		rc.MaxBackoff = 30 * time.Second
+	}
+
+	// callbacks
+	if rc.OnStateChange == nil {
+		rc.OnStateChange = func(ReconnectingClientState, error) {
+		}
+	}
+	if rc.OnDiscontinuity == nil {
+		rc.OnDiscontinuity = func(*description.Media, format.Format) {
+		}
+	}
+
+	rc.ctx, rc.ctxCancel = context.WithCancel(context.Background())
+	rc.onPacketRTP = make(map[format.Format]OnPacketRTPFunc)
+	rc.onPacketRTCP = make(map[*description.Media]OnPacketRTCPFunc)
+	rc.done = make(chan struct{})
+
+	rc.OnStateChange(ReconnectingClientStateConnecting, nil)
+
+	c, desc, err := rc.connect()
+	if err != nil {
+		rc.ctxCancel()
+		return err
+	}
+
+	rc.desc = desc
+	rc.client = c
+	rc.clientDesc = desc
+
+	return nil
+}
+
+// Play starts reading the stream.
+// From now on, the stream is read again every time an error occurs.
+func (rc *ReconnectingClient) Play() {
+	rc.playing = true
+	go rc.run()
+}
+
+// Close closes all the resources and waits for them to exit.
+// It interrupts any connection attempt in progress.
+func (rc *ReconnectingClient) Close() {
+	// Start() has never been called
+	if rc.ctxCancel == nil {
+		return
+	}
+
+	rc.ctxCancel()
+
+	if rc.playing {
+		<-rc.done
+		return
+	}
+
+	rc.closeOnce.Do(func() {
+		// Start() may have failed
+		if rc.client != nil {
+			rc.client.Close()
+		}
+
+		rc.closeError = liberrors.ErrClientTerminated{}
+		close(rc.done)
+	})
+}
+
+// Wait waits until all resources are closed.
+// This happens when Close() is called.
+func (rc *ReconnectingClient) Wait() error {
+	<-rc.done
+	return rc.closeError
+}
+
+// Description returns the description of the stream.
+// Medias and formats of this description can be used to register callbacks,
+// even after a reconnection.
+func (rc *ReconnectingClient) Description() *description.Session {
+	return rc.desc
+}
+
+// OnPacketRTPAny sets a callback that is called when a RTP packet is read from any media.
+// It must be called before Play().
+func (rc *ReconnectingClient) OnPacketRTPAny(cb OnPacketRTPAnyFunc) {
+	for _, medi := range rc.desc.Medias {
+		cmedia := medi
+		for _, forma := range medi.Formats {
+			cforma := forma
+			rc.OnPacketRTP(medi, forma, func(pkt *rtp.Packet) {
+				cb(cmedia, cforma, pkt)
+			})
+		}
+	}
+}
+
+// OnPacketRTCPAny sets a callback that is called when a RTCP packet is read from any media.
+// It must be called before Play().
+func (rc *ReconnectingClient) OnPacketRTCPAny(cb OnPacketRTCPAnyFunc) {
+	for _, medi := range rc.desc.Medias {
+		cmedia := medi
+		rc.OnPacketRTCP(medi, func(pkt rtcp.Packet) {
+			cb(cmedia, pkt)
+		})
+	}
+}
+
+// OnPacketRTP sets a callback that is called when a RTP packet is read.
+// It must be called before Play().
+func (rc *ReconnectingClient) OnPacketRTP(_ *description.Media, forma format.Format, cb OnPacketRTPFunc) {
+	rc.onPacketRTP[forma] = cb
+}
+
+// OnPacketRTCP sets a callback that is called when a RTCP packet is read.
+// It must be called before Play().
+func (rc *ReconnectingClient) OnPacketRTCP(medi *description.Media, cb OnPacketRTCPFunc) {
+	rc.onPacketRTCP[medi] = cb
+}
+
+// PacketPTS2 returns the PTS of an incoming RTP packet.
+// It is computed by decoding the packet timestamp and sychronizing it with other tracks.
+// It restarts after every discontinuity.
+func (rc *ReconnectingClient) PacketPTS2(medi *description.Media, pkt *rtp.Packet) (int64, bool) {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+
+	if rc.client == nil {
+		return 0, false
+	}
+
+	return rc.client.PacketPTS2(rc.clientMedia(medi), pkt)
+}
+
+// PacketNTP returns the NTP (absolute timestamp) of an incoming RTP packet.
+// The NTP is computed from RTCP sender reports.
+func (rc *ReconnectingClient) PacketNTP(medi *description.Media, pkt *rtp.Packet) (time.Time, bool) {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+
+	if rc.client == nil {
+		return time.Time{}, false
+	}
+
+	return rc.client.PacketNTP(rc.clientMedia(medi), pkt)
+}
+
+// clientMedia returns the media of the current client that corresponds to a media of the description.
+func (rc *ReconnectingClient) clientMedia(medi *description.Media) *description.Media {
+	for i, m := range rc.desc.Medias {
+		if m == medi {
+			return rc.clientDesc.Medias[i]
+		}
+	}
+	return medi
+}
+
+func (rc *ReconnectingClient) connect() (*Client, *description.Session, error) {
+	c := rc.NewClient()
+	c.Scheme = rc.URL.Scheme
+	c.Host = rc.URL.Host
+
+	err := c.Start2()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// close the client when the ReconnectingClient is closed,
+	// in order to interrupt pending requests.
+	connected := make(chan struct{})
+	watcherDone := make(chan struct{})
+	go func() {
+		defer close(watcherDone)
+		select {
+		case <-rc.ctx.Done():
+			c.Close()
+		case <-connected:
+		}
+	}()
+
+	desc, err := rc.connectInner(c)
+
+	close(connected)
+	<-watcherDone
+
+	if err != nil {
+		c.Close()
+
+		if rc.ctx.Err() != nil {
+			return nil, nil, liberrors.ErrClientTerminated{}
+		}
+		return nil, nil, err
+	}
+
+	return c, desc, nil
+}
+
+func (rc *ReconnectingClient) connectInner(c *Client) (*description.Session, error) {
+	desc, _, err := c.Describe(rc.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if rc.desc != nil && !descriptionsAreCompatible(rc.desc, desc) {
+		return nil, liberrors.ErrClientDescriptionIncompatible{}
+	}
+
+	err = c.SetupAll(desc.BaseURL, desc.Medias)
+	if err != nil {
+		return nil, err
+	}
+
+	return desc, nil
+}
+
+func (rc *ReconnectingClient) run() {
+	defer close(rc.done)
+
+	rc.closeError = rc.runInner()
+
+	rc.mutex.Lock()
+	rc.client = nil
+	rc.mutex.Unlock()
+}
+
+func (rc *ReconnectingClient) runInner() error {
+	c := rc.client
+	desc := rc.clientDesc
+	discontinuity := false
+	backoff := rc.InitialBackoff
+
+	for {
+		if c == nil {
+			rc.OnStateChange(ReconnectingClientStateConnecting, nil)
+
+			var err error
+			c, desc, err = rc.connect()
+			if err != nil {
+				err = rc.wait(err, &backoff)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			rc.mutex.Lock()
+			rc.client = c
+			rc.clientDesc = desc
+			rc.mutex.Unlock()
+		}
+
+		rc.attachCallbacks(c, desc, discontinuity)
+
+		err := rc.play(c, &backoff)
+		c = nil
+		discontinuity = true
+
+		if err != nil {
+			err = rc.wait(err, &backoff)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (rc *ReconnectingClient) attachCallbacks(c *Client, desc *description.Session, discontinuity bool) {
+	for i, medi := range desc.Medias {
+		refMedia := rc.desc.Medias[i]
+
+		for _, forma := range medi.Formats {
+			var refFormat format.Format
+			for _, f := range refMedia.Formats {
+				if f.PayloadType() == forma.PayloadType() {
+					refFormat = f
+					break
+				}
+			}
+
+			cb := rc.onPacketRTP[refFormat]
+			if cb == nil {
+				cb = func(*rtp.Packet) {}
+			}
+
+			if discontinuity {
+				first := true
+				origCb := cb
+				cb = func(pkt *rtp.Packet) {
+					if first {
+						first = false
+						rc.OnDiscontinuity(refMedia, refFormat)
+					}
+					origCb(pkt)
+				}
+			}
+
+			c.OnPacketRTP(medi, forma, cb)
+		}
+
+		if cb, ok := rc.onPacketRTCP[refMedia]; ok {
+			c.OnPacketRTCP(medi, cb)
+		}
+	}
+}
+
+func (rc *ReconnectingClient) play(c *Client, backoff *time.Duration) error {
+	_, err := c.Play(nil)
+	if err != nil {
+		c.Close()
+		return err
+	}
+
+	*backoff = rc.InitialBackoff
+	rc.OnStateChange(ReconnectingClientStatePlaying, nil)
+
+	clientErr := make(chan error)
+	go func() {
+		clientErr <- c.Wait()
+	}()
+
+	select {
+	case err = <-clientErr:
+		return err
+
+	case <-rc.ctx.Done():
+		c.Close()
+		<-clientErr
+		return liberrors.ErrClientTerminated{}
+	}
+}
+
+// wait waits before the next attempt.
+// It returns an error if the ReconnectingClient has been closed.
+func (rc *ReconnectingClient) wait(err error, backoff *time.Duration) error {
+	select {
+	case <-rc.ctx.Done():
+		return liberrors.ErrClientTerminated{}
+	default:
+	}
+
+	rc.OnStateChange(ReconnectingClientStateWaiting, err)
+
+	t := time.NewTimer(*backoff)
+	defer t.Stop()
+
+	*backoff *= 2
+	if *backoff > rc.MaxBackoff {
+		*backoff = rc.MaxBackoff
+	}
+
+	select {
+	case <-t.C:
+		return nil
+
+	case <-rc.ctx.Done():
+		return liberrors.ErrClientTerminated{}
+	}
+}
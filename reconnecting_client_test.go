@@ -0,0 +1,327 @@
+package gortsplib
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/base"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
+)
+
+func TestReconnectingClient(t *testing.T) {
+	var stream *ServerStream
+	connOpened := make(chan *ServerConn, 2)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				connOpened <- ctx.Conn
+
+				go func() {
+					time.Sleep(200 * time.Millisecond)
+					err := stream.WritePacketRTP(stream.Description().Medias[0], &testRTPPacket)
+					require.NoError(t, err)
+				}()
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		RTSPAddress: "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server: s,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	var mutex sync.Mutex
+	var states []ReconnectingClientState
+	var discontinuities []format.Format
+	packetRecv := make(chan struct{}, 2)
+
+	rc := &ReconnectingClient{
+		URL: mustParseURL("rtsp://localhost:8554/teststream"),
+		NewClient: func() *Client {
+			return &Client{
+				Transport: transportPtr(TransportTCP),
+			}
+		},
+		InitialBackoff: 100 * time.Millisecond,
+		OnStateChange: func(state ReconnectingClientState, err error) {
+			if state == ReconnectingClientStateWaiting {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			states = append(states, state)
+		},
+		OnDiscontinuity: func(_ *description.Media, forma format.Format) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			discontinuities = append(discontinuities, forma)
+		},
+	}
+
+	err = rc.Start()
+	require.NoError(t, err)
+
+	desc := rc.Description()
+
+	rc.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+		require.Equal(t, &testRTPPacket, pkt)
+
+		_, ok := rc.PacketPTS2(desc.Medias[0], pkt)
+		require.True(t, ok)
+
+		packetRecv <- struct{}{}
+	})
+
+	rc.Play()
+
+	sc := <-connOpened
+	<-packetRecv
+
+	sc.Close()
+
+	<-connOpened
+	<-packetRecv
+
+	rc.Close()
+
+	err = rc.Wait()
+	require.Equal(t, liberrors.ErrClientTerminated{}, err)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	require.Equal(t, []ReconnectingClientState{
+		ReconnectingClientStateConnecting,
+		ReconnectingClientStatePlaying,
+		ReconnectingClientStateWaiting,
+		ReconnectingClientStateConnecting,
+		ReconnectingClientStatePlaying,
+	}, states)
+
+	require.Equal(t, []format.Format{desc.Medias[0].Formats[0]}, discontinuities)
+}
+
+func TestReconnectingClientIncompatibleDescription(t *testing.T) {
+	var stream1 *ServerStream
+	var stream2 *ServerStream
+	describeCount := 0
+	connOpened := make(chan *ServerConn, 1)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				describeCount++
+				if describeCount == 1 {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, stream1, nil
+				}
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream2, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream1, nil
+			},
+			onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				connOpened <- ctx.Conn
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		RTSPAddress: "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream1 = &ServerStream{
+		Server: s,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream1.Initialize()
+	require.NoError(t, err)
+	defer stream1.Close()
+
+	stream2 = &ServerStream{
+		Server: s,
+		Desc: &description.Session{Medias: []*description.Media{{
+			Type:    description.MediaTypeAudio,
+			Formats: []format.Format{&format.G711{PayloadTyp: 0, MULaw: true, SampleRate: 8000, ChannelCount: 1}},
+		}}},
+	}
+	err = stream2.Initialize()
+	require.NoError(t, err)
+	defer stream2.Close()
+
+	waitErr := make(chan error, 1)
+
+	rc := &ReconnectingClient{
+		URL: mustParseURL("rtsp://localhost:8554/teststream"),
+		NewClient: func() *Client {
+			return &Client{
+				Transport: transportPtr(TransportTCP),
+			}
+		},
+		InitialBackoff: 100 * time.Millisecond,
+		OnStateChange: func(state ReconnectingClientState, err error) {
+			if state == ReconnectingClientStateWaiting {
+				select {
+				case waitErr <- err:
+				default:
+				}
+			}
+		},
+	}
+
+	err = rc.Start()
+	require.NoError(t, err)
+	defer rc.Close()
+
+	rc.Play()
+
+	sc := <-connOpened
+	sc.Close()
+
+	// first error is caused by the connection closure
+	<-waitErr
+
+	err = <-waitErr
+	require.Equal(t, liberrors.ErrClientDescriptionIncompatible{}, err)
+}
+
+func TestReconnectingClientCloseWhileConnecting(t *testing.T) {
+	var stream *ServerStream
+	describeCount := 0
+	describeBlocked := make(chan struct{})
+	unblockDescribe := make(chan struct{})
+	connOpened := make(chan *ServerConn, 1)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				describeCount++
+				if describeCount == 2 {
+					close(describeBlocked)
+					<-unblockDescribe
+				}
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				connOpened <- ctx.Conn
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		RTSPAddress: "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server: s,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	defer close(unblockDescribe)
+
+	rc := &ReconnectingClient{
+		URL: mustParseURL("rtsp://localhost:8554/teststream"),
+		NewClient: func() *Client {
+			return &Client{
+				Transport: transportPtr(TransportTCP),
+			}
+		},
+		InitialBackoff: 10 * time.Millisecond,
+	}
+
+	err = rc.Start()
+	require.NoError(t, err)
+
+	rc.Play()
+
+	sc := <-connOpened
+	sc.Close()
+
+	<-describeBlocked
+
+	closed := make(chan struct{})
+	go func() {
+		rc.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Error("Close() is blocked by the pending connection")
+	}
+
+	require.Equal(t, liberrors.ErrClientTerminated{}, rc.Wait())
+}
+
+func TestReconnectingClientCloseWithoutStart(t *testing.T) {
+	rc := &ReconnectingClient{
+		URL: mustParseURL("rtsp://localhost:8554/teststream"),
+	}
+	rc.Close()
+	rc.Close()
+
+	rc = &ReconnectingClient{
+		URL: mustParseURL("rtsp://localhost:8554/teststream"),
+	}
+	err := rc.Start()
+	require.Error(t, err)
+	rc.Close()
+	rc.Close()
+	require.Equal(t, liberrors.ErrClientTerminated{}, rc.Wait())
+}
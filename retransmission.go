@@ -0,0 +1,16 @@
+package gortsplib
+
+import (
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+)
+
+// findRTXFormat returns the RTX format that carries retransmissions of a format.
+func findRTXFormat(medi *description.Media, forma format.Format) *format.RTX {
+	for _, f := range medi.Formats {
+		if rtx, ok := f.(*format.RTX); ok && rtx.APT == forma.PayloadType() {
+			return rtx
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package gortsplib
+
+// isRTCPPacket checks whether a packet received on a port shared by RTP and RTCP is a RTCP packet.
+// RTCP packet types are in the range 192-223, that does not overlap with
+// RTP payload types once the marker bit is included.
+// Specification: https://datatracker.ietf.org/doc/html/rfc5761#section-4
+func isRTCPPacket(buf []byte) bool {
+	return len(buf) >= 2 && buf[1] >= 192 && buf[1] <= 223
+}
+
+// rtcpMuxReadFunc returns a readFunc that routes packets received on a
+// port shared by RTP and RTCP to the corresponding callback.
+func rtcpMuxReadFunc(onRTP readFunc, onRTCP readFunc) readFunc {
+	return func(buf []byte) bool {
+		if isRTCPPacket(buf) {
+			return onRTCP(buf)
+		}
+		return onRTP(buf)
+	}
+}
+
+func discardPacket([]byte) bool {
+	return false
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/bluenviron/gortsplib/v4/pkg/auth"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
 	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
+	"github.com/bluenviron/gortsplib/v4/pkg/multicast"
+	"github.com/bluenviron/gortsplib/v4/pkg/ulpfec"
 )
 
 const (
@@ -47,8 +49,33 @@ type sessionRequestReq struct {
 	res    chan sessionRequestRes
 }
 
-type chGetMulticastIPReq struct {
-	res chan net.IP
+type chReserveMulticastGroupRes struct {
+	ip  net.IP
+	err error
+}
+
+type chReserveMulticastGroupReq struct {
+	ip   net.IP // optional
+	port int
+	res  chan chReserveMulticastGroupRes
+}
+
+type chReleaseMulticastGroupReq struct {
+	ip   net.IP
+	port int
+}
+
+type chGetUDPSessionPortReq struct {
+	res chan int
+}
+
+type serverDrainRes struct {
+	sessions []*ServerSession
+	done     chan struct{}
+}
+
+type serverDrainReq struct {
+	res chan serverDrainRes
 }
 
 // Server is a RTSP server.
@@ -65,7 +92,18 @@ type Server struct {
 	// a port to send and receive RTCP packets with the UDP transport.
 	// If UDPRTPAddress and UDPRTCPAddress are filled, the server can support the UDP transport.
 	UDPRTCPAddress string
+	// the first port of a range from which a dedicated pair of RTP and RTCP ports
+	// is allocated to each media setupped with the UDP transport,
+	// instead of sharing UDPRTPAddress and UDPRTCPAddress among all sessions.
+	// Ports are released when the session is closed.
+	// If UDPSessionPortMin and UDPSessionPortMax are filled, the server can support the UDP transport.
+	UDPSessionPortMin int
+	// the last port of the range from which dedicated pairs of RTP and RTCP ports are allocated.
+	// If UDPSessionPortMin and UDPSessionPortMax are filled, the server can support the UDP transport.
+	UDPSessionPortMax int
 	// a range of multicast IPs to use with the UDP-multicast transport.
+	// It can be either an IPv4 or an IPv6 range. When the range is source-specific
+	// (232.0.0.0/8 or ff3x::/32), MulticastInterface is required.
 	// If MulticastIPRange, MulticastRTPPort, MulticastRTCPPort are filled, the server
 	// can support the UDP-multicast transport.
 	MulticastIPRange string
@@ -77,6 +115,17 @@ type Server struct {
 	// If MulticastIPRange, MulticastRTPPort, MulticastRTCPPort are filled, the server
 	// can support the UDP-multicast transport.
 	MulticastRTCPPort int
+	// name of the network interface used to send packets with the UDP-multicast transport.
+	// When it is set, packets are sent through this interface only, and its address
+	// is provided to clients as source of source-specific groups, in order to join them.
+	// It defaults to all multicast-capable interfaces.
+	MulticastInterface string
+	// the address of a listener that accepts RTSP-over-HTTP tunnels
+	// and RTSP-over-WebSocket connections.
+	// If it is equal to RTSPAddress, these are accepted on the RTSP listener,
+	// together with regular RTSP connections.
+	// If TLSConfig is filled, connections are accepted with HTTPS / WSS.
+	HTTPAddress string
 	// timeout of read operations.
 	// It defaults to 10 seconds
 	ReadTimeout time.Duration
@@ -88,12 +137,51 @@ type Server struct {
 	// Size of the queue of outgoing packets.
 	// It defaults to 256.
 	WriteQueueSize int
+	// action taken when a reader is unable to keep up with a ServerStream
+	// and its queue of outgoing packets fills up.
+	// It can be overridden for each session with ServerSession.SetSlowReaderPolicy().
+	// Readers with the UDP-multicast transport cannot be disconnected, therefore
+	// ServerSlowReaderPolicyDisconnect behaves like ServerSlowReaderPolicyDropOldest with them.
+	// It defaults to ServerSlowReaderPolicyDropOldest.
+	SlowReaderPolicy ServerSlowReaderPolicy
+	// maximum duration of the backlog of a reader before it is disconnected,
+	// when SlowReaderPolicy is ServerSlowReaderPolicyDisconnect.
+	// It defaults to 5 seconds.
+	SlowReaderMaxBacklog time.Duration
 	// maximum size of outgoing RTP / RTCP packets.
 	// This must be less than the UDP MTU (1472 bytes).
 	// It defaults to 1472.
 	MaxPacketSize int
+	// number of outgoing RTP packets that are kept in order to be retransmitted
+	// when requested by readers with NACKs. It is used only when the media
+	// description contains a RTX format (RFC 4588).
+	// It defaults to 1024.
+	RetransmissionBufferSize int
+	// number of outgoing RTP packets protected by each FEC packet.
+	// It is used only when the description contains a FEC group
+	// with a ULPFEC format (RFC 5109).
+	// It defaults to 8.
+	FECGroupSize int
+	// allow clients to multiplex RTP and RTCP packets on a single UDP port (RFC 5761).
+	// When enabled, medias are advertised with the rtcp-mux attribute and
+	// clients that do not request multiplexing keep using port pairs.
+	RTCPMux bool
+	// send packets with the UDP transport to the address from which
+	// the first packet of the client is received (symmetric RTP),
+	// instead of the ports advertised by the client.
+	// This allows to serve clients behind NATs that rewrite ports.
+	// It requires UDPSessionPortMin and UDPSessionPortMax, since clients
+	// that share the same ports cannot be told apart by their IP.
+	UDPSymmetricRTP bool
+	// period of empty RTP and RTCP packets that are sent to publishers
+	// with the UDP transport, in order to keep NAT bindings open.
+	// It defaults to zero, that means that keep-alives are disabled.
+	UDPKeepAlivePeriod time.Duration
 	// disable automatic RTCP sender reports.
 	DisableRTCPSenderReports bool
+	// disable RTSP 2.0.
+	// Requests that use it are answered with status code 505.
+	DisableRTSP2 bool
 	// authentication methods.
 	// It defaults to plain and digest+MD5.
 	AuthMethods []auth.VerifyMethod
@@ -124,26 +212,39 @@ type Server struct {
 	receiverReportPeriod time.Duration
 	sessionTimeout       time.Duration
 	checkStreamPeriod    time.Duration
+	// read and write packets with the UDP transport in batches
+	// (recvmmsg, sendmmsg, UDP GSO, Linux only).
+	udpBatchIO bool
 
 	ctx             context.Context
 	ctxCancel       func()
 	wg              sync.WaitGroup
 	multicastNet    *net.IPNet
+	multicastIntf   *net.Interface
 	multicastNextIP net.IP
+	multicastGroups map[string]struct{}
+	udpSessionPort  int
 	tcpListener     *serverTCPListener
+	httpListener    *serverHTTPListener
+	httpHandler     *serverHTTPHandler
 	udpRTPListener  *serverUDPListener
 	udpRTCPListener *serverUDPListener
 	sessions        map[string]*ServerSession
 	conns           map[*ServerConn]struct{}
+	draining        bool
+	drainDone       chan struct{}
 	closeError      error
 
 	// in
-	chNewConn        chan net.Conn
-	chAcceptErr      chan error
-	chCloseConn      chan *ServerConn
-	chHandleRequest  chan sessionRequestReq
-	chCloseSession   chan *ServerSession
-	chGetMulticastIP chan chGetMulticastIPReq
+	chNewConn               chan net.Conn
+	chAcceptErr             chan error
+	chCloseConn             chan *ServerConn
+	chHandleRequest         chan sessionRequestReq
+	chCloseSession          chan *ServerSession
+	chReserveMulticastGroup chan chReserveMulticastGroupReq
+	chReleaseMulticastGroup chan chReleaseMulticastGroupReq
+	chGetUDPSessionPort     chan chGetUDPSessionPortReq
+	chDrain                 chan serverDrainReq
 }
 
 // Start starts the server.
@@ -160,11 +261,22 @@ func (s *Server) Start() error {
 	} else if (s.WriteQueueSize & (s.WriteQueueSize - 1)) != 0 {
 		return fmt.Errorf("WriteQueueSize (%d) must be a power of two", s.WriteQueueSize)
 	}
+	if s.SlowReaderMaxBacklog == 0 {
+		s.SlowReaderMaxBacklog = 5 * time.Second
+	}
 	if s.MaxPacketSize == 0 {
 		s.MaxPacketSize = udpMaxPayloadSize
 	} else if s.MaxPacketSize > udpMaxPayloadSize {
 		return fmt.Errorf("MaxPacketSize (%d) must be less than %d", s.MaxPacketSize, udpMaxPayloadSize)
 	}
+	if s.RetransmissionBufferSize == 0 {
+		s.RetransmissionBufferSize = 1024
+	}
+	if s.FECGroupSize == 0 {
+		s.FECGroupSize = 8
+	} else if s.FECGroupSize < 0 || s.FECGroupSize > ulpfec.MaxGroupSize {
+		return fmt.Errorf("FECGroupSize (%d) must be less than or equal to %d", s.FECGroupSize, ulpfec.MaxGroupSize)
+	}
 	if len(s.AuthMethods) == 0 {
 		// disable VerifyMethodDigestSHA256 unless explicitly set
 		// since it prevents FFmpeg from authenticating
@@ -205,6 +317,31 @@ func (s *Server) Start() error {
 		return fmt.Errorf("UDPRTPAddress and UDPRTCPAddress must be used together")
 	}
 
+	if (s.UDPSessionPortMin != 0 && s.UDPSessionPortMax == 0) ||
+		(s.UDPSessionPortMin == 0 && s.UDPSessionPortMax != 0) {
+		return fmt.Errorf("UDPSessionPortMin and UDPSessionPortMax must be used together")
+	}
+
+	if s.UDPSessionPortMin != 0 {
+		if s.UDPRTPAddress != "" {
+			return fmt.Errorf("UDPSessionPortMin and UDPRTPAddress cannot be used together")
+		}
+
+		if (s.UDPSessionPortMin % 2) != 0 {
+			return fmt.Errorf("UDPSessionPortMin (%d) must be even", s.UDPSessionPortMin)
+		}
+
+		if s.UDPSessionPortMax <= s.UDPSessionPortMin || s.UDPSessionPortMax > 65535 {
+			return fmt.Errorf("invalid UDP session port range (%d-%d)", s.UDPSessionPortMin, s.UDPSessionPortMax)
+		}
+
+		s.udpSessionPort = s.UDPSessionPortMin
+	}
+
+	if s.UDPSymmetricRTP && s.UDPSessionPortMin == 0 {
+		return fmt.Errorf("UDPSymmetricRTP requires UDPSessionPortMin and UDPSessionPortMax")
+	}
+
 	if s.UDPRTPAddress != "" {
 		rtpPort, err := extractPort(s.UDPRTPAddress)
 		if err != nil {
@@ -228,6 +365,7 @@ func (s *Server) Start() error {
 			listenPacket:    s.ListenPacket,
 			writeTimeout:    s.WriteTimeout,
 			multicastEnable: false,
+			batchIO:         s.udpBatchIO,
 			address:         s.UDPRTPAddress,
 		}
 		err = s.udpRTPListener.initialize()
@@ -239,6 +377,7 @@ func (s *Server) Start() error {
 			listenPacket:    s.ListenPacket,
 			writeTimeout:    s.WriteTimeout,
 			multicastEnable: false,
+			batchIO:         s.udpBatchIO,
 			address:         s.UDPRTCPAddress,
 		}
 		err = s.udpRTCPListener.initialize()
@@ -294,7 +433,27 @@ func (s *Server) Start() error {
 			return err
 		}
 
+		if s.MulticastInterface != "" {
+			s.multicastIntf, err = net.InterfaceByName(s.MulticastInterface)
+			if err == nil && (s.multicastIntf.Flags&net.FlagMulticast) == 0 {
+				err = fmt.Errorf("interface %s is not multicast-capable", s.MulticastInterface)
+			}
+		} else if multicast.IsSSM(s.multicastNet.IP) {
+			// packets of source-specific groups must be sent from a single address.
+			err = fmt.Errorf("a source-specific MulticastIPRange requires MulticastInterface")
+		}
+		if err != nil {
+			if s.udpRTPListener != nil {
+				s.udpRTPListener.close()
+			}
+			if s.udpRTCPListener != nil {
+				s.udpRTCPListener.close()
+			}
+			return err
+		}
+
 		s.multicastNextIP = s.multicastNet.IP
+		s.multicastGroups = make(map[string]struct{})
 	}
 
 	s.ctx, s.ctxCancel = context.WithCancel(context.Background())
@@ -306,7 +465,15 @@ func (s *Server) Start() error {
 	s.chCloseConn = make(chan *ServerConn)
 	s.chHandleRequest = make(chan sessionRequestReq)
 	s.chCloseSession = make(chan *ServerSession)
-	s.chGetMulticastIP = make(chan chGetMulticastIPReq)
+	s.chReserveMulticastGroup = make(chan chReserveMulticastGroupReq)
+	s.chReleaseMulticastGroup = make(chan chReleaseMulticastGroupReq)
+	s.chGetUDPSessionPort = make(chan chGetUDPSessionPortReq)
+	s.chDrain = make(chan serverDrainReq)
+
+	s.httpHandler = &serverHTTPHandler{
+		s: s,
+	}
+	s.httpHandler.initialize()
 
 	s.tcpListener = &serverTCPListener{
 		s: s,
@@ -323,6 +490,24 @@ func (s *Server) Start() error {
 		return err
 	}
 
+	if s.HTTPAddress != "" && s.HTTPAddress != s.RTSPAddress {
+		s.httpListener = &serverHTTPListener{
+			s: s,
+		}
+		err = s.httpListener.initialize()
+		if err != nil {
+			s.tcpListener.close()
+			if s.udpRTPListener != nil {
+				s.udpRTPListener.close()
+			}
+			if s.udpRTCPListener != nil {
+				s.udpRTCPListener.close()
+			}
+			s.ctxCancel()
+			return err
+		}
+	}
+
 	s.wg.Add(1)
 	go s.run()
 
@@ -357,7 +542,13 @@ func (s *Server) run() {
 		s.udpRTPListener.close()
 	}
 
+	if s.httpListener != nil {
+		s.httpListener.close()
+	}
+
 	s.tcpListener.close()
+
+	s.httpHandler.close()
 }
 
 func (s *Server) runInner() error {
@@ -415,6 +606,16 @@ func (s *Server) runInner() error {
 					continue
 				}
 
+				// do not accept new sessions while draining
+				if s.draining {
+					req.res <- sessionRequestRes{
+						res: &base.Response{
+							StatusCode: base.StatusServiceUnavailable,
+						},
+					}
+					continue
+				}
+
 				ss := &ServerSession{
 					s:      s,
 					author: req.sc,
@@ -440,20 +641,39 @@ func (s *Server) runInner() error {
 			}
 			delete(s.sessions, ss.secretID)
 			ss.Close()
+			s.checkDrainDone()
+
+		case req := <-s.chReserveMulticastGroup:
+			ip, err := s.doReserveMulticastGroup(req.ip, req.port)
+			req.res <- chReserveMulticastGroupRes{ip: ip, err: err}
+
+		case req := <-s.chReleaseMulticastGroup:
+			delete(s.multicastGroups, multicastGroupKey(req.ip, req.port))
+
+		case req := <-s.chGetUDPSessionPort:
+			req.res <- s.udpSessionPort
+			s.udpSessionPort += 2
+			if (s.udpSessionPort + 1) > s.UDPSessionPortMax {
+				s.udpSessionPort = s.UDPSessionPortMin
+			}
 
-		case req := <-s.chGetMulticastIP:
-			ip32 := uint32(s.multicastNextIP[0])<<24 | uint32(s.multicastNextIP[1])<<16 |
-				uint32(s.multicastNextIP[2])<<8 | uint32(s.multicastNextIP[3])
-			mask := uint32(s.multicastNet.Mask[0])<<24 | uint32(s.multicastNet.Mask[1])<<16 |
-				uint32(s.multicastNet.Mask[2])<<8 | uint32(s.multicastNet.Mask[3])
-			ip32 = (ip32 & mask) | ((ip32 + 1) & ^mask)
-			ip := make(net.IP, 4)
-			ip[0] = byte(ip32 >> 24)
-			ip[1] = byte(ip32 >> 16)
-			ip[2] = byte(ip32 >> 8)
-			ip[3] = byte(ip32)
-			s.multicastNextIP = ip
-			req.res <- ip
+		case req := <-s.chDrain:
+			if !s.draining {
+				s.draining = true
+				s.drainDone = make(chan struct{})
+			}
+
+			sessions := make([]*ServerSession, 0, len(s.sessions))
+			for _, ss := range s.sessions {
+				sessions = append(sessions, ss)
+			}
+
+			s.checkDrainDone()
+
+			req.res <- serverDrainRes{
+				sessions: sessions,
+				done:     s.drainDone,
+			}
 
 		case <-s.ctx.Done():
 			return liberrors.ErrServerTerminated{}
@@ -461,6 +681,18 @@ func (s *Server) runInner() error {
 	}
 }
 
+func (s *Server) checkDrainDone() {
+	if !s.draining || len(s.sessions) != 0 {
+		return
+	}
+
+	select {
+	case <-s.drainDone:
+	default:
+		close(s.drainDone)
+	}
+}
+
 // StartAndWait starts the server and waits until a fatal error.
 func (s *Server) StartAndWait() error {
 	err := s.Start()
@@ -472,17 +704,152 @@ func (s *Server) StartAndWait() error {
 	return s.Wait()
 }
 
-func (s *Server) getMulticastIP() (net.IP, error) {
-	res := make(chan net.IP)
+// Drain stops accepting new sessions, redirects existing sessions
+// to the location returned by ServerHandlerOnDrain and waits until
+// all sessions are closed, or until ctx is canceled.
+// The server must be closed with Close() after Drain() returns.
+func (s *Server) Drain(ctx context.Context) error {
+	cres := make(chan serverDrainRes)
+
 	select {
-	case s.chGetMulticastIP <- chGetMulticastIPReq{res: res}:
-		return <-res, nil
+	case s.chDrain <- serverDrainReq{res: cres}:
+	case <-s.ctx.Done():
+		return liberrors.ErrServerTerminated{}
+	}
+
+	res := <-cres
+
+	if h, ok := s.Handler.(ServerHandlerOnDrain); ok {
+		for _, ss := range res.sessions {
+			location, ra := h.OnDrain(&ServerHandlerOnDrainCtx{
+				Session: ss,
+			})
+			if location != nil {
+				ss.Redirect(location, ra) //nolint:errcheck
+			}
+		}
+	}
+
+	select {
+	case <-res.done:
+		return nil
+
+	case <-ctx.Done():
+		return ctx.Err()
+
+	case <-s.ctx.Done():
+		return liberrors.ErrServerTerminated{}
+	}
+}
+
+// nextMulticastIP returns the IP that follows a given one inside a range,
+// wrapping around when the end of the range is reached.
+// It supports both IPv4 and IPv6 ranges.
+func nextMulticastIP(ip net.IP, ipNet *net.IPNet) net.IP {
+	ret := make(net.IP, len(ip))
+	carry := uint16(1)
+
+	for i := len(ip) - 1; i >= 0; i-- {
+		sum := uint16(ip[i]) + carry
+		carry = sum >> 8
+		ret[i] = (ip[i] & ipNet.Mask[i]) | (byte(sum) & ^ipNet.Mask[i])
+	}
+
+	return ret
+}
+
+func multicastGroupKey(ip net.IP, port int) string {
+	return net.JoinHostPort(ip.String(), strconv.FormatInt(int64(port), 10))
+}
+
+func (s *Server) doReserveMulticastGroup(ip net.IP, port int) (net.IP, error) {
+	// IP requested by the client
+	if ip != nil {
+		key := multicastGroupKey(ip, port)
+		if _, ok := s.multicastGroups[key]; ok {
+			return nil, liberrors.ErrServerMulticastGroupInUse{}
+		}
+
+		s.multicastGroups[key] = struct{}{}
+		return ip, nil
+	}
+
+	// IP picked from the pool.
+	// since len(s.multicastGroups) groups are in use at most,
+	// one of the next len(s.multicastGroups)+1 IPs is free, if the pool is big enough.
+	ones, bits := s.multicastNet.Mask.Size()
+	poolSize := uint64(1) << min(bits-ones, 63)
+	attempts := min(uint64(len(s.multicastGroups))+1, poolSize)
+
+	for range attempts {
+		s.multicastNextIP = nextMulticastIP(s.multicastNextIP, s.multicastNet)
+
+		key := multicastGroupKey(s.multicastNextIP, port)
+		if _, ok := s.multicastGroups[key]; !ok {
+			s.multicastGroups[key] = struct{}{}
+			return s.multicastNextIP, nil
+		}
+	}
+
+	return nil, liberrors.ErrServerMulticastGroupInUse{}
+}
+
+// reserveMulticastGroup reserves a multicast group, identified by a IP and a port.
+// If ip is nil, it is picked from the multicast IP range.
+func (s *Server) reserveMulticastGroup(ip net.IP, port int) (net.IP, error) {
+	res := make(chan chReserveMulticastGroupRes)
+	select {
+	case s.chReserveMulticastGroup <- chReserveMulticastGroupReq{ip: ip, port: port, res: res}:
+		r := <-res
+		return r.ip, r.err
 
 	case <-s.ctx.Done():
 		return nil, liberrors.ErrServerTerminated{}
 	}
 }
 
+func (s *Server) releaseMulticastGroup(ip net.IP, port int) {
+	select {
+	case s.chReleaseMulticastGroup <- chReleaseMulticastGroupReq{ip: ip, port: port}:
+	case <-s.ctx.Done():
+	}
+}
+
+func (s *Server) getUDPSessionPort() (int, error) {
+	res := make(chan int)
+	select {
+	case s.chGetUDPSessionPort <- chGetUDPSessionPortReq{res: res}:
+		return <-res, nil
+
+	case <-s.ctx.Done():
+		return 0, liberrors.ErrServerTerminated{}
+	}
+}
+
+// allocateUDPSessionListeners allocates a dedicated pair of RTP and RTCP listeners
+// by trying all the ports of the UDP session port range.
+func (s *Server) allocateUDPSessionListeners() (*serverUDPListener, *serverUDPListener, error) {
+	for i := 0; i < ((s.UDPSessionPortMax - s.UDPSessionPortMin + 1) / 2); i++ {
+		port, err := s.getUDPSessionPort()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rtpl, rtcpl, err := createUDPListenerPair(
+			s.ListenPacket,
+			s.WriteTimeout,
+			s.udpBatchIO,
+			net.JoinHostPort("", strconv.FormatInt(int64(port), 10)),
+			net.JoinHostPort("", strconv.FormatInt(int64(port+1), 10)),
+		)
+		if err == nil {
+			return rtpl, rtcpl, nil
+		}
+	}
+
+	return nil, nil, liberrors.ErrServerNoUDPPortsAvailable{}
+}
+
 func (s *Server) newConn(nconn net.Conn) {
 	select {
 	case s.chNewConn <- nconn:
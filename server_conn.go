@@ -9,6 +9,7 @@ import (
 	gourl "net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/auth"
@@ -28,6 +29,20 @@ func getSessionID(header base.Header) string {
 	return ""
 }
 
+func getPipelinedRequestsID(req *base.Request) (uint32, bool) {
+	if req.Version != base.Version20 {
+		return 0, false
+	}
+
+	var pr headers.PipelinedRequests
+	err := pr.Unmarshal(req.Header["Pipelined-Requests"])
+	if err != nil {
+		return 0, false
+	}
+
+	return pr.ID, true
+}
+
 func checkMulticastEnabled(multicastIPRange string, query string) bool {
 	// VLC uses multicast if the SDP contains a multicast address.
 	// therefore, we introduce a special query (vlcmulticast) that allows
@@ -53,6 +68,33 @@ func checkBackChannelsEnabled(header base.Header) bool {
 	return false
 }
 
+// addMediaPropertiesHeaders fills the RTSP 2.0 headers that describe
+// the media of a ServerStream, unless they have been already set by the handler.
+// ServerStreams are live, therefore they can't be seeked and their content progresses with time.
+func addMediaPropertiesHeaders(h base.Header) {
+	if _, ok := h["Media-Properties"]; !ok {
+		ra := headers.MediaPropertiesRandomAccessNoSeeking
+		cm := headers.MediaPropertiesContentModificationsTimeProgressing
+		r := headers.MediaPropertiesRetentionTimeDuration
+		d := time.Duration(0)
+
+		h["Media-Properties"] = headers.MediaProperties{
+			RandomAccess:          &ra,
+			ContentModifications:  &cm,
+			Retention:             &r,
+			RetentionTimeDuration: &d,
+		}.Marshal()
+	}
+
+	if _, ok := h["Accept-Ranges"]; !ok {
+		h["Accept-Ranges"] = headers.AcceptRanges{"npt"}.Marshal()
+	}
+
+	if _, ok := h["Media-Range"]; !ok {
+		h["Media-Range"] = headers.MediaRange{}.Marshal()
+	}
+}
+
 func mikeyGenerate(ctx *wrappedSRTPContext) (*mikey.Message, error) {
 	csbID, err := randUint32()
 	if err != nil {
@@ -142,6 +184,7 @@ func prepareForDescribe(
 	multicast bool,
 	backChannels bool,
 	secure bool,
+	rtcpMux bool,
 	medias map[*description.Media]*serverStreamMedia,
 ) (*description.Session, error) {
 	out := &description.Session{
@@ -171,6 +214,7 @@ func prepareForDescribe(
 				// like the Grandstream GXV3500.
 				Control:      "trackID=" + strconv.FormatInt(int64(i), 10),
 				Secure:       secure,
+				RTCPMux:      rtcpMux && !multicast,
 				KeyMgmtMikey: keyMgmtMikey,
 				Formats:      medi.Formats,
 			})
@@ -206,18 +250,41 @@ type ServerConn struct {
 	reader     *serverConnReader
 	authNonce  string
 
+	// pipelined requests (RTSP 2.0)
+	pipelinedSessions map[uint32]string
+
+	// server-initiated requests
+	cseq             int
+	lastURL          *base.URL
+	lastVersion      base.Version
+	pendingResponses int64
+
 	// in
 	chRemoveSession chan *ServerSession
+	chRedirect      chan serverConnRedirectReq
 
 	// out
 	done chan struct{}
 }
 
+type serverConnRedirectReq struct {
+	location *base.URL
+	ra       *headers.Range
+	session  *ServerSession
+	res      chan error
+}
+
 func (sc *ServerConn) initialize() {
 	ctx, ctxCancel := context.WithCancel(sc.s.ctx)
 
-	if sc.s.TLSConfig != nil {
-		sc.nconn = tls.Server(sc.nconn, sc.s.TLSConfig)
+	switch sc.nconn.(type) {
+	case *serverBufferedConn, *httpTunnelConn, *webSocketConn:
+		// TLS has already been set up by serverHTTPHandler
+
+	default:
+		if sc.s.TLSConfig != nil {
+			sc.nconn = tls.Server(sc.nconn, sc.s.TLSConfig)
+		}
 	}
 
 	sc.bc = bytecounter.New(sc.nconn, nil, nil)
@@ -225,6 +292,7 @@ func (sc *ServerConn) initialize() {
 	sc.ctxCancel = ctxCancel
 	sc.remoteAddr = sc.nconn.RemoteAddr().(*net.TCPAddr)
 	sc.chRemoveSession = make(chan *ServerSession)
+	sc.chRedirect = make(chan serverConnRedirectReq)
 	sc.done = make(chan struct{})
 
 	sc.s.wg.Add(1)
@@ -278,6 +346,26 @@ func (sc *ServerConn) Stats() *StatsConn {
 	}
 }
 
+// Redirect sends a REDIRECT request to the client,
+// asking it to move to another location.
+// If ra is not nil, the client is asked to resume playback from the given position.
+func (sc *ServerConn) Redirect(location *base.URL, ra *headers.Range) error {
+	return sc.redirect(location, ra, nil)
+}
+
+// redirect sends a REDIRECT request that refers to a session.
+// If session is nil, the session associated with the connection is used.
+func (sc *ServerConn) redirect(location *base.URL, ra *headers.Range, session *ServerSession) error {
+	cres := make(chan error)
+	select {
+	case sc.chRedirect <- serverConnRedirectReq{location: location, ra: ra, session: session, res: cres}:
+		return <-cres
+
+	case <-sc.ctx.Done():
+		return liberrors.ErrServerTerminated{}
+	}
+}
+
 // VerifyCredentials verifies credentials provided by the user.
 func (sc *ServerConn) VerifyCredentials(
 	req *base.Request,
@@ -382,6 +470,10 @@ func (sc *ServerConn) runInner() error {
 			if sc.session == ss {
 				sc.session = nil
 			}
+			sc.removePipelinedSessions(ss)
+
+		case req := <-sc.chRedirect:
+			req.res <- sc.doRedirect(req.location, req.ra, req.session)
 
 		case <-sc.ctx.Done():
 			return liberrors.ErrServerTerminated{}
@@ -402,8 +494,22 @@ func (sc *ServerConn) handleRequestInner(req *base.Request) (*base.Response, err
 		}, liberrors.ErrServerInvalidPath{}
 	}
 
+	if req.Version == base.Version20 && sc.s.DisableRTSP2 {
+		return &base.Response{
+			StatusCode: base.StatusRTSPVersionNotSupported,
+		}, nil
+	}
+
 	sxID := getSessionID(req.Header)
 
+	// requests that are part of a pipeline can refer to
+	// a session that has been created by a previous request.
+	if sxID == "" {
+		if pipelinedID, ok := getPipelinedRequestsID(req); ok {
+			sxID = sc.pipelinedSessions[pipelinedID]
+		}
+	}
+
 	var path string
 	var query string
 
@@ -481,6 +587,7 @@ func (sc *ServerConn) handleRequestInner(req *base.Request) (*base.Response, err
 					checkMulticastEnabled(sc.s.MulticastIPRange, query),
 					checkBackChannelsEnabled(req.Header),
 					sc.s.TLSConfig != nil,
+					sc.s.RTCPMux,
 					stream.medias,
 				)
 				if err != nil {
@@ -498,6 +605,10 @@ func (sc *ServerConn) handleRequestInner(req *base.Request) (*base.Response, err
 				}
 
 				res.Body = byts
+
+				if req.Version == base.Version20 {
+					addMediaPropertiesHeaders(res.Header)
+				}
 			}
 
 			return res, err
@@ -573,11 +684,85 @@ func (sc *ServerConn) handleRequestInner(req *base.Request) (*base.Response, err
 	}, nil
 }
 
+func (sc *ServerConn) doRedirect(location *base.URL, ra *headers.Range, session *ServerSession) error {
+	h := base.Header{
+		"Location": base.HeaderValue{location.String()},
+	}
+
+	if ra != nil {
+		h["Range"] = ra.Marshal()
+	}
+
+	if session == nil {
+		session = sc.session
+	}
+
+	if session != nil {
+		h["Session"] = base.HeaderValue{session.secretID}
+	}
+
+	u := sc.lastURL
+	if u == nil {
+		u = location
+	}
+
+	return sc.writeRequest(&base.Request{
+		Version: sc.lastVersion,
+		Method:  base.Redirect,
+		URL:     u,
+		Header:  h,
+	})
+}
+
+// writeRequest writes a request to the client.
+// The response is read and discarded by serverConnReader.
+func (sc *ServerConn) writeRequest(req *base.Request) error {
+	sc.cseq++
+	req.Header["CSeq"] = base.HeaderValue{strconv.FormatInt(int64(sc.cseq), 10)}
+
+	atomic.AddInt64(&sc.pendingResponses, 1)
+
+	sc.nconn.SetWriteDeadline(time.Now().Add(sc.s.WriteTimeout))
+	err := sc.conn.WriteRequest(req)
+	if err != nil {
+		atomic.AddInt64(&sc.pendingResponses, -1)
+		return err
+	}
+
+	return nil
+}
+
+// consumePendingResponse is called by serverConnReader when a response is received.
+func (sc *ServerConn) consumePendingResponse() bool {
+	for {
+		v := atomic.LoadInt64(&sc.pendingResponses)
+		if v <= 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&sc.pendingResponses, v, v-1) {
+			return true
+		}
+	}
+}
+
+// localIP returns the IP of the server that received the connection.
+func (sc *ServerConn) localIP() net.IP {
+	if addr, ok := sc.nconn.LocalAddr().(*net.TCPAddr); ok {
+		return addr.IP
+	}
+	return nil
+}
+
 func (sc *ServerConn) handleRequestOuter(req *base.Request) error {
 	if h, ok := sc.s.Handler.(ServerHandlerOnRequest); ok {
 		h.OnRequest(sc, req)
 	}
 
+	if req.URL != nil {
+		sc.lastURL = req.URL
+	}
+	sc.lastVersion = req.Version
+
 	res, err := sc.handleRequestInner(req)
 
 	if res.Header == nil {
@@ -599,6 +784,23 @@ func (sc *ServerConn) handleRequestOuter(req *base.Request) error {
 	// add server
 	res.Header["Server"] = base.HeaderValue{serverHeader}
 
+	// use the same protocol version of the request
+	if res.StatusCode != base.StatusRTSPVersionNotSupported {
+		res.Version = req.Version
+	}
+
+	// associate pipeline with session
+	if pipelinedID, ok := getPipelinedRequestsID(req); ok {
+		res.Header["Pipelined-Requests"] = req.Header["Pipelined-Requests"]
+
+		if sc.session != nil {
+			if sc.pipelinedSessions == nil {
+				sc.pipelinedSessions = make(map[uint32]string)
+			}
+			sc.pipelinedSessions[pipelinedID] = sc.session.secretID
+		}
+	}
+
 	if h, ok := sc.s.Handler.(ServerHandlerOnResponse); ok {
 		h.OnResponse(sc, res)
 	}
@@ -641,8 +843,15 @@ func (sc *ServerConn) handleRequestInSession(
 			res:    cres,
 		}
 
+		prevSession := sc.session
 		res, session, err := sc.session.handleRequest(sreq)
 		sc.session = session
+
+		// the connection has been detached from the session, for instance by TEARDOWN.
+		if session != prevSession {
+			sc.removePipelinedSessions(prevSession)
+		}
+
 		return res, err
 	}
 
@@ -661,6 +870,15 @@ func (sc *ServerConn) handleRequestInSession(
 	return res, err
 }
 
+// removePipelinedSessions removes pipelines associated with a session.
+func (sc *ServerConn) removePipelinedSessions(ss *ServerSession) {
+	for id, sxID := range sc.pipelinedSessions {
+		if sxID == ss.secretID {
+			delete(sc.pipelinedSessions, id)
+		}
+	}
+}
+
 func (sc *ServerConn) removeSession(ss *ServerSession) {
 	select {
 	case sc.chRemoveSession <- ss:
@@ -91,7 +91,10 @@ func (cr *serverConnReader) readFuncStandard() error {
 			}
 
 		case *base.Response:
-			return liberrors.ErrServerUnexpectedResponse{}
+			// responses are allowed only after requests sent by the server
+			if !cr.sc.consumePendingResponse() {
+				return liberrors.ErrServerUnexpectedResponse{}
+			}
 
 		case *base.InterleavedFrame:
 			return liberrors.ErrServerUnexpectedFrame{}
@@ -127,7 +130,10 @@ func (cr *serverConnReader) readFuncTCP() error {
 			}
 
 		case *base.Response:
-			return liberrors.ErrServerUnexpectedResponse{}
+			// responses are allowed only after requests sent by the server
+			if !cr.sc.consumePendingResponse() {
+				return liberrors.ErrServerUnexpectedResponse{}
+			}
 
 		case *base.InterleavedFrame:
 			if cb, ok := cr.sc.session.tcpCallbackByChannel[what.Channel]; ok {
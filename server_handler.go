@@ -1,8 +1,12 @@
 package gortsplib
 
 import (
+	"net"
+
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 )
 
 // ServerHandler is the interface implemented by all the server handlers.
@@ -105,6 +109,13 @@ type ServerHandlerOnSetupCtx struct {
 	Path      string
 	Query     string
 	Transport Transport
+
+	// multicast parameters requested by the client (UDP-multicast only).
+	// They are used only if the handler returns StatusOK,
+	// therefore the handler can reject them by returning an error status.
+	MulticastDestination *net.IP // (optional) requested destination, inside MulticastIPRange
+	MulticastPorts       *[2]int // (optional) requested RTP and RTCP ports
+	MulticastTTL         *uint   // (optional) requested TTL
 }
 
 // ServerHandlerOnSetup can be implemented by a ServerHandler.
@@ -124,6 +135,14 @@ type ServerHandlerOnPlayCtx struct {
 	Request *base.Request
 	Path    string
 	Query   string
+	Scale   *headers.Scale // (optional) requested playback scale
+	Speed   *headers.Speed // (optional) requested delivery speed
+
+	SeekStyle *headers.SeekStyle // (optional) requested seek policy (RTSP 2.0)
+
+	RateControl *headers.RateControl // (optional) requested ONVIF replay rate control
+	Frames      *headers.Frames      // (optional) requested ONVIF replay frame filter
+	Immediate   *headers.Immediate   // (optional) requested ONVIF replay immediate flag
 }
 
 // ServerHandlerOnPlay can be implemented by a ServerHandler.
@@ -243,3 +262,31 @@ type ServerHandlerOnStreamWriteError interface {
 	// called when a ServerStream is unable to write packets to a session.
 	OnStreamWriteError(*ServerHandlerOnStreamWriteErrorCtx)
 }
+
+// ServerHandlerOnKeyFrameRequestCtx is the context of OnKeyFrameRequest.
+type ServerHandlerOnKeyFrameRequestCtx struct {
+	Session *ServerSession
+	Stream  *ServerStream
+	Media   *description.Media
+	Format  format.Format
+}
+
+// ServerHandlerOnKeyFrameRequest can be implemented by a ServerHandler.
+type ServerHandlerOnKeyFrameRequest interface {
+	// called when a reader requests a key frame (RTCP PLI or FIR).
+	// The request can also be handled by ServerStream.OnKeyFrameRequest.
+	OnKeyFrameRequest(*ServerHandlerOnKeyFrameRequestCtx)
+}
+
+// ServerHandlerOnDrainCtx is the context of OnDrain.
+type ServerHandlerOnDrainCtx struct {
+	Session *ServerSession
+}
+
+// ServerHandlerOnDrain can be implemented by a ServerHandler.
+type ServerHandlerOnDrain interface {
+	// called by Server.Drain() for every existing session.
+	// It returns the location the session must be redirected to, and optionally a starting position.
+	// If location is nil, the session is not redirected.
+	OnDrain(*ServerHandlerOnDrainCtx) (*base.URL, *headers.Range)
+}
@@ -0,0 +1,313 @@
+package gortsplib
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// serverBufferedConn is a net.Conn whose first bytes have already been read into a buffer.
+type serverBufferedConn struct {
+	net.Conn
+	br *bufio.Reader
+}
+
+// Read implements net.Conn.
+func (c *serverBufferedConn) Read(p []byte) (int, error) {
+	return c.br.Read(p)
+}
+
+// serverHijackedResponseWriter is a http.ResponseWriter that allows
+// websocket.Server to take over a connection that has already been accepted.
+type serverHijackedResponseWriter struct {
+	nconn net.Conn
+	brw   *bufio.ReadWriter
+}
+
+// Header implements http.ResponseWriter.
+func (w *serverHijackedResponseWriter) Header() http.Header {
+	return http.Header{}
+}
+
+// Write implements http.ResponseWriter.
+func (w *serverHijackedResponseWriter) Write([]byte) (int, error) {
+	return 0, fmt.Errorf("unsupported")
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *serverHijackedResponseWriter) WriteHeader(int) {
+}
+
+// Hijack implements http.Hijacker.
+func (w *serverHijackedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.nconn, w.brw, nil
+}
+
+func webSocketHandshake(config *websocket.Config, _ *http.Request) error {
+	// accept clients that do not specify any subprotocol
+	if len(config.Protocol) == 0 {
+		return nil
+	}
+
+	for _, proto := range config.Protocol {
+		if proto == webSocketSubprotocol {
+			config.Protocol = []string{webSocketSubprotocol}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported subprotocols: %v", config.Protocol)
+}
+
+func writeHTTPResponse(nconn net.Conn, statusCode int, header http.Header) error {
+	buf := []byte("HTTP/1.0 " + strconv.FormatInt(int64(statusCode), 10) + " " + http.StatusText(statusCode) + "\r\n" +
+		"Server: " + serverHeader + "\r\n")
+
+	for k, vals := range header {
+		for _, v := range vals {
+			buf = append(buf, []byte(k+": "+v+"\r\n")...)
+		}
+	}
+
+	buf = append(buf, []byte("\r\n")...)
+
+	_, err := nconn.Write(buf)
+	return err
+}
+
+type serverHTTPTunnelPendingGet struct {
+	nconn net.Conn
+	timer *time.Timer
+}
+
+// serverHTTPHandler handles connections that start with a HTTP request
+// (RTSP-over-HTTP tunnels and RTSP-over-WebSocket connections),
+// and converts them into RTSP connections.
+type serverHTTPHandler struct {
+	s *Server
+
+	mutex       sync.Mutex
+	closed      bool
+	conns       map[net.Conn]struct{}
+	pendingGets map[string]*serverHTTPTunnelPendingGet
+}
+
+func (h *serverHTTPHandler) initialize() {
+	h.conns = make(map[net.Conn]struct{})
+	h.pendingGets = make(map[string]*serverHTTPTunnelPendingGet)
+}
+
+func (h *serverHTTPHandler) close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.closed = true
+
+	for nconn := range h.conns {
+		nconn.Close()
+	}
+
+	for cookie, pg := range h.pendingGets {
+		pg.timer.Stop()
+		pg.nconn.Close()
+		delete(h.pendingGets, cookie)
+	}
+}
+
+// accept processes a new connection.
+// When detect is true, the connection is checked for a HTTP request
+// and is passed to the RTSP server if it doesn't contain one.
+func (h *serverHTTPHandler) accept(nconn net.Conn, detect bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		nconn.Close()
+		return
+	}
+
+	h.conns[nconn] = struct{}{}
+
+	h.s.wg.Add(1)
+	go h.runConn(nconn, detect)
+}
+
+func (h *serverHTTPHandler) release(nconn net.Conn) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.conns, nconn)
+}
+
+func (h *serverHTTPHandler) runConn(rawConn net.Conn, detect bool) {
+	defer h.s.wg.Done()
+	defer h.release(rawConn)
+
+	nconn := rawConn
+
+	if h.s.TLSConfig != nil {
+		nconn = tls.Server(nconn, h.s.TLSConfig)
+	}
+
+	nconn.SetReadDeadline(time.Now().Add(h.s.ReadTimeout))
+	br := bufio.NewReader(nconn)
+
+	if detect {
+		buf, err := br.Peek(4)
+		if err != nil {
+			nconn.Close()
+			return
+		}
+
+		if !isHTTPRequestPrefix(buf) {
+			nconn.SetReadDeadline(time.Time{})
+			h.s.newConn(&serverBufferedConn{
+				Conn: nconn,
+				br:   br,
+			})
+			return
+		}
+	}
+
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		nconn.Close()
+		return
+	}
+
+	nconn.SetReadDeadline(time.Time{})
+	nconn.SetWriteDeadline(time.Now().Add(h.s.WriteTimeout))
+
+	switch {
+	case req.Method == http.MethodGet && strings.EqualFold(req.Header.Get("Upgrade"), "websocket"):
+		h.handleWebSocket(nconn, br, req)
+
+	case req.Method == http.MethodGet:
+		h.handleTunnelGet(nconn, req)
+
+	case req.Method == http.MethodPost:
+		h.handleTunnelPost(nconn, br, req)
+
+	default:
+		writeHTTPResponse(nconn, http.StatusMethodNotAllowed, nil) //nolint:errcheck
+		nconn.Close()
+	}
+}
+
+func (h *serverHTTPHandler) handleTunnelGet(nconn net.Conn, req *http.Request) {
+	cookie := req.Header.Get("x-sessioncookie")
+	if cookie == "" {
+		writeHTTPResponse(nconn, http.StatusBadRequest, nil) //nolint:errcheck
+		nconn.Close()
+		return
+	}
+
+	h.mutex.Lock()
+	_, exists := h.pendingGets[cookie]
+	h.mutex.Unlock()
+
+	if exists {
+		writeHTTPResponse(nconn, http.StatusBadRequest, nil) //nolint:errcheck
+		nconn.Close()
+		return
+	}
+
+	err := writeHTTPResponse(nconn, http.StatusOK, http.Header{
+		"Connection":    []string{"close"},
+		"Cache-Control": []string{"no-store"},
+		"Pragma":        []string{"no-cache"},
+		"Content-Type":  []string{httpTunnelContentType},
+	})
+	if err != nil {
+		nconn.Close()
+		return
+	}
+
+	nconn.SetWriteDeadline(time.Time{})
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		nconn.Close()
+		return
+	}
+
+	pg := &serverHTTPTunnelPendingGet{
+		nconn: nconn,
+	}
+
+	// close the GET leg if the POST leg doesn't show up
+	pg.timer = time.AfterFunc(h.s.ReadTimeout, func() {
+		h.mutex.Lock()
+		defer h.mutex.Unlock()
+
+		if cur, ok := h.pendingGets[cookie]; ok && cur == pg {
+			delete(h.pendingGets, cookie)
+			pg.nconn.Close()
+		}
+	})
+
+	h.pendingGets[cookie] = pg
+}
+
+func (h *serverHTTPHandler) handleTunnelPost(nconn net.Conn, br *bufio.Reader, req *http.Request) {
+	cookie := req.Header.Get("x-sessioncookie")
+
+	h.mutex.Lock()
+	pg, ok := h.pendingGets[cookie]
+	if ok {
+		delete(h.pendingGets, cookie)
+		pg.timer.Stop()
+	}
+	h.mutex.Unlock()
+
+	if !ok {
+		writeHTTPResponse(nconn, http.StatusBadRequest, nil) //nolint:errcheck
+		nconn.Close()
+		return
+	}
+
+	// the POST leg doesn't receive any response.
+	nconn.SetWriteDeadline(time.Time{})
+
+	h.s.newConn(&httpTunnelConn{
+		readConn:  nconn,
+		writeConn: pg.nconn,
+		r:         &base64ChunkReader{r: br},
+		w:         pg.nconn,
+	})
+}
+
+func (h *serverHTTPHandler) handleWebSocket(nconn net.Conn, br *bufio.Reader, req *http.Request) {
+	if h.s.TLSConfig != nil {
+		req.TLS = &tls.ConnectionState{}
+	}
+
+	ws := websocket.Server{
+		Handshake: webSocketHandshake,
+		Handler: func(ws *websocket.Conn) {
+			nconn.SetWriteDeadline(time.Time{})
+
+			wc := newWebSocketConn(ws, nconn)
+			h.s.newConn(wc)
+
+			// websocket.Server closes the connection when the handler returns,
+			// therefore wait until the RTSP connection is closed.
+			<-wc.done
+		},
+	}
+
+	ws.ServeHTTP(&serverHijackedResponseWriter{
+		nconn: nconn,
+		brw:   bufio.NewReadWriter(br, bufio.NewWriter(nconn)),
+	}, req)
+}
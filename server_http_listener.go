@@ -0,0 +1,42 @@
+package gortsplib
+
+import (
+	"net"
+)
+
+type serverHTTPListener struct {
+	s *Server
+
+	ln net.Listener
+}
+
+func (sl *serverHTTPListener) initialize() error {
+	var err error
+	sl.ln, err = sl.s.Listen(restrictNetwork("tcp", sl.s.HTTPAddress))
+	if err != nil {
+		return err
+	}
+
+	sl.s.wg.Add(1)
+	go sl.run()
+
+	return nil
+}
+
+func (sl *serverHTTPListener) close() {
+	sl.ln.Close()
+}
+
+func (sl *serverHTTPListener) run() {
+	defer sl.s.wg.Done()
+
+	for {
+		nconn, err := sl.ln.Accept()
+		if err != nil {
+			sl.s.acceptErr(err)
+			return
+		}
+
+		sl.s.httpHandler.accept(nconn, false)
+	}
+}
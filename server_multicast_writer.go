@@ -1,35 +1,107 @@
 package gortsplib
 
 import (
+	"fmt"
 	"net"
 
-	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/multicast"
 )
 
+// serverMulticastDefaultTTL is the TTL of multicast packets when the client does not request one.
+const serverMulticastDefaultTTL = 127
+
+// multicastSourceAddress returns the address of an interface
+// that can be used as source of a multicast group, that is the first unicast one
+// that belongs to the same family of the group.
+func multicastSourceAddress(intf *net.Interface, group net.IP) (net.IP, error) {
+	if intf == nil {
+		return nil, fmt.Errorf("source-specific groups require a multicast interface")
+	}
+
+	addrs, err := intf.Addrs()
+	if err != nil {
+		return nil, err
+	}
+
+	isIPv4 := group.To4() != nil
+
+	for _, addr := range addrs {
+		if v, ok := addr.(*net.IPNet); ok && v.IP.IsGlobalUnicast() && (v.IP.To4() != nil) == isIPv4 {
+			return v.IP, nil
+		}
+	}
+
+	return nil, fmt.Errorf("interface %s has no address of the same family of group %v", intf.Name, group)
+}
+
 type serverMulticastWriter struct {
-	s *Server
+	s           *Server
+	ring        *serverStreamRing
+	media       *description.Media
+	secure      bool
+	stats       *serverStreamReaderStats
+	destination *net.IP // optional
+	ports       *[2]int // optional
+	ttl         *uint   // optional
 
 	rtpl     *serverUDPListener
 	rtcpl    *serverUDPListener
 	writer   *asyncProcessor
+	reader   *serverStreamReader
 	rtpAddr  *net.UDPAddr
 	rtcpAddr *net.UDPAddr
+	source   net.IP // source-specific groups only
 }
 
 func (h *serverMulticastWriter) initialize() error {
-	ip, err := h.s.getMulticastIP()
+	ports := [2]int{h.s.MulticastRTPPort, h.s.MulticastRTCPPort}
+	if h.ports != nil {
+		ports = *h.ports
+	}
+
+	var requestedIP net.IP
+	if h.destination != nil {
+		requestedIP = *h.destination
+	}
+
+	ip, err := h.s.reserveMulticastGroup(requestedIP, ports[0])
 	if err != nil {
 		return err
 	}
 
+	// source-specific groups can be joined only by knowing the source,
+	// that is the address of the interface that sends packets.
+	if multicast.IsSSM(ip) {
+		h.source, err = multicastSourceAddress(h.s.multicastIntf, ip)
+		if err != nil {
+			h.s.releaseMulticastGroup(ip, ports[0])
+			return err
+		}
+	}
+
 	rtpl, rtcpl, err := createUDPListenerMulticastPair(
 		h.s.ListenPacket,
 		h.s.WriteTimeout,
-		h.s.MulticastRTPPort,
-		h.s.MulticastRTCPPort,
+		ports[0],
+		ports[1],
 		ip,
+		h.s.multicastIntf,
 	)
 	if err != nil {
+		h.s.releaseMulticastGroup(ip, ports[0])
+		return err
+	}
+
+	// the TTL advertised to clients is the one used by sockets.
+	err = rtpl.setMulticastTTL(int(h.ttlValue()))
+	if err == nil {
+		err = rtcpl.setMulticastTTL(int(h.ttlValue()))
+	}
+	if err != nil {
+		rtpl.close()
+		rtcpl.close()
+		h.s.releaseMulticastGroup(ip, ports[0])
 		return err
 	}
 
@@ -54,37 +126,65 @@ func (h *serverMulticastWriter) initialize() error {
 	h.writer.initialize()
 	h.writer.start()
 
+	// multicast readers cannot be disconnected.
+	policy := h.s.SlowReaderPolicy
+	if policy == ServerSlowReaderPolicyDisconnect {
+		policy = ServerSlowReaderPolicyDropOldest
+	}
+
+	h.reader = &serverStreamReader{
+		ring:        h.ring,
+		medias:      map[*description.Media]struct{}{h.media: {}},
+		secure:      h.secure,
+		policy:      policy,
+		maxBacklog:  h.s.SlowReaderMaxBacklog,
+		timeNow:     h.s.timeNow,
+		stats:       h.stats,
+		schedule:    h.writer.push,
+		writePacket: h.writePacket,
+	}
+	h.reader.initialize()
+
 	return nil
 }
 
 func (h *serverMulticastWriter) close() {
+	ip, port := h.rtpl.ip(), h.rtpl.port()
+
 	h.rtpl.close()
 	h.rtcpl.close()
 	h.writer.close()
+	h.s.releaseMulticastGroup(ip, port)
 }
 
 func (h *serverMulticastWriter) ip() net.IP {
 	return h.rtpl.ip()
 }
 
-func (h *serverMulticastWriter) writePacketRTP(byts []byte) error {
-	ok := h.writer.push(func() error {
-		return h.rtpl.write(byts, h.rtpAddr)
-	})
-	if !ok {
-		return liberrors.ErrServerWriteQueueFull{}
+func (h *serverMulticastWriter) portPair() [2]int {
+	return [2]int{h.rtpl.port(), h.rtcpl.port()}
+}
+
+func (h *serverMulticastWriter) ttlValue() uint {
+	if h.ttl != nil {
+		return *h.ttl
 	}
+	return serverMulticastDefaultTTL
+}
 
-	return nil
+// matches checks whether the writer satisfies the parameters requested by a client.
+func (h *serverMulticastWriter) matches(destination *net.IP, ports *[2]int, ttl *uint) bool {
+	return (destination == nil || destination.Equal(h.ip())) &&
+		(ports == nil || *ports == h.portPair()) &&
+		(ttl == nil || *ttl == h.ttlValue())
 }
 
-func (h *serverMulticastWriter) writePacketRTCP(byts []byte) error {
-	ok := h.writer.push(func() error {
-		return h.rtcpl.write(byts, h.rtcpAddr)
-	})
-	if !ok {
-		return liberrors.ErrServerWriteQueueFull{}
-	}
+// writePacket writes a packet read from the stream and releases it.
+func (h *serverMulticastWriter) writePacket(_ *description.Media, pkt *serverStreamPacket) error {
+	defer pkt.release()
 
-	return nil
+	if pkt.rtcp {
+		return h.rtcpl.write(pkt.payload(h.secure), h.rtcpAddr, nil)
+	}
+	return h.rtpl.write(pkt.payload(h.secure), h.rtpAddr, nil)
 }
@@ -17,12 +17,15 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/ipv4"
 
+	"github.com/bluenviron/gortsplib/v4/internal/ntp"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
 	"github.com/bluenviron/gortsplib/v4/pkg/conn"
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 	"github.com/bluenviron/gortsplib/v4/pkg/mikey"
+	"github.com/bluenviron/gortsplib/v4/pkg/multicast"
+	"github.com/bluenviron/gortsplib/v4/pkg/onvif"
 	"github.com/bluenviron/gortsplib/v4/pkg/sdp"
 )
 
@@ -60,6 +63,50 @@ func multicastCapableIP(t *testing.T) string {
 	return ""
 }
 
+func multicastCapableIPv6(t *testing.T) string {
+	intfs, err := net.Interfaces()
+	require.NoError(t, err)
+
+	for _, intf := range intfs {
+		if (intf.Flags & net.FlagMulticast) != 0 {
+			addrs, err := intf.Addrs()
+			if err != nil {
+				continue
+			}
+
+			for _, addr := range addrs {
+				if v, ok := addr.(*net.IPNet); ok && v.IP.To4() == nil && v.IP.IsGlobalUnicast() {
+					return v.IP.String()
+				}
+			}
+		}
+	}
+
+	t.Skip("unable to find a multicast-capable IPv6 address")
+	return ""
+}
+
+func interfaceOfIP(t *testing.T, ip string) string {
+	intfs, err := net.Interfaces()
+	require.NoError(t, err)
+
+	for _, intf := range intfs {
+		addrs, err := intf.Addrs()
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			if v, ok := addr.(*net.IPNet); ok && v.IP.String() == ip {
+				return intf.Name
+			}
+		}
+	}
+
+	t.Errorf("unable to find the interface of %s", ip)
+	return ""
+}
+
 func mediaURL(t *testing.T, baseURL *base.URL, media *description.Media) *base.URL {
 	u, err := media.URL(baseURL)
 	require.NoError(t, err)
@@ -1522,7 +1569,7 @@ func TestServerPlayRTCPReport(t *testing.T) {
 			require.Equal(t, []rtcp.Packet{
 				&rtcp.SenderReport{
 					SSRC:        packets[0].(*rtcp.SenderReport).SSRC,
-					NTPTime:     ntpTimeGoToRTCP(time.Date(2017, 8, 10, 12, 22, 30, 0, time.UTC)),
+					NTPTime:     ntp.Encode(time.Date(2017, 8, 10, 12, 22, 30, 0, time.UTC)),
 					RTPTime:     240000 + 90000*30,
 					PacketCount: 1,
 					OctetCount:  1,
@@ -1764,6 +1811,233 @@ func TestServerPlayPause(t *testing.T) {
 	doPause(t, conn, "rtsp://localhost:8554/teststream", session)
 }
 
+func TestServerPlayScaleSpeed(t *testing.T) {
+	for _, ca := range []string{
+		"valid",
+		"invalid scale",
+		"invalid speed",
+	} {
+		t.Run(ca, func(t *testing.T) {
+			var stream *ServerStream
+			var playCtx *ServerHandlerOnPlayCtx
+			nconnClosed := make(chan struct{})
+
+			s := &Server{
+				Handler: &testServerHandler{
+					onConnClose: func(ctx *ServerHandlerOnConnCloseCtx) {
+						if ca != "valid" {
+							require.EqualError(t, ctx.Error, map[string]string{
+								"invalid scale": "invalid scale header: invalid scale (0)",
+								"invalid speed": "invalid speed header: invalid speed (-1)",
+							}[ca])
+						}
+						close(nconnClosed)
+					},
+					onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+						playCtx = ctx
+						return &base.Response{
+							StatusCode: base.StatusOK,
+							Header: base.Header{
+								"Scale": ctx.Scale.Marshal(),
+							},
+						}, nil
+					},
+				},
+				RTSPAddress: "localhost:8554",
+			}
+
+			err := s.Start()
+			require.NoError(t, err)
+			defer s.Close()
+
+			stream = &ServerStream{
+				Server: s,
+				Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+			}
+			err = stream.Initialize()
+			require.NoError(t, err)
+			defer stream.Close()
+
+			nconn, err := net.Dial("tcp", "localhost:8554")
+			require.NoError(t, err)
+			defer nconn.Close()
+			conn := conn.NewConn(nconn)
+
+			desc := doDescribe(t, conn, false)
+
+			inTH := &headers.Transport{
+				Protocol:       headers.TransportProtocolTCP,
+				Delivery:       deliveryPtr(headers.TransportDeliveryUnicast),
+				Mode:           transportModePtr(headers.TransportModePlay),
+				InterleavedIDs: &[2]int{0, 1},
+			}
+
+			res, _ := doSetup(t, conn, mediaURL(t, desc.BaseURL, desc.Medias[0]).String(), inTH, "")
+
+			session := readSession(t, res)
+
+			header := base.Header{
+				"CSeq":    base.HeaderValue{"1"},
+				"Session": base.HeaderValue{session},
+			}
+
+			switch ca {
+			case "valid":
+				header["Scale"] = base.HeaderValue{"-4"}
+				header["Speed"] = base.HeaderValue{"2"}
+
+			case "invalid scale":
+				header["Scale"] = base.HeaderValue{"0"}
+
+			case "invalid speed":
+				header["Speed"] = base.HeaderValue{"-1"}
+			}
+
+			res, err = writeReqReadRes(conn, base.Request{
+				Method: base.Play,
+				URL:    mustParseURL("rtsp://localhost:8554/teststream"),
+				Header: header,
+			})
+			require.NoError(t, err)
+
+			if ca != "valid" {
+				require.Equal(t, base.StatusBadRequest, res.StatusCode)
+				nconn.Close()
+				<-nconnClosed
+				return
+			}
+
+			require.Equal(t, base.StatusOK, res.StatusCode)
+			require.Equal(t, base.HeaderValue{"-4"}, res.Header["Scale"])
+
+			scale := headers.Scale(-4)
+			speed := headers.Speed(2)
+			require.Equal(t, &scale, playCtx.Scale)
+			require.Equal(t, &speed, playCtx.Speed)
+		})
+	}
+}
+
+func TestServerPlayReplay(t *testing.T) {
+	var stream *ServerStream
+	var playCtx *ServerHandlerOnPlayCtx
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				playCtx = ctx
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		RTSPAddress: "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server:          s,
+		Desc:            &description.Session{Medias: []*description.Media{testH264Media}},
+		ReplayExtension: true,
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	// each reader receives the CSeq of its own PLAY request
+	cseqs := []int{261, 12}
+	conns := make([]*conn.Conn, len(cseqs))
+
+	for i, cseq := range cseqs {
+		nconn, err2 := net.Dial("tcp", "localhost:8554")
+		require.NoError(t, err2)
+		defer nconn.Close()
+		conns[i] = conn.NewConn(nconn)
+
+		desc := doDescribe(t, conns[i], false)
+
+		inTH := &headers.Transport{
+			Protocol:       headers.TransportProtocolTCP,
+			Delivery:       deliveryPtr(headers.TransportDeliveryUnicast),
+			Mode:           transportModePtr(headers.TransportModePlay),
+			InterleavedIDs: &[2]int{0, 1},
+		}
+
+		res, _ := doSetup(t, conns[i], mediaURL(t, desc.BaseURL, desc.Medias[0]).String(), inTH, "")
+
+		session := readSession(t, res)
+
+		res, err2 = writeReqReadRes(conns[i], base.Request{
+			Method: base.Play,
+			URL:    mustParseURL("rtsp://localhost:8554/teststream"),
+			Header: base.Header{
+				"CSeq":         base.HeaderValue{strconv.Itoa(cseq)},
+				"Session":      base.HeaderValue{session},
+				"Require":      base.HeaderValue{"onvif-replay"},
+				"Rate-Control": base.HeaderValue{"no"},
+				"Frames":       base.HeaderValue{"intra/2000"},
+				"Immediate":    base.HeaderValue{"yes"},
+			},
+		})
+		require.NoError(t, err2)
+		require.Equal(t, base.StatusOK, res.StatusCode)
+	}
+
+	rateControl := headers.RateControl(false)
+	immediate := headers.Immediate(true)
+	interval := 2 * time.Second
+	require.Equal(t, &rateControl, playCtx.RateControl)
+	require.Equal(t, &headers.Frames{Type: headers.FramesTypeIntra, Interval: &interval}, playCtx.Frames)
+	require.Equal(t, &immediate, playCtx.Immediate)
+
+	ntpTime := time.Date(2023, 11, 4, 10, 30, 40, 0, time.UTC)
+
+	pkt := testRTPPacket
+	err = stream.WritePacketRTPWithNTP(stream.Description().Medias[0], &pkt, ntpTime)
+	require.NoError(t, err)
+
+	for i, cseq := range cseqs {
+		f, err2 := conns[i].ReadInterleavedFrame()
+		require.NoError(t, err2)
+		require.Equal(t, 0, f.Channel)
+
+		var recv rtp.Packet
+		err2 = recv.Unmarshal(f.Payload)
+		require.NoError(t, err2)
+		require.Equal(t, testRTPPacket.Payload, recv.Payload)
+
+		var e onvif.ReplayExtension
+		err2 = e.Unmarshal(&recv.Header)
+		require.NoError(t, err2)
+		require.True(t, ntpTime.Equal(e.NTP))
+		require.True(t, e.CleanPoint)
+		require.Equal(t, uint8(cseq&0xFF), e.CSeq)
+	}
+}
+
 func TestServerPlayPlayPausePausePlay(t *testing.T) {
 	for _, ca := range []string{"stream", "direct"} {
 		t.Run(ca, func(t *testing.T) {
@@ -2719,3 +2993,1190 @@ func TestServerPlayBackChannel(t *testing.T) {
 		})
 	}
 }
+
+func TestServerPlayRetransmission(t *testing.T) {
+	var stream *ServerStream
+	sessionCreated := make(chan *ServerSession, 1)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				sessionCreated <- ctx.Session
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPRTPAddress:  "127.0.0.1:8000",
+		UDPRTCPAddress: "127.0.0.1:8001",
+		RTSPAddress:    "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server: s,
+		Desc: &description.Session{Medias: []*description.Media{{
+			Type: description.MediaTypeVideo,
+			Formats: []format.Format{
+				testH264Media.Formats[0],
+				&format.RTX{
+					PayloadTyp: 97,
+					ClockRat:   90000,
+					APT:        96,
+				},
+			},
+		}}},
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	c := Client{
+		Transport: transportPtr(TransportUDP),
+	}
+
+	err = c.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer c.Close()
+
+	desc, _, err := c.Describe(mustParseURL("rtsp://localhost:8554/teststream"))
+	require.NoError(t, err)
+
+	err = c.SetupAll(desc.BaseURL, desc.Medias)
+	require.NoError(t, err)
+
+	recv := make(chan *rtp.Packet, 3)
+
+	c.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+		recv <- pkt
+	})
+
+	_, err = c.Play(nil)
+	require.NoError(t, err)
+
+	ss := <-sessionCreated
+
+	writePacket := func(seqNum uint16) {
+		pkt := testRTPPacket
+		pkt.SequenceNumber = seqNum
+		err2 := stream.WritePacketRTP(stream.Description().Medias[0], &pkt)
+		require.NoError(t, err2)
+	}
+
+	writePacket(100)
+
+	require.Equal(t, uint16(100), (<-recv).SequenceNumber)
+
+	// simulate the loss of a packet by adding it to the
+	// retransmission buffer without sending it.
+	sf := stream.medias[stream.Description().Medias[0]].formats[96]
+	lost := testRTPPacket
+	lost.SequenceNumber = 101
+	lost.SSRC = sf.localSSRC
+	sf.rtxBuffer.Add(&lost, time.Now())
+
+	writePacket(102)
+
+	for _, seqNum := range []uint16{101, 102} {
+		pkt := <-recv
+		require.Equal(t, seqNum, pkt.SequenceNumber)
+		require.Equal(t, uint8(96), pkt.PayloadType)
+		require.Equal(t, sf.localSSRC, pkt.SSRC)
+		require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+	}
+
+	clientStats := c.Stats().Session.Medias[desc.Medias[0]].Formats[desc.Medias[0].Formats[0]]
+	require.Equal(t, uint64(1), clientStats.RTPPacketsNACKed)
+	require.Equal(t, uint64(1), clientStats.RTPPacketsRetransmittedReceived)
+	require.Equal(t, uint64(0), clientStats.RTPPacketsLost)
+
+	serverStats := ss.Stats().Medias[stream.Description().Medias[0]].Formats[stream.Description().Medias[0].Formats[0]]
+	require.Equal(t, uint64(1), serverStats.RTPPacketsNACKed)
+	require.Equal(t, uint64(1), serverStats.RTPPacketsRetransmitted)
+}
+
+func TestServerPlayFEC(t *testing.T) {
+	var stream *ServerStream
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPRTPAddress:  "127.0.0.1:8000",
+		UDPRTCPAddress: "127.0.0.1:8001",
+		RTSPAddress:    "localhost:8554",
+		FECGroupSize:   2,
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server: s,
+		Desc: &description.Session{
+			FECGroups: []description.SessionFECGroup{{"1", "2"}},
+			Medias: []*description.Media{
+				{
+					ID:      "1",
+					Type:    description.MediaTypeVideo,
+					Formats: []format.Format{testH264Media.Formats[0]},
+				},
+				{
+					ID:   "2",
+					Type: description.MediaTypeApplication,
+					Formats: []format.Format{&format.ULPFEC{
+						PayloadTyp: 100,
+						ClockRat:   90000,
+					}},
+				},
+			},
+		},
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	c := Client{
+		Transport: transportPtr(TransportUDP),
+	}
+
+	err = c.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer c.Close()
+
+	desc, _, err := c.Describe(mustParseURL("rtsp://localhost:8554/teststream"))
+	require.NoError(t, err)
+	require.Equal(t, []description.SessionFECGroup{{"1", "2"}}, desc.FECGroups)
+
+	err = c.SetupAll(desc.BaseURL, desc.Medias)
+	require.NoError(t, err)
+
+	recv := make(chan *rtp.Packet, 3)
+
+	c.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+		recv <- pkt
+	})
+
+	_, err = c.Play(nil)
+	require.NoError(t, err)
+
+	medi := stream.Description().Medias[0]
+
+	writePacket := func(seqNum uint16) {
+		pkt := testRTPPacket
+		pkt.SequenceNumber = seqNum
+		err2 := stream.WritePacketRTP(medi, &pkt)
+		require.NoError(t, err2)
+	}
+
+	writePacket(100)
+
+	require.Equal(t, uint16(100), (<-recv).SequenceNumber)
+
+	// simulate the loss of a packet by passing it to the
+	// FEC encoder without sending it.
+	sf := stream.medias[medi].formats[96]
+	lost := testRTPPacket
+	lost.SequenceNumber = 101
+	lost.SSRC = sf.localSSRC
+
+	fecPkt, err := sf.fecEncoder.Encode(&lost)
+	require.NoError(t, err)
+	require.NotNil(t, fecPkt)
+
+	stream.mutex.RLock()
+	err = stream.medias[stream.Description().Medias[1]].formats[100].writePacketRTP(fecPkt, time.Now())
+	stream.mutex.RUnlock()
+	require.NoError(t, err)
+
+	// wait for the FEC packet to be received
+	time.Sleep(100 * time.Millisecond)
+
+	writePacket(102)
+
+	for _, seqNum := range []uint16{101, 102} {
+		pkt := <-recv
+		require.Equal(t, seqNum, pkt.SequenceNumber)
+		require.Equal(t, uint8(96), pkt.PayloadType)
+		require.Equal(t, sf.localSSRC, pkt.SSRC)
+		require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+	}
+
+	stats := c.Stats().Session.Medias[desc.Medias[0]].Formats[desc.Medias[0].Formats[0]]
+	require.Equal(t, uint64(1), stats.RTPPacketsRecovered)
+	require.Equal(t, uint64(0), stats.RTPPacketsLost)
+}
+
+func TestServerPlayRTCPMux(t *testing.T) {
+	for _, ca := range []string{
+		"multiplexed",
+		"fallback",
+	} {
+		t.Run(ca, func(t *testing.T) {
+			var stream *ServerStream
+			serverRecvRTCP := make(chan rtcp.Packet, 1)
+
+			s := &Server{
+				Handler: &testServerHandler{
+					onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+						// the client offers multiplexing only when the media advertises it
+						var th headers.Transport
+						err := th.Unmarshal(ctx.Request.Header["Transport"])
+						require.NoError(t, err)
+						require.Equal(t, ca == "multiplexed", th.RTCPMux)
+
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+						ctx.Session.OnPacketRTCPAny(func(_ *description.Media, pkt rtcp.Packet) {
+							if _, ok := pkt.(*rtcp.PictureLossIndication); ok {
+								serverRecvRTCP <- pkt
+							}
+						})
+
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, nil
+					},
+				},
+				UDPRTPAddress:  "127.0.0.1:8000",
+				UDPRTCPAddress: "127.0.0.1:8001",
+				RTSPAddress:    "localhost:8554",
+				RTCPMux:        ca == "multiplexed",
+			}
+
+			err := s.Start()
+			require.NoError(t, err)
+			defer s.Close()
+
+			stream = &ServerStream{
+				Server: s,
+				Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+			}
+			err = stream.Initialize()
+			require.NoError(t, err)
+			defer stream.Close()
+
+			c := Client{
+				Transport: transportPtr(TransportUDP),
+				RTCPMux:   true,
+			}
+
+			err = c.Start("rtsp", "localhost:8554")
+			require.NoError(t, err)
+			defer c.Close()
+
+			desc, _, err := c.Describe(mustParseURL("rtsp://localhost:8554/teststream"))
+			require.NoError(t, err)
+			require.Equal(t, ca == "multiplexed", desc.Medias[0].RTCPMux)
+
+			err = c.SetupAll(desc.BaseURL, desc.Medias)
+			require.NoError(t, err)
+
+			cm := c.setuppedMedias[desc.Medias[0]]
+			require.Equal(t, ca == "multiplexed", cm.rtcpMux)
+			require.Equal(t, ca == "multiplexed", cm.udpRTCPListener == cm.udpRTPListener)
+
+			recvRTP := make(chan *rtp.Packet, 1)
+			recvRTCP := make(chan rtcp.Packet, 1)
+
+			c.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+				recvRTP <- pkt
+			})
+
+			c.OnPacketRTCP(desc.Medias[0], func(pkt rtcp.Packet) {
+				if _, ok := pkt.(*rtcp.PictureLossIndication); ok {
+					recvRTCP <- pkt
+				}
+			})
+
+			_, err = c.Play(nil)
+			require.NoError(t, err)
+
+			err = stream.WritePacketRTP(stream.Description().Medias[0], &testRTPPacket)
+			require.NoError(t, err)
+
+			pkt := <-recvRTP
+			require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+
+			err = stream.WritePacketRTCP(stream.Description().Medias[0], &rtcp.PictureLossIndication{
+				MediaSSRC: 1234,
+			})
+			require.NoError(t, err)
+
+			require.Equal(t, &rtcp.PictureLossIndication{MediaSSRC: 1234}, <-recvRTCP)
+
+			err = c.WritePacketRTCP(desc.Medias[0], &rtcp.PictureLossIndication{
+				MediaSSRC: 4321,
+			})
+			require.NoError(t, err)
+
+			require.Equal(t, &rtcp.PictureLossIndication{MediaSSRC: 4321}, <-serverRecvRTCP)
+		})
+	}
+}
+
+func TestServerPlayMulticastRanges(t *testing.T) {
+	for _, ca := range []struct {
+		name    string
+		ipRange string
+		ipv6    bool
+	}{
+		{
+			"ipv4 ssm",
+			"232.1.0.0/16",
+			false,
+		},
+		{
+			"ipv6",
+			"ff15::1:0/112",
+			true,
+		},
+		{
+			"ipv6 ssm",
+			"ff35::1:0/112",
+			true,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			var stream *ServerStream
+
+			listenIP := multicastCapableIP(t)
+			if ca.ipv6 {
+				listenIP = multicastCapableIPv6(t)
+			}
+
+			s := &Server{
+				Handler: &testServerHandler{
+					onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, nil
+					},
+				},
+				RTSPAddress:        net.JoinHostPort(listenIP, "8554"),
+				MulticastIPRange:   ca.ipRange,
+				MulticastRTPPort:   8000,
+				MulticastRTCPPort:  8001,
+				MulticastInterface: interfaceOfIP(t, listenIP),
+			}
+
+			err := s.Start()
+			require.NoError(t, err)
+			defer s.Close()
+
+			stream = &ServerStream{
+				Server: s,
+				Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+			}
+			err = stream.Initialize()
+			require.NoError(t, err)
+			defer stream.Close()
+
+			c := Client{
+				Transport: transportPtr(TransportUDPMulticast),
+			}
+
+			err = c.Start("rtsp", net.JoinHostPort(listenIP, "8554"))
+			require.NoError(t, err)
+			defer c.Close()
+
+			desc, _, err := c.Describe(mustParseURL("rtsp://" + net.JoinHostPort(listenIP, "8554") + "/teststream"))
+			require.NoError(t, err)
+
+			res, err := c.Setup(desc.BaseURL, desc.Medias[0], 0, 0)
+			require.NoError(t, err)
+
+			var th headers.Transport
+			err = th.Unmarshal(res.Header["Transport"])
+			require.NoError(t, err)
+
+			_, ipNet, _ := net.ParseCIDR(ca.ipRange)
+			require.True(t, ipNet.Contains(*th.Destination))
+
+			if multicast.IsSSM(*th.Destination) {
+				require.Equal(t, listenIP, th.Source.String())
+			} else {
+				require.Nil(t, th.Source)
+			}
+
+			recv := make(chan *rtp.Packet, 1)
+
+			c.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+				recv <- pkt
+			})
+
+			_, err = c.Play(nil)
+			require.NoError(t, err)
+
+			err = stream.WritePacketRTP(stream.Description().Medias[0], &testRTPPacket)
+			require.NoError(t, err)
+
+			pkt := <-recv
+			require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+		})
+	}
+}
+
+func TestServerPlayMulticastRequest(t *testing.T) {
+	for _, ca := range []string{
+		"accepted",
+		"rejected",
+		"out of range",
+		"in use",
+	} {
+		t.Run(ca, func(t *testing.T) {
+			var stream *ServerStream
+
+			listenIP := multicastCapableIP(t)
+
+			s := &Server{
+				Handler: &testServerHandler{
+					onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+						if ctx.MulticastDestination != nil {
+							require.Equal(t, "224.1.0.55", ctx.MulticastDestination.String())
+							require.Equal(t, &[2]int{8002, 8003}, ctx.MulticastPorts)
+							require.Equal(t, uint(8), *ctx.MulticastTTL)
+
+							if ca == "rejected" {
+								return &base.Response{
+									StatusCode: base.StatusUnsupportedTransport,
+								}, nil, nil
+							}
+						}
+
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, stream, nil
+					},
+					onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+						return &base.Response{
+							StatusCode: base.StatusOK,
+						}, nil
+					},
+				},
+				RTSPAddress:       net.JoinHostPort(listenIP, "8554"),
+				MulticastIPRange:  "224.1.0.0/16",
+				MulticastRTPPort:  8000,
+				MulticastRTCPPort: 8001,
+			}
+
+			err := s.Start()
+			require.NoError(t, err)
+			defer s.Close()
+
+			stream = &ServerStream{
+				Server: s,
+				Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+			}
+			err = stream.Initialize()
+			require.NoError(t, err)
+			defer stream.Close()
+
+			if ca == "in use" {
+				c2 := Client{
+					Transport: transportPtr(TransportUDPMulticast),
+				}
+
+				err = c2.Start("rtsp", net.JoinHostPort(listenIP, "8554"))
+				require.NoError(t, err)
+				defer c2.Close()
+
+				var desc *description.Session
+				desc, _, err = c2.Describe(mustParseURL("rtsp://" + net.JoinHostPort(listenIP, "8554") + "/teststream"))
+				require.NoError(t, err)
+
+				// the media is already sent to a group chosen by the server.
+				_, err = c2.Setup(desc.BaseURL, desc.Medias[0], 0, 0)
+				require.NoError(t, err)
+			}
+
+			c := Client{
+				Transport: transportPtr(TransportUDPMulticast),
+			}
+
+			err = c.Start("rtsp", net.JoinHostPort(listenIP, "8554"))
+			require.NoError(t, err)
+			defer c.Close()
+
+			desc, _, err := c.Describe(mustParseURL("rtsp://" + net.JoinHostPort(listenIP, "8554") + "/teststream"))
+			require.NoError(t, err)
+
+			destination := net.ParseIP("224.1.0.55")
+			if ca == "out of range" {
+				destination = net.ParseIP("224.2.0.55")
+			}
+
+			res, err := c.SetupWithOptions(desc.BaseURL, desc.Medias[0], &ClientSetupOptions{
+				MulticastDestination: &destination,
+				MulticastPorts:       &[2]int{8002, 8003},
+				MulticastTTL:         uintPtr(8),
+			})
+
+			if ca != "accepted" {
+				require.EqualError(t, err, "bad status code: 461 (Unsupported Transport)")
+				return
+			}
+			require.NoError(t, err)
+
+			var th headers.Transport
+			err = th.Unmarshal(res.Header["Transport"])
+			require.NoError(t, err)
+			require.Equal(t, "224.1.0.55", th.Destination.String())
+			require.Equal(t, &[2]int{8002, 8003}, th.Ports)
+			require.Equal(t, uint(8), *th.TTL)
+
+			recv := make(chan *rtp.Packet, 1)
+
+			c.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+				recv <- pkt
+			})
+
+			_, err = c.Play(nil)
+			require.NoError(t, err)
+
+			err = stream.WritePacketRTP(stream.Description().Medias[0], &testRTPPacket)
+			require.NoError(t, err)
+
+			pkt := <-recv
+			require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+		})
+	}
+}
+
+func TestServerPlayUDPSessionPorts(t *testing.T) {
+	var stream *ServerStream
+	serverRecvRTCP := make(chan rtcp.Packet, 2)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				ctx.Session.OnPacketRTCPAny(func(_ *description.Media, pkt rtcp.Packet) {
+					if _, ok := pkt.(*rtcp.PictureLossIndication); ok {
+						serverRecvRTCP <- pkt
+					}
+				})
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPSessionPortMin: 8100,
+		UDPSessionPortMax: 8103,
+		RTSPAddress:       "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server: s,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	recvRTP := make(chan *rtp.Packet, 2)
+
+	for i := 0; i < 3; i++ {
+		c := Client{
+			Transport: transportPtr(TransportUDP),
+		}
+
+		err = c.Start("rtsp", "localhost:8554")
+		require.NoError(t, err)
+		defer c.Close()
+
+		var desc *description.Session
+		desc, _, err = c.Describe(mustParseURL("rtsp://localhost:8554/teststream"))
+		require.NoError(t, err)
+
+		var res *base.Response
+		res, err = c.Setup(desc.BaseURL, desc.Medias[0], 0, 0)
+
+		// the range contains two port pairs only.
+		if i == 2 {
+			require.EqualError(t, err, "bad status code: 461 (Unsupported Transport)")
+			break
+		}
+		require.NoError(t, err)
+
+		var th headers.Transport
+		err = th.Unmarshal(res.Header["Transport"])
+		require.NoError(t, err)
+		require.Equal(t, &[2]int{8100 + i*2, 8101 + i*2}, th.ServerPorts)
+
+		c.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+			recvRTP <- pkt
+		})
+
+		_, err = c.Play(nil)
+		require.NoError(t, err)
+
+		err = c.WritePacketRTCP(desc.Medias[0], &rtcp.PictureLossIndication{
+			MediaSSRC: uint32(i),
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, &rtcp.PictureLossIndication{MediaSSRC: uint32(i)}, <-serverRecvRTCP)
+	}
+
+	err = stream.WritePacketRTP(stream.Description().Medias[0], &testRTPPacket)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		pkt := <-recvRTP
+		require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+	}
+}
+
+func TestServerPlayUDPSymmetricRTP(t *testing.T) {
+	var stream *ServerStream
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPSessionPortMin: 8100,
+		UDPSessionPortMax: 8103,
+		UDPSymmetricRTP:   true,
+		RTSPAddress:       "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server: s,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	// two sessions are created by the same IP, and each one must
+	// learn its own address.
+	var l1s [2]net.PacketConn
+
+	for i := 0; i < 2; i++ {
+		nconn, err2 := net.Dial("tcp", "localhost:8554")
+		require.NoError(t, err2)
+		defer nconn.Close()
+		conn := conn.NewConn(nconn)
+
+		desc := doDescribe(t, conn, false)
+
+		// clients advertise ports that differ from the ones they send from,
+		// like clients behind a NAT that rewrites ports.
+		inTH := &headers.Transport{
+			Mode:        transportModePtr(headers.TransportModePlay),
+			Delivery:    deliveryPtr(headers.TransportDeliveryUnicast),
+			Protocol:    headers.TransportProtocolUDP,
+			ClientPorts: &[2]int{35466 + i*4, 35467 + i*4},
+		}
+
+		res, resTH := doSetup(t, conn, mediaURL(t, desc.BaseURL, desc.Medias[0]).String(), inTH, "")
+
+		l1s[i], err2 = net.ListenPacket("udp", "127.0.0.1:"+strconv.FormatInt(int64(35468+i*4), 10))
+		require.NoError(t, err2)
+		defer l1s[i].Close()
+
+		l2, err2 := net.ListenPacket("udp", "127.0.0.1:"+strconv.FormatInt(int64(35469+i*4), 10))
+		require.NoError(t, err2)
+		defer l2.Close()
+
+		buf, err2 := (&rtp.Packet{Header: rtp.Header{Version: 2}}).Marshal()
+		require.NoError(t, err2)
+
+		_, err2 = l1s[i].WriteTo(buf, &net.UDPAddr{
+			IP:   net.ParseIP("127.0.0.1"),
+			Port: resTH.ServerPorts[0],
+		})
+		require.NoError(t, err2)
+
+		buf, err2 = (&rtcp.ReceiverReport{}).Marshal()
+		require.NoError(t, err2)
+
+		_, err2 = l2.WriteTo(buf, &net.UDPAddr{
+			IP:   net.ParseIP("127.0.0.1"),
+			Port: resTH.ServerPorts[1],
+		})
+		require.NoError(t, err2)
+
+		session := readSession(t, res)
+
+		doPlay(t, conn, "rtsp://localhost:8554/teststream", session)
+	}
+
+	// wait for the server to process the packets
+	time.Sleep(100 * time.Millisecond)
+
+	err = stream.WritePacketRTP(stream.Description().Medias[0], &testRTPPacket)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		buf := make([]byte, 2048)
+		n, _, err2 := l1s[i].ReadFrom(buf)
+		require.NoError(t, err2)
+
+		var pkt rtp.Packet
+		err2 = pkt.Unmarshal(buf[:n])
+		require.NoError(t, err2)
+		require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+	}
+}
+
+func TestServerPlayUDPClientKeepAlive(t *testing.T) {
+	var stream *ServerStream
+	serverRecvRTCP := make(chan rtcp.Packet, 10)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				ctx.Session.OnPacketRTCPAny(func(_ *description.Media, pkt rtcp.Packet) {
+					if rr, ok := pkt.(*rtcp.ReceiverReport); ok && len(rr.Reports) == 0 {
+						select {
+						case serverRecvRTCP <- pkt:
+						default:
+						}
+					}
+				})
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPSessionPortMin: 8100,
+		UDPSessionPortMax: 8101,
+		UDPSymmetricRTP:   true,
+		RTSPAddress:       "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server: s,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	c := Client{
+		Transport:          transportPtr(TransportUDP),
+		UDPHolePunching:    true,
+		UDPKeepAlivePeriod: 100 * time.Millisecond,
+	}
+
+	err = readAll(&c, "rtsp://localhost:8554/teststream", nil)
+	require.NoError(t, err)
+	defer c.Close()
+
+	// keep-alives are sent periodically until the session is closed.
+	for i := 0; i < 3; i++ {
+		<-serverRecvRTCP
+	}
+}
+
+func TestServerPlayUDPBatchIO(t *testing.T) {
+	var stream *ServerStream
+	serverRecvRTCP := make(chan rtcp.Packet, 1)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(ctx *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				ctx.Session.OnPacketRTCPAny(func(_ *description.Media, pkt rtcp.Packet) {
+					if _, ok := pkt.(*rtcp.PictureLossIndication); ok {
+						serverRecvRTCP <- pkt
+					}
+				})
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPRTPAddress:  "127.0.0.1:8000",
+		UDPRTCPAddress: "127.0.0.1:8001",
+		udpBatchIO:     true,
+		RTSPAddress:    "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server: s,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	recvRTP := make(chan *rtp.Packet, 10)
+
+	c := Client{
+		Transport:  transportPtr(TransportUDP),
+		udpBatchIO: true,
+	}
+
+	err = c.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer c.Close()
+
+	desc, _, err := c.Describe(mustParseURL("rtsp://localhost:8554/teststream"))
+	require.NoError(t, err)
+
+	err = c.SetupAll(desc.BaseURL, desc.Medias)
+	require.NoError(t, err)
+
+	c.OnPacketRTP(desc.Medias[0], desc.Medias[0].Formats[0], func(pkt *rtp.Packet) {
+		recvRTP <- pkt
+	})
+
+	_, err = c.Play(nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		pkt := testRTPPacket
+		pkt.SequenceNumber += uint16(i)
+		err = stream.WritePacketRTP(stream.Description().Medias[0], &pkt)
+		require.NoError(t, err)
+	}
+
+	for i := 0; i < 10; i++ {
+		pkt := <-recvRTP
+		require.Equal(t, testRTPPacket.SequenceNumber+uint16(i), pkt.SequenceNumber)
+		require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+	}
+
+	err = c.WritePacketRTCP(desc.Medias[0], &rtcp.PictureLossIndication{
+		MediaSSRC: 1234,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, &rtcp.PictureLossIndication{MediaSSRC: 1234}, <-serverRecvRTCP)
+}
+
+func TestServerPlayGOPCache(t *testing.T) {
+	var stream *ServerStream
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		RTSPAddress: "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server:   s,
+		Desc:     &description.Session{Medias: []*description.Media{testH264Media}},
+		GOPCache: true,
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	for _, pkt := range []*rtp.Packet{
+		{ // previous GOP
+			Header:  rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: 10, Timestamp: 0},
+			Payload: []byte{0x65, 0x01},
+		},
+		{ // parameters of the next GOP
+			Header:  rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: 11, Timestamp: 1000},
+			Payload: []byte{0x67, 0x02},
+		},
+		{
+			Header:  rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: 12, Timestamp: 1000, Marker: true},
+			Payload: []byte{0x65, 0x03},
+		},
+		{ // after a lost packet
+			Header:  rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: 14, Timestamp: 4000, Marker: true},
+			Payload: []byte{0x41, 0x04},
+		},
+	} {
+		err = stream.WritePacketRTP(testH264Media, pkt)
+		require.NoError(t, err)
+	}
+
+	nconn, err := net.Dial("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn.Close()
+	conn := conn.NewConn(nconn)
+
+	desc := doDescribe(t, conn, false)
+
+	inTH := &headers.Transport{
+		Delivery:       deliveryPtr(headers.TransportDeliveryUnicast),
+		Mode:           transportModePtr(headers.TransportModePlay),
+		Protocol:       headers.TransportProtocolTCP,
+		InterleavedIDs: &[2]int{0, 1},
+	}
+
+	res, _ := doSetup(t, conn, mediaURL(t, desc.BaseURL, desc.Medias[0]).String(), inTH, "")
+
+	session := readSession(t, res)
+
+	res = doPlay(t, conn, "rtsp://localhost:8554/teststream", session)
+
+	var ri headers.RTPInfo
+	err = ri.Unmarshal(res.Header["RTP-Info"])
+	require.NoError(t, err)
+	require.Equal(t, uint16(12), *ri[0].SequenceNumber)
+	require.Equal(t, uint32(1000), *ri[0].Timestamp)
+
+	// the cache is sent before live packets.
+	err = stream.WritePacketRTP(testH264Media, &rtp.Packet{
+		Header:  rtp.Header{Version: 2, PayloadType: 96, SequenceNumber: 15, Timestamp: 7000, Marker: true},
+		Payload: []byte{0x41, 0x05},
+	})
+	require.NoError(t, err)
+
+	for _, exp := range []struct {
+		seqNum    uint16
+		timestamp uint32
+		payload   []byte
+	}{
+		{12, 1000, []byte{0x67, 0x02}},
+		{13, 1000, []byte{0x65, 0x03}},
+		{14, 4000, []byte{0x41, 0x04}},
+		{15, 7000, []byte{0x41, 0x05}},
+	} {
+		var f *base.InterleavedFrame
+		f, err = conn.ReadInterleavedFrame()
+		require.NoError(t, err)
+
+		var pkt rtp.Packet
+		err = pkt.Unmarshal(f.Payload)
+		require.NoError(t, err)
+		require.Equal(t, exp.seqNum, pkt.SequenceNumber)
+		require.Equal(t, exp.timestamp, pkt.Timestamp)
+		require.Equal(t, exp.payload, pkt.Payload)
+	}
+}
+
+func TestServerPlayMulticastGroupReservation(t *testing.T) {
+	streams := make(map[string]*ServerStream)
+	sessionClosed := make(chan struct{}, 3)
+
+	listenIP := multicastCapableIP(t)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onSessionClose: func(_ *ServerHandlerOnSessionCloseCtx) {
+				sessionClosed <- struct{}{}
+			},
+			onDescribe: func(ctx *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, streams[ctx.Path], nil
+			},
+			onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, streams[ctx.Path], nil
+			},
+		},
+		RTSPAddress:       net.JoinHostPort(listenIP, "8554"),
+		MulticastIPRange:  "224.1.0.0/16",
+		MulticastRTPPort:  8000,
+		MulticastRTCPPort: 8001,
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	for _, path := range []string{"/stream1", "/stream2"} {
+		stream := &ServerStream{
+			Server: s,
+			Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+		}
+		err = stream.Initialize()
+		require.NoError(t, err)
+		defer stream.Close()
+
+		streams[path] = stream
+	}
+
+	setup := func(path string, options *ClientSetupOptions) (*Client, *headers.Transport, error) {
+		c := &Client{
+			Transport: transportPtr(TransportUDPMulticast),
+		}
+
+		err2 := c.Start("rtsp", net.JoinHostPort(listenIP, "8554"))
+		require.NoError(t, err2)
+
+		desc, _, err2 := c.Describe(mustParseURL("rtsp://" + net.JoinHostPort(listenIP, "8554") + path))
+		require.NoError(t, err2)
+
+		res, err2 := c.SetupWithOptions(desc.BaseURL, desc.Medias[0], options)
+		if err2 != nil {
+			c.Close()
+			return nil, nil, err2
+		}
+
+		var th headers.Transport
+		err2 = th.Unmarshal(res.Header["Transport"])
+		require.NoError(t, err2)
+
+		return c, &th, nil
+	}
+
+	// group requested by a client, that is also the next one of the pool.
+	destination := net.ParseIP("224.1.0.1")
+	c1, th, err := setup("/stream1", &ClientSetupOptions{
+		MulticastDestination: &destination,
+		MulticastPorts:       &[2]int{8000, 8001},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "224.1.0.1", th.Destination.String())
+
+	// the pool skips groups that are in use.
+	c2, th, err := setup("/stream2", &ClientSetupOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "224.1.0.2", th.Destination.String())
+	require.Equal(t, &[2]int{8000, 8001}, th.Ports)
+	c2.Close()
+
+	// groups requested by clients cannot be used by other streams.
+	_, _, err = setup("/stream2", &ClientSetupOptions{
+		MulticastDestination: &destination,
+		MulticastPorts:       &[2]int{8000, 8001},
+	})
+	require.EqualError(t, err, "bad status code: 461 (Unsupported Transport)")
+
+	// groups are released when their readers are closed.
+	c1.Close()
+	<-sessionClosed
+	<-sessionClosed
+	<-sessionClosed
+
+	c3, th, err := setup("/stream2", &ClientSetupOptions{
+		MulticastDestination: &destination,
+		MulticastPorts:       &[2]int{8000, 8001},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "224.1.0.1", th.Destination.String())
+	c3.Close()
+}
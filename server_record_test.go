@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/rand"
 	"crypto/tls"
+	"fmt"
 	"net"
 	"strconv"
 	"testing"
@@ -14,6 +15,7 @@ import (
 	psdp "github.com/pion/sdp/v3"
 	"github.com/stretchr/testify/require"
 
+	"github.com/bluenviron/gortsplib/v4/internal/ntp"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
 	"github.com/bluenviron/gortsplib/v4/pkg/conn"
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
@@ -21,6 +23,7 @@ import (
 	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 	"github.com/bluenviron/gortsplib/v4/pkg/mikey"
 	"github.com/bluenviron/gortsplib/v4/pkg/sdp"
+	"github.com/bluenviron/gortsplib/v4/pkg/ulpfec"
 )
 
 func doAnnounce(t *testing.T, conn *conn.Conn, u string, medias []*description.Media) {
@@ -1079,7 +1082,7 @@ func TestServerRecordRTCPReport(t *testing.T) {
 
 	_, err = l2.WriteTo(mustMarshalPacketRTCP(&rtcp.SenderReport{
 		SSRC:        753621,
-		NTPTime:     ntpTimeGoToRTCP(time.Date(2018, 2, 20, 19, 0, 0, 0, time.UTC)),
+		NTPTime:     ntp.Encode(time.Date(2018, 2, 20, 19, 0, 0, 0, time.UTC)),
 		RTPTime:     54352,
 		PacketCount: 1,
 		OctetCount:  4,
@@ -1679,7 +1682,7 @@ func TestServerRecordPacketNTP(t *testing.T) {
 
 	_, err = l2.WriteTo(mustMarshalPacketRTCP(&rtcp.SenderReport{
 		SSRC:        753621,
-		NTPTime:     ntpTimeGoToRTCP(time.Date(2018, 2, 20, 19, 0, 0, 0, time.UTC)),
+		NTPTime:     ntp.Encode(time.Date(2018, 2, 20, 19, 0, 0, 0, time.UTC)),
 		RTPTime:     54352,
 		PacketCount: 1,
 		OctetCount:  4,
@@ -1775,3 +1778,574 @@ func TestServerRecordPausePause(t *testing.T) {
 
 	doPause(t, conn, "rtsp://localhost:8554/teststream", session)
 }
+
+func TestServerRecordRetransmission(t *testing.T) {
+	sessionCreated := make(chan *ServerSession, 1)
+	recv := make(chan *rtp.Packet, 3)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onAnnounce: func(_ *ServerHandlerOnAnnounceCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil, nil
+			},
+			onRecord: func(ctx *ServerHandlerOnRecordCtx) (*base.Response, error) {
+				ctx.Session.OnPacketRTP(
+					ctx.Session.AnnouncedDescription().Medias[0],
+					ctx.Session.AnnouncedDescription().Medias[0].Formats[0],
+					func(pkt *rtp.Packet) {
+						recv <- pkt
+					})
+
+				sessionCreated <- ctx.Session
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPRTPAddress:  "127.0.0.1:8000",
+		UDPRTCPAddress: "127.0.0.1:8001",
+		RTSPAddress:    "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	desc := &description.Session{Medias: []*description.Media{{
+		Type: description.MediaTypeVideo,
+		Formats: []format.Format{
+			testH264Media.Formats[0],
+			&format.RTX{
+				PayloadTyp: 97,
+				ClockRat:   90000,
+				APT:        96,
+			},
+		},
+	}}}
+
+	c := Client{
+		Transport: transportPtr(TransportUDP),
+	}
+
+	err = c.StartRecording("rtsp://localhost:8554/teststream", desc)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ss := <-sessionCreated
+
+	writePacket := func(seqNum uint16) {
+		pkt := testRTPPacket
+		pkt.SequenceNumber = seqNum
+		err2 := c.WritePacketRTP(desc.Medias[0], &pkt)
+		require.NoError(t, err2)
+	}
+
+	writePacket(100)
+
+	require.Equal(t, uint16(100), (<-recv).SequenceNumber)
+
+	// simulate the loss of a packet by adding it to the
+	// retransmission buffer without sending it.
+	cf := c.setuppedMedias[desc.Medias[0]].formats[96]
+	lost := testRTPPacket
+	lost.SequenceNumber = 101
+	lost.SSRC = cf.localSSRC
+	cf.rtxSender.Add(&lost, time.Now())
+
+	writePacket(102)
+
+	for _, seqNum := range []uint16{101, 102} {
+		pkt := <-recv
+		require.Equal(t, seqNum, pkt.SequenceNumber)
+		require.Equal(t, uint8(96), pkt.PayloadType)
+		require.Equal(t, cf.localSSRC, pkt.SSRC)
+		require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+	}
+
+	serverMedia := ss.AnnouncedDescription().Medias[0]
+	serverStats := ss.Stats().Medias[serverMedia].Formats[serverMedia.Formats[0]]
+	require.Equal(t, uint64(1), serverStats.RTPPacketsNACKed)
+	require.Equal(t, uint64(1), serverStats.RTPPacketsRetransmittedReceived)
+	require.Equal(t, uint64(0), serverStats.RTPPacketsLost)
+
+	clientStats := c.Stats().Session.Medias[desc.Medias[0]].Formats[desc.Medias[0].Formats[0]]
+	require.Equal(t, uint64(1), clientStats.RTPPacketsNACKed)
+	require.Equal(t, uint64(1), clientStats.RTPPacketsRetransmitted)
+}
+
+func TestServerRecordFEC(t *testing.T) {
+	sessionCreated := make(chan *ServerSession, 1)
+	recv := make(chan *rtp.Packet, 3)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onAnnounce: func(_ *ServerHandlerOnAnnounceCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil, nil
+			},
+			onRecord: func(ctx *ServerHandlerOnRecordCtx) (*base.Response, error) {
+				ctx.Session.OnPacketRTP(
+					ctx.Session.AnnouncedDescription().Medias[0],
+					ctx.Session.AnnouncedDescription().Medias[0].Formats[0],
+					func(pkt *rtp.Packet) {
+						recv <- pkt
+					})
+
+				sessionCreated <- ctx.Session
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPRTPAddress:  "127.0.0.1:8000",
+		UDPRTCPAddress: "127.0.0.1:8001",
+		RTSPAddress:    "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	desc := &description.Session{
+		FECGroups: []description.SessionFECGroup{{"1", "2"}},
+		Medias: []*description.Media{
+			{
+				ID:      "1",
+				Type:    description.MediaTypeVideo,
+				Formats: []format.Format{testH264Media.Formats[0]},
+			},
+			{
+				ID:   "2",
+				Type: description.MediaTypeApplication,
+				Formats: []format.Format{&format.ULPFEC{
+					PayloadTyp: 100,
+					ClockRat:   90000,
+				}},
+			},
+		},
+	}
+
+	c := Client{
+		Transport:    transportPtr(TransportUDP),
+		FECGroupSize: 2,
+	}
+
+	err = c.StartRecording("rtsp://localhost:8554/teststream", desc)
+	require.NoError(t, err)
+	defer c.Close()
+
+	ss := <-sessionCreated
+
+	writePacket := func(seqNum uint16) {
+		pkt := testRTPPacket
+		pkt.SequenceNumber = seqNum
+		err2 := c.WritePacketRTP(desc.Medias[0], &pkt)
+		require.NoError(t, err2)
+	}
+
+	writePacket(100)
+
+	require.Equal(t, uint16(100), (<-recv).SequenceNumber)
+
+	// simulate the loss of a packet by passing it to the
+	// FEC encoder without sending it.
+	cf := c.setuppedMedias[desc.Medias[0]].formats[96]
+	lost := testRTPPacket
+	lost.SequenceNumber = 101
+	lost.SSRC = cf.localSSRC
+
+	fecPkt, err := cf.fecEncoder.Encode(&lost)
+	require.NoError(t, err)
+	require.NotNil(t, fecPkt)
+
+	err = c.WritePacketRTP(desc.Medias[1], fecPkt)
+	require.NoError(t, err)
+
+	// wait for the FEC packet to be received
+	time.Sleep(100 * time.Millisecond)
+
+	writePacket(102)
+
+	for _, seqNum := range []uint16{101, 102} {
+		pkt := <-recv
+		require.Equal(t, seqNum, pkt.SequenceNumber)
+		require.Equal(t, uint8(96), pkt.PayloadType)
+		require.Equal(t, cf.localSSRC, pkt.SSRC)
+		require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+	}
+
+	serverMedia := ss.AnnouncedDescription().Medias[0]
+	stats := ss.Stats().Medias[serverMedia].Formats[serverMedia.Formats[0]]
+	require.Equal(t, uint64(1), stats.RTPPacketsRecovered)
+	require.Equal(t, uint64(0), stats.RTPPacketsLost)
+}
+
+func TestServerRecordFECInvalidGroupSize(t *testing.T) {
+	s := &Server{
+		Handler: &testServerHandler{
+			onAnnounce: func(_ *ServerHandlerOnAnnounceCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil, nil
+			},
+			onRecord: func(_ *ServerHandlerOnRecordCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPRTPAddress:  "127.0.0.1:8000",
+		UDPRTCPAddress: "127.0.0.1:8001",
+		RTSPAddress:    "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	desc := &description.Session{
+		FECGroups: []description.SessionFECGroup{{"1", "2"}},
+		Medias: []*description.Media{
+			{
+				ID:      "1",
+				Type:    description.MediaTypeVideo,
+				Formats: []format.Format{testH264Media.Formats[0]},
+			},
+			{
+				ID:   "2",
+				Type: description.MediaTypeApplication,
+				Formats: []format.Format{&format.ULPFEC{
+					PayloadTyp: 100,
+					ClockRat:   90000,
+				}},
+			},
+		},
+	}
+
+	c := Client{
+		Transport:    transportPtr(TransportUDP),
+		FECGroupSize: ulpfec.MaxGroupSize + 1,
+	}
+
+	err = c.StartRecording("rtsp://localhost:8554/teststream", desc)
+	require.EqualError(t, err, fmt.Sprintf("FECGroupSize must be less than or equal to %d", ulpfec.MaxGroupSize))
+
+	c = Client{
+		Transport: transportPtr(TransportUDP),
+	}
+
+	u := mustParseURL("rtsp://localhost:8554/teststream")
+
+	err = c.Start(u.Scheme, u.Host)
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.Announce(u, desc)
+	require.NoError(t, err)
+
+	err = c.SetupAll(u, desc.Medias)
+	require.NoError(t, err)
+
+	// bypass the validation performed by Start(),
+	// in order to make initialization of the FEC encoder fail.
+	c.FECGroupSize = -1
+
+	_, err = c.Record()
+	require.EqualError(t, err, fmt.Sprintf("group size must be between 1 and %d", ulpfec.MaxGroupSize))
+
+	// the client is still usable
+	c.FECGroupSize = 2
+
+	_, err = c.Record()
+	require.NoError(t, err)
+}
+
+func TestServerRecordRTCPMux(t *testing.T) {
+	sessionCreated := make(chan *ServerSession, 1)
+	recvRTP := make(chan *rtp.Packet, 1)
+	recvRTCP := make(chan rtcp.Packet, 1)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onAnnounce: func(_ *ServerHandlerOnAnnounceCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil, nil
+			},
+			onRecord: func(ctx *ServerHandlerOnRecordCtx) (*base.Response, error) {
+				ctx.Session.OnPacketRTP(
+					ctx.Session.AnnouncedDescription().Medias[0],
+					ctx.Session.AnnouncedDescription().Medias[0].Formats[0],
+					func(pkt *rtp.Packet) {
+						recvRTP <- pkt
+					})
+
+				ctx.Session.OnPacketRTCPAny(func(_ *description.Media, pkt rtcp.Packet) {
+					if _, ok := pkt.(*rtcp.PictureLossIndication); ok {
+						recvRTCP <- pkt
+					}
+				})
+
+				sessionCreated <- ctx.Session
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPRTPAddress:  "127.0.0.1:8000",
+		UDPRTCPAddress: "127.0.0.1:8001",
+		RTSPAddress:    "localhost:8554",
+		RTCPMux:        true,
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	desc := &description.Session{Medias: []*description.Media{testH264Media}}
+
+	c := Client{
+		Transport: transportPtr(TransportUDP),
+		RTCPMux:   true,
+	}
+
+	err = c.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer c.Close()
+
+	u := mustParseURL("rtsp://localhost:8554/teststream")
+
+	_, err = c.Announce(u, desc)
+	require.NoError(t, err)
+
+	err = c.SetupAll(u, desc.Medias)
+	require.NoError(t, err)
+
+	clientRecvRTCP := make(chan rtcp.Packet, 1)
+
+	c.OnPacketRTCP(desc.Medias[0], func(pkt rtcp.Packet) {
+		if _, ok := pkt.(*rtcp.PictureLossIndication); ok {
+			clientRecvRTCP <- pkt
+		}
+	})
+
+	_, err = c.Record()
+	require.NoError(t, err)
+
+	ss := <-sessionCreated
+
+	require.True(t, c.setuppedMedias[desc.Medias[0]].rtcpMux)
+	require.True(t, ss.AnnouncedDescription().Medias[0].RTCPMux)
+	require.True(t, ss.setuppedMedias[ss.AnnouncedDescription().Medias[0]].rtcpMux)
+
+	err = c.WritePacketRTP(desc.Medias[0], &testRTPPacket)
+	require.NoError(t, err)
+
+	pkt := <-recvRTP
+	require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+
+	err = c.WritePacketRTCP(desc.Medias[0], &rtcp.PictureLossIndication{
+		MediaSSRC: 1234,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, &rtcp.PictureLossIndication{MediaSSRC: 1234}, <-recvRTCP)
+
+	err = ss.WritePacketRTCP(ss.AnnouncedDescription().Medias[0], &rtcp.PictureLossIndication{
+		MediaSSRC: 4321,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, &rtcp.PictureLossIndication{MediaSSRC: 4321}, <-clientRecvRTCP)
+}
+
+func TestServerRecordUDPKeepAlive(t *testing.T) {
+	s := &Server{
+		Handler: &testServerHandler{
+			onAnnounce: func(_ *ServerHandlerOnAnnounceCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil, nil
+			},
+			onRecord: func(_ *ServerHandlerOnRecordCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPRTPAddress:      "127.0.0.1:8000",
+		UDPRTCPAddress:     "127.0.0.1:8001",
+		UDPKeepAlivePeriod: 100 * time.Millisecond,
+		RTSPAddress:        "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	desc := &description.Session{Medias: []*description.Media{testH264Media}}
+
+	clientRecvRTCP := make(chan rtcp.Packet, 10)
+
+	c := Client{
+		Transport: transportPtr(TransportUDP),
+	}
+
+	err = c.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer c.Close()
+
+	u := mustParseURL("rtsp://localhost:8554/teststream")
+
+	_, err = c.Announce(u, desc)
+	require.NoError(t, err)
+
+	err = c.SetupAll(u, desc.Medias)
+	require.NoError(t, err)
+
+	c.OnPacketRTCP(desc.Medias[0], func(pkt rtcp.Packet) {
+		if rr, ok := pkt.(*rtcp.ReceiverReport); ok && len(rr.Reports) == 0 {
+			select {
+			case clientRecvRTCP <- pkt:
+			default:
+			}
+		}
+	})
+
+	_, err = c.Record()
+	require.NoError(t, err)
+
+	// keep-alives are sent periodically until the session is closed.
+	for i := 0; i < 3; i++ {
+		<-clientRecvRTCP
+	}
+}
+
+func TestServerRecordUDPSessionPortsNAT(t *testing.T) {
+	recvRTP := make(chan *rtp.Packet, 1)
+	recvRTCP := make(chan rtcp.Packet, 1)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onAnnounce: func(_ *ServerHandlerOnAnnounceCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil, nil
+			},
+			onRecord: func(ctx *ServerHandlerOnRecordCtx) (*base.Response, error) {
+				ctx.Session.OnPacketRTP(
+					ctx.Session.AnnouncedDescription().Medias[0],
+					ctx.Session.AnnouncedDescription().Medias[0].Formats[0],
+					func(pkt *rtp.Packet) {
+						recvRTP <- pkt
+					})
+
+				ctx.Session.OnPacketRTCPAny(func(_ *description.Media, pkt rtcp.Packet) {
+					if _, ok := pkt.(*rtcp.PictureLossIndication); ok {
+						recvRTCP <- pkt
+					}
+				})
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		UDPSessionPortMin: 8100,
+		UDPSessionPortMax: 8101,
+		RTSPAddress:       "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	nconn, err := net.Dial("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn.Close()
+	conn := conn.NewConn(nconn)
+
+	doAnnounce(t, conn, "rtsp://localhost:8554/teststream", []*description.Media{testH264Media})
+
+	// the client advertises ports that differ from the ones it sends from,
+	// like a client behind a NAT that rewrites ports.
+	inTH := &headers.Transport{
+		Delivery:    deliveryPtr(headers.TransportDeliveryUnicast),
+		Mode:        transportModePtr(headers.TransportModeRecord),
+		Protocol:    headers.TransportProtocolUDP,
+		ClientPorts: &[2]int{35466, 35467},
+	}
+
+	res, th := doSetup(t, conn, "rtsp://localhost:8554/teststream/trackID=0", inTH, "")
+
+	session := readSession(t, res)
+
+	doRecord(t, conn, "rtsp://localhost:8554/teststream", session)
+
+	l1, err := net.ListenPacket("udp", "127.0.0.1:35468")
+	require.NoError(t, err)
+	defer l1.Close()
+
+	l2, err := net.ListenPacket("udp", "127.0.0.1:35469")
+	require.NoError(t, err)
+	defer l2.Close()
+
+	buf, err := testRTPPacket.Marshal()
+	require.NoError(t, err)
+
+	_, err = l1.WriteTo(buf, &net.UDPAddr{
+		IP:   net.ParseIP("127.0.0.1"),
+		Port: th.ServerPorts[0],
+	})
+	require.NoError(t, err)
+
+	pkt := <-recvRTP
+	require.Equal(t, testRTPPacket.Payload, pkt.Payload)
+
+	buf, err = (&rtcp.PictureLossIndication{MediaSSRC: 1234}).Marshal()
+	require.NoError(t, err)
+
+	_, err = l2.WriteTo(buf, &net.UDPAddr{
+		IP:   net.ParseIP("127.0.0.1"),
+		Port: th.ServerPorts[1],
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, &rtcp.PictureLossIndication{MediaSSRC: 1234}, <-recvRTCP)
+}
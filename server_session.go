@@ -171,7 +171,7 @@ func isTransportSupported(s *Server, tr *headers.Transport) bool {
 	// prevent using UDP/UDP-multicast when listeners are disabled
 	if tr.Protocol == headers.TransportProtocolUDP {
 		isMulticast := tr.Delivery != nil && *tr.Delivery == headers.TransportDeliveryMulticast
-		if !isMulticast && s.udpRTPListener == nil {
+		if !isMulticast && s.udpRTPListener == nil && s.UDPSessionPortMin == 0 {
 			return false
 		}
 		if isMulticast && s.MulticastIPRange == "" {
@@ -361,6 +361,7 @@ func generateRTPInfo(
 	now time.Time,
 	mediasOrdered []*serverSessionMedia,
 	stream *ServerStream,
+	replayRTPInfo map[*description.Media]*headers.RTPInfoEntry,
 	path string,
 	u *base.URL,
 ) (headers.RTPInfo, bool) {
@@ -368,7 +369,12 @@ func generateRTPInfo(
 
 	for _, sm := range mediasOrdered {
 		ssm := stream.medias[sm.media]
-		entry := generateRTPInfoEntry(ssm, now)
+
+		// packets of the GOP cache are sent before the others.
+		entry, ok := replayRTPInfo[sm.media]
+		if !ok {
+			entry = generateRTPInfoEntry(ssm, now)
+		}
 		if entry == nil {
 			entry = &headers.RTPInfoEntry{}
 		}
@@ -440,11 +446,16 @@ type ServerSession struct {
 	setuppedQuery         string
 	lastRequestTime       time.Time
 	tcpConn               *ServerConn
+	lastConn              *ServerConn          // connection that sent the last request
 	announcedDesc         *description.Session // record
 	udpLastPacketTime     *int64               // record
 	udpCheckStreamTimer   *time.Timer
+	udpKeepAliveTimer     *time.Timer
 	writer                *asyncProcessor
 	writerMutex           sync.RWMutex
+	slowReaderPolicy      *ServerSlowReaderPolicy
+	readerStats           serverStreamReaderStats // play
+	replayCSeq            atomic.Uint32           // play, lower byte of the CSeq of the last PLAY request
 	timeDecoder           *rtptime.GlobalDecoder2
 	tcpFrame              *base.InterleavedFrame
 	tcpBuffer             []byte
@@ -453,6 +464,8 @@ type ServerSession struct {
 	chHandleRequest    chan sessionRequestReq
 	chRemoveConn       chan *ServerConn
 	chAsyncStartWriter chan struct{}
+	chAsyncWriteError  chan error
+	chGetConn          chan chan *ServerConn
 }
 
 func (ss *ServerSession) initialize() {
@@ -467,10 +480,13 @@ func (ss *ServerSession) initialize() {
 	ss.conns = make(map[*ServerConn]struct{})
 	ss.lastRequestTime = ss.s.timeNow()
 	ss.udpCheckStreamTimer = emptyTimer()
+	ss.udpKeepAliveTimer = emptyTimer()
 
 	ss.chHandleRequest = make(chan sessionRequestReq)
 	ss.chRemoveConn = make(chan *ServerConn)
 	ss.chAsyncStartWriter = make(chan struct{})
+	ss.chAsyncWriteError = make(chan error, 1)
+	ss.chGetConn = make(chan chan *ServerConn)
 
 	ss.s.wg.Add(1)
 	go ss.run()
@@ -481,6 +497,26 @@ func (ss *ServerSession) Close() {
 	ss.ctxCancel()
 }
 
+// Redirect sends a REDIRECT request to the client that owns the session,
+// through the connection that sent the last request of the session,
+// asking it to move to another location.
+// If ra is not nil, the client is asked to resume playback from the given position.
+func (ss *ServerSession) Redirect(location *base.URL, ra *headers.Range) error {
+	cres := make(chan *ServerConn)
+	select {
+	case ss.chGetConn <- cres:
+	case <-ss.ctx.Done():
+		return liberrors.ErrServerTerminated{}
+	}
+
+	sc := <-cres
+	if sc == nil {
+		return fmt.Errorf("session is not associated with any connection")
+	}
+
+	return sc.redirect(location, ra, ss)
+}
+
 // BytesReceived returns the number of read bytes.
 //
 // Deprecated: replaced by Stats()
@@ -559,8 +595,17 @@ func (ss *ServerSession) UserData() interface{} {
 	return ss.userData
 }
 
+// SetSlowReaderPolicy sets the action taken when the session is unable to keep up
+// with the stream it is reading, overriding Server.SlowReaderPolicy.
+// It must be called before the session starts playing, for instance inside OnSetup or OnPlay.
+func (ss *ServerSession) SetSlowReaderPolicy(p ServerSlowReaderPolicy) {
+	ss.slowReaderPolicy = &p
+}
+
 // Stats returns server session statistics.
 func (ss *ServerSession) Stats() *StatsSession {
+	readerStats := ss.slowReaderStats()
+
 	return &StatsSession{
 		BytesReceived: func() uint64 {
 			v := uint64(0)
@@ -650,6 +695,34 @@ func (ss *ServerSession) Stats() *StatsSession {
 			}
 			return v
 		}(),
+		OutgoingPacketsDroppedOldest: func() uint64 {
+			v := uint64(0)
+			for _, rs := range readerStats {
+				v += rs.packetsDroppedOldest.Load()
+			}
+			return v
+		}(),
+		OutgoingPacketsDroppedUntilKeyFrame: func() uint64 {
+			v := uint64(0)
+			for _, rs := range readerStats {
+				v += rs.packetsDroppedUntilKeyFrame.Load()
+			}
+			return v
+		}(),
+		OutgoingPacketsDroppedOnDisconnect: func() uint64 {
+			v := uint64(0)
+			for _, rs := range readerStats {
+				v += rs.packetsDroppedOnDisconnect.Load()
+			}
+			return v
+		}(),
+		OutgoingPacketsSkippedNonReference: func() uint64 {
+			v := uint64(0)
+			for _, rs := range readerStats {
+				v += rs.packetsSkippedNonReference.Load()
+			}
+			return v
+		}(),
 		Medias: func() map[*description.Media]StatsSessionMedia { //nolint:dupl
 			ret := make(map[*description.Media]StatsSessionMedia, len(ss.setuppedMedias))
 
@@ -685,10 +758,14 @@ func (ss *ServerSession) Stats() *StatsSession {
 							}()
 
 							ret[fo.format] = StatsSessionFormat{ //nolint:dupl
-								RTPPacketsReceived: atomic.LoadUint64(fo.rtpPacketsReceived),
-								RTPPacketsSent:     atomic.LoadUint64(fo.rtpPacketsSent),
-								RTPPacketsLost:     atomic.LoadUint64(fo.rtpPacketsLost),
-								LocalSSRC:          fo.localSSRC,
+								RTPPacketsReceived:              atomic.LoadUint64(fo.rtpPacketsReceived),
+								RTPPacketsSent:                  atomic.LoadUint64(fo.rtpPacketsSent),
+								RTPPacketsLost:                  atomic.LoadUint64(fo.rtpPacketsLost),
+								RTPPacketsNACKed:                atomic.LoadUint64(fo.rtpPacketsNACKed),
+								RTPPacketsRetransmitted:         atomic.LoadUint64(fo.rtpPacketsRetrans),
+								RTPPacketsRetransmittedReceived: atomic.LoadUint64(fo.rtpPacketsRetransRecv),
+								RTPPacketsRecovered:             atomic.LoadUint64(fo.rtpPacketsRecovered),
+								LocalSSRC:                       fo.localSSRC,
 								RemoteSSRC: func() uint32 {
 									if v, ok := fo.remoteSSRC(); ok {
 										return v
@@ -741,6 +818,58 @@ func (ss *ServerSession) Stats() *StatsSession {
 	}
 }
 
+// slowReaderStats returns the counters of the actions taken when the session is too slow.
+// With the UDP-multicast transport, counters are shared by all the readers of the stream.
+func (ss *ServerSession) slowReaderStats() []*serverStreamReaderStats {
+	ret := []*serverStreamReaderStats{&ss.readerStats}
+
+	if ss.setuppedStream != nil && ss.setuppedTransport != nil &&
+		*ss.setuppedTransport == TransportUDPMulticast {
+		for medi := range ss.setuppedMedias {
+			ret = append(ret, &ss.setuppedStream.medias[medi].multicastReaderStats)
+		}
+	}
+
+	return ret
+}
+
+func (ss *ServerSession) getSlowReaderPolicy() ServerSlowReaderPolicy {
+	if ss.slowReaderPolicy != nil {
+		return *ss.slowReaderPolicy
+	}
+	return ss.s.SlowReaderPolicy
+}
+
+// pushToWriter schedules a function in the writer routine.
+func (ss *ServerSession) pushToWriter(cb func() error) bool {
+	ss.writerMutex.RLock()
+	defer ss.writerMutex.RUnlock()
+
+	return ss.writer != nil && ss.writer.push(cb)
+}
+
+// writeStreamPacket writes a packet read from the stream and releases it.
+func (ss *ServerSession) writeStreamPacket(medi *description.Media, pkt *serverStreamPacket) error {
+	sm := ss.setuppedMedias[medi]
+	payload := pkt.payload(ss.setuppedSecure)
+
+	if pkt.rtcp {
+		return sm.writePacketRTCPInQueue(payload, pkt)
+	}
+
+	sf, ok := sm.formats[pkt.payloadType]
+	if !ok {
+		pkt.release()
+		return nil
+	}
+
+	if ss.setuppedStream.ReplayExtension {
+		return sf.writeStreamPacketRTPReplay(pkt)
+	}
+
+	return sf.writePacketRTPInQueue(payload, pkt)
+}
+
 func (ss *ServerSession) onStreamWriteError(err error) {
 	if h, ok := ss.s.Handler.(ServerHandlerOnStreamWriteError); ok {
 		h.OnStreamWriteError(&ServerHandlerOnStreamWriteErrorCtx{
@@ -838,6 +967,10 @@ func (ss *ServerSession) run() {
 		ss.destroyWriter()
 	}
 
+	for _, sm := range ss.setuppedMedias {
+		sm.close()
+	}
+
 	ss.s.closeSession(ss)
 
 	if h, ok := ss.s.Handler.(ServerHandlerOnSessionClose); ok {
@@ -848,6 +981,18 @@ func (ss *ServerSession) run() {
 	}
 }
 
+func (ss *ServerSession) detachConn(sc *ServerConn) {
+	delete(ss.conns, sc)
+
+	if ss.lastConn == sc {
+		ss.lastConn = nil
+		for sc2 := range ss.conns {
+			ss.lastConn = sc2
+			break
+		}
+	}
+}
+
 func (ss *ServerSession) runInner() error {
 	for {
 		chWriterError := func() chan struct{} {
@@ -864,6 +1009,7 @@ func (ss *ServerSession) runInner() error {
 			if _, ok := ss.conns[req.sc]; !ok {
 				ss.conns[req.sc] = struct{}{}
 			}
+			ss.lastConn = req.sc
 
 			res, err := ss.handleRequestInner(req.sc, req.req)
 
@@ -897,7 +1043,7 @@ func (ss *ServerSession) runInner() error {
 
 				// after a TEARDOWN, session must be unpaired with the connection
 				if req.req.Method == base.Teardown {
-					delete(ss.conns, req.sc)
+					ss.detachConn(req.sc)
 					returnedSession = nil
 				}
 			}
@@ -915,7 +1061,7 @@ func (ss *ServerSession) runInner() error {
 			}
 
 		case sc := <-ss.chRemoveConn:
-			delete(ss.conns, sc)
+			ss.detachConn(sc)
 
 			// if session is not in state RECORD or PLAY, or transport is TCP,
 			// and there are no associated connections,
@@ -927,6 +1073,9 @@ func (ss *ServerSession) runInner() error {
 				return liberrors.ErrServerSessionNotInUse{}
 			}
 
+		case cres := <-ss.chGetConn:
+			cres <- ss.lastConn
+
 		case <-ss.chAsyncStartWriter:
 			if (ss.state == ServerSessionStateRecord ||
 				ss.state == ServerSessionStatePlay) &&
@@ -953,9 +1102,22 @@ func (ss *ServerSession) runInner() error {
 
 			ss.udpCheckStreamTimer = time.NewTimer(ss.s.checkStreamPeriod)
 
+		case <-ss.udpKeepAliveTimer.C:
+			for _, sm := range ss.setuppedMedias {
+				err := sm.openFirewall()
+				if err != nil {
+					return err
+				}
+			}
+
+			ss.udpKeepAliveTimer = time.NewTimer(ss.s.UDPKeepAlivePeriod)
+
 		case <-chWriterError:
 			return ss.writer.stopError
 
+		case err := <-ss.chAsyncWriteError:
+			return err
+
 		case <-ss.ctx.Done():
 			return liberrors.ErrServerTerminated{}
 		}
@@ -1096,6 +1258,12 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 			}, liberrors.ErrServerTransportHeaderInvalid{Err: err}
 		}
 
+		if req.Version == base.Version20 {
+			for i := range transportHeaders {
+				transportFillFromRTSP2(&transportHeaders[i], true)
+			}
+		}
+
 		// Per RFC2326 section 12.39, client specifies transports in order of preference.
 		// pick the first supported one.
 		inTH := pickFirstSupportedTransport(ss.s, transportHeaders)
@@ -1188,6 +1356,25 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 					}, liberrors.ErrServerTransportHeaderInterleavedIDsInUse{}
 				}
 			}
+
+		case TransportUDPMulticast:
+			if inTH.Destination != nil && !ss.s.multicastNet.Contains(*inTH.Destination) {
+				return &base.Response{
+					StatusCode: base.StatusUnsupportedTransport,
+				}, liberrors.ErrServerTransportHeaderInvalidDestination{Destination: *inTH.Destination}
+			}
+
+			if inTH.Ports != nil && ((inTH.Ports[0]%2) != 0 || inTH.Ports[1] != (inTH.Ports[0]+1)) {
+				return &base.Response{
+					StatusCode: base.StatusBadRequest,
+				}, liberrors.ErrServerTransportHeaderInvalidPorts{Ports: *inTH.Ports}
+			}
+
+			if inTH.TTL != nil && (*inTH.TTL == 0 || *inTH.TTL > 255) {
+				return &base.Response{
+					StatusCode: base.StatusBadRequest,
+				}, liberrors.ErrServerTransportHeaderInvalidTTL{TTL: *inTH.TTL}
+			}
 		}
 
 		switch ss.state {
@@ -1212,14 +1399,22 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 			}
 		}
 
-		res, stream, err := ss.s.Handler.(ServerHandlerOnSetup).OnSetup(&ServerHandlerOnSetupCtx{
+		ctx := &ServerHandlerOnSetupCtx{
 			Session:   ss,
 			Conn:      sc,
 			Request:   req,
 			Path:      path,
 			Query:     query,
 			Transport: transport,
-		})
+		}
+
+		if transport == TransportUDPMulticast {
+			ctx.MulticastDestination = inTH.Destination
+			ctx.MulticastPorts = inTH.Ports
+			ctx.MulticastTTL = inTH.TTL
+		}
+
+		res, stream, err := ss.s.Handler.(ServerHandlerOnSetup).OnSetup(ctx)
 
 		// workaround to prevent a bug in rtspclientsink
 		// that makes impossible for the client to receive the response
@@ -1275,7 +1470,24 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 						StatusCode: base.StatusBadRequest,
 					}, err
 				}
+			}
+
+			var mw *serverMulticastWriter
+
+			if transport == TransportUDPMulticast {
+				mw, err = stream.readerSetupMulticast(medi, inTH.Destination, inTH.Ports, inTH.TTL)
+				if err != nil {
+					if ss.state == ServerSessionStateInitial {
+						stream.readerRemove(ss)
+					}
+
+					return &base.Response{
+						StatusCode: base.StatusUnsupportedTransport,
+					}, err
+				}
+			}
 
+			if ss.state == ServerSessionStateInitial {
 				ss.state = ServerSessionStatePrePlay
 				ss.setuppedPath = path
 				ss.setuppedQuery = query
@@ -1321,33 +1533,85 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 				th.Protocol = headers.TransportProtocolUDP
 
 				if transport == TransportUDP {
+					if ss.s.UDPSessionPortMin != 0 {
+						sm.udpRTPListener, sm.udpRTCPListener, err = ss.s.allocateUDPSessionListeners()
+						if err != nil {
+							return &base.Response{
+								StatusCode: base.StatusUnsupportedTransport,
+							}, err
+						}
+						sm.udpDedicated = true
+					} else {
+						sm.udpRTPListener = ss.s.udpRTPListener
+						sm.udpRTCPListener = ss.s.udpRTCPListener
+					}
+
 					sm.udpRTPReadPort = inTH.ClientPorts[0]
 					sm.udpRTCPReadPort = inTH.ClientPorts[1]
 
-					sm.udpRTPWriteAddr = &net.UDPAddr{
+					// RTCP packets are sent and received through the RTP port.
+					if ss.s.RTCPMux && inTH.RTCPMux {
+						sm.rtcpMux = true
+						sm.udpRTCPReadPort = sm.udpRTPReadPort
+						if sm.udpDedicated {
+							sm.udpRTCPListener.close()
+						}
+						sm.udpRTCPListener = sm.udpRTPListener
+					}
+
+					sm.udpRTPWriteAddr.Store(&net.UDPAddr{
 						IP:   ss.author.ip(),
 						Zone: ss.author.zone(),
 						Port: sm.udpRTPReadPort,
-					}
+					})
 
-					sm.udpRTCPWriteAddr = &net.UDPAddr{
+					sm.udpRTCPWriteAddr.Store(&net.UDPAddr{
 						IP:   ss.author.ip(),
 						Zone: ss.author.zone(),
 						Port: sm.udpRTCPReadPort,
+					})
+
+					if ss.s.UDPSymmetricRTP && sm.udpDedicated {
+						sm.addLatches()
 					}
 
 					de := headers.TransportDeliveryUnicast
 					th.Delivery = &de
-					th.ClientPorts = inTH.ClientPorts
-					th.ServerPorts = &[2]int{sc.s.udpRTPListener.port(), sc.s.udpRTCPListener.port()}
+					clientPorts := [2]int{sm.udpRTPReadPort, sm.udpRTCPReadPort}
+					serverPorts := [2]int{sm.udpRTPListener.port(), sm.udpRTCPListener.port()}
+
+					if sm.rtcpMux {
+						th.RTCPMux = true
+					}
+
+					if req.Version == base.Version20 {
+						th.DestAddr = transportAddrsFromPorts(ss.author.ip(), clientPorts, sm.rtcpMux)
+						th.SrcAddr = transportAddrsFromPorts(sc.localIP(), serverPorts, sm.rtcpMux)
+					} else {
+						th.ClientPorts = &clientPorts
+						th.ServerPorts = &serverPorts
+					}
 				} else {
 					de := headers.TransportDeliveryMulticast
 					th.Delivery = &de
-					v := uint(127)
+					v := mw.ttlValue()
 					th.TTL = &v
-					d := stream.medias[medi].multicastWriter.ip()
-					th.Destination = &d
-					th.Ports = &[2]int{ss.s.MulticastRTPPort, ss.s.MulticastRTCPPort}
+					d := mw.ip()
+					ports := mw.portPair()
+					source := mw.source // source-specific groups only
+
+					if req.Version == base.Version20 {
+						th.DestAddr = transportAddrsFromPorts(d, ports, false)
+						if source != nil {
+							th.SrcAddr = []headers.TransportAddr{{Host: source.String()}}
+						}
+					} else {
+						th.Destination = &d
+						th.Ports = &ports
+						if source != nil {
+							th.Source = &source
+						}
+					}
 				}
 
 			default: // TCP
@@ -1425,6 +1689,78 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 			}, liberrors.ErrServerPathHasChanged{Prev: ss.setuppedPath, Cur: path}
 		}
 
+		var scale *headers.Scale
+		if v, ok := req.Header["Scale"]; ok {
+			var tmp headers.Scale
+			err = tmp.Unmarshal(v)
+			if err != nil {
+				return &base.Response{
+					StatusCode: base.StatusBadRequest,
+				}, liberrors.ErrServerScaleHeaderInvalid{Err: err}
+			}
+			scale = &tmp
+		}
+
+		var speed *headers.Speed
+		if v, ok := req.Header["Speed"]; ok {
+			var tmp headers.Speed
+			err = tmp.Unmarshal(v)
+			if err != nil {
+				return &base.Response{
+					StatusCode: base.StatusBadRequest,
+				}, liberrors.ErrServerSpeedHeaderInvalid{Err: err}
+			}
+			speed = &tmp
+		}
+
+		var rateControl *headers.RateControl
+		if v, ok := req.Header["Rate-Control"]; ok {
+			var tmp headers.RateControl
+			err = tmp.Unmarshal(v)
+			if err != nil {
+				return &base.Response{
+					StatusCode: base.StatusBadRequest,
+				}, liberrors.ErrServerRateControlHeaderInvalid{Err: err}
+			}
+			rateControl = &tmp
+		}
+
+		var frames *headers.Frames
+		if v, ok := req.Header["Frames"]; ok {
+			var tmp headers.Frames
+			err = tmp.Unmarshal(v)
+			if err != nil {
+				return &base.Response{
+					StatusCode: base.StatusBadRequest,
+				}, liberrors.ErrServerFramesHeaderInvalid{Err: err}
+			}
+			frames = &tmp
+		}
+
+		var immediate *headers.Immediate
+		if v, ok := req.Header["Immediate"]; ok {
+			var tmp headers.Immediate
+			err = tmp.Unmarshal(v)
+			if err != nil {
+				return &base.Response{
+					StatusCode: base.StatusBadRequest,
+				}, liberrors.ErrServerImmediateHeaderInvalid{Err: err}
+			}
+			immediate = &tmp
+		}
+
+		var seekStyle *headers.SeekStyle
+		if v, ok := req.Header["Seek-Style"]; ok {
+			var tmp headers.SeekStyle
+			err = tmp.Unmarshal(v)
+			if err != nil {
+				return &base.Response{
+					StatusCode: base.StatusBadRequest,
+				}, liberrors.ErrServerSeekStyleHeaderInvalid{Err: err}
+			}
+			seekStyle = &tmp
+		}
+
 		if ss.state != ServerSessionStatePlay &&
 			*ss.setuppedTransport != TransportUDPMulticast {
 			ss.createWriter()
@@ -1436,9 +1772,23 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 			Request: req,
 			Path:    path,
 			Query:   query,
+			Scale:   scale,
+			Speed:   speed,
+
+			SeekStyle: seekStyle,
+
+			RateControl: rateControl,
+			Frames:      frames,
+			Immediate:   immediate,
 		})
 
 		if res.StatusCode == base.StatusOK {
+			if ss.setuppedStream.ReplayExtension {
+				// CSeq is validated by ServerConn
+				cseq, _ := strconv.ParseUint(req.Header["CSeq"][0], 10, 32)
+				ss.replayCSeq.Store(uint32(cseq & 0xFF))
+			}
+
 			if ss.state != ServerSessionStatePlay {
 				ss.state = ServerSessionStatePlay
 
@@ -1477,12 +1827,13 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 					// after the response has been sent
 				}
 
-				ss.setuppedStream.readerSetActive(ss)
+				replayRTPInfo := ss.setuppedStream.readerSetActive(ss)
 
 				rtpInfo, ok := generateRTPInfo(
 					ss.s.timeNow(),
 					ss.setuppedMediasOrdered,
 					ss.setuppedStream,
+					replayRTPInfo,
 					ss.setuppedPath,
 					req.URL)
 
@@ -1493,6 +1844,13 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 					res.Header["RTP-Info"] = rtpInfo.Marshal()
 				}
 			}
+
+			if req.Version == base.Version20 {
+				if res.Header == nil {
+					res.Header = make(base.Header)
+				}
+				addMediaPropertiesHeaders(res.Header)
+			}
 		} else {
 			if ss.state != ServerSessionStatePlay &&
 				*ss.setuppedTransport != TransportUDPMulticast {
@@ -1560,6 +1918,9 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 			switch *ss.setuppedTransport {
 			case TransportUDP:
 				ss.udpCheckStreamTimer = time.NewTimer(ss.s.checkStreamPeriod)
+				if ss.s.UDPKeepAlivePeriod != 0 {
+					ss.udpKeepAliveTimer = time.NewTimer(ss.s.UDPKeepAlivePeriod)
+				}
 				ss.startWriter()
 
 			default: // TCP
@@ -1629,6 +1990,7 @@ func (ss *ServerSession) handleRequestInner(sc *ServerConn, req *base.Request) (
 					switch *ss.setuppedTransport {
 					case TransportUDP:
 						ss.udpCheckStreamTimer = emptyTimer()
+						ss.udpKeepAliveTimer = emptyTimer()
 
 					default: // TCP
 						err = switchReadFuncError{false}
@@ -1756,6 +2118,31 @@ func (ss *ServerSession) WritePacketRTCP(medi *description.Media, pkt rtcp.Packe
 	return sm.writePacketRTCP(pkt)
 }
 
+// RequestKeyFrame asks the client to send a key frame of a media,
+// by sending a RTCP Picture Loss Indication (PLI) for each format of the media.
+// This can be called only while recording.
+func (ss *ServerSession) RequestKeyFrame(medi *description.Media) error {
+	sm, ok := ss.setuppedMedias[medi]
+	if !ok {
+		return fmt.Errorf("media has not been setupped")
+	}
+
+	for _, sf := range sm.formats {
+		// when the SSRC of the source is still unknown, it is left to zero.
+		mediaSSRC, _ := sf.remoteSSRC()
+
+		err := sm.writePacketRTCP(&rtcp.PictureLossIndication{
+			SenderSSRC: sf.localSSRC,
+			MediaSSRC:  mediaSSRC,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // PacketPTS returns the PTS (presentation timestamp) of an incoming RTP packet.
 // It is computed by decoding the packet timestamp and sychronizing it with other tracks.
 //
@@ -1814,3 +2201,12 @@ func (ss *ServerSession) asyncStartWriter() {
 	case <-ss.ctx.Done():
 	}
 }
+
+// onAsyncWriteError is called by batched UDP listeners when a packet
+// that has been queued by the writer cannot be sent.
+func (ss *ServerSession) onAsyncWriteError(err error) {
+	select {
+	case ss.chAsyncWriteError <- err:
+	default:
+	}
+}
@@ -11,9 +11,12 @@ import (
 
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
+	"github.com/bluenviron/gortsplib/v4/pkg/onvif"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtcpreceiver"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtplossdetector"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtpreorderer"
+	"github.com/bluenviron/gortsplib/v4/pkg/rtx"
+	"github.com/bluenviron/gortsplib/v4/pkg/ulpfec"
 )
 
 func serverSessionPickLocalSSRC(sf *serverSessionFormat) (uint32, error) {
@@ -50,10 +53,19 @@ type serverSessionFormat struct {
 	udpReorderer          *rtpreorderer.Reorderer // publish or back channel
 	tcpLossDetector       *rtplossdetector.LossDetector
 	rtcpReceiver          *rtcpreceiver.RTCPReceiver
-	writePacketRTPInQueue func([]byte) error
+	rtxReceiver           *rtx.Receiver        // publish or back channel
+	rtxSender             *rtx.Sender          // play
+	rtxTarget             *serverSessionFormat // publish or back channel, RTX format only
+	fecDecoder            *ulpfec.Decoder      // publish
+	fecTarget             *serverSessionFormat // publish, ULPFEC format only
+	writePacketRTPInQueue func([]byte, *serverStreamPacket) error
 	rtpPacketsReceived    *uint64
 	rtpPacketsSent        *uint64
 	rtpPacketsLost        *uint64
+	rtpPacketsNACKed      *uint64
+	rtpPacketsRetrans     *uint64
+	rtpPacketsRetransRecv *uint64
+	rtpPacketsRecovered   *uint64
 }
 
 func (sf *serverSessionFormat) initialize() error {
@@ -70,6 +82,32 @@ func (sf *serverSessionFormat) initialize() error {
 	sf.rtpPacketsReceived = new(uint64)
 	sf.rtpPacketsSent = new(uint64)
 	sf.rtpPacketsLost = new(uint64)
+	sf.rtpPacketsNACKed = new(uint64)
+	sf.rtpPacketsRetrans = new(uint64)
+	sf.rtpPacketsRetransRecv = new(uint64)
+	sf.rtpPacketsRecovered = new(uint64)
+
+	if sf.sm.ss.state == ServerSessionStatePreRecord {
+		if fecMedia, _ := findFECFormat(sf.sm.ss.announcedDesc, sf.sm.media); fecMedia != nil {
+			sf.fecDecoder = &ulpfec.Decoder{}
+		}
+	} else if !sf.sm.media.IsBackChannel {
+		streamMedia := sf.sm.ss.setuppedStream.medias[sf.sm.media]
+
+		if rtxBuffer := streamMedia.formats[sf.format.PayloadType()].rtxBuffer; rtxBuffer != nil {
+			rtxFormat := findRTXFormat(sf.sm.media, sf.format)
+
+			sf.rtxSender = &rtx.Sender{
+				PayloadType: rtxFormat.PayloadType(),
+				SSRC:        streamMedia.formats[rtxFormat.PayloadType()].localSSRC,
+				Buffer:      rtxBuffer,
+			}
+			err := sf.rtxSender.Initialize()
+			if err != nil {
+				return err
+			}
+		}
+	}
 
 	return nil
 }
@@ -87,6 +125,12 @@ func (sf *serverSessionFormat) start() {
 		if *sf.sm.ss.setuppedTransport == TransportUDP || *sf.sm.ss.setuppedTransport == TransportUDPMulticast {
 			sf.udpReorderer = &rtpreorderer.Reorderer{}
 			sf.udpReorderer.Initialize()
+
+			if *sf.sm.ss.setuppedTransport == TransportUDP && findRTXFormat(sf.sm.media, sf.format) != nil {
+				sf.rtxReceiver = &rtx.Receiver{
+					PayloadType: sf.format.PayloadType(),
+				}
+			}
 		} else {
 			sf.tcpLossDetector = &rtplossdetector.LossDetector{}
 		}
@@ -127,6 +171,54 @@ func (sf *serverSessionFormat) remoteSSRC() (uint32, bool) {
 }
 
 func (sf *serverSessionFormat) readPacketRTPUDP(pkt *rtp.Packet, now time.Time) {
+	if sf.rtxTarget != nil {
+		orig, err := sf.rtxTarget.rtxReceiver.Decode(pkt)
+		if err != nil {
+			sf.sm.onPacketRTPDecodeError(err)
+			return
+		}
+
+		atomic.AddUint64(sf.rtxTarget.rtpPacketsRetransRecv, 1)
+		sf.rtxTarget.readPacketRTPUDP(orig, now)
+		return
+	}
+
+	if sf.fecTarget != nil {
+		err := sf.fecTarget.fecDecoder.AddFEC(pkt)
+		if err != nil {
+			sf.sm.onPacketRTPDecodeError(err)
+			return
+		}
+		// do not return
+	}
+
+	sf.processPacketRTPUDP(pkt, now)
+
+	if sf.fecDecoder != nil {
+		recovered := sf.fecDecoder.Process(pkt)
+		atomic.AddUint64(sf.rtpPacketsRecovered, uint64(len(recovered)))
+
+		for _, pkt := range recovered {
+			sf.processPacketRTPUDP(pkt, now)
+		}
+	}
+
+	// request packets that are still missing after reordering and recovery.
+	if sf.rtxReceiver != nil {
+		missing := sf.rtxReceiver.ProcessPacket(pkt, sf.udpReorderer.Missing())
+		if missing != nil {
+			atomic.AddUint64(sf.rtpPacketsNACKed, uint64(len(missing)))
+
+			sf.sm.ss.WritePacketRTCP(sf.sm.media, &rtcp.TransportLayerNack{ //nolint:errcheck
+				SenderSSRC: sf.localSSRC,
+				MediaSSRC:  pkt.SSRC,
+				Nacks:      rtcp.NackPairsFromSequenceNumbers(missing),
+			})
+		}
+	}
+}
+
+func (sf *serverSessionFormat) processPacketRTPUDP(pkt *rtp.Packet, now time.Time) {
 	packets, lost := sf.udpReorderer.Process(pkt)
 	if lost != 0 {
 		sf.onPacketRTPLost(uint64(lost))
@@ -190,6 +282,15 @@ func (sf *serverSessionFormat) onPacketRTPLost(lost uint64) {
 func (sf *serverSessionFormat) writePacketRTP(pkt *rtp.Packet) error {
 	pkt.SSRC = sf.localSSRC
 
+	payload, err := sf.encodePacketRTP(pkt)
+	if err != nil {
+		return err
+	}
+
+	return sf.writePacketRTPEncoded(payload)
+}
+
+func (sf *serverSessionFormat) encodePacketRTP(pkt *rtp.Packet) ([]byte, error) {
 	maxPlainPacketSize := sf.sm.ss.s.MaxPacketSize
 	if sf.sm.ss.setuppedSecure {
 		maxPlainPacketSize -= srtpOverhead
@@ -198,23 +299,16 @@ func (sf *serverSessionFormat) writePacketRTP(pkt *rtp.Packet) error {
 	plain := make([]byte, maxPlainPacketSize)
 	n, err := pkt.MarshalTo(plain)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	plain = plain[:n]
 
-	var encr []byte
-	if sf.sm.ss.setuppedSecure {
-		encr = make([]byte, sf.sm.ss.s.MaxPacketSize)
-		encr, err = sf.sm.srtpOutCtx.encryptRTP(encr, plain, &pkt.Header)
-		if err != nil {
-			return err
-		}
+	if !sf.sm.ss.setuppedSecure {
+		return plain, nil
 	}
 
-	if sf.sm.ss.setuppedSecure {
-		return sf.writePacketRTPEncoded(encr)
-	}
-	return sf.writePacketRTPEncoded(plain)
+	encr := make([]byte, sf.sm.ss.s.MaxPacketSize)
+	return sf.sm.srtpOutCtx.encryptRTP(encr, plain, &pkt.Header)
 }
 
 func (sf *serverSessionFormat) writePacketRTPEncoded(payload []byte) error {
@@ -226,7 +320,7 @@ func (sf *serverSessionFormat) writePacketRTPEncoded(payload []byte) error {
 	}
 
 	ok := sf.sm.ss.writer.push(func() error {
-		return sf.writePacketRTPInQueue(payload)
+		return sf.writePacketRTPInQueue(payload, nil)
 	})
 	if !ok {
 		return liberrors.ErrServerWriteQueueFull{}
@@ -235,8 +329,46 @@ func (sf *serverSessionFormat) writePacketRTPEncoded(payload []byte) error {
 	return nil
 }
 
-func (sf *serverSessionFormat) writePacketRTPInQueueUDP(payload []byte) error {
-	err := sf.sm.ss.s.udpRTPListener.write(payload, sf.sm.udpRTPWriteAddr)
+// writeStreamPacketRTPReplay writes a packet of a stream with the ONVIF replay extension.
+// The CSeq of the extension depends on the PLAY request of the session,
+// therefore the packet is copied, filled and encoded again.
+func (sf *serverSessionFormat) writeStreamPacketRTPReplay(shared *serverStreamPacket) error {
+	var pkt rtp.Packet
+	err := pkt.Unmarshal(shared.plain)
+	if err != nil {
+		shared.release()
+		return err
+	}
+
+	var e onvif.ReplayExtension
+	err = e.Unmarshal(&pkt.Header)
+	if err == nil {
+		e.CSeq = uint8(sf.sm.ss.replayCSeq.Load())
+		err = e.Marshal(&pkt.Header)
+		if err != nil {
+			shared.release()
+			return err
+		}
+	}
+
+	payload, err := sf.encodePacketRTP(&pkt)
+	shared.release()
+	if err != nil {
+		return err
+	}
+
+	return sf.writePacketRTPInQueue(payload, nil)
+}
+
+// writePacketRTPInQueueUDP writes a packet.
+// If the packet is shared with other readers, it is released once written.
+func (sf *serverSessionFormat) writePacketRTPInQueueUDP(payload []byte, shared *serverStreamPacket) error {
+	var err error
+	if shared != nil {
+		err = sf.sm.udpRTPListener.writeShared(payload, sf.sm.udpRTPWriteAddr.Load(), shared, sf.sm.ss.onAsyncWriteError)
+	} else {
+		err = sf.sm.udpRTPListener.write(payload, sf.sm.udpRTPWriteAddr.Load(), sf.sm.ss.onAsyncWriteError)
+	}
 	if err != nil {
 		return err
 	}
@@ -246,7 +378,11 @@ func (sf *serverSessionFormat) writePacketRTPInQueueUDP(payload []byte) error {
 	return nil
 }
 
-func (sf *serverSessionFormat) writePacketRTPInQueueTCP(payload []byte) error {
+func (sf *serverSessionFormat) writePacketRTPInQueueTCP(payload []byte, shared *serverStreamPacket) error {
+	if shared != nil {
+		defer shared.release()
+	}
+
 	sf.sm.ss.tcpFrame.Channel = sf.sm.tcpChannel
 	sf.sm.ss.tcpFrame.Payload = payload
 	sf.sm.ss.tcpConn.nconn.SetWriteDeadline(time.Now().Add(sf.sm.ss.s.WriteTimeout))
@@ -12,6 +12,7 @@ import (
 	"github.com/pion/rtp"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
 )
 
@@ -23,12 +24,16 @@ type serverSessionMedia struct {
 
 	srtpOutCtx             *wrappedSRTPContext
 	tcpChannel             int
+	udpRTPListener         *serverUDPListener
+	udpRTCPListener        *serverUDPListener // equal to udpRTPListener when rtcpMux is true
+	udpDedicated           bool               // whether UDP listeners are owned by the media
 	udpRTPReadPort         int
-	udpRTPWriteAddr        *net.UDPAddr
+	udpRTPWriteAddr        atomic.Pointer[net.UDPAddr]
 	udpRTCPReadPort        int
-	udpRTCPWriteAddr       *net.UDPAddr
+	udpRTCPWriteAddr       atomic.Pointer[net.UDPAddr]
+	rtcpMux                bool
 	formats                map[uint8]*serverSessionFormat // record only
-	writePacketRTCPInQueue func([]byte) error
+	writePacketRTCPInQueue func([]byte, *serverStreamPacket) error
 	bytesReceived          *uint64
 	bytesSent              *uint64
 	rtpPacketsInError      *uint64
@@ -99,48 +104,61 @@ func (sm *serverSessionMedia) start() error {
 		sf.start()
 	}
 
+	for _, sf := range sm.formats {
+		switch forma := sf.format.(type) {
+		case *format.RTX:
+			if target, ok := sm.formats[forma.APT]; ok && target.rtxReceiver != nil {
+				sf.rtxTarget = target
+			}
+
+		case *format.ULPFEC:
+			if sf.udpReorderer != nil {
+				if protectedMedia := findFECProtectedMedia(sm.ss.announcedDesc, sm.media); protectedMedia != nil {
+					if psm, ok := sm.ss.setuppedMedias[protectedMedia]; ok {
+						if target := psm.formats[protectedMedia.Formats[0].PayloadType()]; target.fecDecoder != nil {
+							sf.fecTarget = target
+						}
+					}
+				}
+			}
+		}
+	}
+
 	switch *sm.ss.setuppedTransport {
 	case TransportUDP, TransportUDPMulticast:
 		sm.writePacketRTCPInQueue = sm.writePacketRTCPInQueueUDP
 
 		if *sm.ss.setuppedTransport == TransportUDP {
 			if sm.ss.state == ServerSessionStatePlay {
-				if sm.media.IsBackChannel {
-					sm.ss.s.udpRTPListener.addClient(sm.ss.author.ip(), sm.udpRTPReadPort, sm.readPacketRTPUDPPlay)
-				}
-				sm.ss.s.udpRTCPListener.addClient(sm.ss.author.ip(), sm.udpRTCPReadPort, sm.readPacketRTCPUDPPlay)
-			} else {
-				// open the firewall by sending empty packets to the remote part.
-				buf, _ := (&rtp.Packet{Header: rtp.Header{Version: 2}}).Marshal()
-				if sm.srtpOutCtx != nil {
-					encr := make([]byte, sm.ss.s.MaxPacketSize)
-					encr, err := sm.srtpOutCtx.encryptRTP(encr, buf, nil)
-					if err != nil {
-						return err
+				switch {
+				case sm.rtcpMux:
+					onRTP := readFunc(discardPacket)
+					if sm.media.IsBackChannel {
+						onRTP = sm.readPacketRTPUDPPlay
 					}
-					buf = encr
-				}
-				err := sm.ss.s.udpRTPListener.write(buf, sm.udpRTPWriteAddr)
-				if err != nil {
-					return err
-				}
+					sm.udpRTPListener.addClient(sm.ss.author.ip(), sm.udpRTPReadPort,
+						rtcpMuxReadFunc(onRTP, sm.readPacketRTCPUDPPlay))
 
-				buf, _ = (&rtcp.ReceiverReport{}).Marshal()
-				if sm.srtpOutCtx != nil {
-					encr := make([]byte, sm.ss.s.MaxPacketSize)
-					encr, err = sm.srtpOutCtx.encryptRTCP(encr, buf, nil)
-					if err != nil {
-						return err
-					}
-					buf = encr
+				case sm.media.IsBackChannel:
+					sm.udpRTPListener.addClient(sm.ss.author.ip(), sm.udpRTPReadPort, sm.readPacketRTPUDPPlay)
+					sm.udpRTCPListener.addClient(sm.ss.author.ip(), sm.udpRTCPReadPort, sm.readPacketRTCPUDPPlay)
+
+				default:
+					sm.udpRTCPListener.addClient(sm.ss.author.ip(), sm.udpRTCPReadPort, sm.readPacketRTCPUDPPlay)
 				}
-				err = sm.ss.s.udpRTCPListener.write(buf, sm.udpRTCPWriteAddr)
+			} else {
+				err := sm.openFirewall()
 				if err != nil {
 					return err
 				}
 
-				sm.ss.s.udpRTPListener.addClient(sm.ss.author.ip(), sm.udpRTPReadPort, sm.readPacketRTPUDPRecord)
-				sm.ss.s.udpRTCPListener.addClient(sm.ss.author.ip(), sm.udpRTCPReadPort, sm.readPacketRTCPUDPRecord)
+				if sm.rtcpMux {
+					sm.udpRTPListener.addClient(sm.ss.author.ip(), sm.udpRTPReadPort,
+						rtcpMuxReadFunc(sm.readPacketRTPUDPRecord, sm.readPacketRTCPUDPRecord))
+				} else {
+					sm.udpRTPListener.addClient(sm.ss.author.ip(), sm.udpRTPReadPort, sm.readPacketRTPUDPRecord)
+					sm.udpRTCPListener.addClient(sm.ss.author.ip(), sm.udpRTCPReadPort, sm.readPacketRTCPUDPRecord)
+				}
 			}
 		}
 
@@ -163,10 +181,41 @@ func (sm *serverSessionMedia) start() error {
 	return nil
 }
 
+// openFirewall opens the firewall, or keeps it open,
+// by sending empty packets to the remote part.
+func (sm *serverSessionMedia) openFirewall() error {
+	buf, _ := (&rtp.Packet{Header: rtp.Header{Version: 2}}).Marshal()
+	if sm.srtpOutCtx != nil {
+		encr := make([]byte, sm.ss.s.MaxPacketSize)
+		encr, err := sm.srtpOutCtx.encryptRTP(encr, buf, nil)
+		if err != nil {
+			return err
+		}
+		buf = encr
+	}
+	err := sm.udpRTPListener.write(buf, sm.udpRTPWriteAddr.Load(), sm.ss.onAsyncWriteError)
+	if err != nil {
+		return err
+	}
+
+	buf, _ = (&rtcp.ReceiverReport{}).Marshal()
+	if sm.srtpOutCtx != nil {
+		encr := make([]byte, sm.ss.s.MaxPacketSize)
+		encr, err = sm.srtpOutCtx.encryptRTCP(encr, buf, nil)
+		if err != nil {
+			return err
+		}
+		buf = encr
+	}
+	return sm.udpRTCPListener.write(buf, sm.udpRTCPWriteAddr.Load(), sm.ss.onAsyncWriteError)
+}
+
 func (sm *serverSessionMedia) stop() {
 	if *sm.ss.setuppedTransport == TransportUDP {
-		sm.ss.s.udpRTPListener.removeClient(sm.ss.author.ip(), sm.udpRTPReadPort)
-		sm.ss.s.udpRTCPListener.removeClient(sm.ss.author.ip(), sm.udpRTCPReadPort)
+		sm.udpRTPListener.removeClient(sm.ss.author.ip(), sm.udpRTPReadPort)
+		if !sm.rtcpMux {
+			sm.udpRTCPListener.removeClient(sm.ss.author.ip(), sm.udpRTCPReadPort)
+		}
 	}
 
 	for _, sf := range sm.formats {
@@ -174,6 +223,32 @@ func (sm *serverSessionMedia) stop() {
 	}
 }
 
+// addLatches allows to learn the real address of the client from
+// the first packets it sends, and to use it to send packets (symmetric RTP).
+func (sm *serverSessionMedia) addLatches() {
+	sm.udpRTPListener.addLatch(sm.ss.author.ip(), func(addr *net.UDPAddr) {
+		sm.udpRTPWriteAddr.Store(addr)
+		if sm.rtcpMux {
+			sm.udpRTCPWriteAddr.Store(addr)
+		}
+	})
+
+	if !sm.rtcpMux {
+		sm.udpRTCPListener.addLatch(sm.ss.author.ip(), func(addr *net.UDPAddr) {
+			sm.udpRTCPWriteAddr.Store(addr)
+		})
+	}
+}
+
+func (sm *serverSessionMedia) close() {
+	if sm.udpDedicated {
+		sm.udpRTPListener.close()
+		if !sm.rtcpMux {
+			sm.udpRTCPListener.close()
+		}
+	}
+}
+
 func (sm *serverSessionMedia) findFormatByRemoteSSRC(ssrc uint32) *serverSessionFormat {
 	for _, format := range sm.formats {
 		stats := format.rtcpReceiver.Stats()
@@ -184,6 +259,83 @@ func (sm *serverSessionMedia) findFormatByRemoteSSRC(ssrc uint32) *serverSession
 	return nil
 }
 
+func (sm *serverSessionMedia) findFormatByLocalSSRC(ssrc uint32) *serverSessionFormat {
+	for _, sf := range sm.formats {
+		if sf.localSSRC == ssrc {
+			return sf
+		}
+	}
+	return nil
+}
+
+func (sm *serverSessionMedia) handleKeyFrameRequest(pkt rtcp.Packet) {
+	ssrcs, ok := keyFrameRequestSSRCs(pkt)
+	if !ok {
+		return
+	}
+
+	for _, ssrc := range ssrcs {
+		sf := sm.findFormatByLocalSSRC(ssrc)
+
+		// some clients do not fill the media SSRC
+		if sf == nil && len(sm.formats) == 1 {
+			for _, sf2 := range sm.formats {
+				sf = sf2
+			}
+		}
+
+		if sf == nil {
+			continue
+		}
+
+		if h, ok := sm.ss.s.Handler.(ServerHandlerOnKeyFrameRequest); ok {
+			h.OnKeyFrameRequest(&ServerHandlerOnKeyFrameRequestCtx{
+				Session: sm.ss,
+				Stream:  sm.ss.setuppedStream,
+				Media:   sm.media,
+				Format:  sf.format,
+			})
+		}
+
+		if sm.ss.setuppedStream.OnKeyFrameRequest != nil {
+			sm.ss.setuppedStream.OnKeyFrameRequest(sm.media, sf.format)
+		}
+	}
+}
+
+func (sm *serverSessionMedia) handleNACK(pkt rtcp.Packet) {
+	nack, ok := pkt.(*rtcp.TransportLayerNack)
+	if !ok || *sm.ss.setuppedTransport != TransportUDP {
+		return
+	}
+
+	sf := sm.findFormatByLocalSSRC(nack.MediaSSRC)
+	if sf == nil {
+		return
+	}
+
+	if sf.rtxSender == nil {
+		return
+	}
+
+	for _, pair := range nack.Nacks {
+		atomic.AddUint64(sf.rtpPacketsNACKed, uint64(len(pair.PacketList())))
+	}
+
+	rtxFormat := sm.formats[sf.rtxSender.PayloadType]
+
+	rtxPkts := sf.rtxSender.ProcessNACK(nack)
+	atomic.AddUint64(sf.rtpPacketsRetrans, uint64(len(rtxPkts)))
+
+	for _, rtxPkt := range rtxPkts {
+		err := rtxFormat.writePacketRTP(rtxPkt.Packet)
+		if err != nil {
+			sm.ss.onStreamWriteError(err)
+			return
+		}
+	}
+}
+
 func (sm *serverSessionMedia) decodeRTP(payload []byte) (*rtp.Packet, error) {
 	if sm.srtpInCtx != nil {
 		var err error
@@ -262,6 +414,8 @@ func (sm *serverSessionMedia) readPacketRTCPUDPPlay(payload []byte) bool {
 	atomic.AddUint64(sm.rtcpPacketsReceived, uint64(len(packets)))
 
 	for _, pkt := range packets {
+		sm.handleKeyFrameRequest(pkt)
+		sm.handleNACK(pkt)
 		sm.onPacketRTCP(pkt)
 	}
 
@@ -370,6 +524,8 @@ func (sm *serverSessionMedia) readPacketRTCPTCPPlay(payload []byte) bool {
 	atomic.AddUint64(sm.rtcpPacketsReceived, uint64(len(packets)))
 
 	for _, pkt := range packets {
+		sm.handleKeyFrameRequest(pkt)
+		sm.handleNACK(pkt)
 		sm.onPacketRTCP(pkt)
 	}
 
@@ -493,7 +649,7 @@ func (sm *serverSessionMedia) writePacketRTCPEncoded(payload []byte) error {
 	}
 
 	ok := sm.ss.writer.push(func() error {
-		return sm.writePacketRTCPInQueue(payload)
+		return sm.writePacketRTCPInQueue(payload, nil)
 	})
 	if !ok {
 		return liberrors.ErrServerWriteQueueFull{}
@@ -502,8 +658,15 @@ func (sm *serverSessionMedia) writePacketRTCPEncoded(payload []byte) error {
 	return nil
 }
 
-func (sm *serverSessionMedia) writePacketRTCPInQueueUDP(payload []byte) error {
-	err := sm.ss.s.udpRTCPListener.write(payload, sm.udpRTCPWriteAddr)
+// writePacketRTCPInQueueUDP writes a packet.
+// If the packet is shared with other readers, it is released once written.
+func (sm *serverSessionMedia) writePacketRTCPInQueueUDP(payload []byte, shared *serverStreamPacket) error {
+	var err error
+	if shared != nil {
+		err = sm.udpRTCPListener.writeShared(payload, sm.udpRTCPWriteAddr.Load(), shared, sm.ss.onAsyncWriteError)
+	} else {
+		err = sm.udpRTCPListener.write(payload, sm.udpRTCPWriteAddr.Load(), sm.ss.onAsyncWriteError)
+	}
 	if err != nil {
 		return err
 	}
@@ -513,7 +676,11 @@ func (sm *serverSessionMedia) writePacketRTCPInQueueUDP(payload []byte) error {
 	return nil
 }
 
-func (sm *serverSessionMedia) writePacketRTCPInQueueTCP(payload []byte) error {
+func (sm *serverSessionMedia) writePacketRTCPInQueueTCP(payload []byte, shared *serverStreamPacket) error {
+	if shared != nil {
+		defer shared.release()
+	}
+
 	sm.ss.tcpFrame.Channel = sm.tcpChannel + 1
 	sm.ss.tcpFrame.Payload = payload
 	sm.ss.tcpConn.nconn.SetWriteDeadline(time.Now().Add(sm.ss.s.WriteTimeout))
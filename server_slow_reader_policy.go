@@ -0,0 +1,36 @@
+package gortsplib
+
+// ServerSlowReaderPolicy is the action taken by a ServerStream
+// when a reader is unable to keep up with the stream.
+type ServerSlowReaderPolicy int
+
+// slow reader policies.
+const (
+	// packets that have not been sent yet are discarded and replaced by newer ones.
+	ServerSlowReaderPolicyDropOldest ServerSlowReaderPolicy = iota
+
+	// packets are discarded until the next key frame of each media.
+	ServerSlowReaderPolicyDropUntilKeyFrame
+
+	// the reader is disconnected when its backlog lasts more than Server.SlowReaderMaxBacklog.
+	ServerSlowReaderPolicyDisconnect
+
+	// packets of non-reference frames are skipped as long as the backlog is high.
+	// Packets that are not sent in time are discarded like with ServerSlowReaderPolicyDropOldest.
+	ServerSlowReaderPolicySkipNonReference
+)
+
+var serverSlowReaderPolicyLabels = map[ServerSlowReaderPolicy]string{
+	ServerSlowReaderPolicyDropOldest:        "drop oldest",
+	ServerSlowReaderPolicyDropUntilKeyFrame: "drop until key frame",
+	ServerSlowReaderPolicyDisconnect:        "disconnect",
+	ServerSlowReaderPolicySkipNonReference:  "skip non-reference frames",
+}
+
+// String implements fmt.Stringer.
+func (p ServerSlowReaderPolicy) String() string {
+	if l, ok := serverSlowReaderPolicyLabels[p]; ok {
+		return l
+	}
+	return "unknown"
+}
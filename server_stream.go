@@ -2,6 +2,7 @@ package gortsplib
 
 import (
 	"fmt"
+	"net"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
 )
 
@@ -38,11 +40,33 @@ type ServerStream struct {
 	Server *Server
 	Desc   *description.Session
 
+	// (optional) add the ONVIF replay RTP header extension to outgoing RTP packets.
+	// It is needed to serve ONVIF recordings.
+	ReplayExtension bool
+
+	// (optional) keep the packets of video formats (H264, H265, AV1, VP8, VP9)
+	// since the last random access point, and send them to new readers right after PLAY,
+	// in order to allow them to start decoding immediately.
+	// It is not used with the UDP-multicast transport.
+	GOPCache bool
+
+	// (optional) maximum size of the GOP cache of each format, in bytes.
+	// When it is exceeded, caching is suspended until the next random access point.
+	// It defaults to 4 MiB.
+	GOPCacheMaxSize int
+
+	// (optional) called when a reader requests a key frame (RTCP PLI or FIR).
+	// It can be used to forward the request to the source of the stream,
+	// for instance with ServerSession.RequestKeyFrame() or Client.RequestKeyFrame().
+	OnKeyFrameRequest func(medi *description.Media, forma format.Format)
+
 	mutex                sync.RWMutex
 	readers              map[*ServerSession]struct{}
 	multicastReaderCount int
-	activeUnicastReaders map[*ServerSession]struct{}
+	activeUnicastReaders map[*ServerSession]*serverStreamReader
 	medias               map[*description.Media]*serverStreamMedia
+	ring                 *serverStreamRing
+	packetPool           sync.Pool
 	closed               bool
 }
 
@@ -52,8 +76,25 @@ func (st *ServerStream) Initialize() error {
 		return fmt.Errorf("server not present or not initialized")
 	}
 
+	if st.GOPCacheMaxSize == 0 {
+		st.GOPCacheMaxSize = 4 * 1024 * 1024
+	}
+
 	st.readers = make(map[*ServerSession]struct{})
-	st.activeUnicastReaders = make(map[*ServerSession]struct{})
+	st.activeUnicastReaders = make(map[*ServerSession]*serverStreamReader)
+
+	st.ring = &serverStreamRing{
+		size: uint64(st.Server.WriteQueueSize),
+	}
+	st.ring.initialize()
+
+	st.packetPool.New = func() any {
+		// plain and encrypted payloads are stored in the same buffer.
+		return &serverStreamPacket{
+			pool: &st.packetPool,
+			buf:  make([]byte, 2*st.Server.MaxPacketSize),
+		}
+	}
 
 	st.medias = make(map[*description.Media]*serverStreamMedia, len(st.Desc.Medias))
 	for i, medi := range st.Desc.Medias {
@@ -189,18 +230,6 @@ func (st *ServerStream) readerAdd(
 		}
 
 	case TransportUDPMulticast:
-		if st.multicastReaderCount == 0 {
-			for _, media := range st.medias {
-				mw := &serverMulticastWriter{
-					s: st.Server,
-				}
-				err := mw.initialize()
-				if err != nil {
-					return err
-				}
-				media.multicastWriter = mw
-			}
-		}
 		st.multicastReaderCount++
 	}
 
@@ -209,6 +238,50 @@ func (st *ServerStream) readerAdd(
 	return nil
 }
 
+// readerSetupMulticast allocates the multicast writer of a media, if not allocated yet,
+// with parameters requested by the reader.
+func (st *ServerStream) readerSetupMulticast(
+	medi *description.Media,
+	destination *net.IP,
+	ports *[2]int,
+	ttl *uint,
+) (*serverMulticastWriter, error) {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	if st.closed {
+		return nil, liberrors.ErrServerStreamClosed{}
+	}
+
+	sm := st.medias[medi]
+
+	if sm.multicastWriter != nil {
+		if !sm.multicastWriter.matches(destination, ports, ttl) {
+			return nil, liberrors.ErrServerMulticastGroupInUse{}
+		}
+		return sm.multicastWriter, nil
+	}
+
+	mw := &serverMulticastWriter{
+		s:           st.Server,
+		ring:        st.ring,
+		media:       medi,
+		secure:      sm.srtpOutCtx != nil,
+		stats:       &sm.multicastReaderStats,
+		destination: destination,
+		ports:       ports,
+		ttl:         ttl,
+	}
+	err := mw.initialize()
+	if err != nil {
+		return nil, err
+	}
+	sm.multicastWriter = mw
+	sm.addReader(mw.reader)
+
+	return mw, nil
+}
+
 func (st *ServerStream) readerRemove(ss *ServerSession) {
 	st.mutex.Lock()
 	defer st.mutex.Unlock()
@@ -223,21 +296,28 @@ func (st *ServerStream) readerRemove(ss *ServerSession) {
 		st.multicastReaderCount--
 		if st.multicastReaderCount == 0 {
 			for _, media := range st.medias {
-				media.multicastWriter.close()
-				media.multicastWriter = nil
+				if media.multicastWriter != nil {
+					media.removeReader(media.multicastWriter.reader)
+					media.multicastWriter.close()
+					media.multicastWriter = nil
+				}
 			}
 		}
 	}
 }
 
-func (st *ServerStream) readerSetActive(ss *ServerSession) {
+// readerSetActive starts sending packets to a reader.
+// It returns the RTP-Info entries of medias whose GOP cache is replayed to the reader.
+func (st *ServerStream) readerSetActive(ss *ServerSession) map[*description.Media]*headers.RTPInfoEntry {
 	st.mutex.Lock()
 	defer st.mutex.Unlock()
 
 	if st.closed {
-		return
+		return nil
 	}
 
+	var rtpInfo map[*description.Media]*headers.RTPInfoEntry
+
 	if *ss.setuppedTransport == TransportUDPMulticast {
 		for medi, sm := range ss.setuppedMedias {
 			streamMedia := st.medias[medi]
@@ -245,8 +325,64 @@ func (st *ServerStream) readerSetActive(ss *ServerSession) {
 				ss.author.ip(), streamMedia.multicastWriter.rtcpl.port(), sm.readPacketRTCPUDPPlay)
 		}
 	} else {
-		st.activeUnicastReaders[ss] = struct{}{}
+		medias := make(map[*description.Media]struct{}, len(ss.setuppedMedias))
+		for medi := range ss.setuppedMedias {
+			medias[medi] = struct{}{}
+		}
+
+		r := &serverStreamReader{
+			ring:        st.ring,
+			medias:      medias,
+			secure:      ss.setuppedSecure,
+			policy:      ss.getSlowReaderPolicy(),
+			maxBacklog:  st.Server.SlowReaderMaxBacklog,
+			timeNow:     st.Server.timeNow,
+			stats:       &ss.readerStats,
+			schedule:    ss.pushToWriter,
+			writePacket: ss.writeStreamPacket,
+			onError:     ss.onStreamWriteError,
+		}
+		r.initialize()
+
+		st.activeUnicastReaders[ss] = r
+
+		for _, sm := range ss.setuppedMediasOrdered {
+			streamMedia := st.medias[sm.media]
+
+			for _, forma := range sm.media.Formats {
+				sf := streamMedia.formats[forma.PayloadType()]
+				if sf.gopCache == nil {
+					continue
+				}
+
+				pkts, seqNum, ts := sf.gopCache.replay(ss.setuppedSecure)
+				if pkts == nil {
+					continue
+				}
+
+				for _, pkt := range pkts {
+					r.replay = append(r.replay, serverStreamReaderPacket{media: sm.media, pkt: pkt})
+				}
+
+				// RTP-Info does not support multiple formats inside a single media stream.
+				if len(sm.media.Formats) == 1 {
+					if rtpInfo == nil {
+						rtpInfo = make(map[*description.Media]*headers.RTPInfoEntry)
+					}
+					rtpInfo[sm.media] = &headers.RTPInfoEntry{
+						SequenceNumber: &seqNum,
+						Timestamp:      &ts,
+					}
+				}
+			}
+
+			streamMedia.addReader(r)
+		}
+
+		r.notify()
 	}
+
+	return rtpInfo
 }
 
 func (st *ServerStream) readerSetInactive(ss *ServerSession) {
@@ -262,11 +398,24 @@ func (st *ServerStream) readerSetInactive(ss *ServerSession) {
 			streamMedia := st.medias[medi]
 			streamMedia.multicastWriter.rtcpl.removeClient(ss.author.ip(), streamMedia.multicastWriter.rtcpl.port())
 		}
-	} else {
+	} else if r, ok := st.activeUnicastReaders[ss]; ok {
+		r.close()
+
 		delete(st.activeUnicastReaders, ss)
+
+		for medi := range ss.setuppedMedias {
+			st.medias[medi].removeReader(r)
+		}
 	}
 }
 
+// newPacket returns a shared packet from the pool, referenced by the caller.
+func (st *ServerStream) newPacket() *serverStreamPacket {
+	pkt := st.packetPool.Get().(*serverStreamPacket)
+	pkt.refs.Store(1)
+	return pkt
+}
+
 // WritePacketRTP writes a RTP packet to all the readers of the stream.
 func (st *ServerStream) WritePacketRTP(medi *description.Media, pkt *rtp.Packet) error {
 	return st.WritePacketRTPWithNTP(medi, pkt, st.Server.timeNow())
@@ -274,6 +423,9 @@ func (st *ServerStream) WritePacketRTP(medi *description.Media, pkt *rtp.Packet)
 
 // WritePacketRTPWithNTP writes a RTP packet to all the readers of the stream.
 // ntp is the absolute timestamp of the packet, and is sent with periodic RTCP sender reports.
+// If ReplayExtension is true, ntp is also written into the ONVIF replay RTP header extension.
+// Flags of an existing replay extension are preserved, otherwise the clean point flag is filled automatically
+// and CSeq is filled, for each reader, with the CSeq of the last PLAY request of the reader.
 func (st *ServerStream) WritePacketRTPWithNTP(medi *description.Media, pkt *rtp.Packet, ntp time.Time) error {
 	st.mutex.RLock()
 	defer st.mutex.RUnlock()
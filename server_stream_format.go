@@ -6,11 +6,16 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/bluenviron/mediacommon/v2/pkg/codecs/h265"
 	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
 
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
 	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/gortsplib/v4/pkg/onvif"
 	"github.com/bluenviron/gortsplib/v4/pkg/rtcpsender"
+	"github.com/bluenviron/gortsplib/v4/pkg/rtx"
+	"github.com/bluenviron/gortsplib/v4/pkg/ulpfec"
 )
 
 func randUint32() (uint32, error) {
@@ -47,12 +52,59 @@ func serverStreamPickLocalSSRC(sf *serverStreamFormat) (uint32, error) {
 	}
 }
 
+// isKeyFramePacket checks whether a RTP packet is a key frame,
+// from which readers that lost packets can start decoding again.
+func isKeyFramePacket(forma format.Format, pkt *rtp.Packet, ptsEqualsDTS bool) bool {
+	switch forma.(type) {
+	case *format.AV1, *format.VP8, *format.VP9:
+		// PTSEqualsDTS() is always true with these formats.
+		return isRandomAccessPacket(forma, pkt)
+	}
+
+	// with H264 and H265, PTSEqualsDTS() is true with random access points
+	// and with the parameters that precede them.
+	return ptsEqualsDTS
+}
+
+// isNonReferencePacket checks whether a RTP packet belongs to a frame
+// that is not used as reference by other frames, and can be skipped without affecting decoding.
+func isNonReferencePacket(forma format.Format, pkt *rtp.Packet) bool {
+	if len(pkt.Payload) == 0 {
+		return false
+	}
+
+	switch forma.(type) {
+	case *format.H264:
+		// nal_ref_idc of single NALUs, STAP-A and FU-A indicators
+		return (pkt.Payload[0]>>5)&0b11 == 0
+
+	case *format.H265:
+		typ := h265.NALUType((pkt.Payload[0] >> 1) & 0b111111)
+
+		if typ == h265.NALUType_FragmentationUnit {
+			if len(pkt.Payload) < 3 {
+				return false
+			}
+			typ = h265.NALUType(pkt.Payload[2] & 0b111111)
+		}
+
+		// sub-layer non-reference pictures have even types
+		return typ <= h265.NALUType_RSV_VCL_N14 && typ%2 == 0
+	}
+
+	return false
+}
+
 type serverStreamFormat struct {
 	sm     *serverStreamMedia
 	format format.Format
 
 	localSSRC      uint32
 	rtcpSender     *rtcpsender.RTCPSender
+	rtxBuffer      *rtx.Buffer
+	fecEncoder     *ulpfec.Encoder
+	fecMedia       *description.Media
+	gopCache       *serverStreamGOPCache
 	rtpPacketsSent *uint64
 }
 
@@ -77,6 +129,34 @@ func (sf *serverStreamFormat) initialize() error {
 	}
 	sf.rtcpSender.Initialize()
 
+	// the buffer is shared by readers,
+	// while sequence numbers of retransmissions are generated by each reader.
+	if rtxFormat := findRTXFormat(sf.sm.media, sf.format); rtxFormat != nil {
+		sf.rtxBuffer = &rtx.Buffer{
+			Size: sf.sm.st.Server.RetransmissionBufferSize,
+		}
+		sf.rtxBuffer.Initialize()
+	}
+
+	if fecMedia, fecFormat := findFECFormat(sf.sm.st.Desc, sf.sm.media); fecMedia != nil {
+		sf.fecMedia = fecMedia
+		sf.fecEncoder = &ulpfec.Encoder{
+			PayloadType: fecFormat.PayloadType(),
+			GroupSize:   sf.sm.st.Server.FECGroupSize,
+		}
+		err = sf.fecEncoder.Initialize()
+		if err != nil {
+			return err
+		}
+	}
+
+	if sf.sm.st.GOPCache && gopCacheSupported(sf.format) {
+		sf.gopCache = &serverStreamGOPCache{
+			format:  sf.format,
+			maxSize: sf.sm.st.GOPCacheMaxSize,
+		}
+	}
+
 	return nil
 }
 
@@ -84,83 +164,84 @@ func (sf *serverStreamFormat) close() {
 	if sf.rtcpSender != nil {
 		sf.rtcpSender.Close()
 	}
+
+	if sf.gopCache != nil {
+		sf.gopCache.close()
+	}
 }
 
 func (sf *serverStreamFormat) writePacketRTP(pkt *rtp.Packet, ntp time.Time) error {
 	pkt.SSRC = sf.localSSRC
 
-	sf.rtcpSender.ProcessPacket(pkt, ntp, sf.format.PTSEqualsDTS(pkt))
+	ptsEqualsDTS := sf.format.PTSEqualsDTS(pkt)
+	keyFrame := isKeyFramePacket(sf.format, pkt, ptsEqualsDTS)
+
+	sf.rtcpSender.ProcessPacket(pkt, ntp, ptsEqualsDTS)
+
+	if sf.sm.st.ReplayExtension {
+		var e onvif.ReplayExtension
+		err := e.Unmarshal(&pkt.Header)
+		if err != nil {
+			// CSeq is filled by each reader
+			e = onvif.ReplayExtension{
+				CleanPoint: keyFrame,
+			}
+		}
+		e.NTP = ntp
+
+		err = e.Marshal(&pkt.Header)
+		if err != nil {
+			return err
+		}
+	}
 
 	maxPlainPacketSize := sf.sm.st.Server.MaxPacketSize
 	if sf.sm.srtpOutCtx != nil {
 		maxPlainPacketSize -= srtpOverhead
 	}
 
-	plain := make([]byte, maxPlainPacketSize)
-	n, err := pkt.MarshalTo(plain)
+	shared := sf.sm.st.newPacket()
+	defer shared.release()
+
+	shared.rtcp = false
+	shared.payloadType = pkt.PayloadType
+	shared.keyFrame = keyFrame
+	shared.nonReference = isNonReferencePacket(sf.format, pkt)
+
+	n, err := pkt.MarshalTo(shared.buf[:maxPlainPacketSize])
 	if err != nil {
 		return err
 	}
-	plain = plain[:n]
+	shared.plain = shared.buf[:n]
+
+	if sf.rtxBuffer != nil {
+		sf.rtxBuffer.Add(pkt, ntp)
+	}
 
-	var encr []byte
 	if sf.sm.srtpOutCtx != nil {
-		encr = make([]byte, sf.sm.st.Server.MaxPacketSize)
-		encr, err = sf.sm.srtpOutCtx.encryptRTP(encr, plain, &pkt.Header)
+		shared.encr, err = sf.sm.srtpOutCtx.encryptRTP(
+			shared.buf[sf.sm.st.Server.MaxPacketSize:], shared.plain, &pkt.Header)
 		if err != nil {
 			return err
 		}
 	}
 
-	encrLen := uint64(len(encr))
-	plainLen := uint64(len(plain))
-
-	// send unicast
-	for r := range sf.sm.st.activeUnicastReaders {
-		if rsm, ok := r.setuppedMedias[sf.sm.media]; ok {
-			rsf := rsm.formats[pkt.PayloadType]
-
-			if r.setuppedSecure {
-				err := rsf.writePacketRTPEncoded(encr)
-				if err != nil {
-					r.onStreamWriteError(err)
-					continue
-				}
-
-				atomic.AddUint64(sf.sm.bytesSent, encrLen)
-			} else {
-				err := rsf.writePacketRTPEncoded(plain)
-				if err != nil {
-					r.onStreamWriteError(err)
-					continue
-				}
-
-				atomic.AddUint64(sf.sm.bytesSent, plainLen)
-			}
-
-			atomic.AddUint64(sf.rtpPacketsSent, 1)
-		}
+	if sf.gopCache != nil {
+		sf.gopCache.add(pkt, shared)
 	}
 
-	// send multicast
-	if sf.sm.multicastWriter != nil {
-		if sf.sm.srtpOutCtx != nil {
-			err := sf.sm.multicastWriter.writePacketRTP(encr)
-			if err != nil {
-				return err
-			}
-
-			atomic.AddUint64(sf.sm.bytesSent, encrLen)
-		} else {
-			err := sf.sm.multicastWriter.writePacketRTP(plain)
-			if err != nil {
-				return err
-			}
+	sent := sf.sm.writePacket(shared)
+	atomic.AddUint64(sf.rtpPacketsSent, sent)
 
-			atomic.AddUint64(sf.sm.bytesSent, plainLen)
+	if sf.fecEncoder != nil {
+		fecPkt, err := sf.fecEncoder.Encode(pkt)
+		if err != nil {
+			return err
 		}
 
-		atomic.AddUint64(sf.rtpPacketsSent, 1)
+		if fecPkt != nil {
+			return sf.sm.st.medias[sf.fecMedia].formats[fecPkt.PayloadType].writePacketRTP(fecPkt, ntp)
+		}
 	}
 
 	return nil
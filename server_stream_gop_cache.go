@@ -0,0 +1,255 @@
+package gortsplib
+
+import (
+	"encoding/binary"
+
+	"github.com/bluenviron/mediacommon/v2/pkg/codecs/h264"
+	"github.com/bluenviron/mediacommon/v2/pkg/codecs/h265"
+	"github.com/pion/rtp"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+)
+
+func isH264RandomAccess(typ h264.NALUType) bool {
+	return typ == h264.NALUTypeIDR
+}
+
+func isH265RandomAccess(typ h265.NALUType) bool {
+	return typ >= h265.NALUType_BLA_W_LP && typ <= h265.NALUType_RSV_IRAP_VCL23
+}
+
+// isRandomAccessPacket checks whether a RTP packet contains the beginning of a random access point,
+// from which decoding can start.
+func isRandomAccessPacket(forma format.Format, pkt *rtp.Packet) bool {
+	payload := pkt.Payload
+	if len(payload) == 0 {
+		return false
+	}
+
+	switch forma.(type) {
+	case *format.H264:
+		typ := h264.NALUType(payload[0] & 0x1F)
+
+		switch typ {
+		case 24: // STAP-A
+			payload = payload[1:]
+
+			for len(payload) >= 2 {
+				size := int(uint16(payload[0])<<8 | uint16(payload[1]))
+				payload = payload[2:]
+
+				if size == 0 || size > len(payload) {
+					return false
+				}
+
+				if isH264RandomAccess(h264.NALUType(payload[0] & 0x1F)) {
+					return true
+				}
+				payload = payload[size:]
+			}
+			return false
+
+		case 28: // FU-A
+			return len(payload) >= 2 && (payload[1]>>7) == 1 &&
+				isH264RandomAccess(h264.NALUType(payload[1]&0x1F))
+		}
+
+		return isH264RandomAccess(typ)
+
+	case *format.H265:
+		if len(payload) < 2 {
+			return false
+		}
+
+		typ := h265.NALUType((payload[0] >> 1) & 0b111111)
+
+		switch typ {
+		case h265.NALUType_AggregationUnit:
+			payload = payload[2:]
+
+			for len(payload) >= 2 {
+				size := int(uint16(payload[0])<<8 | uint16(payload[1]))
+				payload = payload[2:]
+
+				if size == 0 || size > len(payload) {
+					return false
+				}
+
+				if isH265RandomAccess(h265.NALUType((payload[0] >> 1) & 0b111111)) {
+					return true
+				}
+				payload = payload[size:]
+			}
+			return false
+
+		case h265.NALUType_FragmentationUnit:
+			return len(payload) >= 3 && (payload[2]>>7) == 1 &&
+				isH265RandomAccess(h265.NALUType(payload[2]&0b111111))
+		}
+
+		return isH265RandomAccess(typ)
+
+	case *format.AV1:
+		// N bit of the aggregation header: first packet of a coded video sequence
+		return (payload[0] & 0x08) != 0
+
+	case *format.VP8:
+		// S bit and partition index of the payload descriptor
+		if (payload[0]&0x10) == 0 || (payload[0]&0x07) != 0 {
+			return false
+		}
+
+		i := 1
+
+		// extensions
+		if (payload[0] & 0x80) != 0 {
+			if len(payload) < 2 {
+				return false
+			}
+			ext := payload[1]
+			i++
+
+			if (ext & 0x80) != 0 { // picture ID
+				if len(payload) <= i {
+					return false
+				}
+				if (payload[i] & 0x80) != 0 {
+					i += 2
+				} else {
+					i++
+				}
+			}
+			if (ext & 0x40) != 0 { // TL0PICIDX
+				i++
+			}
+			if (ext & 0x30) != 0 { // TID, KEYIDX
+				i++
+			}
+		}
+
+		// P bit of the payload header
+		return len(payload) > i && (payload[i]&0x01) == 0
+
+	case *format.VP9:
+		// P and B bits of the payload descriptor
+		return (payload[0]&0x40) == 0 && (payload[0]&0x08) != 0
+	}
+
+	return false
+}
+
+// gopCacheSupported checks whether the GOP cache can be used with a format.
+func gopCacheSupported(forma format.Format) bool {
+	switch forma.(type) {
+	case *format.H264, *format.H265, *format.AV1, *format.VP8, *format.VP9:
+		return true
+	}
+	return false
+}
+
+type serverStreamGOPCacheEntry struct {
+	pkt       *serverStreamPacket
+	seqNum    uint16
+	timestamp uint32
+}
+
+// serverStreamGOPCache keeps the packets of a format since the last random access point,
+// in order to send them to new readers, that can start decoding immediately.
+// It is filled while the stream is read-locked by the only routine that writes the format,
+// and read while the stream is locked.
+type serverStreamGOPCache struct {
+	format  format.Format
+	maxSize int
+
+	entries []serverStreamGOPCacheEntry
+	size    int
+}
+
+func (c *serverStreamGOPCache) close() {
+	c.reset(0)
+}
+
+// reset removes all entries except the last n ones.
+func (c *serverStreamGOPCache) reset(n int) {
+	for _, e := range c.entries[:len(c.entries)-n] {
+		e.pkt.release()
+	}
+
+	kept := c.entries[len(c.entries)-n:]
+	c.size = 0
+	for _, e := range kept {
+		c.size += len(e.pkt.plain)
+	}
+
+	copy(c.entries, kept)
+	clear(c.entries[n:])
+	c.entries = c.entries[:n]
+}
+
+// add adds a packet to the cache.
+func (c *serverStreamGOPCache) add(pkt *rtp.Packet, shared *serverStreamPacket) {
+	if isRandomAccessPacket(c.format, pkt) &&
+		(len(c.entries) == 0 || c.entries[0].timestamp != pkt.Timestamp) {
+		// keep packets that belong to the same access unit, like parameters.
+		n := 0
+		for n < len(c.entries) && c.entries[len(c.entries)-1-n].timestamp == pkt.Timestamp {
+			n++
+		}
+		c.reset(n)
+	} else if len(c.entries) == 0 {
+		// wait for a random access point.
+		return
+	}
+
+	if c.size+len(shared.plain) > c.maxSize {
+		// the GOP is too big; wait for the next random access point.
+		c.reset(0)
+		return
+	}
+
+	shared.acquire(1)
+	c.entries = append(c.entries, serverStreamGOPCacheEntry{
+		pkt:       shared,
+		seqNum:    pkt.SequenceNumber,
+		timestamp: pkt.Timestamp,
+	})
+	c.size += len(shared.plain)
+}
+
+// replay returns the cached packets, referenced by the caller.
+// Sequence numbers are rewritten in order to be contiguous and to end
+// with the sequence number of the last packet that has been sent, making gaps disappear.
+// Packets protected with SRTP cannot be rewritten, and are returned unchanged.
+func (c *serverStreamGOPCache) replay(secure bool) ([]*serverStreamPacket, uint16, uint32) {
+	if len(c.entries) == 0 {
+		return nil, 0, 0
+	}
+
+	ret := make([]*serverStreamPacket, len(c.entries))
+
+	if secure {
+		for i, e := range c.entries {
+			e.pkt.acquire(1)
+			ret[i] = e.pkt
+		}
+		return ret, c.entries[0].seqNum, c.entries[0].timestamp
+	}
+
+	seqNum := c.entries[len(c.entries)-1].seqNum - uint16(len(c.entries)-1)
+
+	for i, e := range c.entries {
+		pkt := &serverStreamPacket{
+			payloadType: e.pkt.payloadType,
+			keyFrame:    e.pkt.keyFrame,
+			plain:       append([]byte(nil), e.pkt.plain...),
+		}
+		pkt.refs.Store(1)
+
+		// the sequence number is the third and fourth byte of the RTP header.
+		binary.BigEndian.PutUint16(pkt.plain[2:], seqNum+uint16(i))
+
+		ret[i] = pkt
+	}
+
+	return ret, seqNum, c.entries[0].timestamp
+}
@@ -0,0 +1,72 @@
+package gortsplib
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+)
+
+func TestIsRandomAccessPacket(t *testing.T) {
+	for _, ca := range []struct {
+		name    string
+		format  format.Format
+		payload []byte
+		ok      bool
+	}{
+		{"h264 idr", &format.H264{}, []byte{0x65, 0x88}, true},
+		{"h264 non-idr", &format.H264{}, []byte{0x41, 0x9a}, false},
+		{"h264 stap-a", &format.H264{}, []byte{0x18, 0x00, 0x02, 0x67, 0x01, 0x00, 0x02, 0x65, 0x01}, true},
+		{"h264 fu-a start", &format.H264{}, []byte{0x7c, 0x85, 0x01}, true},
+		{"h264 fu-a middle", &format.H264{}, []byte{0x7c, 0x05, 0x01}, false},
+		{"h265 idr", &format.H265{}, []byte{0x26, 0x01, 0xaf}, true},
+		{"h265 trail", &format.H265{}, []byte{0x02, 0x01, 0xd0}, false},
+		{"h265 fu start", &format.H265{}, []byte{0x62, 0x01, 0x93, 0xaf}, true},
+		{"av1 new sequence", &format.AV1{}, []byte{0x18, 0x0a}, true},
+		{"av1 other", &format.AV1{}, []byte{0x10, 0x32}, false},
+		{"vp8 key frame", &format.VP8{}, []byte{0x10, 0x00}, true},
+		{"vp8 key frame with picture id", &format.VP8{}, []byte{0x90, 0x80, 0x81, 0x02, 0x00}, true},
+		{"vp8 inter frame", &format.VP8{}, []byte{0x10, 0x01}, false},
+		{"vp9 key frame", &format.VP9{}, []byte{0x08, 0x00}, true},
+		{"vp9 inter frame", &format.VP9{}, []byte{0x48, 0x00}, false},
+		{"other format", &format.Opus{}, []byte{0x01}, false},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			require.Equal(t, ca.ok, isRandomAccessPacket(ca.format, &rtp.Packet{Payload: ca.payload}))
+		})
+	}
+}
+
+func TestServerStreamGOPCacheMaxSize(t *testing.T) {
+	c := &serverStreamGOPCache{
+		format:  &format.H264{},
+		maxSize: 10,
+	}
+	defer c.close()
+
+	add := func(seqNum uint16, payload []byte) {
+		shared := &serverStreamPacket{plain: make([]byte, 4)}
+		shared.refs.Store(1)
+		c.add(&rtp.Packet{
+			Header:  rtp.Header{SequenceNumber: seqNum, Timestamp: uint32(seqNum)},
+			Payload: payload,
+		}, shared)
+		shared.release()
+	}
+
+	add(1, []byte{0x65})
+	add(2, []byte{0x41})
+	require.Len(t, c.entries, 2)
+
+	// the GOP exceeds the maximum size and is discarded.
+	add(3, []byte{0x41})
+	require.Empty(t, c.entries)
+
+	add(4, []byte{0x41})
+	require.Empty(t, c.entries)
+
+	add(5, []byte{0x65})
+	require.Len(t, c.entries, 1)
+}
@@ -14,11 +14,14 @@ type serverStreamMedia struct {
 	media   *description.Media
 	trackID int
 
-	srtpOutCtx      *wrappedSRTPContext
-	formats         map[uint8]*serverStreamFormat
-	multicastWriter *serverMulticastWriter
-	bytesSent       *uint64
-	rtcpPacketsSent *uint64
+	srtpOutCtx           *wrappedSRTPContext
+	formats              map[uint8]*serverStreamFormat
+	multicastWriter      *serverMulticastWriter
+	readers              []*serverStreamReader
+	secureReaderCount    int
+	multicastReaderStats serverStreamReaderStats
+	bytesSent            *uint64
+	rtcpPacketsSent      *uint64
 }
 
 func (sm *serverStreamMedia) initialize() error {
@@ -80,6 +83,27 @@ func (sm *serverStreamMedia) close() {
 	}
 }
 
+func (sm *serverStreamMedia) addReader(r *serverStreamReader) {
+	sm.readers = append(sm.readers, r)
+
+	if r.secure {
+		sm.secureReaderCount++
+	}
+}
+
+func (sm *serverStreamMedia) removeReader(r *serverStreamReader) {
+	for i, cur := range sm.readers {
+		if cur == r {
+			sm.readers = append(sm.readers[:i], sm.readers[i+1:]...)
+			break
+		}
+	}
+
+	if r.secure {
+		sm.secureReaderCount--
+	}
+}
+
 func (sm *serverStreamMedia) writePacketRTCP(pkt rtcp.Packet) error {
 	plain, err := pkt.Marshal()
 	if err != nil {
@@ -95,63 +119,45 @@ func (sm *serverStreamMedia) writePacketRTCP(pkt rtcp.Packet) error {
 		return fmt.Errorf("packet is too big")
 	}
 
-	var encr []byte
+	// RTCP packets are rare, therefore they are not pooled.
+	shared := &serverStreamPacket{
+		rtcp:  true,
+		plain: plain,
+	}
+	shared.refs.Store(1)
+	defer shared.release()
+
 	if sm.srtpOutCtx != nil {
-		encr = make([]byte, sm.st.Server.MaxPacketSize)
-		encr, err = sm.srtpOutCtx.encryptRTCP(encr, plain, nil)
+		shared.encr = make([]byte, sm.st.Server.MaxPacketSize)
+		shared.encr, err = sm.srtpOutCtx.encryptRTCP(shared.encr, plain, nil)
 		if err != nil {
 			return err
 		}
 	}
 
-	encrLen := uint64(len(encr))
-	plainLen := uint64(len(plain))
-
-	// send unicast
-	for r := range sm.st.activeUnicastReaders {
-		if sm, ok := r.setuppedMedias[sm.media]; ok {
-			if r.setuppedSecure {
-				err := sm.writePacketRTCPEncoded(encr)
-				if err != nil {
-					r.onStreamWriteError(err)
-					continue
-				}
-
-				atomic.AddUint64(sm.bytesSent, encrLen)
-			} else {
-				err := sm.writePacketRTCPEncoded(plain)
-				if err != nil {
-					r.onStreamWriteError(err)
-					continue
-				}
-
-				atomic.AddUint64(sm.bytesSent, plainLen)
-			}
+	n := sm.writePacket(shared)
+	atomic.AddUint64(sm.rtcpPacketsSent, n)
+	return nil
+}
 
-			atomic.AddUint64(sm.rtcpPacketsSent, 1)
-		}
+// writePacket writes a shared packet to all the readers of the media,
+// and returns the number of readers the packet has been written to.
+// Readers, including the multicast writer, share a single copy of the packet,
+// that is read from the ring of the stream by each reader independently.
+func (sm *serverStreamMedia) writePacket(shared *serverStreamPacket) uint64 {
+	if len(sm.readers) == 0 {
+		return 0
 	}
 
-	// send multicast
-	if sm.multicastWriter != nil {
-		if sm.srtpOutCtx != nil {
-			err := sm.multicastWriter.writePacketRTCP(encr)
-			if err != nil {
-				return err
-			}
-
-			atomic.AddUint64(sm.bytesSent, encrLen)
-		} else {
-			err := sm.multicastWriter.writePacketRTCP(plain)
-			if err != nil {
-				return err
-			}
-
-			atomic.AddUint64(sm.bytesSent, plainLen)
-		}
+	sm.st.ring.write(sm.media, shared)
 
-		atomic.AddUint64(sm.rtcpPacketsSent, 1)
+	for _, r := range sm.readers {
+		r.notify()
 	}
 
-	return nil
+	secureCount := uint64(sm.secureReaderCount)
+	plainCount := uint64(len(sm.readers)) - secureCount
+	atomic.AddUint64(sm.bytesSent, uint64(len(shared.encr))*secureCount+uint64(len(shared.plain))*plainCount)
+
+	return uint64(len(sm.readers))
 }
@@ -0,0 +1,55 @@
+package gortsplib
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// serverStreamPacket is a packet that is encoded once by a ServerStream
+// and shared between all readers of the stream.
+// It is reference-counted, and its buffer is returned to a pool
+// once it has been overwritten in the ring and all readers have written it.
+type serverStreamPacket struct {
+	pool *sync.Pool // nil when the packet is not pooled
+	buf  []byte
+
+	rtcp         bool
+	payloadType  uint8
+	keyFrame     bool // whether decoding can start from the packet
+	nonReference bool // whether the packet belongs to a frame that is not used as reference
+	plain        []byte
+	encr         []byte
+	refs         atomic.Int32
+}
+
+func (p *serverStreamPacket) acquire(n int) {
+	p.refs.Add(int32(n))
+}
+
+// tryAcquire acquires the packet if it is still referenced.
+func (p *serverStreamPacket) tryAcquire() bool {
+	for {
+		refs := p.refs.Load()
+		if refs <= 0 {
+			return false
+		}
+		if p.refs.CompareAndSwap(refs, refs+1) {
+			return true
+		}
+	}
+}
+
+func (p *serverStreamPacket) release() {
+	if p.refs.Add(-1) == 0 && p.pool != nil {
+		p.plain = nil
+		p.encr = nil
+		p.pool.Put(p)
+	}
+}
+
+func (p *serverStreamPacket) payload(secure bool) []byte {
+	if secure {
+		return p.encr
+	}
+	return p.plain
+}
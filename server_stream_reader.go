@@ -0,0 +1,199 @@
+package gortsplib
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
+)
+
+// serverStreamReaderStats contains counters of the actions taken when a reader is too slow.
+type serverStreamReaderStats struct {
+	packetsDroppedOldest        atomic.Uint64
+	packetsDroppedUntilKeyFrame atomic.Uint64
+	packetsDroppedOnDisconnect  atomic.Uint64
+	packetsSkippedNonReference  atomic.Uint64
+}
+
+type serverStreamReaderPacket struct {
+	media *description.Media
+	pkt   *serverStreamPacket
+}
+
+// serverStreamReader reads packets of a ServerStream on behalf of a session or of a multicast writer.
+// It keeps a cursor in the ring of the stream and packets are written
+// by the writer routine of the owner, without allocating a closure per packet.
+// When the reader is too slow, the slow reader policy is applied.
+type serverStreamReader struct {
+	ring        *serverStreamRing
+	medias      map[*description.Media]struct{}
+	secure      bool
+	policy      ServerSlowReaderPolicy
+	maxBacklog  time.Duration
+	timeNow     func() time.Time
+	stats       *serverStreamReaderStats
+	schedule    func(func() error) bool
+	writePacket func(*description.Media, *serverStreamPacket) error
+	onError     func(error) // optional
+
+	next           uint64        // accessed by the writer routine only
+	end            atomic.Uint64 // sequence number after which packets are not read anymore
+	pending        atomic.Bool   // whether a drain has been scheduled
+	drainFunc      func() error
+	congestedSince time.Time // accessed by the writer routine only
+	waitKeyFrame   map[*description.Media]struct{}
+	replay         []serverStreamReaderPacket // packets that are written before the ones in the ring
+}
+
+func (r *serverStreamReader) initialize() {
+	r.next = r.ring.head.Load()
+	r.end.Store(math.MaxUint64)
+	r.drainFunc = r.drain
+	r.waitKeyFrame = make(map[*description.Media]struct{})
+}
+
+// close prevents the reader from reading packets that are added after the call.
+// It must be called while the stream is locked.
+func (r *serverStreamReader) close() {
+	r.end.Store(r.ring.head.Load())
+}
+
+// notify schedules the reading of new packets.
+func (r *serverStreamReader) notify() {
+	if !r.pending.CompareAndSwap(false, true) {
+		return
+	}
+
+	if !r.schedule(r.drainFunc) {
+		r.pending.Store(false)
+	}
+}
+
+// drain writes all packets between the cursor and the head of the ring.
+func (r *serverStreamReader) drain() error {
+	r.pending.Store(false)
+
+	for len(r.replay) != 0 {
+		e := r.replay[0]
+		r.replay = r.replay[1:]
+
+		err := r.writePacket(e.media, e.pkt)
+		if err != nil {
+			r.releaseReplay()
+			return err
+		}
+	}
+
+	end := min(r.ring.head.Load(), r.end.Load())
+
+	for r.next < end {
+		err := r.checkBacklog()
+		if err != nil {
+			return err
+		}
+
+		medi, pkt, ok := r.ring.read(r.next)
+		if !ok {
+			r.overrun()
+			continue
+		}
+		r.next++
+
+		if _, ok = r.medias[medi]; !ok {
+			pkt.release()
+			continue
+		}
+
+		if r.discard(medi, pkt) {
+			pkt.release()
+			continue
+		}
+
+		err = r.writePacket(medi, pkt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// releaseReplay releases packets of the GOP cache that have not been written.
+func (r *serverStreamReader) releaseReplay() {
+	for _, e := range r.replay {
+		e.pkt.release()
+	}
+	r.replay = nil
+}
+
+// checkBacklog keeps track of the time since the reader is congested,
+// that is when more than half of the ring has not been read yet,
+// and disconnects the reader when the policy requires it.
+func (r *serverStreamReader) checkBacklog() error {
+	backlog := r.ring.head.Load() - r.next
+
+	switch {
+	case backlog >= r.ring.size/2:
+		if r.congestedSince.IsZero() {
+			r.congestedSince = r.timeNow()
+		}
+
+	case backlog <= r.ring.size/4:
+		r.congestedSince = time.Time{}
+	}
+
+	if r.policy == ServerSlowReaderPolicyDisconnect && !r.congestedSince.IsZero() {
+		if d := r.timeNow().Sub(r.congestedSince); d >= r.maxBacklog {
+			r.stats.packetsDroppedOnDisconnect.Add(backlog)
+			return liberrors.ErrServerSlowReader{Backlog: d}
+		}
+	}
+
+	return nil
+}
+
+// overrun is called when packets have been overwritten before being read.
+func (r *serverStreamReader) overrun() {
+	next := max(r.next+1, r.ring.oldest())
+	dropped := next - r.next
+	r.next = next
+
+	if r.policy == ServerSlowReaderPolicyDropUntilKeyFrame {
+		r.stats.packetsDroppedUntilKeyFrame.Add(dropped)
+
+		for medi := range r.medias {
+			r.waitKeyFrame[medi] = struct{}{}
+		}
+	} else {
+		r.stats.packetsDroppedOldest.Add(dropped)
+	}
+
+	if r.onError != nil {
+		r.onError(liberrors.ErrServerWriteQueueFull{})
+	}
+}
+
+// discard checks whether a packet must be discarded because of the slow reader policy.
+func (r *serverStreamReader) discard(medi *description.Media, pkt *serverStreamPacket) bool {
+	if pkt.rtcp {
+		return false
+	}
+
+	if _, ok := r.waitKeyFrame[medi]; ok {
+		if !pkt.keyFrame {
+			r.stats.packetsDroppedUntilKeyFrame.Add(1)
+			return true
+		}
+		delete(r.waitKeyFrame, medi)
+	}
+
+	if r.policy == ServerSlowReaderPolicySkipNonReference &&
+		!r.congestedSince.IsZero() && pkt.nonReference {
+		r.stats.packetsSkippedNonReference.Add(1)
+		return true
+	}
+
+	return false
+}
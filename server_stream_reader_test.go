@@ -0,0 +1,249 @@
+package gortsplib
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
+	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
+)
+
+func TestServerStreamReaderSlowReaderPolicy(t *testing.T) {
+	for _, ca := range []struct {
+		name    string
+		policy  ServerSlowReaderPolicy
+		count   int
+		written []uint8
+		err     error
+		stats   [4]uint64
+	}{
+		{
+			"drop oldest",
+			ServerSlowReaderPolicyDropOldest,
+			20,
+			[]uint8{12, 13, 14, 15, 16, 17, 18, 19},
+			nil,
+			[4]uint64{12, 0, 0, 0},
+		},
+		{
+			"drop until key frame",
+			ServerSlowReaderPolicyDropUntilKeyFrame,
+			20,
+			[]uint8{15, 16, 17, 18, 19},
+			nil,
+			[4]uint64{0, 15, 0, 0},
+		},
+		{
+			"disconnect",
+			ServerSlowReaderPolicyDisconnect,
+			6,
+			[]uint8{0, 1},
+			liberrors.ErrServerSlowReader{Backlog: 2 * time.Second},
+			[4]uint64{0, 0, 4, 0},
+		},
+		{
+			"skip non-reference",
+			ServerSlowReaderPolicySkipNonReference,
+			6,
+			[]uint8{0, 2, 4, 5},
+			nil,
+			[4]uint64{0, 0, 0, 2},
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			ring := &serverStreamRing{size: 8}
+			ring.initialize()
+
+			medi := &description.Media{}
+			now := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+			var written []uint8
+			var errors []error
+			var stats serverStreamReaderStats
+
+			r := &serverStreamReader{
+				ring:       ring,
+				medias:     map[*description.Media]struct{}{medi: {}},
+				policy:     ca.policy,
+				maxBacklog: 2 * time.Second,
+				timeNow:    func() time.Time { return now },
+				stats:      &stats,
+				schedule:   func(func() error) bool { return true },
+				writePacket: func(_ *description.Media, pkt *serverStreamPacket) error {
+					written = append(written, pkt.payloadType)
+					pkt.release()
+					now = now.Add(time.Second)
+					return nil
+				},
+				onError: func(err error) {
+					errors = append(errors, err)
+				},
+			}
+			r.initialize()
+
+			var pkts []*serverStreamPacket
+
+			for i := 0; i < ca.count; i++ {
+				pkt := &serverStreamPacket{
+					payloadType:  uint8(i),
+					keyFrame:     i == 15,
+					nonReference: (i % 2) == 1,
+				}
+				pkt.refs.Store(1)
+				pkts = append(pkts, pkt)
+				ring.write(medi, pkt)
+				pkt.release()
+				r.notify()
+			}
+
+			err := r.drain()
+			require.Equal(t, ca.err, err)
+			require.Equal(t, ca.written, written)
+
+			// packets that are still in the ring are referenced by the ring only,
+			// while skipped and overwritten packets are released.
+			for i, pkt := range pkts {
+				if uint64(i) >= ring.oldest() {
+					require.Equal(t, int32(1), pkt.refs.Load())
+				} else {
+					require.Equal(t, int32(0), pkt.refs.Load())
+				}
+			}
+
+			if ca.count > int(ring.size) {
+				require.Equal(t, []error{liberrors.ErrServerWriteQueueFull{}}, errors)
+			} else {
+				require.Empty(t, errors)
+			}
+
+			require.Equal(t, ca.stats, [4]uint64{
+				stats.packetsDroppedOldest.Load(),
+				stats.packetsDroppedUntilKeyFrame.Load(),
+				stats.packetsDroppedOnDisconnect.Load(),
+				stats.packetsSkippedNonReference.Load(),
+			})
+		})
+	}
+}
+
+func TestIsKeyFramePacket(t *testing.T) {
+	for _, ca := range []struct {
+		name     string
+		format   format.Format
+		payload  []byte
+		keyFrame bool
+	}{
+		{
+			"h264 sps",
+			&format.H264{},
+			[]byte{0x67, 0x42},
+			true,
+		},
+		{
+			"h264 idr",
+			&format.H264{},
+			[]byte{0x65, 0x88},
+			true,
+		},
+		{
+			"h264 non-idr",
+			&format.H264{},
+			[]byte{0x41, 0x9a},
+			false,
+		},
+		{
+			"vp8 key frame",
+			&format.VP8{},
+			[]byte{0x10, 0x00},
+			true,
+		},
+		{
+			"vp8 inter frame",
+			&format.VP8{},
+			[]byte{0x10, 0x01},
+			false,
+		},
+		{
+			"vp9 inter frame",
+			&format.VP9{},
+			[]byte{0x48},
+			false,
+		},
+		{
+			"av1 non-first packet",
+			&format.AV1{},
+			[]byte{0x10},
+			false,
+		},
+		{
+			"other format",
+			&format.Opus{},
+			[]byte{0x01, 0x02},
+			true,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			pkt := &rtp.Packet{Payload: ca.payload}
+			require.Equal(t, ca.keyFrame, isKeyFramePacket(ca.format, pkt, ca.format.PTSEqualsDTS(pkt)))
+		})
+	}
+}
+
+func TestIsNonReferencePacket(t *testing.T) {
+	for _, ca := range []struct {
+		name    string
+		format  format.Format
+		payload []byte
+		nonRef  bool
+	}{
+		{
+			"h264 idr",
+			&format.H264{},
+			[]byte{0x65, 0x88},
+			false,
+		},
+		{
+			"h264 non-reference",
+			&format.H264{},
+			[]byte{0x01, 0x9a},
+			true,
+		},
+		{
+			"h264 fu-a non-reference",
+			&format.H264{},
+			[]byte{0x1c, 0x81, 0x9a},
+			true,
+		},
+		{
+			"h265 trail_r",
+			&format.H265{},
+			[]byte{0x02, 0x01, 0xd0},
+			false,
+		},
+		{
+			"h265 trail_n",
+			&format.H265{},
+			[]byte{0x00, 0x01, 0xd0},
+			true,
+		},
+		{
+			"h265 fu non-reference",
+			&format.H265{},
+			[]byte{0x62, 0x01, 0x80, 0xd0},
+			true,
+		},
+		{
+			"other format",
+			&format.Opus{},
+			[]byte{0x01, 0x02},
+			false,
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			require.Equal(t, ca.nonRef, isNonReferencePacket(ca.format, &rtp.Packet{Payload: ca.payload}))
+		})
+	}
+}
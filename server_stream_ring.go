@@ -0,0 +1,93 @@
+package gortsplib
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+)
+
+type serverStreamRingSlot struct {
+	seq   atomic.Uint64 // sequence number of the packet plus one, zero while the slot is being written
+	media atomic.Pointer[description.Media]
+	pkt   atomic.Pointer[serverStreamPacket]
+}
+
+// serverStreamRing is a ring buffer that contains the packets of a ServerStream.
+// Packets are added once by the stream and read by all readers,
+// each one with its own cursor, without locking.
+// The ring holds a reference to each packet until the packet is overwritten,
+// and readers acquire their own reference when they read it.
+// When a reader is too slow, packets it didn't read yet are overwritten.
+type serverStreamRing struct {
+	size uint64
+
+	slots      []serverStreamRingSlot
+	head       atomic.Uint64 // sequence number of the next packet
+	writeMutex sync.Mutex
+}
+
+func (r *serverStreamRing) initialize() {
+	r.slots = make([]serverStreamRingSlot, r.size)
+}
+
+// write adds a packet to the ring.
+func (r *serverStreamRing) write(medi *description.Media, pkt *serverStreamPacket) {
+	r.writeMutex.Lock()
+	defer r.writeMutex.Unlock()
+
+	seq := r.head.Load()
+	slot := &r.slots[seq%r.size]
+	old := slot.pkt.Load()
+
+	pkt.acquire(1)
+
+	slot.seq.Store(0)
+	slot.media.Store(medi)
+	slot.pkt.Store(pkt)
+	slot.seq.Store(seq + 1)
+
+	r.head.Store(seq + 1)
+
+	// release the overwritten packet after the slot has been updated,
+	// in order to prevent readers from acquiring it.
+	if old != nil {
+		old.release()
+	}
+}
+
+// read returns the packet with given sequence number, that must be lower than head,
+// and acquires it on behalf of the caller, that must release it.
+// It returns false if the packet has been overwritten.
+func (r *serverStreamRing) read(seq uint64) (*description.Media, *serverStreamPacket, bool) {
+	slot := &r.slots[seq%r.size]
+
+	if slot.seq.Load() != seq+1 {
+		return nil, nil, false
+	}
+
+	medi := slot.media.Load()
+	pkt := slot.pkt.Load()
+
+	if !pkt.tryAcquire() {
+		return nil, nil, false
+	}
+
+	// make sure that the slot was not overwritten in the meanwhile,
+	// otherwise the packet may have been reused.
+	if slot.seq.Load() != seq+1 {
+		pkt.release()
+		return nil, nil, false
+	}
+
+	return medi, pkt, true
+}
+
+// oldest returns the sequence number of the oldest packet in the ring.
+func (r *serverStreamRing) oldest() uint64 {
+	head := r.head.Load()
+	if head < r.size {
+		return 0
+	}
+	return head - r.size
+}
@@ -0,0 +1,68 @@
+package gortsplib
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/description"
+)
+
+func TestServerStreamRing(t *testing.T) {
+	r := &serverStreamRing{size: 4}
+	r.initialize()
+
+	medi := &description.Media{}
+
+	var pkts []*serverStreamPacket
+
+	for i := 0; i < 6; i++ {
+		pkt := &serverStreamPacket{payloadType: uint8(i)}
+		pkt.refs.Store(1)
+		pkts = append(pkts, pkt)
+		r.write(medi, pkt)
+		pkt.release()
+	}
+
+	require.Equal(t, uint64(6), r.head.Load())
+	require.Equal(t, uint64(2), r.oldest())
+
+	// overwritten packets are released by the ring.
+	for seq := uint64(0); seq < 2; seq++ {
+		_, _, ok := r.read(seq)
+		require.False(t, ok)
+		require.Equal(t, int32(0), pkts[seq].refs.Load())
+	}
+
+	for seq := uint64(2); seq < 6; seq++ {
+		medi2, pkt, ok := r.read(seq)
+		require.True(t, ok)
+		require.Equal(t, medi, medi2)
+		require.Same(t, pkts[seq], pkt)
+		require.Equal(t, int32(2), pkt.refs.Load())
+		pkt.release()
+	}
+
+	// released packets cannot be acquired again.
+	require.False(t, pkts[0].tryAcquire())
+}
+
+func TestServerStreamPacketRelease(t *testing.T) {
+	var pool sync.Pool
+
+	pkt := &serverStreamPacket{
+		pool:  &pool,
+		plain: []byte{1, 2, 3, 4},
+	}
+	pkt.refs.Store(1)
+	pkt.acquire(2)
+
+	pkt.release()
+	pkt.release()
+	require.Equal(t, []byte{1, 2, 3, 4}, pkt.payload(false))
+
+	pkt.release()
+	require.Equal(t, int32(0), pkt.refs.Load())
+	require.Nil(t, pkt.payload(false))
+}
@@ -37,6 +37,10 @@ func (sl *serverTCPListener) run() {
 			return
 		}
 
-		sl.s.newConn(nconn)
+		if sl.s.HTTPAddress == sl.s.RTSPAddress {
+			sl.s.httpHandler.accept(nconn, true)
+		} else {
+			sl.s.newConn(nconn)
+		}
 	}
 }
@@ -1,18 +1,22 @@
 package gortsplib
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/pion/rtp"
 	"github.com/stretchr/testify/require"
 
 	"github.com/bluenviron/gortsplib/v4/pkg/auth"
 	"github.com/bluenviron/gortsplib/v4/pkg/base"
 	"github.com/bluenviron/gortsplib/v4/pkg/conn"
 	"github.com/bluenviron/gortsplib/v4/pkg/description"
+	"github.com/bluenviron/gortsplib/v4/pkg/format"
 	"github.com/bluenviron/gortsplib/v4/pkg/headers"
 	"github.com/bluenviron/gortsplib/v4/pkg/liberrors"
 	"github.com/bluenviron/gortsplib/v4/pkg/sdp"
@@ -129,6 +133,9 @@ type testServerHandler struct {
 	onGetParameter func(*ServerHandlerOnGetParameterCtx) (*base.Response, error)
 	onPacketsLost  func(*ServerHandlerOnPacketsLostCtx)
 	onDecodeError  func(*ServerHandlerOnDecodeErrorCtx)
+	onDrain        func(*ServerHandlerOnDrainCtx) (*base.URL, *headers.Range)
+
+	onKeyFrameRequest func(*ServerHandlerOnKeyFrameRequestCtx)
 }
 
 func (sh *testServerHandler) OnConnOpen(ctx *ServerHandlerOnConnOpenCtx) {
@@ -223,6 +230,19 @@ func (sh *testServerHandler) OnDecodeError(ctx *ServerHandlerOnDecodeErrorCtx) {
 	}
 }
 
+func (sh *testServerHandler) OnDrain(ctx *ServerHandlerOnDrainCtx) (*base.URL, *headers.Range) {
+	if sh.onDrain != nil {
+		return sh.onDrain(ctx)
+	}
+	return nil, nil
+}
+
+func (sh *testServerHandler) OnKeyFrameRequest(ctx *ServerHandlerOnKeyFrameRequestCtx) {
+	if sh.onKeyFrameRequest != nil {
+		sh.onKeyFrameRequest(ctx)
+	}
+}
+
 func TestServerClose(t *testing.T) {
 	s := &Server{
 		Handler:     &testServerHandler{},
@@ -255,6 +275,88 @@ func TestServerErrorInvalidUDPPorts(t *testing.T) {
 		err := s.Start()
 		require.Error(t, err)
 	})
+
+	t.Run("symmetric rtp without session ports", func(t *testing.T) {
+		s := &Server{
+			UDPRTPAddress:   "127.0.0.1:8000",
+			UDPRTCPAddress:  "127.0.0.1:8001",
+			UDPSymmetricRTP: true,
+			RTSPAddress:     "localhost:8554",
+		}
+		err := s.Start()
+		require.EqualError(t, err, "UDPSymmetricRTP requires UDPSessionPortMin and UDPSessionPortMax")
+	})
+}
+
+func TestServerErrorInvalidMulticastInterface(t *testing.T) {
+	t.Run("ssm without interface", func(t *testing.T) {
+		s := &Server{
+			RTSPAddress:       "localhost:8554",
+			MulticastIPRange:  "232.1.0.0/16",
+			MulticastRTPPort:  8000,
+			MulticastRTCPPort: 8001,
+		}
+		err := s.Start()
+		require.EqualError(t, err, "a source-specific MulticastIPRange requires MulticastInterface")
+	})
+
+	t.Run("missing interface", func(t *testing.T) {
+		s := &Server{
+			RTSPAddress:        "localhost:8554",
+			MulticastIPRange:   "224.1.0.0/16",
+			MulticastRTPPort:   8000,
+			MulticastRTCPPort:  8001,
+			MulticastInterface: "nonexisting0",
+		}
+		err := s.Start()
+		require.Error(t, err)
+	})
+}
+
+func TestServerNextMulticastIP(t *testing.T) {
+	for _, ca := range []struct {
+		name    string
+		ipRange string
+		cur     string
+		next    string
+	}{
+		{
+			"ipv4",
+			"224.1.0.0/16",
+			"224.1.0.255",
+			"224.1.1.0",
+		},
+		{
+			"ipv4 wrap",
+			"232.1.0.0/16",
+			"232.1.255.255",
+			"232.1.0.0",
+		},
+		{
+			"ipv6",
+			"ff3e::8000:0/96",
+			"ff3e::8000:ffff",
+			"ff3e::8001:0",
+		},
+		{
+			"ipv6 wrap",
+			"ff15::1:0/112",
+			"ff15::1:ffff",
+			"ff15::1:0",
+		},
+	} {
+		t.Run(ca.name, func(t *testing.T) {
+			_, ipNet, err := net.ParseCIDR(ca.ipRange)
+			require.NoError(t, err)
+
+			cur := net.ParseIP(ca.cur)
+			if cur4 := cur.To4(); cur4 != nil {
+				cur = cur4
+			}
+
+			require.Equal(t, ca.next, nextMulticastIP(cur, ipNet).String())
+		})
+	}
 }
 
 func TestServerConnClose(t *testing.T) {
@@ -758,6 +860,421 @@ func TestServerSetupMultipleTransports(t *testing.T) {
 	}, th)
 }
 
+func TestServerRTSP2(t *testing.T) {
+	var stream *ServerStream
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				// headers set by the handler are not overridden
+				return &base.Response{
+					StatusCode: base.StatusOK,
+					Header: base.Header{
+						"Media-Properties": base.HeaderValue{"Beginning-Only"},
+					},
+				}, nil
+			},
+		},
+		RTSPAddress:    "localhost:8554",
+		UDPRTPAddress:  "127.0.0.1:8000",
+		UDPRTCPAddress: "127.0.0.1:8001",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server: s,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	nconn, err := net.Dial("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn.Close()
+	conn := conn.NewConn(nconn)
+
+	desc := doDescribe(t, conn, false)
+
+	res, err := writeReqReadRes(conn, base.Request{
+		Version: base.Version20,
+		Method:  base.Describe,
+		URL:     mustParseURL("rtsp://localhost:8554/teststream"),
+		Header: base.Header{
+			"CSeq": base.HeaderValue{"1"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusOK, res.StatusCode)
+	require.Equal(t, base.HeaderValue{"No-Seeking, Time-Progressing, Time-Duration=0"}, res.Header["Media-Properties"])
+	require.Equal(t, base.HeaderValue{"npt"}, res.Header["Accept-Ranges"])
+	require.Equal(t, base.HeaderValue{""}, res.Header["Media-Range"])
+
+	inTH := headers.Transport{
+		Delivery: deliveryPtr(headers.TransportDeliveryUnicast),
+		Mode:     transportModePtr(headers.TransportModePlay),
+		Protocol: headers.TransportProtocolUDP,
+		DestAddr: []headers.TransportAddr{{Port: 35466}, {Port: 35467}},
+	}
+
+	res, err = writeReqReadRes(conn, base.Request{
+		Version: base.Version20,
+		Method:  base.Setup,
+		URL:     mediaURL(t, desc.BaseURL, desc.Medias[0]),
+		Header: base.Header{
+			"CSeq":               base.HeaderValue{"2"},
+			"Transport":          inTH.Marshal(),
+			"Pipelined-Requests": base.HeaderValue{"7"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusOK, res.StatusCode)
+	require.Equal(t, base.Version20, res.Version)
+	require.Equal(t, base.HeaderValue{"7"}, res.Header["Pipelined-Requests"])
+
+	var th headers.Transport
+	err = th.Unmarshal(res.Header["Transport"])
+	require.NoError(t, err)
+	require.Equal(t, headers.Transport{
+		Delivery: deliveryPtr(headers.TransportDeliveryUnicast),
+		Protocol: headers.TransportProtocolUDP,
+		SSRC:     th.SSRC,
+		DestAddr: []headers.TransportAddr{{Host: "127.0.0.1", Port: 35466}, {Host: "127.0.0.1", Port: 35467}},
+		SrcAddr:  []headers.TransportAddr{{Host: "127.0.0.1", Port: 8000}, {Host: "127.0.0.1", Port: 8001}},
+	}, th)
+
+	// PLAY without session, that is associated through the pipeline
+	res, err = writeReqReadRes(conn, base.Request{
+		Version: base.Version20,
+		Method:  base.Play,
+		URL:     desc.BaseURL,
+		Header: base.Header{
+			"CSeq":               base.HeaderValue{"3"},
+			"Pipelined-Requests": base.HeaderValue{"7"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusOK, res.StatusCode)
+	require.Equal(t, base.Version20, res.Version)
+	require.Equal(t, base.HeaderValue{"Beginning-Only"}, res.Header["Media-Properties"])
+	require.Equal(t, base.HeaderValue{"npt"}, res.Header["Accept-Ranges"])
+
+	res, err = writeReqReadRes(conn, base.Request{
+		Version: base.Version20,
+		Method:  base.Teardown,
+		URL:     desc.BaseURL,
+		Header: base.Header{
+			"CSeq":               base.HeaderValue{"4"},
+			"Pipelined-Requests": base.HeaderValue{"7"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusOK, res.StatusCode)
+
+	// the pipeline is not associated with the closed session anymore
+	res, err = writeReqReadRes(conn, base.Request{
+		Version: base.Version20,
+		Method:  base.Play,
+		URL:     desc.BaseURL,
+		Header: base.Header{
+			"CSeq":               base.HeaderValue{"5"},
+			"Pipelined-Requests": base.HeaderValue{"7"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusNotImplemented, res.StatusCode)
+}
+
+func TestServerRTSP2Disabled(t *testing.T) {
+	s := &Server{
+		RTSPAddress:  "localhost:8554",
+		DisableRTSP2: true,
+	}
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	nconn, err := net.Dial("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn.Close()
+	conn := conn.NewConn(nconn)
+
+	res, err := writeReqReadRes(conn, base.Request{
+		Version: base.Version20,
+		Method:  base.Options,
+		URL:     mustParseURL("rtsp://localhost:8554/"),
+		Header: base.Header{
+			"CSeq": base.HeaderValue{"1"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusRTSPVersionNotSupported, res.StatusCode)
+	require.Equal(t, base.Version10, res.Version)
+
+	res, err = writeReqReadRes(conn, base.Request{
+		Method: base.Options,
+		URL:    mustParseURL("rtsp://localhost:8554/"),
+		Header: base.Header{
+			"CSeq": base.HeaderValue{"2"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusOK, res.StatusCode)
+}
+
+func TestServerDrain(t *testing.T) {
+	var stream1 *ServerStream
+	var stream2 *ServerStream
+
+	s1 := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream1, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream1, nil
+			},
+			onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onDrain: func(_ *ServerHandlerOnDrainCtx) (*base.URL, *headers.Range) {
+				return mustParseURL("rtsp://localhost:8555/teststream"), nil
+			},
+		},
+		RTSPAddress: "localhost:8554",
+	}
+
+	err := s1.Start()
+	require.NoError(t, err)
+	defer s1.Close()
+
+	stream1 = &ServerStream{
+		Server: s1,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream1.Initialize()
+	require.NoError(t, err)
+	defer stream1.Close()
+
+	s2 := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream2, nil
+			},
+			onSetup: func(_ *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream2, nil
+			},
+			onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				go func() {
+					time.Sleep(500 * time.Millisecond)
+					err2 := stream2.WritePacketRTP(stream2.Description().Medias[0], &testRTPPacket)
+					require.NoError(t, err2)
+				}()
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		RTSPAddress: "localhost:8555",
+	}
+
+	err = s2.Start()
+	require.NoError(t, err)
+	defer s2.Close()
+
+	stream2 = &ServerStream{
+		Server: s2,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream2.Initialize()
+	require.NoError(t, err)
+	defer stream2.Close()
+
+	packetRecv := make(chan struct{})
+
+	c := Client{
+		Transport: transportPtr(TransportTCP),
+	}
+
+	err = c.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer c.Close()
+
+	sd, _, err := c.Describe(mustParseURL("rtsp://localhost:8554/teststream"))
+	require.NoError(t, err)
+
+	err = c.SetupAll(sd.BaseURL, sd.Medias)
+	require.NoError(t, err)
+
+	c.OnPacketRTPAny(func(_ *description.Media, _ format.Format, _ *rtp.Packet) {
+		close(packetRecv)
+	})
+
+	_, err = c.Play(nil)
+	require.NoError(t, err)
+
+	ctx, ctxCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer ctxCancel()
+
+	err = s1.Drain(ctx)
+	require.NoError(t, err)
+
+	<-packetRecv
+
+	// new sessions are refused
+	nconn, err := net.Dial("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn.Close()
+	conn := conn.NewConn(nconn)
+
+	inTH := &headers.Transport{
+		Protocol:       headers.TransportProtocolTCP,
+		Delivery:       deliveryPtr(headers.TransportDeliveryUnicast),
+		Mode:           transportModePtr(headers.TransportModePlay),
+		InterleavedIDs: &[2]int{0, 1},
+	}
+
+	res, err := writeReqReadRes(conn, base.Request{
+		Method: base.Setup,
+		URL:    mediaURL(t, sd.BaseURL, sd.Medias[0]),
+		Header: base.Header{
+			"CSeq":      base.HeaderValue{"1"},
+			"Transport": inTH.Marshal(),
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusServiceUnavailable, res.StatusCode)
+}
+
+func TestServerSessionRedirect(t *testing.T) {
+	var stream *ServerStream
+	var session *ServerSession
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				session = ctx.Session
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onGetParameter: func(_ *ServerHandlerOnGetParameterCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+		},
+		RTSPAddress:    "localhost:8554",
+		UDPRTPAddress:  "127.0.0.1:8000",
+		UDPRTCPAddress: "127.0.0.1:8001",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	stream = &ServerStream{
+		Server: s,
+		Desc:   &description.Session{Medias: []*description.Media{testH264Media}},
+	}
+	err = stream.Initialize()
+	require.NoError(t, err)
+	defer stream.Close()
+
+	nconn1, err := net.Dial("tcp", "localhost:8554")
+	require.NoError(t, err)
+	conn1 := conn.NewConn(nconn1)
+
+	desc := doDescribe(t, conn1, false)
+
+	inTH := &headers.Transport{
+		Delivery:    deliveryPtr(headers.TransportDeliveryUnicast),
+		Mode:        transportModePtr(headers.TransportModePlay),
+		Protocol:    headers.TransportProtocolUDP,
+		ClientPorts: &[2]int{35466, 35467},
+	}
+
+	res, _ := doSetup(t, conn1, mediaURL(t, desc.BaseURL, desc.Medias[0]).String(), inTH, "")
+	sessionID := readSession(t, res)
+
+	doPlay(t, conn1, desc.BaseURL.String(), sessionID)
+
+	// the session survives the connection that created it,
+	// and is reached through another connection.
+	nconn1.Close()
+
+	nconn2, err := net.Dial("tcp", "localhost:8554")
+	require.NoError(t, err)
+	defer nconn2.Close()
+	conn2 := conn.NewConn(nconn2)
+
+	res, err = writeReqReadRes(conn2, base.Request{
+		Method: base.GetParameter,
+		URL:    desc.BaseURL,
+		Header: base.Header{
+			"CSeq":    base.HeaderValue{"1"},
+			"Session": base.HeaderValue{sessionID},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, base.StatusOK, res.StatusCode)
+
+	redirectErr := make(chan error)
+	go func() {
+		redirectErr <- session.Redirect(mustParseURL("rtsp://localhost:8555/teststream"), nil)
+	}()
+
+	req, err := conn2.ReadRequest()
+	require.NoError(t, err)
+	require.Equal(t, base.Redirect, req.Method)
+	require.Equal(t, base.HeaderValue{"rtsp://localhost:8555/teststream"}, req.Header["Location"])
+	require.Equal(t, base.HeaderValue{sessionID}, req.Header["Session"])
+
+	err = conn2.WriteResponse(&base.Response{
+		StatusCode: base.StatusOK,
+		Header: base.Header{
+			"CSeq": req.Header["CSeq"],
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, <-redirectErr)
+}
+
 func TestServerGetSetParameter(t *testing.T) {
 	for _, ca := range []string{"inside session", "outside session"} {
 		t.Run(ca, func(t *testing.T) {
@@ -1290,3 +1807,192 @@ func TestServerStreamErrorNoServer(t *testing.T) {
 	err := stream.Initialize()
 	require.Error(t, err)
 }
+
+func TestServerKeyFrameRequest(t *testing.T) {
+	var stream *ServerStream
+	var publisher *ServerSession
+	var mutex sync.Mutex
+	handlerCalled := make(chan struct{}, 1)
+
+	s := &Server{
+		Handler: &testServerHandler{
+			onAnnounce: func(ctx *ServerHandlerOnAnnounceCtx) (*base.Response, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				stream = &ServerStream{
+					Server: ctx.Session.s,
+					Desc:   ctx.Description,
+					OnKeyFrameRequest: func(medi *description.Media, forma format.Format) {
+						mutex.Lock()
+						defer mutex.Unlock()
+
+						require.Equal(t, stream.Description().Medias[0], medi)
+						require.Equal(t, stream.Description().Medias[0].Formats[0], forma)
+
+						err := publisher.RequestKeyFrame(medi)
+						require.NoError(t, err)
+					},
+				}
+				err := stream.Initialize()
+				require.NoError(t, err)
+
+				publisher = ctx.Session
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onSetup: func(ctx *ServerHandlerOnSetupCtx) (*base.Response, *ServerStream, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				if ctx.Session == publisher {
+					return &base.Response{
+						StatusCode: base.StatusOK,
+					}, nil, nil
+				}
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onRecord: func(ctx *ServerHandlerOnRecordCtx) (*base.Response, error) {
+				ctx.Session.OnPacketRTPAny(func(medi *description.Media, _ format.Format, pkt *rtp.Packet) {
+					stream.WritePacketRTP(medi, pkt) //nolint:errcheck
+				})
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onDescribe: func(_ *ServerHandlerOnDescribeCtx) (*base.Response, *ServerStream, error) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, stream, nil
+			},
+			onPlay: func(_ *ServerHandlerOnPlayCtx) (*base.Response, error) {
+				return &base.Response{
+					StatusCode: base.StatusOK,
+				}, nil
+			},
+			onKeyFrameRequest: func(ctx *ServerHandlerOnKeyFrameRequestCtx) {
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				require.Equal(t, stream, ctx.Stream)
+				require.Equal(t, stream.Description().Medias[0], ctx.Media)
+				require.Equal(t, stream.Description().Medias[0].Formats[0], ctx.Format)
+
+				select {
+				case handlerCalled <- struct{}{}:
+				default:
+				}
+			},
+		},
+		RTSPAddress: "localhost:8554",
+	}
+
+	err := s.Start()
+	require.NoError(t, err)
+	defer s.Close()
+
+	defer func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if stream != nil {
+			stream.Close()
+		}
+	}()
+
+	keyFrameRequested := make(chan struct{})
+
+	desc := &description.Session{Medias: []*description.Media{testH264Media}}
+
+	pc := Client{
+		Transport: transportPtr(TransportTCP),
+		OnKeyFrameRequest: func(medi *description.Media, forma format.Format) {
+			require.Equal(t, desc.Medias[0], medi)
+			require.Equal(t, desc.Medias[0].Formats[0], forma)
+			close(keyFrameRequested)
+		},
+	}
+
+	err = pc.StartRecording("rtsp://localhost:8554/teststream", desc)
+	require.NoError(t, err)
+	defer pc.Close()
+
+	writerDone := make(chan struct{})
+	defer func() { <-writerDone }()
+
+	writerTerminate := make(chan struct{})
+	defer close(writerTerminate)
+
+	go func() {
+		defer close(writerDone)
+
+		ti := time.NewTicker(50 * time.Millisecond)
+		defer ti.Stop()
+
+		for {
+			select {
+			case <-ti.C:
+				err2 := pc.WritePacketRTP(desc.Medias[0], &rtp.Packet{
+					Header: rtp.Header{
+						Version:        2,
+						PayloadType:    96,
+						SequenceNumber: 1,
+					},
+					Payload: []byte{5, 1, 2, 3},
+				})
+				require.NoError(t, err2)
+
+			case <-writerTerminate:
+				return
+			}
+		}
+	}()
+
+	rc := Client{
+		Transport: transportPtr(TransportTCP),
+	}
+
+	err = rc.Start("rtsp", "localhost:8554")
+	require.NoError(t, err)
+	defer rc.Close()
+
+	rdesc, _, err := rc.Describe(mustParseURL("rtsp://localhost:8554/teststream"))
+	require.NoError(t, err)
+
+	err = rc.SetupAll(rdesc.BaseURL, rdesc.Medias)
+	require.NoError(t, err)
+
+	err = rc.RequestKeyFrame(rdesc.Medias[0])
+	require.EqualError(t, err, "stream is not playing")
+
+	packetRecv := make(chan struct{})
+	var packetRecvOnce sync.Once
+
+	rc.OnPacketRTPAny(func(_ *description.Media, _ format.Format, _ *rtp.Packet) {
+		packetRecvOnce.Do(func() {
+			close(packetRecv)
+		})
+	})
+
+	_, err = rc.Play(nil)
+	require.NoError(t, err)
+
+	<-packetRecv
+
+	err = rc.RequestKeyFrame(testH264Media)
+	require.EqualError(t, err, "media has not been setupped")
+
+	err = rc.RequestKeyFrame(rdesc.Medias[0])
+	require.NoError(t, err)
+
+	<-handlerCalled
+	<-keyFrameRequested
+}
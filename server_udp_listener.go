@@ -31,12 +31,14 @@ func createUDPListenerMulticastPair(
 	multicastRTPPort int,
 	multicastRTCPPort int,
 	ip net.IP,
+	intf *net.Interface,
 ) (*serverUDPListener, *serverUDPListener, error) {
 	rtpl := &serverUDPListener{
-		listenPacket:    listenPacket,
-		writeTimeout:    writeTimeout,
-		multicastEnable: true,
-		address:         net.JoinHostPort(ip.String(), strconv.FormatInt(int64(multicastRTPPort), 10)),
+		listenPacket:       listenPacket,
+		writeTimeout:       writeTimeout,
+		multicastEnable:    true,
+		multicastInterface: intf,
+		address:            net.JoinHostPort(ip.String(), strconv.FormatInt(int64(multicastRTPPort), 10)),
 	}
 	err := rtpl.initialize()
 	if err != nil {
@@ -44,10 +46,11 @@ func createUDPListenerMulticastPair(
 	}
 
 	rtcpl := &serverUDPListener{
-		listenPacket:    listenPacket,
-		writeTimeout:    writeTimeout,
-		multicastEnable: true,
-		address:         net.JoinHostPort(ip.String(), strconv.FormatInt(int64(multicastRTCPPort), 10)),
+		listenPacket:       listenPacket,
+		writeTimeout:       writeTimeout,
+		multicastEnable:    true,
+		multicastInterface: intf,
+		address:            net.JoinHostPort(ip.String(), strconv.FormatInt(int64(multicastRTCPPort), 10)),
 	}
 	err = rtcpl.initialize()
 	if err != nil {
@@ -58,16 +61,62 @@ func createUDPListenerMulticastPair(
 	return rtpl, rtcpl, nil
 }
 
+// createUDPListenerPair creates a pair of listeners that are owned by a single session.
+func createUDPListenerPair(
+	listenPacket func(network, address string) (net.PacketConn, error),
+	writeTimeout time.Duration,
+	batchIO bool,
+	rtpAddress string,
+	rtcpAddress string,
+) (*serverUDPListener, *serverUDPListener, error) {
+	rtpl := &serverUDPListener{
+		listenPacket: listenPacket,
+		writeTimeout: writeTimeout,
+		batchIO:      batchIO,
+		dedicated:    true,
+		address:      rtpAddress,
+	}
+	err := rtpl.initialize()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rtcpl := &serverUDPListener{
+		listenPacket: listenPacket,
+		writeTimeout: writeTimeout,
+		batchIO:      batchIO,
+		dedicated:    true,
+		address:      rtcpAddress,
+	}
+	err = rtcpl.initialize()
+	if err != nil {
+		rtpl.close()
+		return nil, nil, err
+	}
+
+	return rtpl, rtcpl, nil
+}
+
+type serverUDPLatch struct {
+	ip      clientAddr
+	onLatch func(*net.UDPAddr)
+}
+
 type serverUDPListener struct {
-	listenPacket    func(network, address string) (net.PacketConn, error)
-	writeTimeout    time.Duration
-	multicastEnable bool
-	address         string
+	listenPacket       func(network, address string) (net.PacketConn, error)
+	writeTimeout       time.Duration
+	multicastEnable    bool
+	multicastInterface *net.Interface // optional
+	batchIO            bool
+	dedicated          bool // whether the listener is owned by a single session
+	address            string
 
 	pc           packetConn
+	batchWriter  *udpBatchWriter
 	listenIP     net.IP
 	clientsMutex sync.RWMutex
 	clients      map[clientAddr]readFunc
+	pendingLatch *serverUDPLatch // dedicated listeners only
 
 	done chan struct{}
 }
@@ -75,7 +124,11 @@ type serverUDPListener struct {
 func (u *serverUDPListener) initialize() error {
 	if u.multicastEnable {
 		var err error
-		u.pc, err = multicast.NewMultiConn(u.address, false, u.listenPacket)
+		if u.multicastInterface != nil {
+			u.pc, err = multicast.NewSingleConn(u.multicastInterface, u.address, u.listenPacket)
+		} else {
+			u.pc, err = multicast.NewMultiConn(u.address, false, u.listenPacket)
+		}
 		if err != nil {
 			return err
 		}
@@ -100,6 +153,14 @@ func (u *serverUDPListener) initialize() error {
 		return err
 	}
 
+	if u.batchIO && udpBatchSupported && !u.multicastEnable {
+		u.batchWriter = &udpBatchWriter{
+			pc:           u.pc.(*net.UDPConn),
+			writeTimeout: u.writeTimeout,
+		}
+		u.batchWriter.initialize()
+	}
+
 	u.clients = make(map[clientAddr]readFunc)
 	u.done = make(chan struct{})
 
@@ -111,6 +172,14 @@ func (u *serverUDPListener) initialize() error {
 func (u *serverUDPListener) close() {
 	u.pc.Close()
 	<-u.done
+
+	if u.batchWriter != nil {
+		u.batchWriter.close()
+	}
+}
+
+func (u *serverUDPListener) setMulticastTTL(ttl int) error {
+	return u.pc.(multicast.Conn).SetMulticastTTL(ttl)
 }
 
 func (u *serverUDPListener) ip() net.IP {
@@ -124,6 +193,11 @@ func (u *serverUDPListener) port() int {
 func (u *serverUDPListener) run() {
 	defer close(u.done)
 
+	if u.batchWriter != nil {
+		udpReadBatch(u.pc.(*net.UDPConn), u.processPacket) //nolint:errcheck
+		return
+	}
+
 	var buf []byte
 
 	createNewBuffer := func() {
@@ -133,31 +207,48 @@ func (u *serverUDPListener) run() {
 	createNewBuffer()
 
 	for {
-		n, addr2, err := u.pc.ReadFrom(buf)
+		n, addr, err := u.pc.ReadFrom(buf)
 		if err != nil {
 			break
 		}
-		addr := addr2.(*net.UDPAddr)
 
-		func() {
-			u.clientsMutex.RLock()
-			defer u.clientsMutex.RUnlock()
+		if u.processPacket(buf[:n], addr.(*net.UDPAddr)) {
+			createNewBuffer()
+		}
+	}
+}
 
-			var ca clientAddr
-			ca.fill(addr.IP, addr.Port)
-			cb, ok := u.clients[ca]
-			if !ok {
-				return
-			}
+// processPacket routes a packet to the client it comes from.
+// It returns true if the buffer has been retained.
+func (u *serverUDPListener) processPacket(buf []byte, addr *net.UDPAddr) bool {
+	var ca clientAddr
+	ca.fill(addr.IP, addr.Port)
+	ca = u.clientKey(ca)
 
-			if cb(buf[:n]) {
-				createNewBuffer()
-			}
-		}()
+	u.clientsMutex.RLock()
+	defer u.clientsMutex.RUnlock()
+
+	if u.pendingLatch != nil && u.pendingLatch.ip == ca {
+		u.clientsMutex.RUnlock()
+		u.latch(ca, addr)
+		u.clientsMutex.RLock()
 	}
+
+	cb, ok := u.clients[ca]
+	if !ok {
+		return false
+	}
+
+	return cb(buf)
 }
 
-func (u *serverUDPListener) write(buf []byte, addr *net.UDPAddr) error {
+// write writes a packet.
+// With batched I/O, the packet is queued and errors that occur after that are passed to onError.
+func (u *serverUDPListener) write(buf []byte, addr *net.UDPAddr, onError func(error)) error {
+	if u.batchWriter != nil {
+		return u.batchWriter.write(buf, addr, nil, onError)
+	}
+
 	// no mutex is needed here since Write() has an internal lock.
 	// https://github.com/golang/go/issues/27203#issuecomment-534386117
 	u.pc.SetWriteDeadline(time.Now().Add(u.writeTimeout))
@@ -165,6 +256,32 @@ func (u *serverUDPListener) write(buf []byte, addr *net.UDPAddr) error {
 	return err
 }
 
+// writeShared writes a packet that is shared between readers, and releases it once written.
+func (u *serverUDPListener) writeShared(
+	buf []byte,
+	addr *net.UDPAddr,
+	shared *serverStreamPacket,
+	onError func(error),
+) error {
+	if u.batchWriter != nil {
+		return u.batchWriter.write(buf, addr, shared, onError)
+	}
+
+	err := u.write(buf, addr, onError)
+	shared.release()
+	return err
+}
+
+// clientKey returns the key of the client a packet belongs to.
+// Dedicated listeners are used by a single client, that is identified by IP only,
+// since NATs may rewrite the ports it sends from.
+func (u *serverUDPListener) clientKey(addr clientAddr) clientAddr {
+	if u.dedicated {
+		addr.port = 0
+	}
+	return addr
+}
+
 func (u *serverUDPListener) addClient(ip net.IP, port int, cb readFunc) {
 	var addr clientAddr
 	addr.fill(ip, port)
@@ -172,7 +289,7 @@ func (u *serverUDPListener) addClient(ip net.IP, port int, cb readFunc) {
 	u.clientsMutex.Lock()
 	defer u.clientsMutex.Unlock()
 
-	u.clients[addr] = cb
+	u.clients[u.clientKey(addr)] = cb
 }
 
 func (u *serverUDPListener) removeClient(ip net.IP, port int) {
@@ -182,5 +299,42 @@ func (u *serverUDPListener) removeClient(ip net.IP, port int) {
 	u.clientsMutex.Lock()
 	defer u.clientsMutex.Unlock()
 
-	delete(u.clients, addr)
+	delete(u.clients, u.clientKey(addr))
+}
+
+// addLatch allows to learn the real address of a client from the first packet
+// received from its IP, in order to support clients behind NATs that rewrite ports
+// (symmetric RTP). Once learned, onLatch is called.
+// It can be used with dedicated listeners only, since clients that share a listener
+// cannot be told apart by their IP.
+func (u *serverUDPListener) addLatch(ip net.IP, onLatch func(*net.UDPAddr)) {
+	var addr clientAddr
+	addr.fill(ip, 0)
+
+	u.clientsMutex.Lock()
+	defer u.clientsMutex.Unlock()
+
+	u.pendingLatch = &serverUDPLatch{
+		ip:      addr,
+		onLatch: onLatch,
+	}
+}
+
+func (u *serverUDPListener) latch(ca clientAddr, addr *net.UDPAddr) {
+	u.clientsMutex.Lock()
+	defer u.clientsMutex.Unlock()
+
+	// address has already been learned by another packet.
+	if u.pendingLatch == nil || u.pendingLatch.ip != ca {
+		return
+	}
+
+	l := u.pendingLatch
+	u.pendingLatch = nil
+
+	l.onLatch(&net.UDPAddr{
+		IP:   addr.IP,
+		Zone: addr.Zone,
+		Port: addr.Port,
+	})
 }
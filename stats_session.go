@@ -15,6 +15,14 @@ type StatsSessionFormat struct {
 	RTPPacketsSent uint64
 	// number of lost RTP packets
 	RTPPacketsLost uint64
+	// number of RTP packets whose retransmission has been requested with NACKs
+	RTPPacketsNACKed uint64
+	// number of RTP packets that have been retransmitted
+	RTPPacketsRetransmitted uint64
+	// number of retransmitted RTP packets that have been received
+	RTPPacketsRetransmittedReceived uint64
+	// number of lost RTP packets that have been recovered with FEC
+	RTPPacketsRecovered uint64
 	// mean jitter of received RTP packets
 	RTPPacketsJitter float64
 	// local SSRC
@@ -70,6 +78,18 @@ type StatsSession struct {
 	RTCPPacketsSent uint64
 	// number of RTCP packets that could not be processed
 	RTCPPacketsInError uint64
+	// number of outgoing packets that have been overwritten by newer ones
+	// before being sent, since the reader was too slow
+	OutgoingPacketsDroppedOldest uint64
+	// number of outgoing packets that have been discarded while waiting for a key frame
+	// (ServerSlowReaderPolicyDropUntilKeyFrame)
+	OutgoingPacketsDroppedUntilKeyFrame uint64
+	// number of outgoing packets that have been discarded when disconnecting the reader
+	// (ServerSlowReaderPolicyDisconnect)
+	OutgoingPacketsDroppedOnDisconnect uint64
+	// number of outgoing packets of non-reference frames that have been skipped
+	// (ServerSlowReaderPolicySkipNonReference)
+	OutgoingPacketsSkippedNonReference uint64
 
 	// media statistics
 	Medias map[*description.Media]StatsSessionMedia
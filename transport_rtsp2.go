@@ -0,0 +1,83 @@
+package gortsplib
+
+import (
+	"net"
+
+	"github.com/bluenviron/gortsplib/v4/pkg/headers"
+)
+
+// transportAddrsPorts returns the RTP and RTCP ports contained in RTSP 2.0 addresses.
+// When RTP and RTCP are multiplexed, a single address is used for both.
+func transportAddrsPorts(addrs []headers.TransportAddr, rtcpMux bool) *[2]int {
+	if rtcpMux && len(addrs) == 1 && addrs[0].Port != 0 {
+		return &[2]int{addrs[0].Port, addrs[0].Port}
+	}
+
+	if len(addrs) != 2 || addrs[0].Port == 0 || addrs[1].Port == 0 {
+		return nil
+	}
+	return &[2]int{addrs[0].Port, addrs[1].Port}
+}
+
+// transportAddrsIP returns the IP contained in RTSP 2.0 addresses.
+func transportAddrsIP(addrs []headers.TransportAddr) *net.IP {
+	if len(addrs) == 0 {
+		return nil
+	}
+
+	ip := net.ParseIP(addrs[0].Host)
+	if ip == nil {
+		return nil
+	}
+
+	return &ip
+}
+
+// transportFillFromRTSP2 fills RTSP 1.0 fields of a Transport header
+// (client_port, server_port, source, destination and port)
+// with the content of RTSP 2.0 fields (dest_addr and src_addr).
+func transportFillFromRTSP2(th *headers.Transport, isRequest bool) {
+	if th.Delivery != nil && *th.Delivery == headers.TransportDeliveryMulticast {
+		if th.Destination == nil {
+			th.Destination = transportAddrsIP(th.DestAddr)
+		}
+		if th.Ports == nil {
+			th.Ports = transportAddrsPorts(th.DestAddr, th.RTCPMux)
+		}
+		if !isRequest && th.Source == nil {
+			th.Source = transportAddrsIP(th.SrcAddr)
+		}
+		return
+	}
+
+	if th.ClientPorts == nil {
+		th.ClientPorts = transportAddrsPorts(th.DestAddr, th.RTCPMux)
+	}
+
+	if !isRequest {
+		if th.ServerPorts == nil {
+			th.ServerPorts = transportAddrsPorts(th.SrcAddr, th.RTCPMux)
+		}
+		if th.Source == nil {
+			th.Source = transportAddrsIP(th.SrcAddr)
+		}
+	}
+}
+
+func transportAddrsFromPorts(ip net.IP, ports [2]int, rtcpMux bool) []headers.TransportAddr {
+	var host string
+	if ip != nil {
+		host = ip.String()
+	}
+
+	if rtcpMux {
+		return []headers.TransportAddr{
+			{Host: host, Port: ports[0]},
+		}
+	}
+
+	return []headers.TransportAddr{
+		{Host: host, Port: ports[0]},
+		{Host: host, Port: ports[1]},
+	}
+}
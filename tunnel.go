@@ -0,0 +1,25 @@
+package gortsplib
+
+// Tunnel is a method to tunnel RTSP connections into other protocols.
+type Tunnel int
+
+// tunnels.
+const (
+	TunnelNone Tunnel = iota
+	TunnelHTTP
+	TunnelWebSocket
+)
+
+var tunnelLabels = map[Tunnel]string{
+	TunnelNone:      "none",
+	TunnelHTTP:      "HTTP",
+	TunnelWebSocket: "WebSocket",
+}
+
+// String implements fmt.Stringer.
+func (t Tunnel) String() string {
+	if l, ok := tunnelLabels[t]; ok {
+		return l
+	}
+	return "unknown"
+}
@@ -0,0 +1,321 @@
+package gortsplib
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/net/ipv4"
+)
+
+const (
+	// maximum number of packets that are read or written with a single system call.
+	udpBatchSize = 32
+
+	// maximum number of segments of a UDP GSO packet.
+	udpGSOMaxSegments = 64
+
+	// maximum size of a UDP GSO packet.
+	udpGSOMaxSize = 65507
+
+	// maximum number of packets that are queued for writing.
+	udpBatchQueueSize = 1024
+)
+
+// udpReadBatch reads packets from a UDP socket in batches (recvmmsg),
+// until an error occurs.
+// If onPacket returns true, the buffer is retained and a new one is allocated.
+func udpReadBatch(pc *net.UDPConn, onPacket func(buf []byte, addr *net.UDPAddr) bool) error {
+	bpc := ipv4.NewPacketConn(pc)
+
+	msgs := make([]ipv4.Message, udpBatchSize)
+	for i := range msgs {
+		msgs[i].Buffers = [][]byte{make([]byte, udpMaxPayloadSize+1)}
+	}
+
+	for {
+		n, err := bpc.ReadBatch(msgs, 0)
+		if err != nil {
+			return err
+		}
+
+		for i := range msgs[:n] {
+			addr, ok := msgs[i].Addr.(*net.UDPAddr)
+			if !ok {
+				continue
+			}
+
+			if onPacket(msgs[i].Buffers[0][:msgs[i].N], addr) {
+				msgs[i].Buffers[0] = make([]byte, udpMaxPayloadSize+1)
+			}
+		}
+	}
+}
+
+type udpBatchWrite struct {
+	buf     []byte
+	addr    *net.UDPAddr
+	shared  *serverStreamPacket // released once the packet has been sent
+	onError func(error)         // called when the packet cannot be sent
+	err     error
+}
+
+// udpBatchWriter writes packets to a UDP socket in batches (sendmmsg).
+// Writes are asynchronous: packets are queued and sent by a dedicated routine,
+// together with all packets queued in the meanwhile by other routines (i.e. other sessions).
+// Writers are blocked only when the queue is full.
+// Packets with the same destination and size are merged with UDP GSO, when available.
+type udpBatchWriter struct {
+	pc           *net.UDPConn
+	writeTimeout time.Duration
+
+	bpc       *ipv4.PacketConn
+	gso       bool
+	mutex     sync.RWMutex
+	closed    bool
+	msgs      []ipv4.Message
+	msgWrites []int
+
+	// in
+	queue     chan udpBatchWrite
+	terminate chan struct{}
+
+	// out
+	done chan struct{}
+}
+
+func (w *udpBatchWriter) initialize() {
+	w.bpc = ipv4.NewPacketConn(w.pc)
+	w.gso = udpGSOSupported(w.pc)
+	w.msgs = make([]ipv4.Message, udpBatchSize)
+	w.msgWrites = make([]int, udpBatchSize)
+	w.queue = make(chan udpBatchWrite, udpBatchQueueSize)
+	w.terminate = make(chan struct{})
+	w.done = make(chan struct{})
+
+	go w.run()
+}
+
+func (w *udpBatchWriter) close() {
+	close(w.terminate)
+	<-w.done
+
+	// writers that are still queueing packets return once terminate is closed.
+	w.mutex.Lock()
+	w.closed = true
+	w.mutex.Unlock()
+
+	for {
+		select {
+		case req := <-w.queue:
+			req.err = net.ErrClosed
+			req.complete()
+
+		default:
+			return
+		}
+	}
+}
+
+// write queues a packet.
+// Errors that occur after the packet has been queued are passed to onError.
+// If shared is not nil, it is released once the packet has been sent.
+func (w *udpBatchWriter) write(
+	buf []byte,
+	addr *net.UDPAddr,
+	shared *serverStreamPacket,
+	onError func(error),
+) error {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	if !w.closed {
+		select {
+		case w.queue <- udpBatchWrite{buf: buf, addr: addr, shared: shared, onError: onError}:
+			return nil
+
+		case <-w.terminate:
+		}
+	}
+
+	if shared != nil {
+		shared.release()
+	}
+	return net.ErrClosed
+}
+
+func (w *udpBatchWriter) run() {
+	defer close(w.done)
+
+	batch := make([]udpBatchWrite, 0, udpBatchQueueSize)
+
+	for {
+		select {
+		case req := <-w.queue:
+			batch = append(batch[:0], req)
+
+		case <-w.terminate:
+			return
+		}
+
+		// add all packets queued in the meanwhile.
+	collect:
+		for len(batch) < cap(batch) {
+			select {
+			case req := <-w.queue:
+				batch = append(batch, req)
+
+			default:
+				break collect
+			}
+		}
+
+		// group packets by destination, in order to merge them with GSO.
+		// Packets with the same destination keep their order.
+		if w.gso && len(batch) > 1 {
+			slices.SortStableFunc(batch, compareUDPBatchWrites)
+		}
+
+		w.writeBatch(batch)
+
+		for i := range batch {
+			batch[i].complete()
+			batch[i] = udpBatchWrite{}
+		}
+	}
+}
+
+func (w *udpBatchWriter) writeBatch(batch []udpBatchWrite) {
+	for len(batch) != 0 {
+		n := w.fillMessages(batch)
+
+		processed, err := w.sendMessages(w.msgs[:n], w.msgWrites[:n], batch)
+
+		for _, count := range w.msgWrites[:processed] {
+			batch = batch[count:]
+		}
+
+		// a deadline error affects all remaining packets.
+		if err != nil {
+			for i := range batch {
+				batch[i].err = err
+			}
+			return
+		}
+	}
+}
+
+// fillMessages fills messages with the first packets of the batch,
+// and returns the number of messages.
+func (w *udpBatchWriter) fillMessages(batch []udpBatchWrite) int {
+	n := 0
+	i := 0
+
+	for i < len(batch) && n < udpBatchSize {
+		msg := &w.msgs[n]
+		msg.Buffers = append(msg.Buffers[:0], batch[i].buf)
+		msg.OOB = msg.OOB[:0]
+		msg.Addr = batch[i].addr
+		count := 1
+
+		if w.gso {
+			size := len(batch[i].buf)
+			total := size
+
+			for j := i + 1; j < len(batch) && count < udpGSOMaxSegments; j++ {
+				next := &batch[j]
+				if !next.addr.IP.Equal(batch[i].addr.IP) || next.addr.Port != batch[i].addr.Port ||
+					len(next.buf) > size || (total+len(next.buf)) > udpGSOMaxSize {
+					break
+				}
+
+				msg.Buffers = append(msg.Buffers, next.buf)
+				total += len(next.buf)
+				count++
+
+				// only the last segment can be smaller than the others.
+				if len(next.buf) != size {
+					break
+				}
+			}
+
+			if count > 1 {
+				msg.OOB = appendUDPGSOControl(msg.OOB, uint16(size))
+			}
+		}
+
+		w.msgWrites[n] = count
+		i += count
+		n++
+	}
+
+	return n
+}
+
+// sendMessages sends messages, assigns errors to the related packets
+// and returns the number of processed messages.
+func (w *udpBatchWriter) sendMessages(
+	msgs []ipv4.Message,
+	counts []int,
+	batch []udpBatchWrite,
+) (int, error) {
+	i := 0
+	offset := 0
+
+	for i < len(msgs) {
+		w.pc.SetWriteDeadline(time.Now().Add(w.writeTimeout)) //nolint:errcheck
+		sent, err := w.bpc.WriteBatch(msgs[i:], 0)
+		sent = max(sent, 0)
+
+		for _, count := range counts[i : i+sent] {
+			offset += count
+		}
+		i += sent
+
+		if err == nil {
+			continue
+		}
+
+		// GSO is not supported by the network interface,
+		// disable it and send the remaining packets again.
+		if w.gso && isUDPGSOError(err) {
+			w.gso = false
+			return i, nil
+		}
+
+		var nerr net.Error
+		if errors.As(err, &nerr) && nerr.Timeout() {
+			return i, err
+		}
+
+		// other errors affect the first unsent message only.
+		for j := range batch[offset : offset+counts[i]] {
+			batch[offset+j].err = err
+		}
+		offset += counts[i]
+		i++
+	}
+
+	return i, nil
+}
+
+func (r *udpBatchWrite) complete() {
+	if r.shared != nil {
+		r.shared.release()
+	}
+
+	if r.err != nil && r.onError != nil {
+		r.onError(r.err)
+	}
+}
+
+func compareUDPBatchWrites(a udpBatchWrite, b udpBatchWrite) int {
+	if c := bytes.Compare(a.addr.IP, b.addr.IP); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.addr.Port, b.addr.Port)
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package gortsplib
+
+import (
+	"net"
+)
+
+// batched UDP I/O is available on Linux only.
+const udpBatchSupported = false
+
+func udpGSOSupported(_ *net.UDPConn) bool {
+	return false
+}
+
+func appendUDPGSOControl(oob []byte, _ uint16) []byte {
+	return oob
+}
+
+func isUDPGSOError(_ error) bool {
+	return false
+}
@@ -0,0 +1,48 @@
+//go:build linux
+
+package gortsplib
+
+import (
+	"errors"
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const udpBatchSupported = true
+
+// udpGSOSupported checks whether the kernel supports UDP generic segmentation offload.
+func udpGSOSupported(pc *net.UDPConn) bool {
+	rc, err := pc.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	var serr error
+	err = rc.Control(func(fd uintptr) {
+		_, serr = unix.GetsockoptInt(int(fd), unix.SOL_UDP, unix.UDP_SEGMENT)
+	})
+
+	return err == nil && serr == nil
+}
+
+// appendUDPGSOControl appends a control message that splits a packet into segments of given size.
+func appendUDPGSOControl(oob []byte, size uint16) []byte {
+	start := len(oob)
+	oob = append(oob, make([]byte, unix.CmsgSpace(2))...)
+
+	h := (*unix.Cmsghdr)(unsafe.Pointer(&oob[start]))
+	h.Level = unix.SOL_UDP
+	h.Type = unix.UDP_SEGMENT
+	h.SetLen(unix.CmsgLen(2))
+
+	*(*uint16)(unsafe.Pointer(&oob[start+unix.CmsgLen(0)])) = size
+
+	return oob
+}
+
+// isUDPGSOError checks whether an error is caused by a network interface that doesn't support GSO.
+func isUDPGSOError(err error) bool {
+	return errors.Is(err, unix.EIO)
+}
@@ -0,0 +1,214 @@
+package gortsplib
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUDPBatchWriter(t *testing.T) {
+	if !udpBatchSupported {
+		t.Skip("batched UDP I/O is not supported")
+	}
+
+	pc, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	w := &udpBatchWriter{
+		pc:           pc.(*net.UDPConn),
+		writeTimeout: 5 * time.Second,
+	}
+	w.initialize()
+	defer w.close()
+
+	var receivers []*net.UDPConn
+
+	for i := 0; i < 2; i++ {
+		var r net.PacketConn
+		r, err = net.ListenPacket("udp4", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer r.Close()
+		receivers = append(receivers, r.(*net.UDPConn))
+	}
+
+	// packets with the same destination and size are merged with GSO, if available.
+	var batch []udpBatchWrite
+	var expected [][][]byte
+
+	for i, size := range []int{1000, 1000, 1000, 400, 1000, 200} {
+		ri := 0
+		if i == 4 {
+			ri = 1
+		}
+
+		buf := bytes.Repeat([]byte{byte(i)}, size)
+		batch = append(batch, udpBatchWrite{
+			buf:  buf,
+			addr: receivers[ri].LocalAddr().(*net.UDPAddr),
+		})
+
+		for len(expected) <= ri {
+			expected = append(expected, nil)
+		}
+		expected[ri] = append(expected[ri], buf)
+	}
+
+	w.writeBatch(batch)
+
+	for i := range batch {
+		require.NoError(t, batch[i].err)
+	}
+
+	for ri, r := range receivers {
+		for _, exp := range expected[ri] {
+			buf := make([]byte, 2048)
+			r.SetReadDeadline(time.Now().Add(2 * time.Second))
+			var n int
+			n, _, err = r.ReadFrom(buf)
+			require.NoError(t, err)
+			require.Equal(t, exp, buf[:n])
+		}
+	}
+
+	// errors are assigned to the failed packet only.
+	invalidAddr := &net.UDPAddr{IP: net.ParseIP("::1"), Port: 1234}
+	batch = []udpBatchWrite{
+		{buf: []byte{1, 2, 3, 4}, addr: invalidAddr},
+		{buf: []byte{5, 6, 7, 8}, addr: receivers[0].LocalAddr().(*net.UDPAddr)},
+	}
+	w.writeBatch(batch)
+	require.Error(t, batch[0].err)
+	require.NoError(t, batch[1].err)
+
+	buf := make([]byte, 2048)
+	receivers[0].SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := receivers[0].ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, []byte{5, 6, 7, 8}, buf[:n])
+
+	// errors that occur after packets have been queued are passed to onError.
+	writeErr := make(chan error)
+	err = w.write([]byte{1, 2, 3, 4}, invalidAddr, nil, func(err2 error) {
+		writeErr <- err2
+	})
+	require.NoError(t, err)
+	require.Error(t, <-writeErr)
+
+	// concurrent writes. Shared packets are released once sent.
+	shared := &serverStreamPacket{}
+	shared.acquire(10)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err2 := w.write([]byte{1, 2, 3, 4}, receivers[0].LocalAddr().(*net.UDPAddr), shared, nil)
+			require.NoError(t, err2)
+		}()
+	}
+
+	wg.Wait()
+
+	for i := 0; i < 10; i++ {
+		buf := make([]byte, 2048)
+		receivers[0].SetReadDeadline(time.Now().Add(2 * time.Second))
+		var n int
+		n, _, err = receivers[0].ReadFrom(buf)
+		require.NoError(t, err)
+		require.Equal(t, []byte{1, 2, 3, 4}, buf[:n])
+	}
+
+	require.Eventually(t, func() bool {
+		return shared.refs.Load() == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestUDPBatchWriterClosed(t *testing.T) {
+	pc, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	w := &udpBatchWriter{
+		pc:           pc.(*net.UDPConn),
+		writeTimeout: 5 * time.Second,
+	}
+	w.initialize()
+	w.close()
+
+	shared := &serverStreamPacket{}
+	shared.acquire(1)
+
+	err = w.write([]byte{1, 2, 3, 4}, pc.LocalAddr().(*net.UDPAddr), shared, nil)
+	require.ErrorIs(t, err, net.ErrClosed)
+	require.Equal(t, int32(0), shared.refs.Load())
+}
+
+// BenchmarkServerUDPListenerWrite measures the packets per second that are
+// sent to readers of a stream, with and without batched UDP I/O.
+func BenchmarkServerUDPListenerWrite(b *testing.B) {
+	const readerCount = 200
+
+	for _, ca := range []string{"standard", "batch"} {
+		b.Run(ca, func(b *testing.B) {
+			if ca == "batch" && !udpBatchSupported {
+				b.Skip("batched UDP I/O is not supported")
+			}
+
+			l := &serverUDPListener{
+				listenPacket: net.ListenPacket,
+				writeTimeout: 5 * time.Second,
+				batchIO:      ca == "batch",
+				address:      "127.0.0.1:0",
+			}
+			err := l.initialize()
+			require.NoError(b, err)
+			defer l.close()
+
+			addrs := make([]*net.UDPAddr, readerCount)
+
+			for i := range addrs {
+				var r net.PacketConn
+				r, err = net.ListenPacket("udp4", "127.0.0.1:0")
+				require.NoError(b, err)
+				defer r.Close()
+				addrs[i] = r.LocalAddr().(*net.UDPAddr)
+			}
+
+			buf := bytes.Repeat([]byte{1}, 1200)
+
+			// packets are released once sent.
+			shared := &serverStreamPacket{}
+			shared.acquire(readerCount * (b.N/readerCount + 1))
+
+			b.ResetTimer()
+
+			// every reader is served by its own routine, like server sessions.
+			var wg sync.WaitGroup
+
+			for i := range addrs {
+				wg.Add(1)
+				go func(addr *net.UDPAddr, count int) {
+					defer wg.Done()
+					for j := 0; j < count; j++ {
+						l.writeShared(buf, addr, shared, nil) //nolint:errcheck
+					}
+				}(addrs[i], b.N/readerCount+1)
+			}
+
+			wg.Wait()
+
+			for shared.refs.Load() != 0 {
+				time.Sleep(100 * time.Microsecond)
+			}
+
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "packets/s")
+		})
+	}
+}
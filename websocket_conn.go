@@ -0,0 +1,52 @@
+package gortsplib
+
+import (
+	"net"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	webSocketSubprotocol = "rtsp.onvif.org"
+)
+
+// webSocketConn is a net.Conn that sends and receives data through WebSocket binary frames.
+// Unlike websocket.Conn, it exposes the addresses of the underlying connection.
+type webSocketConn struct {
+	*websocket.Conn
+	nconn net.Conn
+
+	closeOnce sync.Once
+	closeErr  error
+	done      chan struct{}
+}
+
+func newWebSocketConn(ws *websocket.Conn, nconn net.Conn) *webSocketConn {
+	ws.PayloadType = websocket.BinaryFrame
+
+	return &webSocketConn{
+		Conn:  ws,
+		nconn: nconn,
+		done:  make(chan struct{}),
+	}
+}
+
+// Close implements net.Conn.
+func (c *webSocketConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.closeErr = c.Conn.Close()
+		close(c.done)
+	})
+	return c.closeErr
+}
+
+// LocalAddr implements net.Conn.
+func (c *webSocketConn) LocalAddr() net.Addr {
+	return c.nconn.LocalAddr()
+}
+
+// RemoteAddr implements net.Conn.
+func (c *webSocketConn) RemoteAddr() net.Addr {
+	return c.nconn.RemoteAddr()
+}